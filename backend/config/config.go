@@ -0,0 +1,500 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config アプリケーション設定
+type Config struct {
+	Server      ServerConfig     `yaml:"server"`
+	Database    DatabaseConfig   `yaml:"database"`
+	Auth        AuthConfig       `yaml:"auth"`
+	Payroll     PayrollConfig    `yaml:"payroll"`
+	Scheduling  SchedulingConfig `yaml:"scheduling"`
+	ShiftTrades ShiftTradeConfig `yaml:"shift_trades"`
+	OpenShifts  OpenShiftConfig  `yaml:"open_shifts"`
+	Labor       LaborConfig      `yaml:"labor"`
+	Attendance  AttendanceConfig `yaml:"attendance"`
+}
+
+// ServerConfig HTTPサーバー設定
+type ServerConfig struct {
+	ListenAddr     string   `yaml:"listen_addr"`
+	AllowedOrigins []string `yaml:"allowed_origins"`
+	// EnableTestEndpoints テストユーザー作成用エンドポイントを公開するか（開発用）
+	EnableTestEndpoints bool `yaml:"enable_test_endpoints"`
+}
+
+// DatabaseConfig データベース接続設定
+type DatabaseConfig struct {
+	Host            string   `yaml:"host"`
+	Port            int      `yaml:"port"`
+	User            string   `yaml:"user"`
+	Password        string   `yaml:"password"`
+	Name            string   `yaml:"name"`
+	SSLMode         string   `yaml:"sslmode"`
+	MaxOpenConns    int      `yaml:"max_open_conns"`
+	MaxIdleConns    int      `yaml:"max_idle_conns"`
+	ConnMaxLifetime Duration `yaml:"conn_max_lifetime"`
+}
+
+// DSN lib/pq 用の接続文字列
+func (d DatabaseConfig) DSN() string {
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		quoteDSN(d.Host), d.Port, quoteDSN(d.User), quoteDSN(d.Password), quoteDSN(d.Name), quoteDSN(d.SSLMode))
+}
+
+// AuthConfig 認証設定
+type AuthConfig struct {
+	SessionIdleTimeout     Duration `yaml:"session_idle_timeout"`
+	SessionAbsoluteTimeout Duration `yaml:"session_absolute_timeout"`
+	AccessTokenTTL         Duration `yaml:"access_token_ttl"`
+	// SigningKeys kid -> 署名鍵
+	SigningKeys map[string]string `yaml:"signing_keys"`
+	ActiveKeyID string            `yaml:"active_key_id"`
+}
+
+// PayrollConfig 給与計算設定
+type PayrollConfig struct {
+	// DefaultHourlyWage 時給設定がない従業員に適用する時給
+	DefaultHourlyWage int `yaml:"default_hourly_wage"`
+}
+
+// SchedulingConfig 自動シフト作成の設定（ジョブごとに上書きできる）
+type SchedulingConfig struct {
+	// MaxWeeklyHours 1週間（日曜始まり）の勤務時間の上限（シフト交代の引き受け可否の判定にも使う）
+	MaxWeeklyHours int `yaml:"max_weekly_hours"`
+	// MinRestInterval 勤務日が異なるシフトの間に必要な休息時間（勤務間インターバル）
+	// シフトの作成・更新時の確認（labor.rest_interval）にも使う。0 の場合は確認しない
+	MinRestInterval Duration `yaml:"min_rest_interval"`
+	// Availability 担当者の勤務可能な時間帯の外にあるシフトの作成・更新時の扱い
+	// "error"（シフトを保存しない）/ "warning"（保存して警告を返す）/ "off"（確認しない）
+	Availability string `yaml:"availability"`
+}
+
+// ShiftTradeConfig シフト交代（交換・譲渡）の設定
+type ShiftTradeConfig struct {
+	// AutoApprove 引き受けられた時点でオーナーの承認なしに担当者を変更する
+	AutoApprove bool `yaml:"auto_approve"`
+}
+
+// OpenShiftConfig 担当者が決まっていないシフトの募集の設定
+type OpenShiftConfig struct {
+	// ClaimMode 応募の扱い（"first_come": 先着順で担当者に決める / "approval": オーナーが応募者から選ぶ）
+	ClaimMode string `yaml:"claim_mode"`
+}
+
+// LaborConfig 労働基準法に基づくシフトの確認の設定
+// ルールごとの扱いは "error"（シフトを保存しない）/ "warning"（保存して警告を返す）/ "off"（確認しない）で指定する
+type LaborConfig struct {
+	// DailyHours 1日の勤務時間（休憩を除く）の上限
+	DailyHours int `yaml:"daily_hours"`
+	// WeeklyHours 1週間（日曜始まり）の勤務時間（休憩を除く）の上限（特例措置対象事業場は44）
+	WeeklyHours int `yaml:"weekly_hours"`
+	// BreakOver6h 6時間を超える勤務に45分以上の休憩がない
+	BreakOver6h string `yaml:"break_over_6h"`
+	// BreakOver8h 8時間を超える勤務に60分以上の休憩がない
+	BreakOver8h string `yaml:"break_over_8h"`
+	// DailyLimit 1日の勤務時間が DailyHours を超える
+	DailyLimit string `yaml:"daily_limit"`
+	// WeeklyLimit 1週間の勤務時間が WeeklyHours を超える
+	WeeklyLimit string `yaml:"weekly_limit"`
+	// WeeklyRestDay 1週間に休日がない
+	WeeklyRestDay string `yaml:"weekly_rest_day"`
+	// RestInterval 勤務日が異なるシフトの間の休息時間が scheduling.min_rest_interval に満たない
+	RestInterval string `yaml:"rest_interval"`
+}
+
+// AttendanceConfig 出退勤記録と予定のシフトの照合の設定
+type AttendanceConfig struct {
+	// LateGrace 出勤がシフトの開始からこの時間以内の遅れなら遅刻としない
+	LateGrace Duration `yaml:"late_grace"`
+	// EarlyLeaveGrace 退勤がシフトの終了よりこの時間以内の早さなら早退としない
+	EarlyLeaveGrace Duration `yaml:"early_leave_grace"`
+	// OvertimeGrace 退勤がシフトの終了からこの時間以内の遅れなら残業としない
+	OvertimeGrace Duration `yaml:"overtime_grace"`
+	// NoShowGrace シフトの開始からこの時間を過ぎても出勤がない場合は無断欠勤とする
+	NoShowGrace Duration `yaml:"no_show_grace"`
+}
+
+// Severities ルールごとの扱い
+func (l LaborConfig) Severities() map[string]string {
+	return map[string]string{
+		"break_over_6h":   l.BreakOver6h,
+		"break_over_8h":   l.BreakOver8h,
+		"daily_limit":     l.DailyLimit,
+		"weekly_limit":    l.WeeklyLimit,
+		"weekly_rest_day": l.WeeklyRestDay,
+		"rest_interval":   l.RestInterval,
+	}
+}
+
+// Duration YAMLで "2h" "15m" のように指定できる time.Duration
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalYAML 文字列を time.Duration として読み込む
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	parsed, err := time.ParseDuration(value.Value)
+	if err != nil {
+		return fmt.Errorf("期間の形式が不正です: %q", value.Value)
+	}
+	d.Duration = parsed
+	return nil
+}
+
+// Default デフォルト設定
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			ListenAddr:     ":8080",
+			AllowedOrigins: []string{"http://localhost:3000", "http://127.0.0.1:3000", "null"},
+		},
+		Database: DatabaseConfig{
+			Host:            "localhost",
+			Port:            5432,
+			User:            "postgres",
+			Name:            "shift-management",
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: Duration{30 * time.Minute},
+		},
+		Auth: AuthConfig{
+			SessionIdleTimeout:     Duration{2 * time.Hour},
+			SessionAbsoluteTimeout: Duration{7 * 24 * time.Hour},
+			AccessTokenTTL:         Duration{15 * time.Minute},
+		},
+		Payroll: PayrollConfig{
+			DefaultHourlyWage: 1000,
+		},
+		Scheduling: SchedulingConfig{
+			MaxWeeklyHours:  40,
+			MinRestInterval: Duration{11 * time.Hour},
+			Availability:    "warning",
+		},
+		OpenShifts: OpenShiftConfig{
+			ClaimMode: "first_come",
+		},
+		Labor: LaborConfig{
+			DailyHours:    8,
+			WeeklyHours:   40,
+			BreakOver6h:   "error",
+			BreakOver8h:   "error",
+			DailyLimit:    "warning",
+			WeeklyLimit:   "warning",
+			WeeklyRestDay: "warning",
+			RestInterval:  "error",
+		},
+		Attendance: AttendanceConfig{
+			LateGrace:       Duration{5 * time.Minute},
+			EarlyLeaveGrace: Duration{5 * time.Minute},
+			OvertimeGrace:   Duration{15 * time.Minute},
+			NoShowGrace:     Duration{30 * time.Minute},
+		},
+	}
+}
+
+// Load 設定を読み込む
+// デフォルト値 → 設定ファイル（path が空でなければ）→ 環境変数 の順に上書きし、最後に検証する
+func Load(path string) (Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Config{}, fmt.Errorf("設定ファイルの読み込みに失敗しました: %v", err)
+		}
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return Config{}, fmt.Errorf("設定ファイルの解析に失敗しました: %v", err)
+		}
+	}
+
+	if err := applyEnv(&cfg); err != nil {
+		return Config{}, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+// applyEnv 環境変数で設定を上書き
+func applyEnv(cfg *Config) error {
+	var errs []error
+	str := func(name string, dst *string) {
+		if v, ok := os.LookupEnv(name); ok {
+			*dst = v
+		}
+	}
+	integer := func(name string, dst *int) {
+		if v, ok := os.LookupEnv(name); ok {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s は整数で指定してください: %q", name, v))
+				return
+			}
+			*dst = n
+		}
+	}
+	duration := func(name string, dst *Duration) {
+		if v, ok := os.LookupEnv(name); ok {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s は期間（例: 15m, 2h）で指定してください: %q", name, v))
+				return
+			}
+			dst.Duration = d
+		}
+	}
+	boolean := func(name string, dst *bool) {
+		if v, ok := os.LookupEnv(name); ok {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s は true/false で指定してください: %q", name, v))
+				return
+			}
+			*dst = b
+		}
+	}
+
+	// サーバー
+	str("LISTEN_ADDR", &cfg.Server.ListenAddr)
+	if port, ok := os.LookupEnv("PORT"); ok && os.Getenv("LISTEN_ADDR") == "" {
+		cfg.Server.ListenAddr = ":" + port
+	}
+	if v, ok := os.LookupEnv("CORS_ALLOWED_ORIGINS"); ok {
+		cfg.Server.AllowedOrigins = splitList(v)
+	}
+	boolean("ENABLE_TEST_ENDPOINTS", &cfg.Server.EnableTestEndpoints)
+
+	// データベース
+	str("DB_HOST", &cfg.Database.Host)
+	integer("DB_PORT", &cfg.Database.Port)
+	str("DB_USER", &cfg.Database.User)
+	str("DB_PASSWORD", &cfg.Database.Password)
+	str("DB_NAME", &cfg.Database.Name)
+	str("DB_SSLMODE", &cfg.Database.SSLMode)
+	integer("DB_MAX_OPEN_CONNS", &cfg.Database.MaxOpenConns)
+	integer("DB_MAX_IDLE_CONNS", &cfg.Database.MaxIdleConns)
+	duration("DB_CONN_MAX_LIFETIME", &cfg.Database.ConnMaxLifetime)
+
+	// 認証
+	duration("SESSION_IDLE_TIMEOUT", &cfg.Auth.SessionIdleTimeout)
+	duration("SESSION_ABSOLUTE_TIMEOUT", &cfg.Auth.SessionAbsoluteTimeout)
+	duration("ACCESS_TOKEN_TTL", &cfg.Auth.AccessTokenTTL)
+	if v, ok := os.LookupEnv("JWT_SIGNING_KEYS"); ok {
+		keys, err := parseSigningKeys(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("JWT_SIGNING_KEYS: %v", err))
+		} else {
+			cfg.Auth.SigningKeys = keys
+		}
+	}
+	str("JWT_ACTIVE_KEY_ID", &cfg.Auth.ActiveKeyID)
+
+	// 給与
+	integer("DEFAULT_HOURLY_WAGE", &cfg.Payroll.DefaultHourlyWage)
+
+	// 自動シフト作成
+	integer("SCHEDULING_MAX_WEEKLY_HOURS", &cfg.Scheduling.MaxWeeklyHours)
+	duration("SCHEDULING_MIN_REST_INTERVAL", &cfg.Scheduling.MinRestInterval)
+	str("SCHEDULING_AVAILABILITY", &cfg.Scheduling.Availability)
+
+	// シフト交代
+	boolean("SHIFT_TRADE_AUTO_APPROVE", &cfg.ShiftTrades.AutoApprove)
+
+	// 募集中のシフト
+	str("OPEN_SHIFT_CLAIM_MODE", &cfg.OpenShifts.ClaimMode)
+
+	// 労働基準法に基づく確認
+	integer("LABOR_DAILY_HOURS", &cfg.Labor.DailyHours)
+	integer("LABOR_WEEKLY_HOURS", &cfg.Labor.WeeklyHours)
+	str("LABOR_BREAK_OVER_6H", &cfg.Labor.BreakOver6h)
+	str("LABOR_BREAK_OVER_8H", &cfg.Labor.BreakOver8h)
+	str("LABOR_DAILY_LIMIT", &cfg.Labor.DailyLimit)
+	str("LABOR_WEEKLY_LIMIT", &cfg.Labor.WeeklyLimit)
+	str("LABOR_WEEKLY_REST_DAY", &cfg.Labor.WeeklyRestDay)
+	str("LABOR_REST_INTERVAL", &cfg.Labor.RestInterval)
+
+	// 出退勤記録とシフトの照合
+	duration("ATTENDANCE_LATE_GRACE", &cfg.Attendance.LateGrace)
+	duration("ATTENDANCE_EARLY_LEAVE_GRACE", &cfg.Attendance.EarlyLeaveGrace)
+	duration("ATTENDANCE_OVERTIME_GRACE", &cfg.Attendance.OvertimeGrace)
+	duration("ATTENDANCE_NO_SHOW_GRACE", &cfg.Attendance.NoShowGrace)
+
+	return errors.Join(errs...)
+}
+
+// Validate 設定値を検証し、問題をまとめて返す
+func (c *Config) Validate() error {
+	var errs []error
+	add := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.Server.ListenAddr == "" {
+		add("server.listen_addr が設定されていません")
+	}
+	if len(c.Server.AllowedOrigins) == 0 {
+		add("server.allowed_origins が設定されていません")
+	}
+
+	if c.Database.Host == "" {
+		add("database.host が設定されていません")
+	}
+	if c.Database.Port <= 0 || c.Database.Port > 65535 {
+		add("database.port は1-65535の範囲で指定してください: %d", c.Database.Port)
+	}
+	if c.Database.User == "" {
+		add("database.user が設定されていません")
+	}
+	if c.Database.Name == "" {
+		add("database.name が設定されていません")
+	}
+	switch c.Database.SSLMode {
+	case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+	default:
+		add("database.sslmode が不正です: %q", c.Database.SSLMode)
+	}
+	if c.Database.MaxOpenConns < 0 || c.Database.MaxIdleConns < 0 {
+		add("database のコネクションプールサイズは0以上で指定してください")
+	}
+	if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		add("database.max_idle_conns は max_open_conns 以下にしてください")
+	}
+
+	if c.Auth.SessionIdleTimeout.Duration <= 0 {
+		add("auth.session_idle_timeout は正の期間で指定してください")
+	}
+	if c.Auth.SessionAbsoluteTimeout.Duration < c.Auth.SessionIdleTimeout.Duration {
+		add("auth.session_absolute_timeout は session_idle_timeout 以上にしてください")
+	}
+	if c.Auth.AccessTokenTTL.Duration <= 0 {
+		add("auth.access_token_ttl は正の期間で指定してください")
+	}
+	if len(c.Auth.SigningKeys) == 0 {
+		add("auth.signing_keys（JWT_SIGNING_KEYS）が設定されていません")
+	}
+	for kid, key := range c.Auth.SigningKeys {
+		if len(key) < 32 {
+			add("署名鍵 %q は32バイト以上必要です", kid)
+		}
+	}
+	if c.Auth.ActiveKeyID == "" && len(c.Auth.SigningKeys) == 1 {
+		for kid := range c.Auth.SigningKeys {
+			c.Auth.ActiveKeyID = kid
+		}
+	}
+	if _, ok := c.Auth.SigningKeys[c.Auth.ActiveKeyID]; len(c.Auth.SigningKeys) > 0 && !ok {
+		add("auth.active_key_id（JWT_ACTIVE_KEY_ID）に対応する署名鍵がありません: %q", c.Auth.ActiveKeyID)
+	}
+
+	if c.Payroll.DefaultHourlyWage <= 0 {
+		add("payroll.default_hourly_wage は1円以上で指定してください")
+	}
+
+	if c.Scheduling.MaxWeeklyHours <= 0 || c.Scheduling.MaxWeeklyHours > 168 {
+		add("scheduling.max_weekly_hours は1-168の範囲で指定してください: %d", c.Scheduling.MaxWeeklyHours)
+	}
+	if c.Scheduling.MinRestInterval.Duration < 0 || c.Scheduling.MinRestInterval.Duration > 24*time.Hour {
+		add("scheduling.min_rest_interval は0-24時間の範囲で指定してください")
+	}
+	switch c.Scheduling.Availability {
+	case "error", "warning", "off":
+	default:
+		add("scheduling.availability は error、warning、off のいずれかで指定してください: %q", c.Scheduling.Availability)
+	}
+
+	switch c.OpenShifts.ClaimMode {
+	case "first_come", "approval":
+	default:
+		add("open_shifts.claim_mode は first_come または approval で指定してください: %q", c.OpenShifts.ClaimMode)
+	}
+
+	if c.Labor.DailyHours <= 0 || c.Labor.DailyHours > 24 {
+		add("labor.daily_hours は1-24の範囲で指定してください: %d", c.Labor.DailyHours)
+	}
+	if c.Labor.WeeklyHours <= 0 || c.Labor.WeeklyHours > 168 {
+		add("labor.weekly_hours は1-168の範囲で指定してください: %d", c.Labor.WeeklyHours)
+	}
+	for rule, severity := range c.Labor.Severities() {
+		switch severity {
+		case "error", "warning", "off":
+		default:
+			add("labor.%s は error、warning、off のいずれかで指定してください: %q", rule, severity)
+		}
+	}
+
+	for name, grace := range map[string]Duration{
+		"late_grace":        c.Attendance.LateGrace,
+		"early_leave_grace": c.Attendance.EarlyLeaveGrace,
+		"overtime_grace":    c.Attendance.OvertimeGrace,
+		"no_show_grace":     c.Attendance.NoShowGrace,
+	} {
+		if grace.Duration < 0 || grace.Duration > 24*time.Hour {
+			add("attendance.%s は0-24時間の範囲で指定してください", name)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("設定エラー:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// SigningKeyBytes 署名鍵をバイト列に変換
+func (a AuthConfig) SigningKeyBytes() map[string][]byte {
+	keys := make(map[string][]byte, len(a.SigningKeys))
+	for kid, key := range a.SigningKeys {
+		keys[kid] = []byte(key)
+	}
+	return keys
+}
+
+// parseSigningKeys "kid:secret" をカンマ区切りで並べた文字列を鍵のセットに変換
+func parseSigningKeys(raw string) (map[string]string, error) {
+	keys := make(map[string]string)
+	for _, entry := range splitList(raw) {
+		kid, secret, ok := strings.Cut(entry, ":")
+		if !ok || kid == "" || secret == "" {
+			return nil, errors.New("署名鍵の形式が不正です（kid:secret の形式で指定してください）")
+		}
+		if _, dup := keys[kid]; dup {
+			return nil, fmt.Errorf("署名鍵 %q が重複しています", kid)
+		}
+		keys[kid] = secret
+	}
+	return keys, nil
+}
+
+// splitList カンマ区切りの文字列を分割（空要素は除く）
+func splitList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// quoteDSN 接続文字列の値をエスケープ
+func quoteDSN(value string) string {
+	if value != "" && !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
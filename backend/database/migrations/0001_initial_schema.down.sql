@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS gantt_settings;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS shift_coverage;
+DROP TABLE IF EXISTS time_slots;
+DROP TABLE IF EXISTS hourly_wages;
+DROP TABLE IF EXISTS attendance;
+DROP TABLE IF EXISTS shift_requests;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS shifts;
+DROP TABLE IF EXISTS employees;
@@ -1,8 +1,8 @@
--- シフト管理アプリケーション データベーススキーマ
--- PostgreSQL用
+-- 初期スキーマ
+-- 既存環境（schema.sql を手動で実行した、または旧 CreateTables で作成した）でも適用できるよう IF NOT EXISTS を付けている
 
--- 1. employees（従業員）テーブル
-CREATE TABLE employees (
+-- 従業員
+CREATE TABLE IF NOT EXISTS employees (
     id SERIAL PRIMARY KEY,
     name VARCHAR(100) NOT NULL,
     email VARCHAR(255) UNIQUE,
@@ -12,8 +12,8 @@ CREATE TABLE employees (
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
--- 2. shifts（シフト）テーブル
-CREATE TABLE shifts (
+-- シフト
+CREATE TABLE IF NOT EXISTS shifts (
     id SERIAL PRIMARY KEY,
     employee_id INTEGER REFERENCES employees(id),
     date DATE NOT NULL,
@@ -24,8 +24,8 @@ CREATE TABLE shifts (
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
--- 3. users（認証）テーブル
-CREATE TABLE users (
+-- 認証ユーザー
+CREATE TABLE IF NOT EXISTS users (
     id SERIAL PRIMARY KEY,
     email VARCHAR(255) UNIQUE NOT NULL,
     password_hash VARCHAR(255) NOT NULL,
@@ -36,8 +36,8 @@ CREATE TABLE users (
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
--- 4. shift_requests（シフト希望）テーブル
-CREATE TABLE shift_requests (
+-- シフト希望
+CREATE TABLE IF NOT EXISTS shift_requests (
     id SERIAL PRIMARY KEY,
     employee_id INTEGER REFERENCES employees(id),
     date DATE NOT NULL,
@@ -48,8 +48,8 @@ CREATE TABLE shift_requests (
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
--- 5. attendance（出退勤記録）テーブル
-CREATE TABLE attendance (
+-- 出退勤記録
+CREATE TABLE IF NOT EXISTS attendance (
     id SERIAL PRIMARY KEY,
     employee_id INTEGER REFERENCES employees(id),
     date DATE NOT NULL,
@@ -61,8 +61,8 @@ CREATE TABLE attendance (
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
--- 6. hourly_wages（時給設定）テーブル
-CREATE TABLE hourly_wages (
+-- 時給設定
+CREATE TABLE IF NOT EXISTS hourly_wages (
     id SERIAL PRIMARY KEY,
     employee_id INTEGER REFERENCES employees(id),
     hourly_wage INTEGER NOT NULL,
@@ -71,8 +71,8 @@ CREATE TABLE hourly_wages (
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
--- 7. time_slots（時間帯設定）テーブル
-CREATE TABLE time_slots (
+-- 時間帯設定
+CREATE TABLE IF NOT EXISTS time_slots (
     id SERIAL PRIMARY KEY,
     day_of_week INTEGER NOT NULL CHECK (day_of_week BETWEEN 0 AND 6), -- 0=日曜日, 1=月曜日, ..., 6=土曜日
     start_time TIME NOT NULL,
@@ -83,8 +83,8 @@ CREATE TABLE time_slots (
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
--- 8. shift_coverage（シフトカバレッジ）テーブル
-CREATE TABLE shift_coverage (
+-- シフトカバレッジ
+CREATE TABLE IF NOT EXISTS shift_coverage (
     id SERIAL PRIMARY KEY,
     date DATE NOT NULL,
     time_slot_id INTEGER REFERENCES time_slots(id),
@@ -95,10 +95,8 @@ CREATE TABLE shift_coverage (
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
--- インデックスの作成（パフォーマンス向上）
-CREATE INDEX idx_shifts_employee_date ON shifts(employee_id, date);
--- 9. permissions（権限設定）テーブル
-CREATE TABLE permissions (
+-- 権限設定
+CREATE TABLE IF NOT EXISTS permissions (
     id SERIAL PRIMARY KEY,
     employee_id INTEGER REFERENCES employees(id) ON DELETE CASCADE,
     can_view_other_shifts BOOLEAN DEFAULT FALSE,
@@ -110,10 +108,20 @@ CREATE TABLE permissions (
     UNIQUE(employee_id)
 );
 
-CREATE INDEX idx_shifts_date ON shifts(date);
-CREATE INDEX idx_attendance_employee_date ON attendance(employee_id, date);
-CREATE INDEX idx_shift_requests_employee_date ON shift_requests(employee_id, date);
-CREATE INDEX idx_hourly_wages_employee_date ON hourly_wages(employee_id, effective_date);
-CREATE INDEX idx_time_slots_day_time ON time_slots(day_of_week, start_time);
-CREATE INDEX idx_shift_coverage_date ON shift_coverage(date);
-CREATE INDEX idx_permissions_employee_id ON permissions(employee_id); 
\ No newline at end of file
+-- ガントチャート設定
+CREATE TABLE IF NOT EXISTS gantt_settings (
+    id SERIAL PRIMARY KEY,
+    start_hour INTEGER NOT NULL CHECK (start_hour BETWEEN 0 AND 23),
+    end_hour INTEGER NOT NULL CHECK (end_hour BETWEEN 0 AND 48),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_shifts_employee_date ON shifts(employee_id, date);
+CREATE INDEX IF NOT EXISTS idx_shifts_date ON shifts(date);
+CREATE INDEX IF NOT EXISTS idx_attendance_employee_date ON attendance(employee_id, date);
+CREATE INDEX IF NOT EXISTS idx_shift_requests_employee_date ON shift_requests(employee_id, date);
+CREATE INDEX IF NOT EXISTS idx_hourly_wages_employee_date ON hourly_wages(employee_id, effective_date);
+CREATE INDEX IF NOT EXISTS idx_time_slots_day_time ON time_slots(day_of_week, start_time);
+CREATE INDEX IF NOT EXISTS idx_shift_coverage_date ON shift_coverage(date);
+CREATE INDEX IF NOT EXISTS idx_permissions_employee_id ON permissions(employee_id);
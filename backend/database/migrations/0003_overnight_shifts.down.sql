@@ -0,0 +1,9 @@
+ALTER TABLE attendance DROP CONSTRAINT IF EXISTS attendance_time_range_check;
+ALTER TABLE time_slots DROP CONSTRAINT IF EXISTS time_slots_time_range_check;
+ALTER TABLE shift_requests DROP CONSTRAINT IF EXISTS shift_requests_time_range_check;
+ALTER TABLE shifts DROP CONSTRAINT IF EXISTS shifts_time_range_check;
+
+ALTER TABLE attendance DROP COLUMN IF EXISTS clock_out_next_day;
+ALTER TABLE time_slots DROP COLUMN IF EXISTS ends_next_day;
+ALTER TABLE shift_requests DROP COLUMN IF EXISTS ends_next_day;
+ALTER TABLE shifts DROP COLUMN IF EXISTS ends_next_day;
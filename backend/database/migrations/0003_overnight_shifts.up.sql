@@ -0,0 +1,22 @@
+-- 日付をまたぐ勤務（例: 22:00〜翌3:00）に対応するため、終了時刻が翌日であることを示すフラグを追加する
+
+ALTER TABLE shifts ADD COLUMN ends_next_day BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE shift_requests ADD COLUMN ends_next_day BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE time_slots ADD COLUMN ends_next_day BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE attendance ADD COLUMN clock_out_next_day BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- 既存データで終了時刻が開始時刻以前のものは日付をまたぐ勤務として扱う
+UPDATE shifts SET ends_next_day = TRUE WHERE end_time <= start_time;
+UPDATE shift_requests SET ends_next_day = TRUE WHERE preferred_end_time <= preferred_start_time;
+UPDATE time_slots SET ends_next_day = TRUE WHERE end_time <= start_time;
+UPDATE attendance SET clock_out_next_day = TRUE WHERE clock_out_time < clock_in_time;
+
+-- 翌日終了でない場合は終了が開始より後、翌日終了の場合は終了が開始以前（24時間以内）
+ALTER TABLE shifts ADD CONSTRAINT shifts_time_range_check
+    CHECK (ends_next_day = (end_time <= start_time));
+ALTER TABLE shift_requests ADD CONSTRAINT shift_requests_time_range_check
+    CHECK (ends_next_day = (preferred_end_time <= preferred_start_time));
+ALTER TABLE time_slots ADD CONSTRAINT time_slots_time_range_check
+    CHECK (ends_next_day = (end_time <= start_time));
+ALTER TABLE attendance ADD CONSTRAINT attendance_time_range_check
+    CHECK (clock_in_time IS NULL OR clock_out_time IS NULL OR clock_out_next_day = (clock_out_time < clock_in_time));
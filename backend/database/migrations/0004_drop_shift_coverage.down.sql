@@ -0,0 +1,13 @@
+-- シフトカバレッジ
+CREATE TABLE IF NOT EXISTS shift_coverage (
+    id SERIAL PRIMARY KEY,
+    date DATE NOT NULL,
+    time_slot_id INTEGER REFERENCES time_slots(id),
+    position VARCHAR(50) NOT NULL,
+    required_count INTEGER NOT NULL,
+    actual_count INTEGER DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_shift_coverage_date ON shift_coverage(date);
@@ -0,0 +1,2 @@
+-- カバレッジは shifts から都度計算するため、書き込まれることのなかった shift_coverage を削除する
+DROP TABLE IF EXISTS shift_coverage;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS shift_segments;
+ALTER TABLE shifts DROP COLUMN IF EXISTS position;
+DROP TABLE IF EXISTS employee_positions;
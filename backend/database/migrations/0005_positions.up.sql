@@ -0,0 +1,21 @@
+-- 従業員が担当できるポジション（'ホール', 'キッチン', 'レジ' など time_slots.position と同じ値）
+CREATE TABLE IF NOT EXISTS employee_positions (
+    employee_id INTEGER NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    position VARCHAR(50) NOT NULL,
+    PRIMARY KEY (employee_id, position)
+);
+
+-- シフトの担当ポジション（未設定の場合は空文字）
+ALTER TABLE shifts ADD COLUMN position VARCHAR(50) NOT NULL DEFAULT '';
+
+-- シフト内で時間帯ごとに担当ポジションを変える場合の区間
+-- 時刻はシフトの勤務時間内で解釈する（シフト開始より前の時刻は翌日の時刻）
+CREATE TABLE IF NOT EXISTS shift_segments (
+    id SERIAL PRIMARY KEY,
+    shift_id INTEGER NOT NULL REFERENCES shifts(id) ON DELETE CASCADE,
+    start_time TIME NOT NULL,
+    end_time TIME NOT NULL,
+    position VARCHAR(50) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_shift_segments_shift_id ON shift_segments(shift_id);
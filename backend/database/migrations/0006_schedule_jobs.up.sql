@@ -0,0 +1,17 @@
+-- 自動シフト作成ジョブ
+-- result には作成案（models.ScheduleResult）を JSON で保持する
+CREATE TABLE IF NOT EXISTS schedule_jobs (
+    id SERIAL PRIMARY KEY,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'running', 'completed', 'failed', 'applied')),
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    seed BIGINT NOT NULL,
+    max_weekly_hours INTEGER NOT NULL,
+    min_rest_minutes INTEGER NOT NULL,
+    result JSONB,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP,
+    CHECK (end_date >= start_date)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS schedule_period_snapshots;
+DROP TABLE IF EXISTS schedule_periods;
@@ -0,0 +1,29 @@
+-- シフト期間（期間内の日付のシフトが属する）
+CREATE TABLE IF NOT EXISTS schedule_periods (
+    id SERIAL PRIMARY KEY,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'published')),
+    published_version INTEGER NOT NULL DEFAULT 0, -- 最後に公開したスナップショットの版
+    published_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (end_date >= start_date)
+);
+
+-- 公開時点の期間内のシフト（models.Shift の配列を JSON で保持する）
+CREATE TABLE IF NOT EXISTS schedule_period_snapshots (
+    id SERIAL PRIMARY KEY,
+    period_id INTEGER NOT NULL REFERENCES schedule_periods(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    shifts JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (period_id, version)
+);
+
+-- これまでのシフトは作成と同時に従業員に公開されていたため、シフトのある月を公開済みの期間にする
+-- スナップショットは公開済みの期間を初めて変更するときに作成する
+INSERT INTO schedule_periods (start_date, end_date, status, published_at)
+SELECT month, (month + INTERVAL '1 month' - INTERVAL '1 day')::date, 'published', CURRENT_TIMESTAMP
+FROM (SELECT DISTINCT date_trunc('month', date)::date AS month FROM shifts) months
+ORDER BY month;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS shift_holders;
+DROP TABLE IF EXISTS shift_trades;
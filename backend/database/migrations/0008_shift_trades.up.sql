@@ -0,0 +1,38 @@
+-- シフト交代（交換・譲渡）の募集
+CREATE TABLE IF NOT EXISTS shift_trades (
+    id SERIAL PRIMARY KEY,
+    shift_id INTEGER NOT NULL REFERENCES shifts(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('swap', 'giveaway')),
+    offered_by INTEGER NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    accepted_by INTEGER REFERENCES employees(id) ON DELETE SET NULL,
+    swap_shift_id INTEGER REFERENCES shifts(id) ON DELETE SET NULL, -- 交換の場合に引き受けた従業員が差し出すシフト
+    status VARCHAR(20) NOT NULL DEFAULT 'open'
+        CHECK (status IN ('open', 'accepted', 'approved', 'rejected', 'cancelled')),
+    note TEXT NOT NULL DEFAULT '',
+    decision_reason TEXT NOT NULL DEFAULT '',
+    decided_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_shift_trades_shift_id ON shift_trades(shift_id);
+
+-- 1つのシフトに同時に進行中の募集は1件まで
+CREATE UNIQUE INDEX IF NOT EXISTS idx_shift_trades_active
+    ON shift_trades(shift_id) WHERE status IN ('open', 'accepted');
+
+-- シフトの担当者の履歴
+CREATE TABLE IF NOT EXISTS shift_holders (
+    id SERIAL PRIMARY KEY,
+    shift_id INTEGER NOT NULL REFERENCES shifts(id) ON DELETE CASCADE,
+    employee_id INTEGER NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('assigned', 'changed', 'swap', 'giveaway')),
+    trade_id INTEGER REFERENCES shift_trades(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_shift_holders_shift_id ON shift_holders(shift_id);
+
+-- 既存のシフトは現在の担当者を最初の担当者とする
+INSERT INTO shift_holders (shift_id, employee_id, reason, created_at)
+SELECT id, employee_id, 'assigned', created_at FROM shifts;
@@ -0,0 +1,7 @@
+UPDATE shift_holders SET reason = 'changed' WHERE reason = 'claimed';
+ALTER TABLE shift_holders DROP CONSTRAINT IF EXISTS shift_holders_reason_check;
+ALTER TABLE shift_holders ADD CONSTRAINT shift_holders_reason_check
+    CHECK (reason IN ('assigned', 'changed', 'swap', 'giveaway'));
+
+DROP INDEX IF EXISTS idx_shifts_open;
+DROP TABLE IF EXISTS open_shift_claims;
@@ -0,0 +1,29 @@
+-- 担当者が決まっていないシフト（employee_id が NULL）への応募
+CREATE TABLE IF NOT EXISTS open_shift_claims (
+    id SERIAL PRIMARY KEY,
+    shift_id INTEGER NOT NULL REFERENCES shifts(id) ON DELETE CASCADE,
+    employee_id INTEGER NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'rejected', 'cancelled')),
+    decision_reason TEXT NOT NULL DEFAULT '',
+    decided_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_open_shift_claims_shift_id ON open_shift_claims(shift_id);
+
+-- 同じ従業員の承認待ちの応募は1シフトにつき1件まで
+CREATE UNIQUE INDEX IF NOT EXISTS idx_open_shift_claims_pending
+    ON open_shift_claims(shift_id, employee_id) WHERE status = 'pending';
+
+-- 1つのシフトで採用される応募は1件まで
+CREATE UNIQUE INDEX IF NOT EXISTS idx_open_shift_claims_approved
+    ON open_shift_claims(shift_id) WHERE status = 'approved';
+
+CREATE INDEX IF NOT EXISTS idx_shifts_open ON shifts(date) WHERE employee_id IS NULL;
+
+-- 応募で担当者が決まった履歴を記録できるようにする
+ALTER TABLE shift_holders DROP CONSTRAINT IF EXISTS shift_holders_reason_check;
+ALTER TABLE shift_holders ADD CONSTRAINT shift_holders_reason_check
+    CHECK (reason IN ('assigned', 'changed', 'swap', 'giveaway', 'claimed'));
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_shifts_pattern_date;
+ALTER TABLE shifts DROP COLUMN IF EXISTS pattern_id;
+DROP TABLE IF EXISTS shift_patterns;
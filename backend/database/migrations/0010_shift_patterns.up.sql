@@ -0,0 +1,26 @@
+-- 繰り返しシフトの定義（毎週・隔週の曜日と時刻）
+CREATE TABLE IF NOT EXISTS shift_patterns (
+    id SERIAL PRIMARY KEY,
+    employee_id INTEGER NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    weekdays INTEGER[] NOT NULL,                    -- 0（日曜）-6（土曜）
+    interval_weeks INTEGER NOT NULL DEFAULT 1 CHECK (interval_weeks IN (1, 2)),
+    start_date DATE NOT NULL,                       -- 隔週の場合はこの日を含む週から数える
+    end_date DATE,
+    start_time TIME NOT NULL,
+    end_time TIME NOT NULL,
+    ends_next_day BOOLEAN NOT NULL DEFAULT FALSE,
+    break_time INTEGER NOT NULL DEFAULT 0,
+    position VARCHAR(50) NOT NULL DEFAULT '',
+    exceptions DATE[] NOT NULL DEFAULT '{}',        -- シフトを作成しない日
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (end_date IS NULL OR end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_shift_patterns_employee_id ON shift_patterns(employee_id);
+
+-- 繰り返しシフトから作成したシフト（個別に変更したシフトは繰り返しから外す）
+ALTER TABLE shifts ADD COLUMN IF NOT EXISTS pattern_id INTEGER REFERENCES shift_patterns(id) ON DELETE SET NULL;
+
+-- 1つの繰り返しから同じ日に作成するシフトは1件まで
+CREATE UNIQUE INDEX IF NOT EXISTS idx_shifts_pattern_date ON shifts(pattern_id, date) WHERE pattern_id IS NOT NULL;
@@ -0,0 +1,24 @@
+-- シフトテンプレート（1週間分のシフトを曜日ごとに保存する）
+CREATE TABLE IF NOT EXISTS schedule_templates (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS schedule_template_shifts (
+    id SERIAL PRIMARY KEY,
+    template_id INTEGER NOT NULL REFERENCES schedule_templates(id) ON DELETE CASCADE,
+    weekday INTEGER NOT NULL CHECK (weekday BETWEEN 0 AND 6), -- 0（日曜）-6（土曜）
+    -- 従業員の削除後も適用時に報告できるよう外部キーにせず、名前も保存する（NULL は担当者を募集するシフト）
+    employee_id INTEGER,
+    employee_name VARCHAR(100) NOT NULL DEFAULT '',
+    start_time TIME NOT NULL,
+    end_time TIME NOT NULL,
+    ends_next_day BOOLEAN NOT NULL DEFAULT FALSE,
+    break_time INTEGER NOT NULL DEFAULT 0,
+    position VARCHAR(50) NOT NULL DEFAULT '',
+    segments JSONB NOT NULL DEFAULT '[]'
+);
+
+CREATE INDEX IF NOT EXISTS idx_schedule_template_shifts_template_id ON schedule_template_shifts(template_id);
@@ -0,0 +1,3 @@
+ALTER TABLE shift_requests DROP COLUMN IF EXISTS late;
+ALTER TABLE shift_requests DROP COLUMN IF EXISTS period_id;
+DROP TABLE IF EXISTS shift_request_periods;
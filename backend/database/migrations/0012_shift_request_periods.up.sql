@@ -0,0 +1,22 @@
+-- シフト希望の提出期間（対象の日付の範囲と受付期間）
+CREATE TABLE IF NOT EXISTS shift_request_periods (
+    id SERIAL PRIMARY KEY,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    opens_at TIMESTAMP NOT NULL,
+    closes_at TIMESTAMP NOT NULL,
+    late_until TIMESTAMP, -- 締切後も提出を受け付ける日時（NULL は締切後は受け付けない）
+    reminder_hours INTEGER[] NOT NULL DEFAULT '{}', -- 未提出の従業員にリマインドする、締切の何時間前か
+    reminded_at TIMESTAMP, -- 最後にリマインドした日時
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (end_date >= start_date),
+    CHECK (closes_at > opens_at),
+    CHECK (late_until IS NULL OR late_until >= closes_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_shift_request_periods_dates ON shift_request_periods(start_date, end_date);
+
+-- 提出したシフト希望の提出期間と、締切後の提出か
+ALTER TABLE shift_requests ADD COLUMN IF NOT EXISTS period_id INTEGER REFERENCES shift_request_periods(id) ON DELETE SET NULL;
+ALTER TABLE shift_requests ADD COLUMN IF NOT EXISTS late BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,28 @@
+DROP TABLE IF EXISTS desired_work_hours;
+
+-- 最初の時間帯を希望時刻に戻す（時間帯のないシフト希望は戻せないため削除する）
+ALTER TABLE shift_requests ADD COLUMN preferred_start_time TIME;
+ALTER TABLE shift_requests ADD COLUMN preferred_end_time TIME;
+ALTER TABLE shift_requests ADD COLUMN ends_next_day BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE shift_requests sr
+SET preferred_start_time = w.start_time,
+    preferred_end_time = w.end_time,
+    ends_next_day = w.ends_next_day
+FROM (
+    SELECT DISTINCT ON (request_id) request_id, start_time, end_time, ends_next_day
+    FROM shift_request_windows
+    ORDER BY request_id, id
+) w
+WHERE w.request_id = sr.id;
+
+DELETE FROM shift_requests WHERE preferred_start_time IS NULL OR availability = 'unavailable';
+
+ALTER TABLE shift_requests ALTER COLUMN preferred_start_time SET NOT NULL;
+ALTER TABLE shift_requests ALTER COLUMN preferred_end_time SET NOT NULL;
+ALTER TABLE shift_requests ADD CONSTRAINT shift_requests_time_range_check
+    CHECK (ends_next_day = (preferred_end_time <= preferred_start_time));
+
+DROP TABLE IF EXISTS shift_request_windows;
+ALTER TABLE shift_requests DROP COLUMN IF EXISTS note;
+ALTER TABLE shift_requests DROP COLUMN IF EXISTS availability;
@@ -0,0 +1,36 @@
+-- シフト希望に種類（勤務できない・勤務したい・必要なら勤務できる・終日勤務できる）とメモを追加する
+-- 既存のシフト希望は「勤務したい」として扱う
+ALTER TABLE shift_requests ADD COLUMN IF NOT EXISTS availability VARCHAR(20) NOT NULL DEFAULT 'preferred'
+    CHECK (availability IN ('unavailable', 'preferred', 'if_needed', 'all_day'));
+ALTER TABLE shift_requests ADD COLUMN IF NOT EXISTS note TEXT NOT NULL DEFAULT '';
+
+-- シフト希望の時間帯（1日に複数指定できる）
+CREATE TABLE IF NOT EXISTS shift_request_windows (
+    id SERIAL PRIMARY KEY,
+    request_id INTEGER NOT NULL REFERENCES shift_requests(id) ON DELETE CASCADE,
+    start_time TIME NOT NULL,
+    end_time TIME NOT NULL,
+    ends_next_day BOOLEAN NOT NULL DEFAULT FALSE,
+    CHECK (ends_next_day = (end_time <= start_time))
+);
+
+CREATE INDEX IF NOT EXISTS idx_shift_request_windows_request_id ON shift_request_windows(request_id);
+
+-- 既存の希望時刻を時間帯に移す
+INSERT INTO shift_request_windows (request_id, start_time, end_time, ends_next_day)
+SELECT id, preferred_start_time, preferred_end_time, ends_next_day FROM shift_requests;
+
+ALTER TABLE shift_requests DROP CONSTRAINT IF EXISTS shift_requests_time_range_check;
+ALTER TABLE shift_requests DROP COLUMN preferred_start_time;
+ALTER TABLE shift_requests DROP COLUMN preferred_end_time;
+ALTER TABLE shift_requests DROP COLUMN ends_next_day;
+
+-- 従業員が希望する1週間または1か月の勤務時間
+CREATE TABLE IF NOT EXISTS desired_work_hours (
+    employee_id INTEGER PRIMARY KEY REFERENCES employees(id) ON DELETE CASCADE,
+    unit VARCHAR(10) NOT NULL CHECK (unit IN ('week', 'month')),
+    hours NUMERIC(5, 1) NOT NULL CHECK (hours > 0),
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
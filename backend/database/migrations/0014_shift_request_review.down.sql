@@ -0,0 +1,9 @@
+ALTER TABLE shifts DROP COLUMN IF EXISTS request_id;
+
+ALTER TABLE shift_requests DROP COLUMN IF EXISTS reviewed_at;
+ALTER TABLE shift_requests DROP COLUMN IF EXISTS review_reason;
+
+ALTER TABLE shift_requests DROP CONSTRAINT IF EXISTS shift_requests_status_check;
+UPDATE shift_requests SET status = 'processed' WHERE status <> 'submitted';
+ALTER TABLE shift_requests ADD CONSTRAINT shift_requests_status_check
+    CHECK (status IN ('submitted', 'processed'));
@@ -0,0 +1,13 @@
+-- シフト希望の承認・一部承認・却下（processed は承認として扱う）
+ALTER TABLE shift_requests DROP CONSTRAINT IF EXISTS shift_requests_status_check;
+UPDATE shift_requests SET status = 'approved' WHERE status = 'processed';
+ALTER TABLE shift_requests ADD CONSTRAINT shift_requests_status_check
+    CHECK (status IN ('submitted', 'approved', 'partially_approved', 'rejected'));
+
+ALTER TABLE shift_requests ADD COLUMN IF NOT EXISTS review_reason TEXT NOT NULL DEFAULT ''; -- 承認・却下の理由
+ALTER TABLE shift_requests ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMP;
+
+-- シフト希望を承認して作成したシフト
+ALTER TABLE shifts ADD COLUMN IF NOT EXISTS request_id INTEGER REFERENCES shift_requests(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_shifts_request_id ON shifts(request_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS availability_windows;
+DROP TABLE IF EXISTS availability_profiles;
@@ -0,0 +1,26 @@
+-- 従業員の勤務可能な時間帯（曜日ごと）。適用期間ごとに登録し、同じ従業員の適用期間は重ならない
+CREATE TABLE IF NOT EXISTS availability_profiles (
+    id SERIAL PRIMARY KEY,
+    employee_id INTEGER NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL DEFAULT '',
+    valid_from DATE NOT NULL,
+    valid_to DATE, -- NULL は終了日なし
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (valid_to IS NULL OR valid_to >= valid_from)
+);
+
+CREATE INDEX IF NOT EXISTS idx_availability_profiles_employee_id ON availability_profiles(employee_id, valid_from);
+
+CREATE TABLE IF NOT EXISTS availability_windows (
+    id SERIAL PRIMARY KEY,
+    profile_id INTEGER NOT NULL REFERENCES availability_profiles(id) ON DELETE CASCADE,
+    weekday INTEGER NOT NULL CHECK (weekday BETWEEN 0 AND 6), -- 0（日曜）-6（土曜）
+    start_time TIME NOT NULL,
+    end_time TIME NOT NULL,
+    ends_next_day BOOLEAN NOT NULL DEFAULT FALSE,
+    CHECK (ends_next_day = (end_time <= start_time))
+);
+
+CREATE INDEX IF NOT EXISTS idx_availability_windows_profile_id ON availability_windows(profile_id);
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_attendance_shift_id;
+ALTER TABLE attendance DROP COLUMN IF EXISTS overtime_minutes;
+ALTER TABLE attendance DROP COLUMN IF EXISTS early_leave_minutes;
+ALTER TABLE attendance DROP COLUMN IF EXISTS late_minutes;
+ALTER TABLE attendance DROP COLUMN IF EXISTS shift_id;
+
+-- 早退・シフトのない勤務は出勤として戻す
+UPDATE attendance SET status = 'present' WHERE status IN ('early_leave', 'unscheduled');
+ALTER TABLE attendance DROP CONSTRAINT IF EXISTS attendance_status_check;
+ALTER TABLE attendance ADD CONSTRAINT attendance_status_check CHECK (status IN ('present', 'absent', 'late'));
@@ -0,0 +1,83 @@
+-- 出退勤記録を予定のシフトと照合する
+-- ステータスは照合結果から決める（early_leave: 早退 / unscheduled: シフトのない勤務）
+ALTER TABLE attendance DROP CONSTRAINT IF EXISTS attendance_status_check;
+ALTER TABLE attendance ADD CONSTRAINT attendance_status_check
+    CHECK (status IN ('present', 'late', 'early_leave', 'absent', 'unscheduled'));
+
+ALTER TABLE attendance ADD COLUMN IF NOT EXISTS shift_id INTEGER REFERENCES shifts(id) ON DELETE SET NULL; -- 照合したシフト
+ALTER TABLE attendance ADD COLUMN IF NOT EXISTS late_minutes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE attendance ADD COLUMN IF NOT EXISTS early_leave_minutes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE attendance ADD COLUMN IF NOT EXISTS overtime_minutes INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_attendance_shift_id ON attendance(shift_id);
+
+-- 既存の出退勤記録を予定のシフトと照合する（handlers の reconcileAttendance と同じ規則）
+-- 猶予時間は設定（attendance）の既定値（遅刻・早退5分、残業15分）を使う。以降は記録・シフトの変更時に設定値で照合し直す
+WITH att AS (
+    SELECT a.id, a.employee_id, a.date,
+           a.date + a.clock_in_time AS start_at,
+           CASE WHEN a.clock_out_time IS NOT NULL THEN
+               a.date + a.clock_out_time + CASE WHEN a.clock_out_next_day THEN INTERVAL '1 day' ELSE INTERVAL '0' END
+           END AS end_at
+    FROM attendance a
+    WHERE a.clock_in_time IS NOT NULL AND a.employee_id IS NOT NULL
+),
+sh AS (
+    SELECT s.id, s.employee_id, s.date,
+           s.date + s.start_time AS start_at,
+           s.date + s.end_time + CASE WHEN s.ends_next_day THEN INTERVAL '1 day' ELSE INTERVAL '0' END AS end_at
+    FROM shifts s
+    WHERE s.employee_id IS NOT NULL
+),
+-- 勤務時間が最も長く重なるシフト、重ならない場合は出勤時刻を含むシフト、
+-- それもない場合は勤務日が同じで出勤時刻より後に始まる最初のシフトに照合する
+matched AS (
+    SELECT att.*, m.shift_id, m.shift_start, m.shift_end
+    FROM att
+    LEFT JOIN LATERAL (
+        SELECT sh.id AS shift_id, sh.start_at AS shift_start, sh.end_at AS shift_end
+        FROM sh
+        WHERE sh.employee_id = att.employee_id
+          AND ((att.end_at IS NOT NULL AND sh.start_at < att.end_at AND att.start_at < sh.end_at)
+               OR (att.start_at >= sh.start_at AND att.start_at < sh.end_at)
+               OR (sh.date = att.date AND att.start_at < sh.start_at))
+        ORDER BY
+            CASE WHEN att.end_at IS NOT NULL AND sh.start_at < att.end_at AND att.start_at < sh.end_at THEN 0
+                 WHEN att.start_at >= sh.start_at AND att.start_at < sh.end_at THEN 1
+                 ELSE 2 END,
+            CASE WHEN att.end_at IS NOT NULL AND sh.start_at < att.end_at AND att.start_at < sh.end_at
+                 THEN LEAST(att.end_at, sh.end_at) - GREATEST(att.start_at, sh.start_at) END DESC NULLS LAST,
+            sh.start_at
+        LIMIT 1
+    ) m ON TRUE
+),
+-- 遅刻はシフトの最初の出勤、早退・残業はシフトの最後の退勤で判定する（退勤前の記録がある場合は早退・残業を判定しない）
+grouped AS (
+    SELECT matched.*,
+           ROW_NUMBER() OVER (PARTITION BY shift_id ORDER BY start_at, id) AS first_rank,
+           ROW_NUMBER() OVER (PARTITION BY shift_id ORDER BY end_at DESC NULLS LAST, id) AS last_rank,
+           BOOL_AND(end_at IS NOT NULL) OVER (PARTITION BY shift_id) AS clocked_out
+    FROM matched
+),
+result AS (
+    SELECT id, shift_id,
+           CASE WHEN shift_id IS NOT NULL AND first_rank = 1 AND start_at - shift_start > INTERVAL '5 minutes'
+                THEN FLOOR(EXTRACT(EPOCH FROM start_at - shift_start) / 60)::INTEGER ELSE 0 END AS late_minutes,
+           CASE WHEN shift_id IS NOT NULL AND clocked_out AND last_rank = 1 AND shift_end - end_at > INTERVAL '5 minutes'
+                THEN FLOOR(EXTRACT(EPOCH FROM shift_end - end_at) / 60)::INTEGER ELSE 0 END AS early_leave_minutes,
+           CASE WHEN shift_id IS NULL THEN COALESCE(FLOOR(EXTRACT(EPOCH FROM end_at - start_at) / 60)::INTEGER, 0)
+                WHEN clocked_out AND last_rank = 1 AND end_at - shift_end > INTERVAL '15 minutes'
+                THEN FLOOR(EXTRACT(EPOCH FROM end_at - shift_end) / 60)::INTEGER ELSE 0 END AS overtime_minutes
+    FROM grouped
+)
+UPDATE attendance a
+SET shift_id = r.shift_id,
+    late_minutes = r.late_minutes,
+    early_leave_minutes = r.early_leave_minutes,
+    overtime_minutes = r.overtime_minutes,
+    status = CASE WHEN r.shift_id IS NULL THEN 'unscheduled'
+                  WHEN r.late_minutes > 0 THEN 'late'
+                  WHEN r.early_leave_minutes > 0 THEN 'early_leave'
+                  ELSE 'present' END
+FROM result r
+WHERE a.id = r.id;
@@ -0,0 +1,4 @@
+-- 自動シフト作成ジョブのリース
+-- 実行中のサーバーが期限を延長し続け、期限が切れた待機中・実行中のジョブは中断したものとして失敗にする
+-- 既存のジョブは期限なし（NULL）とし、期限切れとして扱う
+ALTER TABLE schedule_jobs ADD COLUMN IF NOT EXISTS lease_expires_at TIMESTAMP;
@@ -0,0 +1,285 @@
+package database
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"shift-management-backend/models"
+)
+
+// ErrSessionNotFound セッションが存在しない、期限切れ、または失効済み
+var ErrSessionNotFound = errors.New("session not found")
+
+// ErrRefreshTokenReused 使用済みのリフレッシュトークンが再利用された
+var ErrRefreshTokenReused = errors.New("refresh token reused")
+
+// SessionOptions セッションの有効期限設定
+type SessionOptions struct {
+	// IdleTimeout 最終アクセスからこの時間が経過すると無効
+	IdleTimeout time.Duration
+	// AbsoluteTimeout 作成からこの時間が経過すると無効
+	AbsoluteTimeout time.Duration
+}
+
+// DefaultSessionOptions デフォルトのセッション有効期限
+var DefaultSessionOptions = SessionOptions{
+	IdleTimeout:     2 * time.Hour,
+	AbsoluteTimeout: 7 * 24 * time.Hour,
+}
+
+// SessionMetadata セッション作成時のクライアント情報
+type SessionMetadata struct {
+	UserAgent string
+	IPAddress string
+}
+
+// SessionStore セッションの保存先
+// セッションはログイン単位で作成され、リフレッシュトークンはローテーションのたびにセッションに紐づけて発行される
+type SessionStore interface {
+	// Create 新しいセッションを作成し、最初のリフレッシュトークンを返す
+	Create(user models.User, meta SessionMetadata) (string, models.Session, error)
+	// Get セッションIDからユーザーとセッションを取得し、最終アクセス日時を更新する
+	Get(sessionID int) (models.User, models.Session, error)
+	// Rotate リフレッシュトークンを使用済みにして新しいトークンを発行する
+	// 使用済みトークンが提示された場合はセッションを失効させて ErrRefreshTokenReused を返す
+	Rotate(refreshToken string) (string, models.User, models.Session, error)
+	// RevokeByID 指定ユーザーのセッションをIDで失効させる
+	RevokeByID(userID, sessionID int) error
+	// ListByUser 指定ユーザーの有効なセッション一覧を取得
+	ListByUser(userID int) ([]models.Session, error)
+	// DeleteExpired 期限切れ・失効済みのセッションを削除する
+	DeleteExpired() (int64, error)
+}
+
+// NewRefreshToken ランダムなリフレッシュトークンを生成
+func NewRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// HashRefreshToken リフレッシュトークンを保存用にハッシュ化
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// PostgresSessionStore PostgreSQLに保存するセッションストア
+type PostgresSessionStore struct {
+	db   *sql.DB
+	opts SessionOptions
+}
+
+// NewPostgresSessionStore PostgreSQLセッションストアを作成
+func NewPostgresSessionStore(db *sql.DB, opts SessionOptions) *PostgresSessionStore {
+	return &PostgresSessionStore{db: db, opts: opts}
+}
+
+// Create 新しいセッションを作成
+func (s *PostgresSessionStore) Create(user models.User, meta SessionMetadata) (string, models.Session, error) {
+	token, err := NewRefreshToken()
+	if err != nil {
+		return "", models.Session{}, fmt.Errorf("トークン生成エラー: %v", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return "", models.Session{}, fmt.Errorf("トランザクション開始エラー: %v", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	var session models.Session
+	err = tx.QueryRow(`
+		INSERT INTO sessions (user_id, user_agent, ip_address, created_at, last_seen_at, expires_at)
+		VALUES ($1, $2, $3, $4, $4, $5)
+		RETURNING id, user_id, user_agent, ip_address, created_at, last_seen_at, expires_at
+	`, user.ID, meta.UserAgent, meta.IPAddress, now, now.Add(s.opts.AbsoluteTimeout)).Scan(
+		&session.ID, &session.UserID, &session.UserAgent, &session.IPAddress,
+		&session.CreatedAt, &session.LastSeenAt, &session.ExpiresAt)
+	if err != nil {
+		return "", models.Session{}, fmt.Errorf("セッション作成エラー: %v", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO refresh_tokens (session_id, token_hash, created_at)
+		VALUES ($1, $2, $3)
+	`, session.ID, HashRefreshToken(token), now)
+	if err != nil {
+		return "", models.Session{}, fmt.Errorf("リフレッシュトークン作成エラー: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", models.Session{}, fmt.Errorf("トランザクションコミットエラー: %v", err)
+	}
+
+	return token, session, nil
+}
+
+// Get セッションIDからユーザーとセッションを取得
+func (s *PostgresSessionStore) Get(sessionID int) (models.User, models.Session, error) {
+	return s.touch(s.db, sessionID, time.Now())
+}
+
+// Rotate リフレッシュトークンをローテーション
+func (s *PostgresSessionStore) Rotate(refreshToken string) (string, models.User, models.Session, error) {
+	newToken, err := NewRefreshToken()
+	if err != nil {
+		return "", models.User{}, models.Session{}, fmt.Errorf("トークン生成エラー: %v", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return "", models.User{}, models.Session{}, fmt.Errorf("トランザクション開始エラー: %v", err)
+	}
+	defer tx.Rollback()
+
+	// 同じトークンでの同時リクエストが両方成功しないよう行ロックを取る
+	var tokenID, sessionID int
+	var usedAt sql.NullTime
+	err = tx.QueryRow(`
+		SELECT id, session_id, used_at FROM refresh_tokens
+		WHERE token_hash = $1
+		FOR UPDATE
+	`, HashRefreshToken(refreshToken)).Scan(&tokenID, &sessionID, &usedAt)
+	if err == sql.ErrNoRows {
+		return "", models.User{}, models.Session{}, ErrSessionNotFound
+	}
+	if err != nil {
+		return "", models.User{}, models.Session{}, fmt.Errorf("リフレッシュトークン取得エラー: %v", err)
+	}
+
+	now := time.Now()
+	if usedAt.Valid {
+		// 再利用を検知したらトークンが漏洩したとみなしてセッションごと失効させる
+		if _, err := tx.Exec(`
+			UPDATE sessions SET revoked_at = $2
+			WHERE id = $1 AND revoked_at IS NULL
+		`, sessionID, now); err != nil {
+			return "", models.User{}, models.Session{}, fmt.Errorf("セッション失効エラー: %v", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return "", models.User{}, models.Session{}, fmt.Errorf("トランザクションコミットエラー: %v", err)
+		}
+		return "", models.User{}, models.Session{}, ErrRefreshTokenReused
+	}
+
+	user, session, err := s.touch(tx, sessionID, now)
+	if err != nil {
+		return "", models.User{}, models.Session{}, err
+	}
+
+	if _, err := tx.Exec(`UPDATE refresh_tokens SET used_at = $2 WHERE id = $1`, tokenID, now); err != nil {
+		return "", models.User{}, models.Session{}, fmt.Errorf("リフレッシュトークン更新エラー: %v", err)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO refresh_tokens (session_id, token_hash, created_at)
+		VALUES ($1, $2, $3)
+	`, sessionID, HashRefreshToken(newToken), now); err != nil {
+		return "", models.User{}, models.Session{}, fmt.Errorf("リフレッシュトークン作成エラー: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", models.User{}, models.Session{}, fmt.Errorf("トランザクションコミットエラー: %v", err)
+	}
+
+	return newToken, user, session, nil
+}
+
+// queryRower QueryRow を持つ *sql.DB / *sql.Tx
+type queryRower interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// touch 有効なセッションの最終アクセス日時を更新し、ユーザーとセッションを返す
+func (s *PostgresSessionStore) touch(q queryRower, sessionID int, now time.Time) (models.User, models.Session, error) {
+	var user models.User
+	var session models.Session
+	err := q.QueryRow(`
+		UPDATE sessions ss
+		SET last_seen_at = $2
+		FROM users u
+		WHERE ss.user_id = u.id
+		  AND ss.id = $1
+		  AND ss.revoked_at IS NULL
+		  AND ss.expires_at > $2
+		  AND ss.last_seen_at > $3
+		RETURNING ss.id, ss.user_id, ss.user_agent, ss.ip_address, ss.created_at, ss.last_seen_at, ss.expires_at,
+		          u.id, u.email, u.role, u.name, u.employee_id, u.created_at, u.updated_at
+	`, sessionID, now, now.Add(-s.opts.IdleTimeout)).Scan(
+		&session.ID, &session.UserID, &session.UserAgent, &session.IPAddress,
+		&session.CreatedAt, &session.LastSeenAt, &session.ExpiresAt,
+		&user.ID, &user.Email, &user.Role, &user.Name, &user.EmployeeID, &user.CreatedAt, &user.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return models.User{}, models.Session{}, ErrSessionNotFound
+	}
+	if err != nil {
+		return models.User{}, models.Session{}, fmt.Errorf("セッション取得エラー: %v", err)
+	}
+
+	return user, session, nil
+}
+
+// RevokeByID 指定ユーザーのセッションをIDで失効
+func (s *PostgresSessionStore) RevokeByID(userID, sessionID int) error {
+	result, err := s.db.Exec(`
+		UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`, sessionID, userID)
+	if err != nil {
+		return fmt.Errorf("セッション失効エラー: %v", err)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// ListByUser 指定ユーザーの有効なセッション一覧を取得
+func (s *PostgresSessionStore) ListByUser(userID int) ([]models.Session, error) {
+	now := time.Now()
+	rows, err := s.db.Query(`
+		SELECT id, user_id, user_agent, ip_address, created_at, last_seen_at, expires_at
+		FROM sessions
+		WHERE user_id = $1
+		  AND revoked_at IS NULL
+		  AND expires_at > $2
+		  AND last_seen_at > $3
+		ORDER BY last_seen_at DESC
+	`, userID, now, now.Add(-s.opts.IdleTimeout))
+	if err != nil {
+		return nil, fmt.Errorf("セッション一覧取得エラー: %v", err)
+	}
+	defer rows.Close()
+
+	sessions := []models.Session{}
+	for rows.Next() {
+		var session models.Session
+		if err := rows.Scan(&session.ID, &session.UserID, &session.UserAgent, &session.IPAddress,
+			&session.CreatedAt, &session.LastSeenAt, &session.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("セッションデータ読み込みエラー: %v", err)
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// DeleteExpired 期限切れ・失効済みのセッションを削除
+func (s *PostgresSessionStore) DeleteExpired() (int64, error) {
+	now := time.Now()
+	result, err := s.db.Exec(`
+		DELETE FROM sessions
+		WHERE revoked_at IS NOT NULL OR expires_at <= $1 OR last_seen_at <= $2
+	`, now, now.Add(-s.opts.IdleTimeout))
+	if err != nil {
+		return 0, fmt.Errorf("期限切れセッション削除エラー: %v", err)
+	}
+	return result.RowsAffected()
+}
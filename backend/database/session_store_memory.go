@@ -0,0 +1,182 @@
+package database
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"shift-management-backend/models"
+)
+
+// MemorySessionStore メモリ上に保持するセッションストア（テスト用）
+type MemorySessionStore struct {
+	mu       sync.Mutex
+	opts     SessionOptions
+	nextID   int
+	sessions map[int]*memorySession
+	tokens   map[string]*memoryRefreshToken // キーはリフレッシュトークンのハッシュ
+	now      func() time.Time
+}
+
+type memorySession struct {
+	user    models.User
+	session models.Session
+}
+
+type memoryRefreshToken struct {
+	sessionID int
+	used      bool
+}
+
+// NewMemorySessionStore メモリセッションストアを作成
+func NewMemorySessionStore(opts SessionOptions) *MemorySessionStore {
+	return &MemorySessionStore{
+		opts:     opts,
+		sessions: make(map[int]*memorySession),
+		tokens:   make(map[string]*memoryRefreshToken),
+		now:      time.Now,
+	}
+}
+
+// Create 新しいセッションを作成
+func (s *MemorySessionStore) Create(user models.User, meta SessionMetadata) (string, models.Session, error) {
+	token, err := NewRefreshToken()
+	if err != nil {
+		return "", models.Session{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	now := s.now()
+	entry := &memorySession{
+		user: user,
+		session: models.Session{
+			ID:         s.nextID,
+			UserID:     user.ID,
+			UserAgent:  meta.UserAgent,
+			IPAddress:  meta.IPAddress,
+			CreatedAt:  now,
+			LastSeenAt: now,
+			ExpiresAt:  now.Add(s.opts.AbsoluteTimeout),
+		},
+	}
+	s.sessions[entry.session.ID] = entry
+	s.tokens[HashRefreshToken(token)] = &memoryRefreshToken{sessionID: entry.session.ID}
+
+	return token, entry.session, nil
+}
+
+// Get セッションIDからユーザーとセッションを取得
+func (s *MemorySessionStore) Get(sessionID int) (models.User, models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.touch(sessionID, s.now())
+}
+
+// Rotate リフレッシュトークンをローテーション
+func (s *MemorySessionStore) Rotate(refreshToken string) (string, models.User, models.Session, error) {
+	newToken, err := NewRefreshToken()
+	if err != nil {
+		return "", models.User{}, models.Session{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rt, ok := s.tokens[HashRefreshToken(refreshToken)]
+	if !ok {
+		return "", models.User{}, models.Session{}, ErrSessionNotFound
+	}
+
+	now := s.now()
+	if rt.used {
+		if entry, ok := s.sessions[rt.sessionID]; ok && entry.session.RevokedAt == nil {
+			entry.session.RevokedAt = &now
+		}
+		return "", models.User{}, models.Session{}, ErrRefreshTokenReused
+	}
+
+	user, session, err := s.touch(rt.sessionID, now)
+	if err != nil {
+		return "", models.User{}, models.Session{}, err
+	}
+
+	rt.used = true
+	s.tokens[HashRefreshToken(newToken)] = &memoryRefreshToken{sessionID: rt.sessionID}
+
+	return newToken, user, session, nil
+}
+
+// RevokeByID 指定ユーザーのセッションをIDで失効
+func (s *MemorySessionStore) RevokeByID(userID, sessionID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.sessions[sessionID]
+	if !ok || entry.session.UserID != userID || entry.session.RevokedAt != nil {
+		return ErrSessionNotFound
+	}
+	now := s.now()
+	entry.session.RevokedAt = &now
+	return nil
+}
+
+// ListByUser 指定ユーザーの有効なセッション一覧を取得
+func (s *MemorySessionStore) ListByUser(userID int) ([]models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	sessions := []models.Session{}
+	for _, entry := range s.sessions {
+		if entry.session.UserID == userID && s.active(entry.session, now) {
+			sessions = append(sessions, entry.session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+	return sessions, nil
+}
+
+// DeleteExpired 期限切れ・失効済みのセッションを削除
+func (s *MemorySessionStore) DeleteExpired() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	var deleted int64
+	for id, entry := range s.sessions {
+		if !s.active(entry.session, now) {
+			delete(s.sessions, id)
+			deleted++
+		}
+	}
+	for hash, rt := range s.tokens {
+		if _, ok := s.sessions[rt.sessionID]; !ok {
+			delete(s.tokens, hash)
+		}
+	}
+	return deleted, nil
+}
+
+// touch 有効なセッションの最終アクセス日時を更新（呼び出し側でロックを取ること）
+func (s *MemorySessionStore) touch(sessionID int, now time.Time) (models.User, models.Session, error) {
+	entry, ok := s.sessions[sessionID]
+	if !ok || !s.active(entry.session, now) {
+		return models.User{}, models.Session{}, ErrSessionNotFound
+	}
+
+	entry.session.LastSeenAt = now
+	return entry.user, entry.session, nil
+}
+
+// active セッションが有効かどうか
+func (s *MemorySessionStore) active(session models.Session, now time.Time) bool {
+	return session.RevokedAt == nil &&
+		now.Before(session.ExpiresAt) &&
+		now.Before(session.LastSeenAt.Add(s.opts.IdleTimeout))
+}
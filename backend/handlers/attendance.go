@@ -5,75 +5,47 @@ import (
 	"strconv"
 	"time"
 
-	"shift-management-backend/database"
 	"shift-management-backend/models"
+	"shift-management-backend/repository"
 
 	"github.com/labstack/echo/v4"
 )
 
 // GetAttendances 出退勤記録一覧を取得
-func GetAttendances(c echo.Context) error {
-	// クエリパラメータの取得
-	employeeID := c.QueryParam("employee_id")
-	startDate := c.QueryParam("start_date")
-	endDate := c.QueryParam("end_date")
+func (h *Handler) GetAttendances(c echo.Context) error {
+	var filter repository.AttendanceFilter
+	filter.StartDate = c.QueryParam("start_date")
+	filter.EndDate = c.QueryParam("end_date")
 
-	query := `
-		SELECT a.id, a.employee_id, a.date, a.clock_in_time, a.clock_out_time, 
-		       a.actual_hours, a.status, a.created_at, a.updated_at, e.name as employee_name
-		FROM attendance a
-		JOIN employees e ON a.employee_id = e.id
-		WHERE 1=1
-	`
-	args := []interface{}{}
-	argIndex := 1
-
-	if employeeID != "" {
-		query += " AND a.employee_id = $" + strconv.Itoa(argIndex)
-		args = append(args, employeeID)
-		argIndex++
+	if employeeIDParam := c.QueryParam("employee_id"); employeeIDParam != "" {
+		employeeID, err := strconv.Atoi(employeeIDParam)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "従業員IDは数値で指定してください",
+			})
+		}
+		filter.EmployeeID = &employeeID
 	}
 
-	if startDate != "" {
-		query += " AND a.date >= $" + strconv.Itoa(argIndex)
-		args = append(args, startDate)
-		argIndex++
+	// 従業員は自分の記録のみ取得できる
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
 	}
-
-	if endDate != "" {
-		query += " AND a.date <= $" + strconv.Itoa(argIndex)
-		args = append(args, endDate)
-		argIndex++
+	if !acc.IsOwner() {
+		filter.EmployeeID = &acc.EmployeeID
 	}
 
-	query += " ORDER BY a.date DESC, a.created_at DESC"
-
-	rows, err := database.DB.Query(query, args...)
+	attendances, err := h.repos.Attendance.List(filter)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "出退勤記録一覧の取得に失敗しました",
-		})
-	}
-	defer rows.Close()
-
-	var attendances []models.Attendance
-	for rows.Next() {
-		var att models.Attendance
-		err := rows.Scan(&att.ID, &att.EmployeeID, &att.Date, &att.ClockInTime,
-			&att.ClockOutTime, &att.ActualHours, &att.Status, &att.CreatedAt, &att.UpdatedAt, &att.EmployeeName)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{
-				"error": "データの読み込みに失敗しました",
-			})
-		}
-		attendances = append(attendances, att)
+		return serverError(c, err, "出退勤記録一覧の取得に失敗しました")
 	}
 
 	return c.JSON(http.StatusOK, attendances)
 }
 
 // GetAttendance 出退勤記録詳細を取得
-func GetAttendance(c echo.Context) error {
+func (h *Handler) GetAttendance(c echo.Context) error {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
@@ -81,27 +53,29 @@ func GetAttendance(c echo.Context) error {
 		})
 	}
 
-	var att models.Attendance
-	err = database.DB.QueryRow(`
-		SELECT a.id, a.employee_id, a.date, a.clock_in_time, a.clock_out_time, 
-		       a.actual_hours, a.status, a.created_at, a.updated_at, e.name as employee_name
-		FROM attendance a
-		JOIN employees e ON a.employee_id = e.id
-		WHERE a.id = $1
-	`, id).Scan(&att.ID, &att.EmployeeID, &att.Date, &att.ClockInTime,
-		&att.ClockOutTime, &att.ActualHours, &att.Status, &att.CreatedAt, &att.UpdatedAt, &att.EmployeeName)
-
-	if err != nil {
+	att, err := h.repos.Attendance.Get(id)
+	if err == repository.ErrNotFound {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "出退勤記録が見つかりません",
 		})
 	}
+	if err != nil {
+		return serverError(c, err, "出退勤記録の取得に失敗しました")
+	}
+
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	if !acc.IsOwner() && !acc.IsSelf(att.EmployeeID) {
+		return forbidden(c)
+	}
 
 	return c.JSON(http.StatusOK, att)
 }
 
 // CreateAttendance 出退勤記録を作成
-func CreateAttendance(c echo.Context) error {
+func (h *Handler) CreateAttendance(c echo.Context) error {
 	var req models.CreateAttendanceRequest
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
@@ -116,49 +90,69 @@ func CreateAttendance(c echo.Context) error {
 		})
 	}
 
+	// 勤怠編集権限の確認
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	if !acc.CanEditAttendanceOf(req.EmployeeID) {
+		return forbidden(c)
+	}
+
 	// 従業員の存在確認
-	var employeeExists bool
-	err := database.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM employees WHERE id = $1)", req.EmployeeID).Scan(&employeeExists)
+	employeeExists, err := h.repos.Employees.Exists(req.EmployeeID)
 	if err != nil || !employeeExists {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"error": "指定された従業員が存在しません",
 		})
 	}
 
-	// 同じ日付の記録が既に存在するかチェック
-	var existingID int
-	err = database.DB.QueryRow("SELECT id FROM attendance WHERE employee_id = $1 AND date = $2", req.EmployeeID, req.Date).Scan(&existingID)
-	if err == nil {
-		return c.JSON(http.StatusConflict, map[string]string{
-			"error": "同じ日付の出退勤記録が既に存在します",
-		})
-	}
-
-	// ステータスのデフォルト値設定
+	// ステータスのデフォルト値設定（出勤時刻のある記録はシフトとの照合結果で置き換える）
 	if req.Status == "" {
-		req.Status = "present"
+		req.Status = models.AttendancePresent
 	}
 
-	var attendance models.Attendance
-	err = database.DB.QueryRow(`
-		INSERT INTO attendance (employee_id, date, clock_in_time, clock_out_time, status) 
-		VALUES ($1, $2, $3, $4, $5) 
-		RETURNING id, employee_id, date, clock_in_time, clock_out_time, actual_hours, status, created_at, updated_at
-	`, req.EmployeeID, req.Date, req.ClockInTime, req.ClockOutTime, req.Status).Scan(
-		&attendance.ID, &attendance.EmployeeID, &attendance.Date, &attendance.ClockInTime,
-		&attendance.ClockOutTime, &attendance.ActualHours, &attendance.Status, &attendance.CreatedAt, &attendance.UpdatedAt)
+	attendance := models.Attendance{
+		EmployeeID:      req.EmployeeID,
+		Date:            req.Date,
+		ClockInTime:     req.ClockInTime,
+		ClockOutTime:    req.ClockOutTime,
+		ClockOutNextDay: req.ClockOutNextDay,
+		Status:          req.Status,
+	}
+	if err := validateAttendanceStatus(attendance); err != nil {
+		return respondError(c, err, "出退勤記録の作成に失敗しました")
+	}
+	if err := setActualHours(&attendance); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": spanErrorMessage(err),
+		})
+	}
 
+	// 1日に複数の出退勤を記録できるが、勤務時間が重なる記録は作成できない
+	err = h.repos.InTx(func(r *repository.Repositories) error {
+		if err := lockAndCheckAttendanceOverlap(r, attendance); err != nil {
+			return err
+		}
+		created, err := r.Attendance.Create(attendance)
+		if err != nil {
+			return err
+		}
+		if err := h.syncAttendance(r, created.EmployeeID, created.Date); err != nil {
+			return err
+		}
+		attendance, err = r.Attendance.Get(created.ID)
+		return err
+	})
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "出退勤記録の作成に失敗しました",
-		})
+		return respondError(c, err, "出退勤記録の作成に失敗しました")
 	}
 
 	return c.JSON(http.StatusCreated, attendance)
 }
 
 // UpdateAttendance 出退勤記録を更新
-func UpdateAttendance(c echo.Context) error {
+func (h *Handler) UpdateAttendance(c echo.Context) error {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
@@ -173,42 +167,108 @@ func UpdateAttendance(c echo.Context) error {
 		})
 	}
 
-	// ステータスのバリデーション
-	if req.Status != "" && req.Status != "present" && req.Status != "absent" && req.Status != "late" {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "無効なステータスです",
+	// 勤怠編集権限の確認
+	existing, err := h.repos.Attendance.Get(id)
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "出退勤記録が見つかりません",
 		})
 	}
+	if err != nil {
+		return serverError(c, err, "出退勤記録の取得に失敗しました")
+	}
 
-	result, err := database.DB.Exec(`
-		UPDATE attendance 
-		SET clock_in_time = COALESCE($1, clock_in_time),
-		    clock_out_time = COALESCE($2, clock_out_time),
-		    status = COALESCE($3, status),
-		    updated_at = CURRENT_TIMESTAMP
-		WHERE id = $4
-	`, req.ClockInTime, req.ClockOutTime, req.Status, id)
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	if !acc.CanEditAttendanceOf(existing.EmployeeID) {
+		return forbidden(c)
+	}
 
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "出退勤記録の更新に失敗しました",
+	applyAttendanceUpdate(&existing, req)
+	if err := validateAttendanceStatus(existing); err != nil {
+		return respondError(c, err, "出退勤記録の更新に失敗しました")
+	}
+	if err := setActualHours(&existing); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": spanErrorMessage(err),
 		})
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
+	err = h.repos.InTx(func(r *repository.Repositories) error {
+		if err := lockAndCheckAttendanceOverlap(r, existing); err != nil {
+			return err
+		}
+		if err := r.Attendance.Update(existing); err != nil {
+			return err
+		}
+		return h.syncAttendance(r, existing.EmployeeID, existing.Date)
+	})
+	if err == repository.ErrNotFound {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "出退勤記録が見つかりません",
 		})
 	}
+	if err != nil {
+		return respondError(c, err, "出退勤記録の更新に失敗しました")
+	}
 
 	return c.JSON(http.StatusOK, map[string]string{
 		"message": "出退勤記録が更新されました",
 	})
 }
 
+// applyAttendanceUpdate 更新リクエストで指定された項目を出退勤記録に反映する
+func applyAttendanceUpdate(att *models.Attendance, req models.UpdateAttendanceRequest) {
+	if req.ClockInTime != nil {
+		att.ClockInTime = req.ClockInTime
+	}
+	if req.ClockOutTime != nil {
+		att.ClockOutTime = req.ClockOutTime
+	}
+	if req.ClockOutNextDay != nil {
+		att.ClockOutNextDay = *req.ClockOutNextDay
+	}
+	if req.Status != "" {
+		att.Status = req.Status
+	} else if req.ClockInTime != nil && att.Status == models.AttendanceAbsent {
+		// 欠勤の記録に出勤時刻を記録した場合は出勤として照合する
+		att.Status = models.AttendancePresent
+	}
+}
+
+// validateAttendanceStatus ステータスを検証する（出勤時刻のある記録のステータスは保存時にシフトとの照合結果で置き換える）
+func validateAttendanceStatus(att models.Attendance) error {
+	switch att.Status {
+	case models.AttendancePresent, models.AttendanceLate, models.AttendanceEarlyLeave, models.AttendanceUnscheduled:
+		return nil
+	case models.AttendanceAbsent:
+		if att.ClockInTime != nil {
+			return &httpError{http.StatusBadRequest, "出勤時刻のある記録は欠勤にできません"}
+		}
+		return nil
+	}
+	return &httpError{http.StatusBadRequest, "無効なステータスです"}
+}
+
+// setActualHours 出退勤時刻から実労働時間を再計算する（出退勤のどちらかが未記録の場合は未設定にする）
+func setActualHours(att *models.Attendance) error {
+	span, ok, err := att.WorkedSpan()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		att.ActualHours = nil
+		return nil
+	}
+	hours := span.Hours()
+	att.ActualHours = &hours
+	return nil
+}
+
 // DeleteAttendance 出退勤記録を削除
-func DeleteAttendance(c echo.Context) error {
+func (h *Handler) DeleteAttendance(c echo.Context) error {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
@@ -216,27 +276,33 @@ func DeleteAttendance(c echo.Context) error {
 		})
 	}
 
-	result, err := database.DB.Exec("DELETE FROM attendance WHERE id = $1", id)
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "出退勤記録の削除に失敗しました",
-		})
-	}
-
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
+	// 同じシフトに照合していた他の記録の遅刻・早退・残業を判定し直す
+	err = h.repos.InTx(func(r *repository.Repositories) error {
+		att, err := r.Attendance.Get(id)
+		if err != nil {
+			return err
+		}
+		if err := r.Attendance.Delete(id); err != nil {
+			return err
+		}
+		return h.syncAttendance(r, att.EmployeeID, att.Date)
+	})
+	if err == repository.ErrNotFound {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "出退勤記録が見つかりません",
 		})
 	}
+	if err != nil {
+		return serverError(c, err, "出退勤記録の削除に失敗しました")
+	}
 
 	return c.JSON(http.StatusOK, map[string]string{
 		"message": "出退勤記録が削除されました",
 	})
 }
 
-// ClockIn 出勤記録
-func ClockIn(c echo.Context) error {
+// ClockIn 出勤記録（予定のシフトと照合し、遅刻の場合はステータスを遅刻にする）
+func (h *Handler) ClockIn(c echo.Context) error {
 	var req models.ClockInRequest
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
@@ -251,59 +317,79 @@ func ClockIn(c echo.Context) error {
 		})
 	}
 
+	// 従業員は自分の打刻のみ可能
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	if !acc.IsOwner() && !acc.IsSelf(req.EmployeeID) {
+		return forbidden(c)
+	}
+
 	// 従業員の存在確認
-	var employeeExists bool
-	err := database.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM employees WHERE id = $1)", req.EmployeeID).Scan(&employeeExists)
+	employeeExists, err := h.repos.Employees.Exists(req.EmployeeID)
 	if err != nil || !employeeExists {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"error": "指定された従業員が存在しません",
 		})
 	}
 
-	// 既存の記録を確認
-	var existingID int
-	err = database.DB.QueryRow("SELECT id FROM attendance WHERE employee_id = $1 AND date = $2", req.EmployeeID, req.Date).Scan(&existingID)
+	clockIn := models.Attendance{
+		EmployeeID:  req.EmployeeID,
+		Date:        req.Date,
+		ClockInTime: &req.Time,
+		Status:      models.AttendancePresent,
+	}
+	if _, err := models.ParseDate(req.Date); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "日付の形式が正しくありません",
+		})
+	}
+	if _, err := models.ParseClock(req.Time); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "時刻の形式が正しくありません",
+		})
+	}
 
-	if err != nil {
-		// 記録が存在しない場合は新規作成
-		var attendance models.Attendance
-		err = database.DB.QueryRow(`
-			INSERT INTO attendance (employee_id, date, clock_in_time, status) 
-			VALUES ($1, $2, $3, 'present') 
-			RETURNING id, employee_id, date, clock_in_time, clock_out_time, actual_hours, status, created_at, updated_at
-		`, req.EmployeeID, req.Date, req.Time).Scan(
-			&attendance.ID, &attendance.EmployeeID, &attendance.Date, &attendance.ClockInTime,
-			&attendance.ClockOutTime, &attendance.ActualHours, &attendance.Status, &attendance.CreatedAt, &attendance.UpdatedAt)
+	// 1日に複数回の出勤（分割シフト）を記録できるよう、打刻ごとに新しい記録を作成する
+	// 退勤していない出勤がある場合や、記録済みの勤務時間中の出勤は受け付けない
+	var attendance models.Attendance
+	err = h.repos.InTx(func(r *repository.Repositories) error {
+		if err := r.Employees.Lock(req.EmployeeID); err != nil {
+			return err
+		}
 
+		records, err := attendanceAround(r.Attendance, req.EmployeeID, req.Date)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{
-				"error": "出勤記録の作成に失敗しました",
-			})
+			return err
+		}
+		if open, found := findOpenAttendance(records, dateKey(req.Date)); found {
+			return &httpError{http.StatusConflict, "退勤が記録されていない出勤があります（" + dateKey(open.Date) + "）。先に退勤を記録してください"}
+		}
+		if err := checkAttendanceOverlap(records, clockIn); err != nil {
+			return err
 		}
 
-		return c.JSON(http.StatusCreated, attendance)
-	} else {
-		// 既存の記録を更新
-		_, err = database.DB.Exec(`
-			UPDATE attendance 
-			SET clock_in_time = $1, updated_at = CURRENT_TIMESTAMP
-			WHERE id = $2
-		`, req.Time, existingID)
-
+		created, err := r.Attendance.Create(clockIn)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{
-				"error": "出勤記録の更新に失敗しました",
-			})
+			return err
 		}
-
-		return c.JSON(http.StatusOK, map[string]string{
-			"message": "出勤記録が更新されました",
-		})
+		// 予定のシフトと照合して遅刻を判定する
+		if err := h.syncAttendance(r, created.EmployeeID, created.Date); err != nil {
+			return err
+		}
+		attendance, err = r.Attendance.Get(created.ID)
+		return err
+	})
+	if err != nil {
+		return respondError(c, err, "出勤記録の作成に失敗しました")
 	}
+
+	return c.JSON(http.StatusCreated, attendance)
 }
 
-// ClockOut 退勤記録
-func ClockOut(c echo.Context) error {
+// ClockOut 退勤記録（予定のシフトと照合し、早退・残業を判定する）
+func (h *Handler) ClockOut(c echo.Context) error {
 	var req models.ClockOutRequest
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
@@ -318,53 +404,174 @@ func ClockOut(c echo.Context) error {
 		})
 	}
 
+	// 従業員は自分の打刻のみ可能
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	if !acc.IsOwner() && !acc.IsSelf(req.EmployeeID) {
+		return forbidden(c)
+	}
+
 	// 従業員の存在確認
-	var employeeExists bool
-	err := database.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM employees WHERE id = $1)", req.EmployeeID).Scan(&employeeExists)
+	employeeExists, err := h.repos.Employees.Exists(req.EmployeeID)
 	if err != nil || !employeeExists {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"error": "指定された従業員が存在しません",
 		})
 	}
 
-	// 既存の記録を確認
-	var existingID int
-	var clockInTime string
-	err = database.DB.QueryRow("SELECT id, clock_in_time FROM attendance WHERE employee_id = $1 AND date = $2", req.EmployeeID, req.Date).Scan(&existingID, &clockInTime)
-
+	clockOut, err := models.ParseClock(req.Time)
 	if err != nil {
-		return c.JSON(http.StatusNotFound, map[string]string{
-			"error": "出勤記録が見つかりません。先に出勤記録を作成してください",
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "時刻の形式が正しくありません",
 		})
 	}
 
-	// 退勤時間を更新
-	_, err = database.DB.Exec(`
-		UPDATE attendance 
-		SET clock_out_time = $1, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $2
-	`, req.Time, existingID)
+	// 退勤していない出勤記録に退勤を記録する（日付をまたぐ勤務は前日の記録に退勤を記録する）
+	err = h.repos.InTx(func(r *repository.Repositories) error {
+		if err := r.Employees.Lock(req.EmployeeID); err != nil {
+			return err
+		}
 
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "退勤記録の更新に失敗しました",
+		records, err := attendanceAround(r.Attendance, req.EmployeeID, req.Date)
+		if err != nil {
+			return err
+		}
+		open, found := findOpenAttendance(records, dateKey(req.Date))
+		if !found {
+			return &httpError{http.StatusNotFound, "出勤記録が見つかりません。先に出勤記録を作成してください"}
+		}
+
+		open.ClockOutTime = &req.Time
+		open.ClockOutNextDay = dateKey(open.Date) != dateKey(req.Date)
+		if clockIn, err := models.ParseClock(*open.ClockInTime); err == nil && !open.ClockOutNextDay && clockOut < clockIn {
+			open.ClockOutNextDay = true
+		}
+		if err := setActualHours(&open); err != nil {
+			return &httpError{http.StatusBadRequest, spanErrorMessage(err)}
+		}
+		if err := checkAttendanceOverlap(records, open); err != nil {
+			return err
+		}
+		if err := r.Attendance.Update(open); err != nil {
+			return err
+		}
+		// 予定のシフトと照合して早退・残業を判定する
+		return h.syncAttendance(r, open.EmployeeID, open.Date)
+	})
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "指定された従業員が存在しません",
 		})
 	}
-
-	// 実際の勤務時間を計算
-	if clockInTime != "" {
-		clockIn, _ := time.Parse("15:04", clockInTime)
-		clockOut, _ := time.Parse("15:04", req.Time)
-		actualHours := clockOut.Sub(clockIn).Hours()
-
-		_, err = database.DB.Exec(`
-			UPDATE attendance 
-			SET actual_hours = $1
-			WHERE id = $2
-		`, actualHours, existingID)
+	if err != nil {
+		return respondError(c, err, "退勤記録の更新に失敗しました")
 	}
 
 	return c.JSON(http.StatusOK, map[string]string{
 		"message": "退勤記録が更新されました",
 	})
 }
+
+// lockAndCheckAttendanceOverlap 従業員をロックしたうえで、勤務時間が重なる同じ従業員の出退勤記録がないか確認する
+func lockAndCheckAttendanceOverlap(r *repository.Repositories, att models.Attendance) error {
+	if err := r.Employees.Lock(att.EmployeeID); err != nil {
+		if err == repository.ErrNotFound {
+			return &httpError{http.StatusBadRequest, "指定された従業員が存在しません"}
+		}
+		return err
+	}
+
+	records, err := attendanceAround(r.Attendance, att.EmployeeID, att.Date)
+	if err != nil {
+		return err
+	}
+	return checkAttendanceOverlap(records, att)
+}
+
+// attendanceAround 指定日と前後1日の従業員の出退勤記録（日付をまたぐ勤務を考慮するため）
+func attendanceAround(attendance repository.AttendanceRepository, employeeID int, date string) ([]models.Attendance, error) {
+	day, err := models.ParseDate(date)
+	if err != nil {
+		return nil, &httpError{http.StatusBadRequest, "日付の形式が正しくありません"}
+	}
+	return attendance.List(repository.AttendanceFilter{
+		EmployeeID: &employeeID,
+		StartDate:  day.AddDate(0, 0, -1).Format(models.DateLayout),
+		EndDate:    day.AddDate(0, 0, 1).Format(models.DateLayout),
+	})
+}
+
+// findOpenAttendance 退勤が記録されていない出勤を探す
+// 指定日の記録を優先し、なければ前日の記録（日付をまたぐ勤務）を返す
+func findOpenAttendance(records []models.Attendance, date string) (models.Attendance, bool) {
+	var previous *models.Attendance
+	for i := range records {
+		att := records[i]
+		if att.ClockInTime == nil || att.ClockOutTime != nil {
+			continue
+		}
+		switch {
+		case dateKey(att.Date) == date:
+			return att, true
+		case dateKey(att.Date) < date && previous == nil:
+			previous = &records[i]
+		}
+	}
+	if previous != nil {
+		return *previous, true
+	}
+	return models.Attendance{}, false
+}
+
+// checkAttendanceOverlap att の勤務時間（退勤前の場合は出勤時刻）が records の他の記録と重なる場合は409の httpError を返す
+func checkAttendanceOverlap(records []models.Attendance, att models.Attendance) error {
+	span, ok := attendancePeriod(att)
+	if !ok {
+		return nil
+	}
+	for _, other := range records {
+		if other.ID == att.ID {
+			continue
+		}
+		otherSpan, ok := attendancePeriod(other)
+		if !ok {
+			continue
+		}
+		if span.Overlaps(otherSpan) || span.Start.Equal(otherSpan.Start) {
+			return &httpError{http.StatusConflict, "勤務時間が重なる出退勤記録が既にあります（" + otherSpan.String() + "）"}
+		}
+	}
+	return nil
+}
+
+// attendancePeriod 出退勤記録が占める区間。退勤前の記録は出勤時刻のみの区間（長さ0）とする
+func attendancePeriod(att models.Attendance) (models.Span, bool) {
+	if att.ClockInTime == nil {
+		return models.Span{}, false
+	}
+	if att.ClockOutTime == nil {
+		day, errDate := models.ParseDate(att.Date)
+		clockIn, errClock := models.ParseClock(*att.ClockInTime)
+		if errDate != nil || errClock != nil {
+			return models.Span{}, false
+		}
+		at := day.Add(time.Duration(clockIn) * time.Minute)
+		return models.Span{Start: at, End: at}, true
+	}
+	span, ok, err := att.WorkedSpan()
+	if err != nil || !ok {
+		return models.Span{}, false
+	}
+	return span, true
+}
+
+// dateKey DB から読み込んだ日付（"2006-01-02T00:00:00Z"）を "2006-01-02" 形式に揃える
+func dateKey(date string) string {
+	day, err := models.ParseDate(date)
+	if err != nil {
+		return date
+	}
+	return day.Format(models.DateLayout)
+}
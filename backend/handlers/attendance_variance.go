@@ -0,0 +1,391 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"shift-management-backend/config"
+	"shift-management-backend/models"
+	"shift-management-backend/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetAttendanceVariance 期間の予定のシフトと出退勤記録を照合し、従業員ごと・日ごとの差異を返す（従業員は自分の分のみ）
+// 照合は現在のシフトで行い、遅刻・早退・残業の判定には設定（attendance）の猶予時間を使う
+func (h *Handler) GetAttendanceVariance(c echo.Context) error {
+	start, end, message := parseDateRange(c.QueryParam("start_date"), c.QueryParam("end_date"), maxRangeDays)
+	if message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	var employeeID *int
+	if employeeIDStr := c.QueryParam("employee_id"); employeeIDStr != "" {
+		id, err := strconv.Atoi(employeeIDStr)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "無効な従業員IDです",
+			})
+		}
+		employeeID = &id
+	}
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	if !acc.IsOwner() {
+		employeeID = &acc.EmployeeID
+	}
+
+	// 日付をまたぐ勤務を照合するため、前後1日のシフトと出退勤記録も取得する
+	from := start.AddDate(0, 0, -1).Format(models.DateLayout)
+	to := end.AddDate(0, 0, 1).Format(models.DateLayout)
+	// 従業員には公開済みのシフトと照合した結果を見せる
+	shifts, err := h.visibleShifts(acc, repository.ShiftFilter{EmployeeID: employeeID, StartDate: from, EndDate: to})
+	if err != nil {
+		return serverError(c, err, "シフトの取得に失敗しました")
+	}
+	records, err := h.repos.Attendance.List(repository.AttendanceFilter{EmployeeID: employeeID, StartDate: from, EndDate: to})
+	if err != nil {
+		return serverError(c, err, "出退勤記録の取得に失敗しました")
+	}
+
+	return c.JSON(http.StatusOK, models.AttendanceVarianceReport{
+		StartDate: start.Format(models.DateLayout),
+		EndDate:   end.Format(models.DateLayout),
+		Days: attendanceVariance(shifts, records, h.cfg.Attendance, start.Format(models.DateLayout),
+			end.Format(models.DateLayout), wallClock(time.Now())),
+	})
+}
+
+// syncAttendance 従業員の指定日と前後1日の出退勤記録を予定のシフトと照合し、ステータスと遅刻・早退・残業の時間を保存する
+func (h *Handler) syncAttendance(r *repository.Repositories, employeeID int, date string) error {
+	day, err := models.ParseDate(date)
+	if err != nil {
+		return err
+	}
+	// 前後1日の記録と同じシフトに照合される記録を含めるため、前後2日分を照合する
+	from := day.AddDate(0, 0, -2).Format(models.DateLayout)
+	to := day.AddDate(0, 0, 2).Format(models.DateLayout)
+	records, err := r.Attendance.List(repository.AttendanceFilter{EmployeeID: &employeeID, StartDate: from, EndDate: to})
+	if err != nil || len(records) == 0 {
+		return err
+	}
+	shifts, err := r.Shifts.List(repository.ShiftFilter{EmployeeID: &employeeID, StartDate: from, EndDate: to})
+	if err != nil {
+		return err
+	}
+
+	before := append([]models.Attendance(nil), records...)
+	reconcileAttendance(records, shifts, h.cfg.Attendance)
+
+	first := day.AddDate(0, 0, -1).Format(models.DateLayout)
+	last := day.AddDate(0, 0, 1).Format(models.DateLayout)
+	for i, att := range records {
+		if d := dateKey(att.Date); d < first || d > last || !attendanceChanged(before[i], att) {
+			continue
+		}
+		if err := r.Attendance.Update(att); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncShiftAttendance シフトの作成・変更・削除の後に、担当者の勤務日の出退勤記録を照合し直す
+// 変更の場合は変更前と変更後のシフトを渡す（担当者が決まっていないシフトは対象にしない）
+func (h *Handler) syncShiftAttendance(r *repository.Repositories, shifts ...models.Shift) error {
+	type key struct {
+		employeeID int
+		date       string
+	}
+	synced := make(map[key]bool, len(shifts))
+	for _, shift := range shifts {
+		k := key{shift.EmployeeID, dateKey(shift.Date)}
+		if shift.IsOpen() || synced[k] {
+			continue
+		}
+		synced[k] = true
+		if err := h.syncAttendance(r, k.employeeID, k.date); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scheduledShift 担当者が決まっている予定のシフトと勤務区間
+type scheduledShift struct {
+	shift models.Shift
+	span  models.Span
+}
+
+// scheduledShifts 照合の対象にするシフト（担当者が決まっていないシフトは除く）
+func scheduledShifts(shifts []models.Shift) []scheduledShift {
+	var scheduled []scheduledShift
+	for _, shift := range shifts {
+		if shift.IsOpen() {
+			continue
+		}
+		span, err := shift.Span()
+		if err != nil {
+			continue
+		}
+		scheduled = append(scheduled, scheduledShift{shift: shift, span: span})
+	}
+	sort.Slice(scheduled, func(i, j int) bool {
+		return scheduled[i].span.Start.Before(scheduled[j].span.Start)
+	})
+	return scheduled
+}
+
+// matchAttendanceShift 出退勤記録に対応する同じ従業員のシフトを選ぶ
+// 勤務時間が最も長く重なるシフト、重ならない場合（退勤前を含む）は出勤時刻を含むシフト、
+// それもない場合は勤務日が同じで出勤時刻より後に始まる最初のシフトとする
+func matchAttendanceShift(att models.Attendance, period models.Span, scheduled []scheduledShift) (scheduledShift, bool) {
+	var best scheduledShift
+	var bestOverlap time.Duration
+	for _, s := range scheduled {
+		if s.shift.EmployeeID != att.EmployeeID {
+			continue
+		}
+		if overlap, ok := period.Intersect(s.span); ok && overlap.Duration() > bestOverlap {
+			best, bestOverlap = s, overlap.Duration()
+		}
+	}
+	if bestOverlap > 0 {
+		return best, true
+	}
+
+	date := dateKey(att.Date)
+	for _, s := range scheduled {
+		if s.shift.EmployeeID == att.EmployeeID && !period.Start.Before(s.span.Start) && period.Start.Before(s.span.End) {
+			return s, true
+		}
+	}
+	for _, s := range scheduled {
+		if s.shift.EmployeeID == att.EmployeeID && dateKey(s.shift.Date) == date && period.Start.Before(s.span.Start) {
+			return s, true
+		}
+	}
+	return scheduledShift{}, false
+}
+
+// reconcileAttendance 出退勤記録を予定のシフトと照合し、照合したシフト・ステータス・遅刻・早退・残業の時間を設定する
+//   - 遅刻はシフトの最初の出勤、早退・残業はシフトの最後の退勤で判定する（退勤前の記録がある場合は早退・残業を判定しない）
+//   - 猶予時間を超えた場合は、遅れた（早かった）時間全体を遅刻（早退・残業）の時間とする
+//   - シフトに照合できない勤務はシフトのない勤務とし、勤務時間全体を残業の時間とする
+//   - 出勤時刻のない記録（欠勤など）は照合しない
+func reconcileAttendance(records []models.Attendance, shifts []models.Shift, grace config.AttendanceConfig) {
+	scheduled := scheduledShifts(shifts)
+	groups := make(map[int][]int)
+	for i := range records {
+		att := &records[i]
+		att.ShiftID = nil
+		att.LateMinutes, att.EarlyLeaveMinutes, att.OvertimeMinutes = 0, 0, 0
+		period, ok := attendancePeriod(*att)
+		if !ok {
+			continue
+		}
+		s, found := matchAttendanceShift(*att, period, scheduled)
+		if !found {
+			att.Status = models.AttendanceUnscheduled
+			att.OvertimeMinutes = wholeMinutes(period.Duration())
+			continue
+		}
+		id := s.shift.ID
+		att.ShiftID = &id
+		groups[id] = append(groups[id], i)
+	}
+
+	for _, s := range scheduled {
+		indexes := groups[s.shift.ID]
+		if len(indexes) == 0 {
+			continue
+		}
+		var firstStart, lastEnd time.Time
+		first, last, clockedOut := -1, -1, true
+		for _, i := range indexes {
+			period, _ := attendancePeriod(records[i])
+			if first < 0 || period.Start.Before(firstStart) {
+				first, firstStart = i, period.Start
+			}
+			if records[i].ClockOutTime == nil {
+				clockedOut = false
+				continue
+			}
+			if last < 0 || period.End.After(lastEnd) {
+				last, lastEnd = i, period.End
+			}
+		}
+
+		if late := firstStart.Sub(s.span.Start); late > grace.LateGrace.Duration {
+			records[first].LateMinutes = wholeMinutes(late)
+		}
+		if clockedOut && last >= 0 {
+			if early := s.span.End.Sub(lastEnd); early > grace.EarlyLeaveGrace.Duration {
+				records[last].EarlyLeaveMinutes = wholeMinutes(early)
+			}
+			if overtime := lastEnd.Sub(s.span.End); overtime > grace.OvertimeGrace.Duration {
+				records[last].OvertimeMinutes = wholeMinutes(overtime)
+			}
+		}
+		for _, i := range indexes {
+			switch {
+			case records[i].LateMinutes > 0:
+				records[i].Status = models.AttendanceLate
+			case records[i].EarlyLeaveMinutes > 0:
+				records[i].Status = models.AttendanceEarlyLeave
+			default:
+				records[i].Status = models.AttendancePresent
+			}
+		}
+	}
+}
+
+// attendanceVariance 期間内の勤務日について、従業員ごと・日ごとの予定のシフトと出退勤記録の差異を作る
+// now（サーバーのローカル時刻の日時）の時点で開始から猶予時間を過ぎても出勤のないシフトは無断欠勤とする
+func attendanceVariance(shifts []models.Shift, records []models.Attendance, grace config.AttendanceConfig, startDate, endDate string, now time.Time) []models.AttendanceVariance {
+	reconcileAttendance(records, shifts, grace)
+
+	type dayKey struct {
+		employeeID int
+		date       string
+	}
+	days := make(map[dayKey]*models.AttendanceVariance)
+	dayOf := func(employeeID int, name, date string) *models.AttendanceVariance {
+		key := dayKey{employeeID, date}
+		v, ok := days[key]
+		if !ok {
+			v = &models.AttendanceVariance{
+				EmployeeID:    employeeID,
+				EmployeeName:  name,
+				Date:          date,
+				Flags:         []string{},
+				ShiftIDs:      []int{},
+				AttendanceIDs: []int{},
+			}
+			days[key] = v
+		}
+		if v.EmployeeName == "" {
+			v.EmployeeName = name
+		}
+		return v
+	}
+	inRange := func(date string) bool {
+		return date >= startDate && date <= endDate
+	}
+
+	attended := make(map[int]bool)
+	absent := make(map[dayKey]bool)
+	for _, att := range records {
+		date := dateKey(att.Date)
+		if att.ShiftID != nil {
+			attended[*att.ShiftID] = true
+		}
+		if !inRange(date) {
+			continue
+		}
+		v := dayOf(att.EmployeeID, att.EmployeeName, date)
+		v.AttendanceIDs = append(v.AttendanceIDs, att.ID)
+		if att.ClockInTime == nil {
+			if att.Status == models.AttendanceAbsent {
+				absent[dayKey{att.EmployeeID, date}] = true
+			}
+			continue
+		}
+		if att.ClockOutTime == nil {
+			addFlag(v, models.VarianceMissingClockOut)
+		} else if span, ok, err := att.WorkedSpan(); err == nil && ok {
+			v.ActualMinutes += wholeMinutes(span.Duration())
+		}
+		v.LateMinutes += att.LateMinutes
+		v.EarlyLeaveMinutes += att.EarlyLeaveMinutes
+		v.OvertimeMinutes += att.OvertimeMinutes
+		if att.Status == models.AttendanceUnscheduled {
+			addFlag(v, models.VarianceUnscheduled)
+		}
+	}
+
+	for _, s := range scheduledShifts(shifts) {
+		date := dateKey(s.shift.Date)
+		if !inRange(date) {
+			continue
+		}
+		v := dayOf(s.shift.EmployeeID, s.shift.EmployeeName, date)
+		v.ShiftIDs = append(v.ShiftIDs, s.shift.ID)
+		v.ScheduledMinutes += wholeMinutes(s.span.Duration())
+		if attended[s.shift.ID] || now.Sub(s.span.Start) <= grace.NoShowGrace.Duration {
+			continue
+		}
+		if absent[dayKey{s.shift.EmployeeID, date}] {
+			addFlag(v, models.VarianceAbsent)
+		} else {
+			addFlag(v, models.VarianceNoShow)
+		}
+	}
+
+	result := make([]models.AttendanceVariance, 0, len(days))
+	for _, v := range days {
+		v.DifferenceMinutes = v.ActualMinutes - v.ScheduledMinutes
+		if v.LateMinutes > 0 {
+			addFlag(v, models.VarianceLate)
+		}
+		if v.EarlyLeaveMinutes > 0 {
+			addFlag(v, models.VarianceEarlyLeave)
+		}
+		if v.OvertimeMinutes > 0 && !hasFlag(v, models.VarianceUnscheduled) {
+			addFlag(v, models.VarianceOvertime)
+		}
+		result = append(result, *v)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Date != result[j].Date {
+			return result[i].Date < result[j].Date
+		}
+		return result[i].EmployeeID < result[j].EmployeeID
+	})
+	return result
+}
+
+// addFlag 差異の種類を重複しないように加える
+func addFlag(v *models.AttendanceVariance, flag string) {
+	if !hasFlag(v, flag) {
+		v.Flags = append(v.Flags, flag)
+	}
+}
+
+// hasFlag 差異の種類が含まれるか
+func hasFlag(v *models.AttendanceVariance, flag string) bool {
+	for _, f := range v.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// attendanceChanged 照合結果（照合したシフト・ステータス・遅刻・早退・残業の時間）が変わったか
+func attendanceChanged(before, after models.Attendance) bool {
+	if (before.ShiftID == nil) != (after.ShiftID == nil) || (before.ShiftID != nil && *before.ShiftID != *after.ShiftID) {
+		return true
+	}
+	return before.Status != after.Status ||
+		before.LateMinutes != after.LateMinutes ||
+		before.EarlyLeaveMinutes != after.EarlyLeaveMinutes ||
+		before.OvertimeMinutes != after.OvertimeMinutes
+}
+
+// wholeMinutes 時間を分に切り捨てる
+func wholeMinutes(d time.Duration) int {
+	return int(d / time.Minute)
+}
+
+// wallClock 現在時刻をサーバーのローカル時刻の日時として表す
+// シフト・出退勤記録の日時はタイムゾーンを持たないため、比較にはこの値を使う
+func wallClock(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), now.Second(), 0, time.UTC)
+}
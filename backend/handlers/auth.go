@@ -1,22 +1,19 @@
 package handlers
 
 import (
-	"crypto/rand"
-	"encoding/hex"
+	"log"
 	"net/http"
 
 	"shift-management-backend/database"
 	"shift-management-backend/models"
+	"shift-management-backend/repository"
 
 	"github.com/labstack/echo/v4"
 	"golang.org/x/crypto/bcrypt"
 )
 
-// セッション管理（簡易版）
-var sessions = make(map[string]models.User)
-
 // Login ログイン処理
-func Login(c echo.Context) error {
+func (h *Handler) Login(c echo.Context) error {
 	var req models.LoginRequest
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
@@ -32,13 +29,10 @@ func Login(c echo.Context) error {
 	}
 
 	// ユーザーを検索
-	var user models.User
-	err := database.DB.QueryRow(`
-		SELECT id, email, password_hash, role, name, employee_id, created_at, updated_at
-		FROM users 
-		WHERE email = $1
-	`, req.Email).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Role, &user.Name, &user.EmployeeID, &user.CreatedAt, &user.UpdatedAt)
-
+	user, err := h.repos.Users.FindByEmail(req.Email)
+	if err != nil && err != repository.ErrNotFound {
+		return serverError(c, err, "ログインに失敗しました")
+	}
 	if err != nil {
 		return c.JSON(http.StatusUnauthorized, map[string]string{
 			"error": "メールアドレスまたはパスワードが正しくありません",
@@ -53,28 +47,96 @@ func Login(c echo.Context) error {
 		})
 	}
 
-	// セッショントークンを生成
-	token := generateToken()
-	sessions[token] = user
+	// セッションとリフレッシュトークンを作成
+	refreshToken, session, err := h.sessions.Create(user, database.SessionMetadata{
+		UserAgent: c.Request().UserAgent(),
+		IPAddress: c.RealIP(),
+	})
+	if err != nil {
+		log.Printf("セッション作成エラー: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "ログインに失敗しました",
+		})
+	}
+
+	// アクセストークンを発行
+	accessToken, expiresAt, err := h.signer.Issue(user, session.ID)
+	if err != nil {
+		log.Printf("アクセストークン発行エラー: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "ログインに失敗しました",
+		})
+	}
 
 	// レスポンス
 	response := models.LoginResponse{
-		User:  user,
-		Token: token,
+		User:         user,
+		Token:        accessToken,
+		ExpiresAt:    expiresAt,
+		RefreshToken: refreshToken,
 	}
 
 	return c.JSON(http.StatusOK, response)
 }
 
+// Refresh リフレッシュトークンをローテーションしてアクセストークンを再発行
+func (h *Handler) Refresh(c echo.Context) error {
+	var req models.RefreshRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "リクエストの解析に失敗しました",
+		})
+	}
+
+	if req.RefreshToken == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "リフレッシュトークンは必須です",
+		})
+	}
+
+	refreshToken, user, session, err := h.sessions.Rotate(req.RefreshToken)
+	if err == database.ErrRefreshTokenReused {
+		log.Printf("使用済みリフレッシュトークンの再利用を検知しました。セッションを失効させました")
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "トークンが再利用されたため、セッションを無効にしました。再度ログインしてください",
+		})
+	}
+	if err == database.ErrSessionNotFound {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "セッションの有効期限が切れています。再度ログインしてください",
+		})
+	}
+	if err != nil {
+		log.Printf("リフレッシュトークン更新エラー: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "トークンの更新に失敗しました",
+		})
+	}
+
+	accessToken, expiresAt, err := h.signer.Issue(user, session.ID)
+	if err != nil {
+		log.Printf("アクセストークン発行エラー: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "トークンの更新に失敗しました",
+		})
+	}
+
+	return c.JSON(http.StatusOK, models.LoginResponse{
+		User:         user,
+		Token:        accessToken,
+		ExpiresAt:    expiresAt,
+		RefreshToken: refreshToken,
+	})
+}
+
 // Logout ログアウト処理
-func Logout(c echo.Context) error {
-	token := c.Request().Header.Get("Authorization")
-	if token != "" {
-		// Bearer プレフィックスを除去
-		if len(token) > 7 && token[:7] == "Bearer " {
-			token = token[7:]
+func (h *Handler) Logout(c echo.Context) error {
+	user, ok := CurrentUser(c)
+	sessionID, hasSession := c.Get(contextKeySessionID).(int)
+	if ok && hasSession {
+		if err := h.sessions.RevokeByID(user.ID, sessionID); err != nil && err != database.ErrSessionNotFound {
+			log.Printf("セッション失効エラー: %v", err)
 		}
-		delete(sessions, token)
 	}
 
 	return c.JSON(http.StatusOK, map[string]string{
@@ -83,31 +145,17 @@ func Logout(c echo.Context) error {
 }
 
 // GetProfile プロフィール取得
-func GetProfile(c echo.Context) error {
-	token := c.Request().Header.Get("Authorization")
-	if token == "" {
-		return c.JSON(http.StatusUnauthorized, map[string]string{
-			"error": "認証が必要です",
-		})
-	}
-
-	// Bearer プレフィックスを除去
-	if len(token) > 7 && token[:7] == "Bearer " {
-		token = token[7:]
-	}
-
-	user, exists := sessions[token]
-	if !exists {
-		return c.JSON(http.StatusUnauthorized, map[string]string{
-			"error": "無効なトークンです",
-		})
+func (h *Handler) GetProfile(c echo.Context) error {
+	user, ok := CurrentUser(c)
+	if !ok {
+		return unauthorized(c)
 	}
 
 	return c.JSON(http.StatusOK, user)
 }
 
 // Register ユーザー登録
-func Register(c echo.Context) error {
+func (h *Handler) Register(c echo.Context) error {
 	var req models.RegisterRequest
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
@@ -138,8 +186,7 @@ func Register(c echo.Context) error {
 
 	// 従業員の存在確認（employee_idが指定されている場合）
 	if req.EmployeeID != nil {
-		var employeeExists bool
-		err := database.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM employees WHERE id = $1)", *req.EmployeeID).Scan(&employeeExists)
+		employeeExists, err := h.repos.Employees.Exists(*req.EmployeeID)
 		if err != nil || !employeeExists {
 			return c.JSON(http.StatusBadRequest, map[string]string{
 				"error": "指定された従業員が存在しません",
@@ -148,14 +195,13 @@ func Register(c echo.Context) error {
 	}
 
 	// ユーザーを作成
-	var user models.User
-	err = database.DB.QueryRow(`
-		INSERT INTO users (email, password_hash, role, name, employee_id) 
-		VALUES ($1, $2, $3, $4, $5) 
-		RETURNING id, email, role, name, employee_id, created_at, updated_at
-	`, req.Email, string(hashedPassword), req.Role, req.Name, req.EmployeeID).Scan(
-		&user.ID, &user.Email, &user.Role, &user.Name, &user.EmployeeID, &user.CreatedAt, &user.UpdatedAt)
-
+	user, err := h.repos.Users.Create(models.User{
+		Email:        req.Email,
+		PasswordHash: string(hashedPassword),
+		Role:         req.Role,
+		Name:         req.Name,
+		EmployeeID:   req.EmployeeID,
+	})
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "ユーザーの作成に失敗しました",
@@ -165,15 +211,8 @@ func Register(c echo.Context) error {
 	return c.JSON(http.StatusCreated, user)
 }
 
-// generateToken セッショントークンを生成
-func generateToken() string {
-	b := make([]byte, 16)
-	rand.Read(b)
-	return hex.EncodeToString(b)
-}
-
 // CreateTestUser テスト用ユーザーを作成
-func CreateTestUser(c echo.Context) error {
+func (h *Handler) CreateTestUser(c echo.Context) error {
 	// テスト用のオーナーユーザーを作成（より安全なパスワード）
 	testUser := models.RegisterRequest{
 		Email:    "owner@test.com",
@@ -191,8 +230,7 @@ func CreateTestUser(c echo.Context) error {
 	}
 
 	// ユーザーが既に存在するかチェック
-	var exists bool
-	err = database.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)", testUser.Email).Scan(&exists)
+	exists, err := h.repos.Users.ExistsByEmail(testUser.Email)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "ユーザー存在チェックに失敗しました",
@@ -208,14 +246,12 @@ func CreateTestUser(c echo.Context) error {
 	}
 
 	// ユーザーを作成
-	var user models.User
-	err = database.DB.QueryRow(`
-		INSERT INTO users (email, password_hash, role, name) 
-		VALUES ($1, $2, $3, $4) 
-		RETURNING id, email, role, name, created_at, updated_at
-	`, testUser.Email, string(hashedPassword), testUser.Role, testUser.Name).Scan(
-		&user.ID, &user.Email, &user.Role, &user.Name, &user.CreatedAt, &user.UpdatedAt)
-
+	user, err := h.repos.Users.Create(models.User{
+		Email:        testUser.Email,
+		PasswordHash: string(hashedPassword),
+		Role:         testUser.Role,
+		Name:         testUser.Name,
+	})
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "ユーザーの作成に失敗しました",
@@ -233,7 +269,7 @@ func CreateTestUser(c echo.Context) error {
 }
 
 // CreateTestEmployee テスト用従業員ユーザーを作成
-func CreateTestEmployee(c echo.Context) error {
+func (h *Handler) CreateTestEmployee(c echo.Context) error {
 	// テスト用の従業員ユーザーを作成
 	testEmployee := models.RegisterRequest{
 		Email:    "employee@test.com",
@@ -251,8 +287,7 @@ func CreateTestEmployee(c echo.Context) error {
 	}
 
 	// ユーザーが既に存在するかチェック
-	var exists bool
-	err = database.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)", testEmployee.Email).Scan(&exists)
+	exists, err := h.repos.Users.ExistsByEmail(testEmployee.Email)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "ユーザー存在チェックに失敗しました",
@@ -267,33 +302,28 @@ func CreateTestEmployee(c echo.Context) error {
 		})
 	}
 
-	// 従業員レコードを作成
-	var employeeID int
-	err = database.DB.QueryRow(`
-		INSERT INTO employees (name, email, hourly_wage) 
-		VALUES ($1, $2, $3) 
-		RETURNING id
-	`, testEmployee.Name, testEmployee.Email, 1000).Scan(&employeeID)
-
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "従業員レコードの作成に失敗しました",
-		})
-	}
-
-	// ユーザーを作成
+	// 従業員レコードとユーザーを作成
 	var user models.User
-	err = database.DB.QueryRow(`
-		INSERT INTO users (email, password_hash, role, name, employee_id) 
-		VALUES ($1, $2, $3, $4, $5) 
-		RETURNING id, email, role, name, employee_id, created_at, updated_at
-	`, testEmployee.Email, string(hashedPassword), testEmployee.Role, testEmployee.Name, employeeID).Scan(
-		&user.ID, &user.Email, &user.Role, &user.Name, &user.EmployeeID, &user.CreatedAt, &user.UpdatedAt)
+	err = h.repos.InTx(func(r *repository.Repositories) error {
+		employee, err := r.Employees.Create(models.CreateEmployeeRequest{
+			Name:       testEmployee.Name,
+			HourlyWage: h.cfg.Payroll.DefaultHourlyWage,
+		})
+		if err != nil {
+			return err
+		}
 
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "ユーザーの作成に失敗しました",
+		user, err = r.Users.Create(models.User{
+			Email:        testEmployee.Email,
+			PasswordHash: string(hashedPassword),
+			Role:         testEmployee.Role,
+			Name:         testEmployee.Name,
+			EmployeeID:   &employee.ID,
 		})
+		return err
+	})
+	if err != nil {
+		return serverError(c, err, "テスト従業員ユーザーの作成に失敗しました")
 	}
 
 	return c.JSON(http.StatusCreated, map[string]interface{}{
@@ -307,16 +337,13 @@ func CreateTestEmployee(c echo.Context) error {
 }
 
 // DeleteTestUser テスト用ユーザーを削除
-func DeleteTestUser(c echo.Context) error {
+func (h *Handler) DeleteTestUser(c echo.Context) error {
 	// テストユーザーを削除
-	result, err := database.DB.Exec("DELETE FROM users WHERE email = 'owner@test.com'")
+	rowsAffected, err := h.repos.Users.DeleteByEmail("owner@test.com")
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "ユーザー削除に失敗しました",
-		})
+		return serverError(c, err, "ユーザー削除に失敗しました")
 	}
 
-	rowsAffected, _ := result.RowsAffected()
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"message":       "テストユーザーを削除しました",
 		"rows_affected": rowsAffected,
@@ -324,17 +351,35 @@ func DeleteTestUser(c echo.Context) error {
 }
 
 // GetCurrentUser 現在のユーザーを取得（ミドルウェア用）
-func GetCurrentUser(c echo.Context) (models.User, bool) {
-	token := c.Request().Header.Get("Authorization")
-	if token == "" {
+// アクセストークンの署名を検証したうえで、セッションが失効していないことも確認する
+func (h *Handler) GetCurrentUser(c echo.Context) (models.User, bool) {
+	accessToken := bearerToken(c)
+	if accessToken == "" || h.signer == nil {
 		return models.User{}, false
 	}
 
-	// Bearer プレフィックスを除去
-	if len(token) > 7 && token[:7] == "Bearer " {
-		token = token[7:]
+	claims, err := h.signer.Parse(accessToken)
+	if err != nil {
+		return models.User{}, false
 	}
 
-	user, exists := sessions[token]
-	return user, exists
+	user, session, err := h.sessions.Get(claims.SessionID)
+	if err != nil {
+		if err != database.ErrSessionNotFound {
+			log.Printf("セッション取得エラー: %v", err)
+		}
+		return models.User{}, false
+	}
+
+	c.Set(contextKeySessionID, session.ID)
+	return user, true
+}
+
+// bearerToken AuthorizationヘッダーからBearerトークンを取り出す
+func bearerToken(c echo.Context) string {
+	token := c.Request().Header.Get(echo.HeaderAuthorization)
+	if len(token) > 7 && token[:7] == "Bearer " {
+		return token[7:]
+	}
+	return ""
 }
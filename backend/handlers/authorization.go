@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"shift-management-backend/models"
+	"shift-management-backend/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// access リクエストしたユーザーの権限情報
+// オーナーはすべて許可、従業員は permissions テーブルのフラグに従う
+type access struct {
+	User       models.User
+	EmployeeID int // 従業員ロールの場合のみ
+	Permission models.EmployeePermission
+}
+
+// IsOwner オーナーかどうか
+func (a access) IsOwner() bool {
+	return a.User.Role == RoleOwner
+}
+
+// IsSelf 指定した従業員が自分自身かどうか
+func (a access) IsSelf(employeeID int) bool {
+	return !a.IsOwner() && a.EmployeeID == employeeID
+}
+
+// CanViewShiftsOf 指定した従業員のシフトを閲覧できるか
+func (a access) CanViewShiftsOf(employeeID int) bool {
+	return a.IsOwner() || a.IsSelf(employeeID) || a.Permission.CanViewOtherShifts
+}
+
+// ShiftFilter シフト一覧を自分のシフトに絞り込む必要がある場合は従業員IDを返す
+func (a access) ShiftFilter() (int, bool) {
+	if a.IsOwner() || a.Permission.CanViewOtherShifts {
+		return 0, false
+	}
+	return a.EmployeeID, true
+}
+
+// CanViewPayrollOf 指定した従業員の給与を閲覧できるか
+func (a access) CanViewPayrollOf(employeeID int) bool {
+	return a.IsOwner() || (a.IsSelf(employeeID) && a.Permission.CanViewPayroll)
+}
+
+// CanEditAttendanceOf 指定した従業員の出退勤記録を編集できるか
+func (a access) CanEditAttendanceOf(employeeID int) bool {
+	return a.IsOwner() || (a.IsSelf(employeeID) && a.Permission.CanEditAttendance)
+}
+
+// CanSubmitShiftRequestFor 指定した従業員のシフト希望を提出できるか
+func (a access) CanSubmitShiftRequestFor(employeeID int) bool {
+	return a.IsOwner() || (a.IsSelf(employeeID) && a.Permission.CanSubmitShiftRequests)
+}
+
+// callerAccess リクエストしたユーザーの権限情報を取得
+// 取得できない場合はHTTPステータスとエラーメッセージを返す
+func (h *Handler) callerAccess(c echo.Context) (access, int, string) {
+	user, ok := CurrentUser(c)
+	if !ok {
+		return access{}, http.StatusUnauthorized, "認証が必要です"
+	}
+
+	if user.Role == RoleOwner {
+		return access{User: user}, 0, ""
+	}
+
+	if user.EmployeeID == nil {
+		return access{}, http.StatusForbidden, "従業員情報が紐づいていないユーザーです"
+	}
+
+	permission, err := h.loadEmployeePermission(*user.EmployeeID)
+	if err != nil {
+		log.Printf("権限設定取得エラー: %v", err)
+		return access{}, http.StatusInternalServerError, "権限設定の取得に失敗しました"
+	}
+
+	return access{User: user, EmployeeID: *user.EmployeeID, Permission: permission}, 0, ""
+}
+
+// loadEmployeePermission 従業員の権限設定を取得（未設定の場合はデフォルト値）
+func (h *Handler) loadEmployeePermission(employeeID int) (models.EmployeePermission, error) {
+	permission, err := h.repos.Permissions.GetByEmployee(employeeID)
+	if err == repository.ErrNotFound {
+		return defaultPermission(employeeID), nil
+	}
+	return permission, err
+}
+
+// defaultPermission 権限設定が未登録の従業員に適用する権限
+func defaultPermission(employeeID int) models.EmployeePermission {
+	return models.EmployeePermission{
+		EmployeeID:             employeeID,
+		CanSubmitShiftRequests: true, // デフォルトで許可
+	}
+}
+
+// forbidden 権限エラーのレスポンスを返す
+func forbidden(c echo.Context) error {
+	return c.JSON(http.StatusForbidden, map[string]string{
+		"error": "この操作を行う権限がありません",
+	})
+}
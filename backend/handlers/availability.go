@@ -0,0 +1,519 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"shift-management-backend/models"
+	"shift-management-backend/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxAvailabilityWindows 1件の勤務可能な時間帯に指定できる時間帯の数（1週間分）
+const maxAvailabilityWindows = 7 * maxShiftRequestWindows
+
+// GetAvailabilityProfiles 勤務可能な時間帯の一覧を取得（従業員は自分の設定のみ）
+func (h *Handler) GetAvailabilityProfiles(c echo.Context) error {
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+
+	var employeeID *int
+	if !acc.IsOwner() {
+		employeeID = &acc.EmployeeID
+	} else if employeeIDStr := c.QueryParam("employee_id"); employeeIDStr != "" {
+		id, err := strconv.Atoi(employeeIDStr)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "無効な従業員IDです",
+			})
+		}
+		employeeID = &id
+	}
+
+	profiles, err := h.repos.Availability.List(employeeID)
+	if err != nil {
+		return serverError(c, err, "勤務可能な時間帯の取得に失敗しました")
+	}
+	if profiles == nil {
+		profiles = []models.AvailabilityProfile{}
+	}
+
+	return c.JSON(http.StatusOK, profiles)
+}
+
+// GetAvailabilityProfile 勤務可能な時間帯の詳細を取得
+func (h *Handler) GetAvailabilityProfile(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効なIDです",
+		})
+	}
+
+	profile, err := h.repos.Availability.Get(id)
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "勤務可能な時間帯が見つかりません",
+		})
+	}
+	if err != nil {
+		return serverError(c, err, "勤務可能な時間帯の取得に失敗しました")
+	}
+
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	if !acc.IsOwner() && !acc.IsSelf(profile.EmployeeID) {
+		return forbidden(c)
+	}
+
+	return c.JSON(http.StatusOK, profile)
+}
+
+// CreateAvailabilityProfile 勤務可能な時間帯を作成
+// 同じ従業員の他の勤務可能な時間帯と適用期間が重なる場合は作成しない
+func (h *Handler) CreateAvailabilityProfile(c echo.Context) error {
+	var req models.AvailabilityProfileRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "リクエストの解析に失敗しました",
+		})
+	}
+
+	profile, err := newAvailabilityProfile(req)
+	if err != nil {
+		return respondError(c, err, "勤務可能な時間帯の作成に失敗しました")
+	}
+
+	// シフト希望提出権限の確認
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	if !acc.CanSubmitShiftRequestFor(profile.EmployeeID) {
+		return forbidden(c)
+	}
+
+	err = h.repos.InTx(func(r *repository.Repositories) error {
+		if err := checkAvailabilityProfile(r, profile); err != nil {
+			return err
+		}
+		created, err := r.Availability.Create(profile)
+		if err != nil {
+			return err
+		}
+		profile = created
+		return nil
+	})
+	if err != nil {
+		return respondError(c, err, "勤務可能な時間帯の作成に失敗しました")
+	}
+
+	return c.JSON(http.StatusCreated, profile)
+}
+
+// UpdateAvailabilityProfile 勤務可能な時間帯を更新（時間帯を含む全項目を置き換える）
+func (h *Handler) UpdateAvailabilityProfile(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効なIDです",
+		})
+	}
+
+	var req models.AvailabilityProfileRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "リクエストの解析に失敗しました",
+		})
+	}
+
+	profile, err := newAvailabilityProfile(req)
+	if err != nil {
+		return respondError(c, err, "勤務可能な時間帯の更新に失敗しました")
+	}
+	profile.ID = id
+
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+
+	err = h.repos.InTx(func(r *repository.Repositories) error {
+		existing, err := r.Availability.Get(id)
+		if err != nil {
+			return err
+		}
+		if !acc.CanSubmitShiftRequestFor(existing.EmployeeID) || !acc.CanSubmitShiftRequestFor(profile.EmployeeID) {
+			return &httpError{http.StatusForbidden, "この操作を行う権限がありません"}
+		}
+		if err := checkAvailabilityProfile(r, profile); err != nil {
+			return err
+		}
+		if err := r.Availability.Update(profile); err != nil {
+			return err
+		}
+		profile, err = r.Availability.Get(id)
+		return err
+	})
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "勤務可能な時間帯が見つかりません",
+		})
+	}
+	if err != nil {
+		return respondError(c, err, "勤務可能な時間帯の更新に失敗しました")
+	}
+
+	return c.JSON(http.StatusOK, profile)
+}
+
+// DeleteAvailabilityProfile 勤務可能な時間帯を削除（作成済みのシフト希望は削除しない）
+func (h *Handler) DeleteAvailabilityProfile(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効なIDです",
+		})
+	}
+
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+
+	err = h.repos.InTx(func(r *repository.Repositories) error {
+		profile, err := r.Availability.Get(id)
+		if err != nil {
+			return err
+		}
+		if !acc.CanSubmitShiftRequestFor(profile.EmployeeID) {
+			return &httpError{http.StatusForbidden, "この操作を行う権限がありません"}
+		}
+		return r.Availability.Delete(id)
+	})
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "勤務可能な時間帯が見つかりません",
+		})
+	}
+	if err != nil {
+		return respondError(c, err, "勤務可能な時間帯の削除に失敗しました")
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "勤務可能な時間帯が削除されました",
+	})
+}
+
+// PrefillShiftRequests 勤務可能な時間帯から期間内のシフト希望を作成する
+// 勤務できる希望（勤務したい・必要なら勤務できる・終日勤務できる）が既にある日、勤務可能な時間帯のない日、
+// 提出期間の受付外の日、時間帯が重なるシフト希望がある日は作成しない
+func (h *Handler) PrefillShiftRequests(c echo.Context) error {
+	var req models.PrefillShiftRequestsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "リクエストの解析に失敗しました",
+		})
+	}
+
+	if req.EmployeeID == 0 || req.StartDate == "" || req.EndDate == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "必須項目が不足しています",
+		})
+	}
+	start, end, message := parseDateRange(req.StartDate, req.EndDate, maxRangeDays)
+	if message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+	if req.Availability == "" {
+		req.Availability = models.ShiftRequestPreferred
+	}
+	if req.Availability != models.ShiftRequestPreferred && req.Availability != models.ShiftRequestIfNeeded {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "作成するシフト希望の種類は preferred または if_needed を指定してください",
+		})
+	}
+
+	// シフト希望提出権限の確認
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	if !acc.CanSubmitShiftRequestFor(req.EmployeeID) {
+		return forbidden(c)
+	}
+
+	res := models.PrefillShiftRequestsResponse{
+		Created: []models.ShiftRequest{},
+		Skipped: []models.PrefillSkippedDate{},
+	}
+	err := h.repos.InTx(func(r *repository.Repositories) error {
+		exists, err := r.Employees.Exists(req.EmployeeID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return &httpError{http.StatusBadRequest, "指定された従業員が存在しません"}
+		}
+		profiles, err := r.Availability.List(&req.EmployeeID)
+		if err != nil {
+			return err
+		}
+		if len(profiles) == 0 {
+			return &httpError{http.StatusBadRequest, "勤務可能な時間帯が登録されていません"}
+		}
+		requests, err := r.ShiftRequests.List(&req.EmployeeID)
+		if err != nil {
+			return err
+		}
+		requested := make(map[string]bool)
+		for _, sr := range requests {
+			if sr.Availability != models.ShiftRequestUnavailable && sr.Status != models.ShiftRequestRejected {
+				requested[dateKey(sr.Date)] = true
+			}
+		}
+
+		now := time.Now()
+		for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+			date := day.Format(models.DateLayout)
+			skip := func(reason string) {
+				res.Skipped = append(res.Skipped, models.PrefillSkippedDate{Date: date, Reason: reason})
+			}
+			if requested[date] {
+				skip("シフト希望が既にあります")
+				continue
+			}
+			profile, ok := models.ActiveProfile(profiles, day)
+			if !ok || len(profile.WindowsOn(day)) == 0 {
+				skip("勤務可能な時間帯がありません")
+				continue
+			}
+
+			shiftReq := models.ShiftRequest{
+				EmployeeID:   req.EmployeeID,
+				Date:         date,
+				Availability: req.Availability,
+				Status:       models.ShiftRequestSubmitted,
+			}
+			for _, w := range profile.WindowsOn(day) {
+				shiftReq.Windows = append(shiftReq.Windows, models.ShiftRequestWindow{
+					StartTime:   w.StartTime,
+					EndTime:     w.EndTime,
+					EndsNextDay: w.EndsNextDay,
+				})
+			}
+			shiftReq.PeriodID, shiftReq.Late, err = checkSubmissionPeriod(r, acc, date, now)
+			if err == nil {
+				err = checkShiftRequestConflict(r, shiftReq)
+			}
+			if he, ok := err.(*httpError); ok {
+				skip(he.message)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			created, err := r.ShiftRequests.Create(shiftReq)
+			if err != nil {
+				return err
+			}
+			res.Created = append(res.Created, created)
+		}
+		return nil
+	})
+	if err != nil {
+		return respondError(c, err, "シフト希望の作成に失敗しました")
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// newAvailabilityProfile 作成・更新リクエストから勤務可能な時間帯を組み立てて検証する
+func newAvailabilityProfile(req models.AvailabilityProfileRequest) (models.AvailabilityProfile, error) {
+	if req.EmployeeID == 0 || req.ValidFrom == "" {
+		return models.AvailabilityProfile{}, &httpError{http.StatusBadRequest, "必須項目が不足しています"}
+	}
+	if req.ValidTo != nil && *req.ValidTo == "" {
+		req.ValidTo = nil
+	}
+	profile := models.AvailabilityProfile{
+		EmployeeID: req.EmployeeID,
+		Name:       strings.TrimSpace(req.Name),
+		ValidFrom:  req.ValidFrom,
+		ValidTo:    req.ValidTo,
+		Windows:    append([]models.AvailabilityWindow{}, req.Windows...),
+		Note:       strings.TrimSpace(req.Note),
+	}
+
+	if len([]rune(profile.Name)) > 100 {
+		return models.AvailabilityProfile{}, &httpError{http.StatusBadRequest, "名前は100文字以内で入力してください"}
+	}
+	if len([]rune(profile.Note)) > maxShiftRequestNoteLength {
+		return models.AvailabilityProfile{}, &httpError{http.StatusBadRequest, "メモは" + strconv.Itoa(maxShiftRequestNoteLength) + "文字以内で入力してください"}
+	}
+	if len(profile.Windows) > maxAvailabilityWindows {
+		return models.AvailabilityProfile{}, &httpError{http.StatusBadRequest, "時間帯は" + strconv.Itoa(maxAvailabilityWindows) + "個以内で指定してください"}
+	}
+	if err := profile.Validate(); err != nil {
+		switch err {
+		case models.ErrAvailabilityWeekday, models.ErrAvailabilityDateRange:
+			return models.AvailabilityProfile{}, &httpError{http.StatusBadRequest, err.Error()}
+		}
+		return models.AvailabilityProfile{}, &httpError{http.StatusBadRequest, spanErrorMessage(err)}
+	}
+	return profile, nil
+}
+
+// checkAvailabilityProfile 従業員の存在と、同じ従業員の他の勤務可能な時間帯と適用期間が重ならないかを確認する
+func checkAvailabilityProfile(r *repository.Repositories, profile models.AvailabilityProfile) error {
+	exists, err := r.Employees.Exists(profile.EmployeeID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return &httpError{http.StatusBadRequest, "指定された従業員が存在しません"}
+	}
+
+	profiles, err := r.Availability.List(&profile.EmployeeID)
+	if err != nil {
+		return err
+	}
+	for _, p := range profiles {
+		if p.ID == profile.ID || !p.Overlaps(profile) {
+			continue
+		}
+		validTo := "終了日なし"
+		if p.ValidTo != nil {
+			validTo = dateKey(*p.ValidTo)
+		}
+		return &httpError{http.StatusConflict, "適用期間が他の勤務可能な時間帯（" + dateKey(p.ValidFrom) + "〜" + validTo + "）と重なっています"}
+	}
+	return nil
+}
+
+// checkAvailability 保存前のシフトが担当者の勤務可能な時間帯に含まれるか確認する
+// 勤務日に適用する勤務可能な時間帯がない場合と、シフトを含む勤務できる希望がある場合は確認しない
+// 設定（scheduling.availability）がエラーの場合は422の httpError を返し、警告の場合は警告を返す
+func (h *Handler) checkAvailability(r *repository.Repositories, shift models.Shift) ([]models.LaborViolation, error) {
+	severity := h.cfg.Scheduling.Availability
+	if severity == models.LaborSeverityOff || shift.IsOpen() {
+		return nil, nil
+	}
+	span, err := shift.Span()
+	if err != nil {
+		return nil, err
+	}
+	day, err := models.ParseDate(shift.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles, err := r.Availability.List(&shift.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+	profile, ok := models.ActiveProfile(profiles, day)
+	if !ok {
+		return nil, nil
+	}
+
+	requests, err := r.ShiftRequests.List(&shift.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+	date := dateKey(shift.Date)
+	for _, req := range requests {
+		if dateKey(req.Date) != date || req.Availability == models.ShiftRequestUnavailable || req.Status == models.ShiftRequestRejected {
+			continue
+		}
+		spans, err := req.Spans()
+		if err != nil {
+			continue
+		}
+		if containsSpan(spans, span) {
+			return nil, nil
+		}
+	}
+
+	// 前日から日付をまたぐ時間帯も含めて確認する
+	var spans []models.Span
+	for _, d := range []time.Time{day.AddDate(0, 0, -1), day} {
+		p, ok := models.ActiveProfile(profiles, d)
+		if !ok {
+			continue
+		}
+		daySpans, err := p.Spans(d)
+		if err != nil {
+			return nil, err
+		}
+		spans = append(spans, daySpans...)
+	}
+	if containsSpan(spans, span) {
+		return nil, nil
+	}
+
+	var windows []string
+	for _, w := range profile.WindowsOn(day) {
+		if s, err := models.NewSpan(date, w.StartTime, w.EndTime, w.EndsNextDay); err == nil {
+			windows = append(windows, clockRangeLabel(s))
+		}
+	}
+	message := date + "（" + models.GetDayOfWeekName(int(day.Weekday())) + "）のシフト（" + clockRangeLabel(span) + "）が"
+	if len(windows) == 0 {
+		message += "勤務可能な時間帯のない曜日に入っています"
+	} else {
+		message += "勤務可能な時間帯（" + strings.Join(windows, "、") + "）の外にあります"
+	}
+	if severity == models.LaborSeverityError {
+		return nil, &httpError{http.StatusUnprocessableEntity, message}
+	}
+	return []models.LaborViolation{{
+		Rule:         models.ShiftRuleOutsideAvailability,
+		Severity:     severity,
+		EmployeeID:   shift.EmployeeID,
+		EmployeeName: shift.EmployeeName,
+		Date:         date,
+		ShiftIDs:     []int{shift.ID},
+		Message:      message,
+	}}, nil
+}
+
+// containsSpan 区間全体がいずれかの区間（連続する区間はつなげたもの）に含まれるか
+func containsSpan(spans []models.Span, target models.Span) bool {
+	sorted := append([]models.Span(nil), spans...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+	var merged []models.Span
+	for _, s := range sorted {
+		if n := len(merged); n > 0 && !s.Start.After(merged[n-1].End) {
+			if s.End.After(merged[n-1].End) {
+				merged[n-1].End = s.End
+			}
+			continue
+		}
+		merged = append(merged, s)
+	}
+	for _, s := range merged {
+		if !s.Start.After(target.Start) && !s.End.Before(target.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// clockRangeLabel 区間の表示用の時刻の範囲（例: "22:00〜翌03:00"）
+func clockRangeLabel(s models.Span) string {
+	label := s.String()
+	return label[strings.Index(label, " ")+1:]
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"shift-management-backend/models"
+	"shift-management-backend/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetCoverageSummary 時間帯ごとのカバレッジサマリーを取得
+// 配置人数は shifts から計算する。期間は次のいずれかで指定する
+//   - date: 指定日（省略時は今日）
+//   - start_date, end_date: 指定期間
+//   - view=week / view=month と date: date を含む週（日曜始まり）または月
+func (h *Handler) GetCoverageSummary(c echo.Context) error {
+	startDate, endDate, message := coverageRange(c.QueryParam("date"), c.QueryParam("start_date"), c.QueryParam("end_date"), c.QueryParam("view"))
+	if message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	timeSlots, err := h.repos.TimeSlots.List(nil)
+	if err != nil {
+		return serverError(c, err, "時間帯設定の取得に失敗しました")
+	}
+
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+
+	// 日付をまたぐシフト・時間帯を考慮して前後1日のシフトも取得する
+	// 従業員には公開済みのシフトだけで計算する
+	shifts, err := h.visibleShifts(acc, repository.ShiftFilter{
+		StartDate: startDate.AddDate(0, 0, -1).Format(models.DateLayout),
+		EndDate:   endDate.AddDate(0, 0, 1).Format(models.DateLayout),
+	})
+	if err != nil {
+		return serverError(c, err, "シフトデータの取得に失敗しました")
+	}
+
+	return c.JSON(http.StatusOK, computeCoverage(timeSlots, shifts, startDate, endDate))
+}
+
+// coverageRange クエリパラメータからカバレッジの対象期間を決める
+// 不正な指定の場合はエラーメッセージを返す
+func coverageRange(date, start, end, view string) (time.Time, time.Time, string) {
+	if start != "" || end != "" {
+		return parseDateRange(start, end, maxRangeDays)
+	}
+
+	if date == "" {
+		date = time.Now().Format(models.DateLayout)
+	}
+	targetDate, err := time.Parse(models.DateLayout, date)
+	if err != nil {
+		return time.Time{}, time.Time{}, "無効な日付です"
+	}
+
+	switch view {
+	case "", "day":
+		return targetDate, targetDate, ""
+	case "week":
+		startDate := targetDate.AddDate(0, 0, -int(targetDate.Weekday()))
+		return startDate, startDate.AddDate(0, 0, 6), ""
+	case "month":
+		startDate := time.Date(targetDate.Year(), targetDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return startDate, startDate.AddDate(0, 1, -1), ""
+	default:
+		return time.Time{}, time.Time{}, "view は day / week / month のいずれかを指定してください"
+	}
+}
+
+// computeCoverage 期間内の各日について、時間帯ごとに同じポジションを担当する勤務時間が重なるシフトの従業員数を数える
+// 時間帯は曜日ごとに適用し、日付をまたぐ時間帯・シフトは翌日の終了時刻まで重なりを判定する
+// ポジションが未設定のシフトと、担当者が決まっていないシフトはどの時間帯にも数えない
+func computeCoverage(timeSlots []models.TimeSlot, shifts []models.Shift, startDate, endDate time.Time) []models.CoverageSummary {
+	type assignment struct {
+		employeeID int
+		span       models.PositionSpan
+	}
+	var assignments []assignment
+	for _, shift := range shifts {
+		if shift.IsOpen() {
+			continue
+		}
+		spans, err := shift.PositionSpans()
+		if err != nil {
+			continue
+		}
+		for _, span := range spans {
+			assignments = append(assignments, assignment{employeeID: shift.EmployeeID, span: span})
+		}
+	}
+
+	summaries := []models.CoverageSummary{}
+	for day := startDate; !day.After(endDate); day = day.AddDate(0, 0, 1) {
+		date := day.Format(models.DateLayout)
+		dayOfWeek := int(day.Weekday())
+
+		for _, ts := range timeSlots {
+			if ts.DayOfWeek != dayOfWeek {
+				continue
+			}
+			slotSpan, err := ts.SpanOn(date)
+			if err != nil {
+				continue
+			}
+
+			// 同じ従業員の分割シフトが両方重なる場合も1人として数える
+			employees := make(map[int]bool)
+			for _, a := range assignments {
+				if a.span.Position == ts.Position && a.span.Overlaps(slotSpan) {
+					employees[a.employeeID] = true
+				}
+			}
+
+			summary := models.CoverageSummary{
+				Date:          date,
+				TimeSlotID:    ts.ID,
+				DayOfWeek:     ts.DayOfWeek,
+				StartTime:     ts.StartTime,
+				EndTime:       ts.EndTime,
+				EndsNextDay:   ts.EndsNextDay,
+				Position:      ts.Position,
+				RequiredCount: ts.RequiredCount,
+				ActualCount:   len(employees),
+			}
+			if summary.ActualCount < summary.RequiredCount {
+				summary.Shortage = summary.RequiredCount - summary.ActualCount
+			} else {
+				summary.Excess = summary.ActualCount - summary.RequiredCount
+			}
+			summary.Status = summary.GetStatus()
+			summaries = append(summaries, summary)
+		}
+	}
+	return summaries
+}
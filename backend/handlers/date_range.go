@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"shift-management-backend/models"
+)
+
+// maxRangeDays 期間を指定する操作（自動シフト作成・カバレッジ・レポートなど）で一度に指定できる最大日数
+const maxRangeDays = 62
+
+// parseDateRange 開始日と終了日（"2006-01-02"）を解析し、終了日が開始日以降で期間が maxDays 日以内か確認する
+// 不正な指定の場合はエラーメッセージを返す
+func parseDateRange(start, end string, maxDays int) (time.Time, time.Time, string) {
+	if start == "" || end == "" {
+		return time.Time{}, time.Time{}, "開始日と終了日を指定してください"
+	}
+	startDate, errStart := time.Parse(models.DateLayout, start)
+	endDate, errEnd := time.Parse(models.DateLayout, end)
+	if errStart != nil || errEnd != nil {
+		return time.Time{}, time.Time{}, "無効な日付です"
+	}
+	if endDate.Before(startDate) {
+		return time.Time{}, time.Time{}, "終了日は開始日以降である必要があります"
+	}
+	if endDate.Sub(startDate) >= time.Duration(maxDays)*24*time.Hour {
+		return time.Time{}, time.Time{}, "期間は" + strconv.Itoa(maxDays) + "日以内で指定してください"
+	}
+	return startDate, endDate, ""
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"testing"
+
+	"shift-management-backend/models"
+)
+
+func TestParseDateRange(t *testing.T) {
+	tests := []struct {
+		name, start, end string
+		wantMessage      string
+	}{
+		{"同じ日", "2030-10-07", "2030-10-07", ""},
+		{"上限ちょうど", "2030-10-01", "2030-12-01", ""},
+		{"上限を超える", "2030-10-01", "2030-12-02", "期間は62日以内で指定してください"},
+		{"終了日が開始日より前", "2030-10-07", "2030-10-06", "終了日は開始日以降である必要があります"},
+		{"日付の形式が不正", "2030/10/07", "2030-10-08", "無効な日付です"},
+		{"終了日がない", "2030-10-07", "", "開始日と終了日を指定してください"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, message := parseDateRange(tt.start, tt.end, maxRangeDays)
+			if message != tt.wantMessage {
+				t.Fatalf("message = %q, want %q", message, tt.wantMessage)
+			}
+			if message == "" && (start.Format(models.DateLayout) != tt.start || end.Format(models.DateLayout) != tt.end) {
+				t.Errorf("期間 = %s〜%s, want %s〜%s", start, end, tt.start, tt.end)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"shift-management-backend/models"
+	"shift-management-backend/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetDesiredHours 従業員が希望する勤務時間の一覧を取得（従業員は自分の設定のみ）
+func (h *Handler) GetDesiredHours(c echo.Context) error {
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+
+	list, err := h.repos.DesiredHours.List()
+	if err != nil {
+		return serverError(c, err, "希望する勤務時間の取得に失敗しました")
+	}
+
+	visible := []models.DesiredHours{}
+	for _, hours := range list {
+		if acc.IsOwner() || acc.IsSelf(hours.EmployeeID) {
+			visible = append(visible, hours)
+		}
+	}
+	return c.JSON(http.StatusOK, visible)
+}
+
+// SaveDesiredHours 従業員が希望する1週間または1か月の勤務時間を設定
+func (h *Handler) SaveDesiredHours(c echo.Context) error {
+	employeeID, err := strconv.Atoi(c.Param("employee_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効な従業員IDです",
+		})
+	}
+
+	var req models.DesiredHoursRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "リクエストの解析に失敗しました",
+		})
+	}
+
+	// バリデーション
+	if req.Unit != models.DesiredHoursWeek && req.Unit != models.DesiredHoursMonth {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "単位は week または month を指定してください",
+		})
+	}
+	maxHours := 7 * 24.0
+	if req.Unit == models.DesiredHoursMonth {
+		maxHours = 31 * 24.0
+	}
+	if req.Hours <= 0 || req.Hours > maxHours {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "希望する勤務時間が正しくありません",
+		})
+	}
+	req.Note = strings.TrimSpace(req.Note)
+	if len([]rune(req.Note)) > maxShiftRequestNoteLength {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "メモは" + strconv.Itoa(maxShiftRequestNoteLength) + "文字以内で入力してください",
+		})
+	}
+
+	// シフト希望提出権限の確認
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	if !acc.CanSubmitShiftRequestFor(employeeID) {
+		return forbidden(c)
+	}
+
+	exists, err := h.repos.Employees.Exists(employeeID)
+	if err != nil {
+		return serverError(c, err, "従業員の確認に失敗しました")
+	}
+	if !exists {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "従業員が見つかりません",
+		})
+	}
+
+	hours, err := h.repos.DesiredHours.Save(models.DesiredHours{
+		EmployeeID: employeeID,
+		Unit:       req.Unit,
+		Hours:      req.Hours,
+		Note:       req.Note,
+	})
+	if err != nil {
+		return serverError(c, err, "希望する勤務時間の設定に失敗しました")
+	}
+
+	return c.JSON(http.StatusOK, hours)
+}
+
+// DeleteDesiredHours 従業員が希望する勤務時間の設定を削除
+func (h *Handler) DeleteDesiredHours(c echo.Context) error {
+	employeeID, err := strconv.Atoi(c.Param("employee_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効な従業員IDです",
+		})
+	}
+
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	if !acc.CanSubmitShiftRequestFor(employeeID) {
+		return forbidden(c)
+	}
+
+	err = h.repos.DesiredHours.Delete(employeeID)
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "希望する勤務時間が設定されていません",
+		})
+	}
+	if err != nil {
+		return serverError(c, err, "希望する勤務時間の削除に失敗しました")
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "希望する勤務時間が削除されました",
+	})
+}
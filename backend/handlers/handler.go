@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+
+	"shift-management-backend/config"
+	"shift-management-backend/database"
+	"shift-management-backend/repository"
+	"shift-management-backend/token"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Handler APIハンドラー
+// データベースには直接アクセスせず、注入されたリポジトリを経由する
+type Handler struct {
+	repos    *repository.Repositories
+	sessions database.SessionStore
+	signer   *token.Signer
+	cfg      config.Config
+	// jobs 実行中の自動シフト作成ジョブ（停止時に完了を待つ）
+	jobs sync.WaitGroup
+}
+
+// New ハンドラーを作成
+func New(repos *repository.Repositories, sessions database.SessionStore, signer *token.Signer, cfg config.Config) *Handler {
+	return &Handler{
+		repos:    repos,
+		sessions: sessions,
+		signer:   signer,
+		cfg:      cfg,
+	}
+}
+
+// serverError ログを出力して500エラーのレスポンスを返す
+func serverError(c echo.Context, err error, message string) error {
+	log.Printf("%s: %v", message, err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error": message,
+	})
+}
+
+// httpError トランザクション内などで検出した検証エラー（respondError でそのままレスポンスにする）
+type httpError struct {
+	status  int
+	message string
+}
+
+func (e *httpError) Error() string {
+	return e.message
+}
+
+// respondError httpError の場合はそのステータスとメッセージを返し、それ以外は500エラーを返す
+func respondError(c echo.Context, err error, message string) error {
+	var he *httpError
+	if errors.As(err, &he) {
+		return c.JSON(he.status, map[string]string{
+			"error": he.message,
+		})
+	}
+	return serverError(c, err, message)
+}
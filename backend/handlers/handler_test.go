@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"shift-management-backend/config"
+	"shift-management-backend/models"
+	"shift-management-backend/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// testOwner テスト用のオーナー
+var testOwner = models.User{ID: 1, Role: "owner"}
+
+// newTestHandler メモリ上のリポジトリと既定の設定でハンドラーを作る
+func newTestHandler(t *testing.T) *Handler {
+	t.Helper()
+	return New(repository.NewMemory(), nil, nil, config.Default())
+}
+
+// createTestEmployee テスト用の従業員を作る
+func createTestEmployee(t *testing.T, h *Handler, name string, positions ...string) models.Employee {
+	t.Helper()
+	employee, err := h.repos.Employees.Create(models.CreateEmployeeRequest{Name: name, HourlyWage: 1000, Positions: positions})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return employee
+}
+
+// createTestShift シフトの作成と同じ確認を行ってシフトを保存する
+func createTestShift(t *testing.T, h *Handler, req models.CreateShiftRequest) models.Shift {
+	t.Helper()
+	var created models.Shift
+	err := h.repos.InTx(func(r *repository.Repositories) error {
+		shift, err := newShift(req)
+		if err != nil {
+			return err
+		}
+		created, err = h.createShift(r, shift)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return created
+}
+
+// serve ログイン中のユーザーとパスパラメータ（名前と値を交互に指定）を設定してハンドラーを呼び出し、
+// HTTPステータスとレスポンスの本文を返す
+func serve(t *testing.T, fn echo.HandlerFunc, method, target string, body interface{}, user models.User, params ...string) (int, []byte) {
+	t.Helper()
+	var payload string
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		payload = string(b)
+	}
+	req := httptest.NewRequest(method, target, strings.NewReader(payload))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.Set(contextKeyUser, user)
+	var names, values []string
+	for i := 0; i+1 < len(params); i += 2 {
+		names = append(names, params[i])
+		values = append(values, params[i+1])
+	}
+	c.SetParamNames(names...)
+	c.SetParamValues(values...)
+	// 並行して呼び出す場合に備えて t.Fatal は使わない
+	if err := fn(c); err != nil {
+		t.Error(err)
+	}
+	return rec.Code, rec.Body.Bytes()
+}
+
+// decodeBody レスポンスの本文を v に読み込む
+func decodeBody(t *testing.T, body []byte, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(body, v); err != nil {
+		t.Fatalf("レスポンスの解析に失敗しました: %v: %s", err, body)
+	}
+}
+
+// listTestShifts 保存されているシフトをすべて取得する
+func listTestShifts(t *testing.T, h *Handler) []models.Shift {
+	t.Helper()
+	shifts, err := h.repos.Shifts.List(repository.ShiftFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return shifts
+}
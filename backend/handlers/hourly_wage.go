@@ -4,61 +4,36 @@ import (
 	"net/http"
 	"strconv"
 
-	"shift-management-backend/database"
 	"shift-management-backend/models"
+	"shift-management-backend/repository"
 
 	"github.com/labstack/echo/v4"
 )
 
 // GetHourlyWages 時給設定一覧を取得
-func GetHourlyWages(c echo.Context) error {
+func (h *Handler) GetHourlyWages(c echo.Context) error {
 	// クエリパラメータの取得
-	employeeID := c.QueryParam("employee_id")
-
-	query := `
-		SELECT hw.id, hw.employee_id, hw.hourly_wage, hw.effective_date, 
-		       hw.created_at, hw.updated_at, e.name as employee_name
-		FROM hourly_wages hw
-		JOIN employees e ON hw.employee_id = e.id
-		WHERE 1=1
-	`
-	args := []interface{}{}
-	argIndex := 1
-
-	if employeeID != "" {
-		query += " AND hw.employee_id = $" + strconv.Itoa(argIndex)
-		args = append(args, employeeID)
-		argIndex++
-	}
-
-	query += " ORDER BY hw.effective_date DESC, hw.created_at DESC"
-
-	rows, err := database.DB.Query(query, args...)
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "時給設定一覧の取得に失敗しました",
-		})
-	}
-	defer rows.Close()
-
-	var hourlyWages []models.HourlyWage
-	for rows.Next() {
-		var hw models.HourlyWage
-		err := rows.Scan(&hw.ID, &hw.EmployeeID, &hw.HourlyWage, &hw.EffectiveDate,
-			&hw.CreatedAt, &hw.UpdatedAt, &hw.EmployeeName)
+	var employeeID *int
+	if employeeIDParam := c.QueryParam("employee_id"); employeeIDParam != "" {
+		id, err := strconv.Atoi(employeeIDParam)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{
-				"error": "データの読み込みに失敗しました",
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "従業員IDは数値で指定してください",
 			})
 		}
-		hourlyWages = append(hourlyWages, hw)
+		employeeID = &id
+	}
+
+	hourlyWages, err := h.repos.Wages.List(employeeID)
+	if err != nil {
+		return serverError(c, err, "時給設定一覧の取得に失敗しました")
 	}
 
 	return c.JSON(http.StatusOK, hourlyWages)
 }
 
 // GetHourlyWage 時給設定詳細を取得
-func GetHourlyWage(c echo.Context) error {
+func (h *Handler) GetHourlyWage(c echo.Context) error {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
@@ -66,27 +41,21 @@ func GetHourlyWage(c echo.Context) error {
 		})
 	}
 
-	var hw models.HourlyWage
-	err = database.DB.QueryRow(`
-		SELECT hw.id, hw.employee_id, hw.hourly_wage, hw.effective_date, 
-		       hw.created_at, hw.updated_at, e.name as employee_name
-		FROM hourly_wages hw
-		JOIN employees e ON hw.employee_id = e.id
-		WHERE hw.id = $1
-	`, id).Scan(&hw.ID, &hw.EmployeeID, &hw.HourlyWage, &hw.EffectiveDate,
-		&hw.CreatedAt, &hw.UpdatedAt, &hw.EmployeeName)
-
-	if err != nil {
+	hw, err := h.repos.Wages.Get(id)
+	if err == repository.ErrNotFound {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "時給設定が見つかりません",
 		})
 	}
+	if err != nil {
+		return serverError(c, err, "時給設定の取得に失敗しました")
+	}
 
 	return c.JSON(http.StatusOK, hw)
 }
 
 // CreateHourlyWage 時給設定を作成
-func CreateHourlyWage(c echo.Context) error {
+func (h *Handler) CreateHourlyWage(c echo.Context) error {
 	var req models.CreateHourlyWageRequest
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
@@ -102,8 +71,7 @@ func CreateHourlyWage(c echo.Context) error {
 	}
 
 	// 従業員の存在確認
-	var employeeExists bool
-	err := database.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM employees WHERE id = $1)", req.EmployeeID).Scan(&employeeExists)
+	employeeExists, err := h.repos.Employees.Exists(req.EmployeeID)
 	if err != nil || !employeeExists {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"error": "指定された従業員が存在しません",
@@ -111,33 +79,30 @@ func CreateHourlyWage(c echo.Context) error {
 	}
 
 	// 同じ日付の設定が既に存在するかチェック
-	var existingID int
-	err = database.DB.QueryRow("SELECT id FROM hourly_wages WHERE employee_id = $1 AND effective_date = $2", req.EmployeeID, req.EffectiveDate).Scan(&existingID)
-	if err == nil {
+	exists, err := h.repos.Wages.ExistsOnDate(req.EmployeeID, req.EffectiveDate)
+	if err != nil {
+		return serverError(c, err, "時給設定の確認に失敗しました")
+	}
+	if exists {
 		return c.JSON(http.StatusConflict, map[string]string{
 			"error": "同じ日付の時給設定が既に存在します",
 		})
 	}
 
-	var hourlyWage models.HourlyWage
-	err = database.DB.QueryRow(`
-		INSERT INTO hourly_wages (employee_id, hourly_wage, effective_date) 
-		VALUES ($1, $2, $3) 
-		RETURNING id, employee_id, hourly_wage, effective_date, created_at, updated_at
-	`, req.EmployeeID, req.HourlyWage, req.EffectiveDate).Scan(
-		&hourlyWage.ID, &hourlyWage.EmployeeID, &hourlyWage.HourlyWage, &hourlyWage.EffectiveDate, &hourlyWage.CreatedAt, &hourlyWage.UpdatedAt)
-
+	hourlyWage, err := h.repos.Wages.Create(models.HourlyWage{
+		EmployeeID:    req.EmployeeID,
+		HourlyWage:    req.HourlyWage,
+		EffectiveDate: req.EffectiveDate,
+	})
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "時給設定の作成に失敗しました",
-		})
+		return serverError(c, err, "時給設定の作成に失敗しました")
 	}
 
 	return c.JSON(http.StatusCreated, hourlyWage)
 }
 
 // UpdateHourlyWage 時給設定を更新
-func UpdateHourlyWage(c echo.Context) error {
+func (h *Handler) UpdateHourlyWage(c echo.Context) error {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
@@ -159,24 +124,15 @@ func UpdateHourlyWage(c echo.Context) error {
 		})
 	}
 
-	result, err := database.DB.Exec(`
-		UPDATE hourly_wages 
-		SET hourly_wage = $1, effective_date = $2, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $3
-	`, req.HourlyWage, req.EffectiveDate, id)
-
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "時給設定の更新に失敗しました",
-		})
-	}
-
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
+	err = h.repos.Wages.Update(id, req)
+	if err == repository.ErrNotFound {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "時給設定が見つかりません",
 		})
 	}
+	if err != nil {
+		return serverError(c, err, "時給設定の更新に失敗しました")
+	}
 
 	return c.JSON(http.StatusOK, map[string]string{
 		"message": "時給設定が更新されました",
@@ -184,7 +140,7 @@ func UpdateHourlyWage(c echo.Context) error {
 }
 
 // DeleteHourlyWage 時給設定を削除
-func DeleteHourlyWage(c echo.Context) error {
+func (h *Handler) DeleteHourlyWage(c echo.Context) error {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
@@ -192,19 +148,15 @@ func DeleteHourlyWage(c echo.Context) error {
 		})
 	}
 
-	result, err := database.DB.Exec("DELETE FROM hourly_wages WHERE id = $1", id)
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "時給設定の削除に失敗しました",
-		})
-	}
-
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
+	err = h.repos.Wages.Delete(id)
+	if err == repository.ErrNotFound {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "時給設定が見つかりません",
 		})
 	}
+	if err != nil {
+		return serverError(c, err, "時給設定の削除に失敗しました")
+	}
 
 	return c.JSON(http.StatusOK, map[string]string{
 		"message": "時給設定が削除されました",
@@ -212,70 +164,75 @@ func DeleteHourlyWage(c echo.Context) error {
 }
 
 // GetHourlyWageHistory 時給履歴を取得
-func GetHourlyWageHistory(c echo.Context) error {
-	employeeID := c.QueryParam("employee_id")
-	if employeeID == "" {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "従業員IDが必要です",
-		})
+func (h *Handler) GetHourlyWageHistory(c echo.Context) error {
+	employeeID, ok, err := h.wageTargetEmployee(c)
+	if !ok {
+		return err
 	}
 
-	rows, err := database.DB.Query(`
-		SELECT hw.employee_id, e.name as employee_name, hw.hourly_wage, 
-		       hw.effective_date, hw.created_at
-		FROM hourly_wages hw
-		JOIN employees e ON hw.employee_id = e.id
-		WHERE hw.employee_id = $1
-		ORDER BY hw.effective_date DESC, hw.created_at DESC
-	`, employeeID)
+	wages, err := h.repos.Wages.List(&employeeID)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "時給履歴の取得に失敗しました",
-		})
+		return serverError(c, err, "時給履歴の取得に失敗しました")
 	}
-	defer rows.Close()
 
 	var history []models.HourlyWageHistory
-	for rows.Next() {
-		var h models.HourlyWageHistory
-		err := rows.Scan(&h.EmployeeID, &h.EmployeeName, &h.HourlyWage, &h.EffectiveDate, &h.CreatedAt)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{
-				"error": "データの読み込みに失敗しました",
-			})
-		}
-		history = append(history, h)
+	for _, hw := range wages {
+		history = append(history, models.HourlyWageHistory{
+			EmployeeID:    hw.EmployeeID,
+			EmployeeName:  hw.EmployeeName,
+			HourlyWage:    hw.HourlyWage,
+			EffectiveDate: hw.EffectiveDate,
+			CreatedAt:     hw.CreatedAt,
+		})
 	}
 
 	return c.JSON(http.StatusOK, history)
 }
 
 // GetCurrentHourlyWage 現在の時給を取得
-func GetCurrentHourlyWage(c echo.Context) error {
-	employeeID := c.QueryParam("employee_id")
-	if employeeID == "" {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "従業員IDが必要です",
-		})
+func (h *Handler) GetCurrentHourlyWage(c echo.Context) error {
+	employeeID, ok, err := h.wageTargetEmployee(c)
+	if !ok {
+		return err
 	}
 
-	var hw models.HourlyWage
-	err := database.DB.QueryRow(`
-		SELECT hw.id, hw.employee_id, hw.hourly_wage, hw.effective_date, 
-		       hw.created_at, hw.updated_at, e.name as employee_name
-		FROM hourly_wages hw
-		JOIN employees e ON hw.employee_id = e.id
-		WHERE hw.employee_id = $1
-		ORDER BY hw.effective_date DESC, hw.created_at DESC
-		LIMIT 1
-	`, employeeID).Scan(&hw.ID, &hw.EmployeeID, &hw.HourlyWage, &hw.EffectiveDate,
-		&hw.CreatedAt, &hw.UpdatedAt, &hw.EmployeeName)
-
-	if err != nil {
+	hw, err := h.repos.Wages.Current(employeeID)
+	if err == repository.ErrNotFound {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "時給設定が見つかりません",
 		})
 	}
+	if err != nil {
+		return serverError(c, err, "時給設定の取得に失敗しました")
+	}
 
 	return c.JSON(http.StatusOK, hw)
 }
+
+// wageTargetEmployee employee_id クエリから時給を参照する従業員を取得
+// 従業員は自分の時給のみ参照できる。参照できない場合はエラーレスポンスを書き込み ok=false を返す
+func (h *Handler) wageTargetEmployee(c echo.Context) (int, bool, error) {
+	employeeIDParam := c.QueryParam("employee_id")
+	if employeeIDParam == "" {
+		return 0, false, c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "従業員IDが必要です",
+		})
+	}
+
+	employeeID, err := strconv.Atoi(employeeIDParam)
+	if err != nil {
+		return 0, false, c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "従業員IDは数値で指定してください",
+		})
+	}
+
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return 0, false, c.JSON(status, map[string]string{"error": message})
+	}
+	if !acc.IsOwner() && !acc.IsSelf(employeeID) {
+		return 0, false, forbidden(c)
+	}
+
+	return employeeID, true, nil
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"shift-management-backend/labor"
+	"shift-management-backend/models"
+	"shift-management-backend/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// laborRules 設定から労働基準法に基づく確認のルールを作る
+func (h *Handler) laborRules() labor.Rules {
+	return labor.Rules{
+		DailyHours:  h.cfg.Labor.DailyHours,
+		WeeklyHours: h.cfg.Labor.WeeklyHours,
+		MinRest:     h.cfg.Scheduling.MinRestInterval.Duration,
+		Severity:    h.cfg.Labor.Severities(),
+	}
+}
+
+// checkLaborRules 保存前のシフト（作成する場合は ID が0）を、同じ週の担当者の保存済みのシフトとあわせて労働基準法に基づくルールで確認する
+// exclude に指定したシフト（交換で手放すシフトなど）は担当者のシフトに含めない
+// エラーとするルールに違反した場合は422の httpError を返し、警告とするルールへの違反を返す
+func (h *Handler) checkLaborRules(r *repository.Repositories, shift models.Shift, exclude ...int) ([]models.LaborViolation, error) {
+	if shift.IsOpen() {
+		return nil, nil
+	}
+	span, err := shift.Span()
+	if err != nil {
+		return nil, err
+	}
+
+	// 日付をまたぐシフトと前後のシフトとの休息時間を考慮して、
+	// 前週の金曜から終了日を含む週の翌週の月曜までのシフトを確認する
+	from := labor.WeekStart(span.Start).AddDate(0, 0, -2)
+	to := labor.WeekStart(span.End.Add(-time.Nanosecond)).AddDate(0, 0, 8)
+	shifts, err := r.Shifts.List(repository.ShiftFilter{
+		EmployeeID: &shift.EmployeeID,
+		StartDate:  from.Format(models.DateLayout),
+		EndDate:    to.Format(models.DateLayout),
+	})
+	if err != nil {
+		return nil, err
+	}
+	// 保存済みの変更前のシフトを保存前のシフトに置き換える
+	candidates := make([]models.Shift, 0, len(shifts)+1)
+	for _, s := range shifts {
+		if s.ID != shift.ID && !containsID(exclude, s.ID) {
+			candidates = append(candidates, s)
+		}
+	}
+	candidates = append(candidates, shift)
+
+	var warnings []models.LaborViolation
+	var errs []string
+	for _, v := range labor.Check(h.laborRules(), candidates) {
+		if !containsID(v.ShiftIDs, shift.ID) {
+			continue
+		}
+		if v.Severity == models.LaborSeverityError {
+			errs = append(errs, v.Message)
+			continue
+		}
+		warnings = append(warnings, v)
+	}
+	if len(errs) > 0 {
+		return nil, &httpError{http.StatusUnprocessableEntity, strings.Join(errs, "\n")}
+	}
+	return warnings, nil
+}
+
+// GetLaborAudit 期間のシフトを労働基準法に基づくルールで確認し、違反を返す
+// 週のルールは期間を含む週（日曜始まり）全体で判定する
+func (h *Handler) GetLaborAudit(c echo.Context) error {
+	start, end, message := parseDateRange(c.QueryParam("start_date"), c.QueryParam("end_date"), maxRangeDays)
+	if message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	filter := repository.ShiftFilter{
+		StartDate: labor.WeekStart(start).AddDate(0, 0, -1).Format(models.DateLayout),
+		EndDate:   labor.WeekStart(end).AddDate(0, 0, 6).Format(models.DateLayout),
+	}
+	if employeeIDStr := c.QueryParam("employee_id"); employeeIDStr != "" {
+		employeeID, err := strconv.Atoi(employeeIDStr)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "無効な従業員IDです",
+			})
+		}
+		filter.EmployeeID = &employeeID
+	}
+
+	shifts, err := h.repos.Shifts.List(filter)
+	if err != nil {
+		return serverError(c, err, "シフトの取得に失敗しました")
+	}
+
+	res := models.LaborAuditResponse{
+		StartDate:  start.Format(models.DateLayout),
+		EndDate:    end.Format(models.DateLayout),
+		Violations: []models.LaborViolation{},
+	}
+	for _, v := range labor.Check(h.laborRules(), shifts) {
+		// 週のルールは週の開始日、それ以外は勤務日が期間に含まれるものを返す
+		last := v.Date
+		if v.Rule == models.LaborRuleWeeklyLimit || v.Rule == models.LaborRuleWeeklyRestDay {
+			weekStart, err := models.ParseDate(v.Date)
+			if err != nil {
+				continue
+			}
+			last = weekStart.AddDate(0, 0, 6).Format(models.DateLayout)
+		}
+		if last < res.StartDate || v.Date > res.EndDate {
+			continue
+		}
+		if v.Severity == models.LaborSeverityError {
+			res.Errors++
+		} else {
+			res.Warnings++
+		}
+		res.Violations = append(res.Violations, v)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// containsID ID の一覧に id が含まれるか
+func containsID(ids []int, id int) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// GetRestIntervalReport 期間内に勤務を始めるシフトのうち、前のシフトとの休息時間（勤務間インターバル）が足りないものを返す
+func (h *Handler) GetRestIntervalReport(c echo.Context) error {
+	start, end, message := parseDateRange(c.QueryParam("start_date"), c.QueryParam("end_date"), maxRangeDays)
+	if message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	// 期間の最初のシフトの前のシフトを含めるため、前の2日分も取得する
+	filter := repository.ShiftFilter{
+		StartDate: start.AddDate(0, 0, -2).Format(models.DateLayout),
+		EndDate:   end.Format(models.DateLayout),
+	}
+	if employeeIDStr := c.QueryParam("employee_id"); employeeIDStr != "" {
+		employeeID, err := strconv.Atoi(employeeIDStr)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "無効な従業員IDです",
+			})
+		}
+		filter.EmployeeID = &employeeID
+	}
+
+	shifts, err := h.repos.Shifts.List(filter)
+	if err != nil {
+		return serverError(c, err, "シフトの取得に失敗しました")
+	}
+
+	minRest := h.cfg.Scheduling.MinRestInterval.Duration
+	res := models.RestIntervalReport{
+		StartDate:      start.Format(models.DateLayout),
+		EndDate:        end.Format(models.DateLayout),
+		MinRestMinutes: int(minRest.Minutes()),
+		Violations:     []models.RestIntervalViolation{},
+	}
+	for _, v := range labor.ShortRests(minRest, shifts) {
+		if date := dateKey(v.Next.Date); date < res.StartDate || date > res.EndDate {
+			continue
+		}
+		res.Violations = append(res.Violations, v)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
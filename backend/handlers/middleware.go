@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http"
+
+	"shift-management-backend/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// echo.Context に認証情報を格納するキー
+const (
+	contextKeyUser      = "currentUser"
+	contextKeySessionID = "currentSessionID"
+)
+
+// ロール
+const (
+	RoleOwner    = "owner"
+	RoleEmployee = "employee"
+)
+
+// RequireAuth Bearerトークンを検証し、認証済みユーザーをコンテキストに格納するミドルウェア
+func (h *Handler) RequireAuth(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		user, ok := h.GetCurrentUser(c)
+		if !ok {
+			return unauthorized(c)
+		}
+
+		c.Set(contextKeyUser, user)
+		return next(c)
+	}
+}
+
+// RequireRole 指定したロールのいずれかを持つユーザーのみ許可するミドルウェア
+// RequireAuth の後に適用する
+func RequireRole(roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user, ok := CurrentUser(c)
+			if !ok {
+				return unauthorized(c)
+			}
+
+			for _, role := range roles {
+				if user.Role == role {
+					return next(c)
+				}
+			}
+
+			return forbidden(c)
+		}
+	}
+}
+
+// RequireOwner オーナーのみ許可するミドルウェア
+var RequireOwner = RequireRole(RoleOwner)
+
+// RequireEmployee 従業員・オーナーのどちらでも許可するミドルウェア
+var RequireEmployee = RequireRole(RoleOwner, RoleEmployee)
+
+// CurrentUser ミドルウェアで格納された認証済みユーザーを取得
+func CurrentUser(c echo.Context) (models.User, bool) {
+	user, ok := c.Get(contextKeyUser).(models.User)
+	return user, ok
+}
+
+// unauthorized 認証エラーのレスポンスを返す
+func unauthorized(c echo.Context) error {
+	return c.JSON(http.StatusUnauthorized, map[string]string{
+		"error": "認証が必要です",
+	})
+}
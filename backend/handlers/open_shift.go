@@ -0,0 +1,392 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"shift-management-backend/models"
+	"shift-management-backend/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetOpenShifts 担当者が決まっていない開始前のシフトの一覧を取得
+// オーナーにはすべて、従業員には公開済みで自分が担当できるもの（ポジション・勤務時間の重なり・週の勤務時間）だけを返す
+// start_date / end_date で期間を絞り込める（start_date の既定は今日）
+func (h *Handler) GetOpenShifts(c echo.Context) error {
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+
+	filter := repository.ShiftFilter{
+		StartDate: c.QueryParam("start_date"),
+		EndDate:   c.QueryParam("end_date"),
+		OpenOnly:  true,
+	}
+	if filter.StartDate == "" {
+		filter.StartDate = time.Now().Format(models.DateLayout)
+	}
+	if _, err := models.ParseDate(filter.StartDate); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効な日付です",
+		})
+	}
+	if filter.EndDate != "" {
+		if _, err := models.ParseDate(filter.EndDate); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "無効な日付です",
+			})
+		}
+	}
+
+	// 従業員には公開済みのシフトだけを見せる
+	candidates, err := h.visibleShifts(acc, filter)
+	if err != nil {
+		return serverError(c, err, "募集中のシフトの取得に失敗しました")
+	}
+
+	shifts := []models.Shift{}
+	for _, shift := range candidates {
+		current := shift
+		if !acc.IsOwner() {
+			// 公開後に応募で担当者が決まっている場合があるため、現在の内容で判定する（返すのは公開済みの内容）
+			current, err = h.repos.Shifts.Get(shift.ID)
+			if err == repository.ErrNotFound {
+				continue
+			}
+			if err != nil {
+				return serverError(c, err, "募集中のシフトの取得に失敗しました")
+			}
+			if !current.IsOpen() {
+				continue
+			}
+		}
+		started, err := shiftStarted(current)
+		if err != nil || started {
+			continue
+		}
+		if !acc.IsOwner() {
+			_, err = h.checkShiftEligibility(h.repos, current, acc.EmployeeID, 0)
+			if _, rejected := err.(*httpError); rejected {
+				continue
+			}
+			if err != nil {
+				return serverError(c, err, "募集中のシフトの取得に失敗しました")
+			}
+		}
+		shifts = append(shifts, shift)
+	}
+	return c.JSON(http.StatusOK, shifts)
+}
+
+// ClaimOpenShift 担当者が決まっていないシフトに応募する
+// 先着順の場合は応募した時点で担当者に決まり、承認制の場合はオーナーの承認待ちになる
+// シフトの行をロックしてから担当者を確認するため、同時に応募しても担当者に決まるのは1人だけ
+func (h *Handler) ClaimOpenShift(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効なIDです",
+		})
+	}
+
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	if acc.IsOwner() {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": "募集中のシフトには従業員のみ応募できます",
+		})
+	}
+
+	// 公開されていないシフトには応募できない
+	if _, err := h.visibleShift(acc, id); err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "シフトが見つかりません",
+		})
+	} else if err != nil {
+		return serverError(c, err, "シフトの取得に失敗しました")
+	}
+
+	var res models.ClaimOpenShiftResponse
+	err = h.repos.InTx(func(r *repository.Repositories) error {
+		if err := r.Shifts.Lock(id); err != nil {
+			return err
+		}
+		shift, err := r.Shifts.Get(id)
+		if err != nil {
+			return err
+		}
+		if !shift.IsOpen() {
+			return &httpError{http.StatusConflict, "このシフトは既に担当者が決まっています"}
+		}
+		if started, err := shiftStarted(shift); err != nil {
+			return err
+		} else if started {
+			return &httpError{http.StatusConflict, "開始済みのシフトには応募できません"}
+		}
+
+		if err := r.Employees.Lock(acc.EmployeeID); err != nil {
+			return err
+		}
+		pending, err := r.OpenShiftClaims.List(repository.OpenShiftClaimFilter{
+			ShiftID:    &shift.ID,
+			EmployeeID: &acc.EmployeeID,
+			Status:     models.OpenShiftClaimPending,
+		})
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return &httpError{http.StatusConflict, "このシフトには既に応募しています"}
+		}
+		warnings, err := h.checkShiftEligibility(r, shift, acc.EmployeeID, 0)
+		if err != nil {
+			return err
+		}
+
+		claim := models.OpenShiftClaim{
+			ShiftID:    shift.ID,
+			EmployeeID: acc.EmployeeID,
+			Status:     models.OpenShiftClaimPending,
+		}
+		if h.cfg.OpenShifts.ClaimMode == models.OpenShiftApproval {
+			res.Claim, err = r.OpenShiftClaims.Create(claim)
+			res.Claim.Warnings = warnings
+			return err
+		}
+
+		now := time.Now()
+		claim.Status = models.OpenShiftClaimApproved
+		claim.DecidedAt = &now
+		if res.Claim, err = r.OpenShiftClaims.Create(claim); err != nil {
+			return err
+		}
+		previous := shift
+		shift.EmployeeID = acc.EmployeeID
+		if err := h.saveShift(r, previous, shift, models.ShiftHolderClaimed, nil); err != nil {
+			return err
+		}
+		assigned, err := r.Shifts.Get(id)
+		assigned.Warnings = warnings
+		res.Shift = &assigned
+		return err
+	})
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "シフトが見つかりません",
+		})
+	}
+	if err != nil {
+		return respondError(c, err, "シフトへの応募に失敗しました")
+	}
+
+	if res.Shift == nil {
+		return c.JSON(http.StatusAccepted, res)
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// GetOpenShiftClaims 募集中のシフトへの応募の一覧を取得（先着順）
+// オーナーにはすべて（status・shift_id で絞り込み可）、従業員には自分の応募を返す
+func (h *Handler) GetOpenShiftClaims(c echo.Context) error {
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+
+	filter := repository.OpenShiftClaimFilter{Status: c.QueryParam("status")}
+	if v := c.QueryParam("shift_id"); v != "" {
+		shiftID, err := strconv.Atoi(v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "無効なシフトIDです",
+			})
+		}
+		filter.ShiftID = &shiftID
+	}
+	if !acc.IsOwner() {
+		filter.EmployeeID = &acc.EmployeeID
+	}
+
+	claims, err := h.repos.OpenShiftClaims.List(filter)
+	if err != nil {
+		return serverError(c, err, "応募の取得に失敗しました")
+	}
+	for i := range claims {
+		// 従業員には公開済みの内容を見せる（公開されていない場合はシフトを含めない）
+		shift, err := h.visibleShift(acc, claims[i].ShiftID)
+		if err == repository.ErrNotFound && !acc.IsOwner() {
+			continue
+		}
+		if err != nil {
+			return serverError(c, err, "応募の取得に失敗しました")
+		}
+		claims[i].Shift = &shift
+	}
+	if claims == nil {
+		claims = []models.OpenShiftClaim{}
+	}
+	return c.JSON(http.StatusOK, claims)
+}
+
+// ApproveOpenShiftClaim 承認待ちの応募を採用し、応募した従業員をシフトの担当者にする
+// 同じシフトへの他の承認待ちの応募は不採用になる
+func (h *Handler) ApproveOpenShiftClaim(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効なIDです",
+		})
+	}
+
+	var claim models.OpenShiftClaim
+	err = h.repos.InTx(func(r *repository.Repositories) error {
+		var err error
+		claim, err = r.OpenShiftClaims.Get(id)
+		if err != nil {
+			return err
+		}
+		if claim.Status != models.OpenShiftClaimPending {
+			return &httpError{http.StatusConflict, "承認待ちの応募ではありません"}
+		}
+
+		if err := r.Shifts.Lock(claim.ShiftID); err != nil {
+			return err
+		}
+		shift, err := r.Shifts.Get(claim.ShiftID)
+		if err != nil {
+			return err
+		}
+		if !shift.IsOpen() {
+			return &httpError{http.StatusConflict, "このシフトは既に担当者が決まっています"}
+		}
+
+		// 応募後にシフトや他のシフトが変更されている場合があるため、改めて条件を確認する
+		if err := r.Employees.Lock(claim.EmployeeID); err != nil {
+			return err
+		}
+		warnings, err := h.checkShiftEligibility(r, shift, claim.EmployeeID, 0)
+		if err != nil {
+			return err
+		}
+
+		previous := shift
+		shift.EmployeeID = claim.EmployeeID
+		if err := h.saveShift(r, previous, shift, models.ShiftHolderClaimed, nil); err != nil {
+			return err
+		}
+		if claim, err = r.OpenShiftClaims.Get(id); err != nil {
+			return err
+		}
+		claim.Warnings = warnings
+		return nil
+	})
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "応募が見つかりません",
+		})
+	}
+	if err != nil {
+		return respondError(c, err, "応募の承認に失敗しました")
+	}
+
+	return c.JSON(http.StatusOK, claim)
+}
+
+// RejectOpenShiftClaim 承認待ちの応募を不採用にする
+func (h *Handler) RejectOpenShiftClaim(c echo.Context) error {
+	return h.closeOpenShiftClaim(c, models.OpenShiftClaimRejected)
+}
+
+// CancelOpenShiftClaim 承認待ちの応募を取り下げる（応募した従業員またはオーナー）
+func (h *Handler) CancelOpenShiftClaim(c echo.Context) error {
+	return h.closeOpenShiftClaim(c, models.OpenShiftClaimCancelled)
+}
+
+// closeOpenShiftClaim 承認待ちの応募を不採用にするか取り下げる
+func (h *Handler) closeOpenShiftClaim(c echo.Context, status string) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効なIDです",
+		})
+	}
+
+	var req models.DecideOpenShiftClaimRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "リクエストの解析に失敗しました",
+		})
+	}
+
+	acc, code, message := h.callerAccess(c)
+	if code != 0 {
+		return c.JSON(code, map[string]string{"error": message})
+	}
+
+	var claim models.OpenShiftClaim
+	err = h.repos.InTx(func(r *repository.Repositories) error {
+		var err error
+		claim, err = r.OpenShiftClaims.Get(id)
+		if err != nil {
+			return err
+		}
+		if !acc.IsOwner() && !acc.IsSelf(claim.EmployeeID) {
+			return &httpError{http.StatusForbidden, "この操作を行う権限がありません"}
+		}
+		if claim.Status != models.OpenShiftClaimPending {
+			return &httpError{http.StatusConflict, "この応募は既に終了しています"}
+		}
+
+		now := time.Now()
+		claim.Status = status
+		claim.DecisionReason = req.Reason
+		claim.DecidedAt = &now
+		if err := r.OpenShiftClaims.Update(claim); err != nil {
+			return err
+		}
+		claim, err = r.OpenShiftClaims.Get(id)
+		return err
+	})
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "応募が見つかりません",
+		})
+	}
+	if err != nil {
+		return respondError(c, err, "応募の更新に失敗しました")
+	}
+
+	return c.JSON(http.StatusOK, claim)
+}
+
+// closePendingClaims シフトの担当者が決まったときに、承認待ちの応募を締め切る
+// 担当者に決まった従業員の応募は採用、それ以外は不採用にする
+func closePendingClaims(r *repository.Repositories, shift models.Shift) error {
+	pending, err := r.OpenShiftClaims.List(repository.OpenShiftClaimFilter{
+		ShiftID: &shift.ID,
+		Status:  models.OpenShiftClaimPending,
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, claim := range pending {
+		claim.DecidedAt = &now
+		if claim.EmployeeID == shift.EmployeeID {
+			claim.Status = models.OpenShiftClaimApproved
+		} else {
+			claim.Status = models.OpenShiftClaimRejected
+			claim.DecisionReason = "他の従業員が担当者に決まりました"
+		}
+		if err := r.OpenShiftClaims.Update(claim); err != nil {
+			return err
+		}
+	}
+	return nil
+}
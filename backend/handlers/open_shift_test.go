@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"shift-management-backend/models"
+)
+
+func TestClaimOpenShiftChecksRestInterval(t *testing.T) {
+	h := newTestHandler(t)
+	employee := createTestEmployee(t, h, "山田")
+	open := createTestShift(t, h, models.CreateShiftRequest{Date: "2030-10-08", StartTime: "09:00", EndTime: "13:00"})
+	// 応募したシフトまで、前日の勤務の終了から10時間しか空かない（必要な休息時間は11時間）
+	createTestShift(t, h, models.CreateShiftRequest{EmployeeID: employee.ID, Date: "2030-10-07", StartTime: "17:00", EndTime: "23:00"})
+	if _, err := h.repos.Periods.Create(models.SchedulePeriod{
+		StartDate: "2030-10-07",
+		EndDate:   "2030-10-13",
+		Status:    models.SchedulePeriodPublished,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	id := strconv.Itoa(open.ID)
+	code, body := serve(t, h.ClaimOpenShift, http.MethodPost, "/api/open-shifts/"+id+"/claim", nil, employeeUser(employee), "id", id)
+	if code != http.StatusConflict {
+		t.Fatalf("休息時間が足りない場合は応募できないはずです: status = %d: %s", code, body)
+	}
+	if got, err := h.repos.Shifts.Get(open.ID); err != nil || !got.IsOpen() {
+		t.Fatalf("シフトは募集中のままのはずです: %+v, %v", got, err)
+	}
+}
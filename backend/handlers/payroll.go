@@ -2,17 +2,17 @@ package handlers
 
 import (
 	"net/http"
+	"sort"
 	"strconv"
-	"time"
 
-	"shift-management-backend/database"
 	"shift-management-backend/models"
+	"shift-management-backend/repository"
 
 	"github.com/labstack/echo/v4"
 )
 
 // CalculatePayroll 月別給与計算
-func CalculatePayroll(c echo.Context) error {
+func (h *Handler) CalculatePayroll(c echo.Context) error {
 	year := c.QueryParam("year")
 	month := c.QueryParam("month")
 
@@ -22,89 +22,128 @@ func CalculatePayroll(c echo.Context) error {
 		})
 	}
 
-	yearInt, err := strconv.Atoi(year)
+	startDate, endDate, err := monthRange(year, month)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "年は数値で指定してください",
+			"error": "年と月は数値で指定してください",
 		})
 	}
 
-	monthInt, err := strconv.Atoi(month)
+	// シフトデータを取得
+	shifts, err := h.repos.Shifts.List(repository.ShiftFilter{
+		StartDate: startDate.Format(models.DateLayout),
+		EndDate:   endDate.Format(models.DateLayout),
+	})
+	if err != nil {
+		return serverError(c, err, "シフトデータの取得に失敗しました")
+	}
+
+	attendances, err := h.repos.Attendance.List(repository.AttendanceFilter{
+		StartDate: startDate.Format(models.DateLayout),
+		EndDate:   endDate.Format(models.DateLayout),
+	})
+	if err != nil {
+		return serverError(c, err, "出退勤記録の取得に失敗しました")
+	}
+
+	wages, err := h.repos.Wages.List(nil)
 	if err != nil {
+		return serverError(c, err, "時給設定の取得に失敗しました")
+	}
+
+	return c.JSON(http.StatusOK, summarizePayroll(shifts, attendances, wages, h.cfg.Payroll.DefaultHourlyWage))
+}
+
+// GetEmployeePayroll 従業員個人の給与取得
+func (h *Handler) GetEmployeePayroll(c echo.Context) error {
+	employeeID := c.Param("id")
+	year := c.QueryParam("year")
+	month := c.QueryParam("month")
+
+	if year == "" || month == "" {
 		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "月は数値で指定してください",
+			"error": "年と月のパラメータが必要です",
 		})
 	}
 
-	// 月の開始日と終了日を計算
-	startDate := time.Date(yearInt, time.Month(monthInt), 1, 0, 0, 0, 0, time.UTC)
-	endDate := startDate.AddDate(0, 1, -1)
+	startDate, endDate, err := monthRange(year, month)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "年と月は数値で指定してください",
+		})
+	}
 
-	// シフトデータを取得
-	query := `
-		SELECT 
-			s.id,
-			s.employee_id,
-			e.name as employee_name,
-			s.date,
-			s.start_time,
-			s.end_time,
-			s.break_time,
-			COALESCE(hw.hourly_wage, 1000) as hourly_wage
-		FROM shifts s
-		JOIN employees e ON s.employee_id = e.id
-		LEFT JOIN hourly_wages hw ON s.employee_id = hw.employee_id 
-			AND hw.effective_date <= s.date
-			AND hw.id = (
-				SELECT MAX(id) FROM hourly_wages hw2 
-				WHERE hw2.employee_id = s.employee_id 
-				AND hw2.effective_date <= s.date
-			)
-		WHERE s.date >= $1 AND s.date <= $2
-		ORDER BY s.employee_id, s.date
-	`
+	employeeIDInt, err := strconv.Atoi(employeeID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "従業員IDは数値で指定してください",
+		})
+	}
+
+	// 従業員は給与閲覧権限がある場合のみ自分の給与を閲覧できる
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	if !acc.CanViewPayrollOf(employeeIDInt) {
+		return forbidden(c)
+	}
+
+	// 従業員のシフトデータを取得（従業員には公開済みのシフトで計算する）
+	shifts, err := h.visibleShifts(acc, repository.ShiftFilter{
+		EmployeeID: &employeeIDInt,
+		StartDate:  startDate.Format(models.DateLayout),
+		EndDate:    endDate.Format(models.DateLayout),
+	})
+	if err != nil {
+		return serverError(c, err, "シフトデータの取得に失敗しました")
+	}
 
-	rows, err := database.DB.Query(query, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	attendances, err := h.repos.Attendance.List(repository.AttendanceFilter{
+		EmployeeID: &employeeIDInt,
+		StartDate:  startDate.Format(models.DateLayout),
+		EndDate:    endDate.Format(models.DateLayout),
+	})
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "シフトデータの取得に失敗しました",
+		return serverError(c, err, "出退勤記録の取得に失敗しました")
+	}
+
+	wages, err := h.repos.Wages.List(&employeeIDInt)
+	if err != nil {
+		return serverError(c, err, "時給設定の取得に失敗しました")
+	}
+
+	result := summarizePayroll(shifts, attendances, wages, h.cfg.Payroll.DefaultHourlyWage)
+	if len(result) == 0 {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "指定月のシフトデータが見つかりません",
 		})
 	}
-	defer rows.Close()
 
-	// 従業員別にデータを集計
+	return c.JSON(http.StatusOK, result[0])
+}
+
+// summarizePayroll シフトを従業員ごとに集計して給与を計算（従業員ID順）
+// 1日に複数のシフト（分割シフト）がある場合はそれぞれの勤務時間を合計する
+// 時給はシフト日時点で有効な時給設定を使い、設定がない場合は defaultWage を使う
+// 出退勤記録の実労働時間は、シフトのある従業員について参考値として合計する
+func summarizePayroll(shifts []models.Shift, attendances []models.Attendance, wages []models.HourlyWage, defaultWage int) []models.PayrollData {
 	employeeData := make(map[int]*models.PayrollData)
 
-	for rows.Next() {
-		var shift struct {
-			ID           int       `json:"id"`
-			EmployeeID   int       `json:"employee_id"`
-			EmployeeName string    `json:"employee_name"`
-			Date         time.Time `json:"date"`
-			StartTime    time.Time `json:"start_time"`
-			EndTime      time.Time `json:"end_time"`
-			BreakTime    int       `json:"break_time"`
-			HourlyWage   int       `json:"hourly_wage"`
+	for _, shift := range shifts {
+		// 担当者が決まっていないシフトは給与に含めない
+		if shift.IsOpen() {
+			continue
 		}
-
-		err := rows.Scan(
-			&shift.ID,
-			&shift.EmployeeID,
-			&shift.EmployeeName,
-			&shift.Date,
-			&shift.StartTime,
-			&shift.EndTime,
-			&shift.BreakTime,
-			&shift.HourlyWage,
-		)
+		span, err := shift.Span()
 		if err != nil {
 			continue
 		}
 
-		// 労働時間を計算
-		duration := shift.EndTime.Sub(shift.StartTime)
-		totalHours := duration.Hours()
+		// 労働時間を計算（日付をまたぐシフトは翌日の終了時刻まで）
+		totalHours := span.Hours()
 		netHours := totalHours - float64(shift.BreakTime)/60.0
+		hourlyWage := wageOn(wages, shift.EmployeeID, shift.Date, defaultWage)
 
 		if data, exists := employeeData[shift.EmployeeID]; exists {
 			data.TotalHours += totalHours
@@ -112,8 +151,8 @@ func CalculatePayroll(c echo.Context) error {
 			data.NetHours += netHours
 			data.ShiftCount++
 			// 最新の時給を使用
-			if shift.HourlyWage > data.HourlyWage {
-				data.HourlyWage = shift.HourlyWage
+			if hourlyWage > data.HourlyWage {
+				data.HourlyWage = hourlyWage
 			}
 		} else {
 			employeeData[shift.EmployeeID] = &models.PayrollData{
@@ -122,152 +161,46 @@ func CalculatePayroll(c echo.Context) error {
 				TotalHours:     totalHours,
 				TotalBreakTime: shift.BreakTime,
 				NetHours:       netHours,
-				HourlyWage:     shift.HourlyWage,
+				HourlyWage:     hourlyWage,
 				ShiftCount:     1,
 			}
 		}
 	}
 
+	for _, att := range attendances {
+		if data, exists := employeeData[att.EmployeeID]; exists && att.ActualHours != nil {
+			data.ActualHours += *att.ActualHours
+		}
+	}
+
 	// 給与を計算
-	var result []models.PayrollData
+	result := make([]models.PayrollData, 0, len(employeeData))
 	for _, data := range employeeData {
 		data.TotalSalary = int(data.NetHours * float64(data.HourlyWage))
 		result = append(result, *data)
 	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].EmployeeID < result[j].EmployeeID
+	})
 
-	return c.JSON(http.StatusOK, result)
+	return result
 }
 
-// GetEmployeePayroll 従業員個人の給与取得
-func GetEmployeePayroll(c echo.Context) error {
-	employeeID := c.Param("id")
-	year := c.QueryParam("year")
-	month := c.QueryParam("month")
-
-	if year == "" || month == "" {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "年と月のパラメータが必要です",
-		})
-	}
-
-	yearInt, err := strconv.Atoi(year)
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "年は数値で指定してください",
-		})
-	}
-
-	monthInt, err := strconv.Atoi(month)
+// wageOn 指定日に有効な時給を取得（wages は適用日の新しい順）
+func wageOn(wages []models.HourlyWage, employeeID int, date string, defaultWage int) int {
+	day, err := models.ParseDate(date)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "月は数値で指定してください",
-		})
+		return defaultWage
 	}
 
-	employeeIDInt, err := strconv.Atoi(employeeID)
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "従業員IDは数値で指定してください",
-		})
-	}
-
-	// 月の開始日と終了日を計算
-	startDate := time.Date(yearInt, time.Month(monthInt), 1, 0, 0, 0, 0, time.UTC)
-	endDate := startDate.AddDate(0, 1, -1)
-
-	// 従業員のシフトデータを取得
-	query := `
-		SELECT 
-			s.id,
-			s.employee_id,
-			e.name as employee_name,
-			s.date,
-			s.start_time,
-			s.end_time,
-			s.break_time,
-			COALESCE(hw.hourly_wage, 1000) as hourly_wage
-		FROM shifts s
-		JOIN employees e ON s.employee_id = e.id
-		LEFT JOIN hourly_wages hw ON s.employee_id = hw.employee_id 
-			AND hw.effective_date <= s.date
-			AND hw.id = (
-				SELECT MAX(id) FROM hourly_wages hw2 
-				WHERE hw2.employee_id = s.employee_id 
-				AND hw2.effective_date <= s.date
-			)
-		WHERE s.employee_id = $1 AND s.date >= $2 AND s.date <= $3
-		ORDER BY s.date
-	`
-
-	rows, err := database.DB.Query(query, employeeIDInt, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "シフトデータの取得に失敗しました",
-		})
-	}
-	defer rows.Close()
-
-	var totalHours, totalBreakTime, netHours float64
-	var shiftCount int
-	var hourlyWage int
-	var employeeName string
-
-	for rows.Next() {
-		var shift struct {
-			ID           int       `json:"id"`
-			EmployeeID   int       `json:"employee_id"`
-			EmployeeName string    `json:"employee_name"`
-			Date         time.Time `json:"date"`
-			StartTime    time.Time `json:"start_time"`
-			EndTime      time.Time `json:"end_time"`
-			BreakTime    int       `json:"break_time"`
-			HourlyWage   int       `json:"hourly_wage"`
-		}
-
-		err := rows.Scan(
-			&shift.ID,
-			&shift.EmployeeID,
-			&shift.EmployeeName,
-			&shift.Date,
-			&shift.StartTime,
-			&shift.EndTime,
-			&shift.BreakTime,
-			&shift.HourlyWage,
-		)
-		if err != nil {
+	for _, hw := range wages {
+		if hw.EmployeeID != employeeID {
 			continue
 		}
-
-		employeeName = shift.EmployeeName
-		duration := shift.EndTime.Sub(shift.StartTime)
-		totalHours += duration.Hours()
-		totalBreakTime += float64(shift.BreakTime)
-		netHours += duration.Hours() - float64(shift.BreakTime)/60.0
-		shiftCount++
-		// 最新の時給を使用
-		if shift.HourlyWage > hourlyWage {
-			hourlyWage = shift.HourlyWage
+		effective, err := models.ParseDate(hw.EffectiveDate)
+		if err == nil && !effective.After(day) {
+			return hw.HourlyWage
 		}
 	}
-
-	if shiftCount == 0 {
-		return c.JSON(http.StatusNotFound, map[string]string{
-			"error": "指定月のシフトデータが見つかりません",
-		})
-	}
-
-	totalSalary := int(netHours * float64(hourlyWage))
-
-	result := models.PayrollData{
-		EmployeeID:     employeeIDInt,
-		EmployeeName:   employeeName,
-		TotalHours:     totalHours,
-		TotalBreakTime: int(totalBreakTime),
-		NetHours:       netHours,
-		HourlyWage:     hourlyWage,
-		TotalSalary:    totalSalary,
-		ShiftCount:     shiftCount,
-	}
-
-	return c.JSON(http.StatusOK, result)
+	return defaultWage
 }
@@ -0,0 +1,332 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"shift-management-backend/models"
+	"shift-management-backend/repository"
+	"shift-management-backend/scheduler"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 自動シフト作成ジョブのリース
+// ジョブを実行するサーバーは scheduleJobHeartbeat ごとにリースを延長し、
+// 期限が切れた待機中・実行中のジョブは停止したサーバーで中断したものとみなす
+const (
+	scheduleJobLease     = 2 * time.Minute
+	scheduleJobHeartbeat = 30 * time.Second
+)
+
+// GetScheduleJobs 自動シフト作成ジョブ一覧を取得（作成案は含めない）
+func (h *Handler) GetScheduleJobs(c echo.Context) error {
+	jobs, err := h.repos.ScheduleJobs.List()
+	if err != nil {
+		return serverError(c, err, "自動シフト作成ジョブの取得に失敗しました")
+	}
+
+	for i := range jobs {
+		jobs[i].Result = nil
+	}
+	if jobs == nil {
+		jobs = []models.ScheduleJob{}
+	}
+	return c.JSON(http.StatusOK, jobs)
+}
+
+// GetScheduleJob 自動シフト作成ジョブを作成案とあわせて取得
+func (h *Handler) GetScheduleJob(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効なIDです",
+		})
+	}
+
+	job, err := h.repos.ScheduleJobs.Get(id)
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "自動シフト作成ジョブが見つかりません",
+		})
+	}
+	if err != nil {
+		return serverError(c, err, "自動シフト作成ジョブの取得に失敗しました")
+	}
+
+	return c.JSON(http.StatusOK, job)
+}
+
+// CreateScheduleJob 自動シフト作成ジョブを登録し、バックグラウンドで作成案を作る
+// 作成案はシフトには反映しない。結果は GetScheduleJob で確認し、ApplyScheduleJob で反映する
+func (h *Handler) CreateScheduleJob(c echo.Context) error {
+	var req models.CreateScheduleJobRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "リクエストの解析に失敗しました",
+		})
+	}
+
+	_, _, message := parseDateRange(req.StartDate, req.EndDate, maxRangeDays)
+	if message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	leaseExpiresAt := time.Now().Add(scheduleJobLease)
+	job := models.ScheduleJob{
+		Status:         models.ScheduleJobPending,
+		StartDate:      req.StartDate,
+		EndDate:        req.EndDate,
+		Seed:           time.Now().UnixNano(),
+		MaxWeeklyHours: h.cfg.Scheduling.MaxWeeklyHours,
+		MinRestMinutes: int(h.cfg.Scheduling.MinRestInterval.Minutes()),
+		LeaseExpiresAt: &leaseExpiresAt,
+	}
+	if req.Seed != nil {
+		job.Seed = *req.Seed
+	}
+	if req.MaxWeeklyHours != nil {
+		if *req.MaxWeeklyHours <= 0 || *req.MaxWeeklyHours > 168 {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "週の勤務時間の上限は1-168の範囲で指定してください",
+			})
+		}
+		job.MaxWeeklyHours = *req.MaxWeeklyHours
+	}
+	if req.MinRestMinutes != nil {
+		if *req.MinRestMinutes < 0 || *req.MinRestMinutes > 24*60 {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "休息時間は0-1440分の範囲で指定してください",
+			})
+		}
+		job.MinRestMinutes = *req.MinRestMinutes
+	}
+
+	job, err := h.repos.ScheduleJobs.Create(job)
+	if err != nil {
+		return serverError(c, err, "自動シフト作成ジョブの登録に失敗しました")
+	}
+
+	h.jobs.Add(1)
+	go h.runScheduleJob(job)
+
+	return c.JSON(http.StatusAccepted, job)
+}
+
+// runScheduleJob 作成案を作り、結果をジョブに保存する
+// 作成中はジョブのリースを延長し続け、パニックが発生した場合はジョブを失敗にする
+func (h *Handler) runScheduleJob(job models.ScheduleJob) {
+	defer h.jobs.Done()
+	stop := make(chan struct{})
+	defer close(stop)
+	go h.renewScheduleJobLease(job.ID, stop)
+	defer func() {
+		if p := recover(); p != nil {
+			log.Printf("自動シフト作成ジョブ(%d)でパニックが発生しました: %v", job.ID, p)
+			now := time.Now()
+			job.Status = models.ScheduleJobFailed
+			job.Result = nil
+			job.Error = "作成案の作成中にエラーが発生しました"
+			job.FinishedAt = &now
+			if err := h.repos.ScheduleJobs.Update(job); err != nil {
+				log.Printf("自動シフト作成ジョブ(%d)の更新に失敗しました: %v", job.ID, err)
+			}
+		}
+	}()
+
+	job.Status = models.ScheduleJobRunning
+	if err := h.repos.ScheduleJobs.Update(job); err != nil {
+		log.Printf("自動シフト作成ジョブ(%d)の更新に失敗しました: %v", job.ID, err)
+		return
+	}
+
+	result, err := h.generateSchedule(job)
+	now := time.Now()
+	job.FinishedAt = &now
+	if err != nil {
+		log.Printf("自動シフト作成ジョブ(%d)が失敗しました: %v", job.ID, err)
+		job.Status = models.ScheduleJobFailed
+		job.Error = "作成案の作成に必要なデータの取得に失敗しました"
+	} else {
+		job.Status = models.ScheduleJobCompleted
+		job.Result = &result
+	}
+	if err := h.repos.ScheduleJobs.Update(job); err != nil {
+		log.Printf("自動シフト作成ジョブ(%d)の更新に失敗しました: %v", job.ID, err)
+	}
+}
+
+// renewScheduleJobLease stop が閉じられるまで、ジョブのリースを定期的に延長する
+func (h *Handler) renewScheduleJobLease(id int, stop <-chan struct{}) {
+	ticker := time.NewTicker(scheduleJobHeartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			if err := h.repos.ScheduleJobs.RenewLease(id, now.Add(scheduleJobLease)); err != nil {
+				log.Printf("自動シフト作成ジョブ(%d)のリースの延長に失敗しました: %v", id, err)
+			}
+		}
+	}
+}
+
+// WaitScheduleJobs 実行中の自動シフト作成ジョブの完了を待つ（ctx の期限を過ぎた場合は ctx のエラーを返す）
+func (h *Handler) WaitScheduleJobs(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		h.jobs.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// FailInterruptedScheduleJobs リースの期限が切れた待機中・実行中の自動シフト作成ジョブを失敗にし、件数を返す
+// 起動時と定期的に呼び出し、停止したサーバーで中断したジョブを再作成できるようにする
+// 他のサーバーが実行中のジョブはリースが延長されているため失敗にしない
+func (h *Handler) FailInterruptedScheduleJobs() (int, error) {
+	return h.repos.ScheduleJobs.FailExpired(time.Now(), "サーバーの停止により作成案の作成が中断されました。もう一度ジョブを登録してください")
+}
+
+// generateSchedule ジョブの条件で作成案を作る
+func (h *Handler) generateSchedule(job models.ScheduleJob) (models.ScheduleResult, error) {
+	startDate, err := models.ParseDate(job.StartDate)
+	if err != nil {
+		return models.ScheduleResult{}, err
+	}
+	endDate, err := models.ParseDate(job.EndDate)
+	if err != nil {
+		return models.ScheduleResult{}, err
+	}
+
+	timeSlots, err := h.repos.TimeSlots.List(nil)
+	if err != nil {
+		return models.ScheduleResult{}, err
+	}
+	requests, err := h.repos.ShiftRequests.List(nil)
+	if err != nil {
+		return models.ScheduleResult{}, err
+	}
+	employees, err := h.repos.Employees.List()
+	if err != nil {
+		return models.ScheduleResult{}, err
+	}
+	wages, err := h.repos.Wages.List(nil)
+	if err != nil {
+		return models.ScheduleResult{}, err
+	}
+	desiredHours, err := h.repos.DesiredHours.List()
+	if err != nil {
+		return models.ScheduleResult{}, err
+	}
+	availability, err := h.repos.Availability.List(nil)
+	if err != nil {
+		return models.ScheduleResult{}, err
+	}
+
+	// 週・月の勤務時間を判定するため期間を含む週全体（日曜始まり）・月全体と、休息時間を判定するため前後1日のシフトを取得する
+	from := startDate.AddDate(0, 0, -int(startDate.Weekday())-1)
+	if monthStart := startDate.AddDate(0, 0, 1-startDate.Day()); monthStart.Before(from) {
+		from = monthStart
+	}
+	to := endDate.AddDate(0, 0, 7-int(endDate.Weekday()))
+	if monthEnd := endDate.AddDate(0, 1, -endDate.Day()); monthEnd.After(to) {
+		to = monthEnd
+	}
+	shifts, err := h.repos.Shifts.List(repository.ShiftFilter{
+		StartDate: from.Format(models.DateLayout),
+		EndDate:   to.Format(models.DateLayout),
+	})
+	if err != nil {
+		return models.ScheduleResult{}, err
+	}
+
+	return scheduler.Generate(scheduler.Input{
+		StartDate:    startDate,
+		EndDate:      endDate,
+		TimeSlots:    timeSlots,
+		Requests:     requests,
+		Employees:    employees,
+		DesiredHours: desiredHours,
+		Availability: availability,
+		Existing:     shifts,
+		HourlyWage: func(employeeID int, date string) int {
+			return wageOn(wages, employeeID, date, h.cfg.Payroll.DefaultHourlyWage)
+		},
+		MaxWeeklyHours: job.MaxWeeklyHours,
+		MinRest:        time.Duration(job.MinRestMinutes) * time.Minute,
+		Seed:           job.Seed,
+	}), nil
+}
+
+// ApplyScheduleJob 作成案のシフトをまとめて作成する
+// 労働基準法に基づくルールと勤務可能な時間帯は設定どおりに確認し、警告は作成したシフトに含めて返す
+// 作成案を作った後に他のシフトが登録されて重なる場合や、エラーとするルールに違反する場合は、1件も作成せずにエラーを返す
+func (h *Handler) ApplyScheduleJob(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効なIDです",
+		})
+	}
+
+	var created []models.Shift
+	err = h.repos.InTx(func(r *repository.Repositories) error {
+		// 同じ作成案の並行した反映を直列化し、ロックした後の状態で確認する
+		if err := r.ScheduleJobs.Lock(id); err != nil {
+			return err
+		}
+		job, err := r.ScheduleJobs.Get(id)
+		if err != nil {
+			return err
+		}
+		switch job.Status {
+		case models.ScheduleJobCompleted:
+		case models.ScheduleJobApplied:
+			return &httpError{http.StatusConflict, "この作成案は既にシフトに反映されています"}
+		default:
+			return &httpError{http.StatusConflict, "作成が完了していないジョブは反映できません"}
+		}
+
+		// 作成案を作った後に登録されたシフトも含めて、シフトを手動で作成する場合と同じルールで確認する
+		for _, shift := range job.Result.Shifts {
+			saved, err := h.createShift(r, shift)
+			if err != nil {
+				if he, ok := err.(*httpError); ok {
+					span, _ := shift.Span()
+					return &httpError{he.status, shift.EmployeeName + "さんの " + span.String() + " のシフト: " + he.message}
+				}
+				return err
+			}
+			created = append(created, saved)
+		}
+
+		job.Status = models.ScheduleJobApplied
+		return r.ScheduleJobs.Update(job)
+	})
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "自動シフト作成ジョブが見つかりません",
+		})
+	}
+	if err != nil {
+		return respondError(c, err, "作成案の反映に失敗しました")
+	}
+
+	if created == nil {
+		created = []models.Shift{}
+	}
+	return c.JSON(http.StatusCreated, created)
+}
@@ -0,0 +1,497 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"shift-management-backend/models"
+	"shift-management-backend/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetSchedulePeriods シフト期間一覧を取得
+func (h *Handler) GetSchedulePeriods(c echo.Context) error {
+	periods, err := h.repos.Periods.List()
+	if err != nil {
+		return serverError(c, err, "シフト期間の取得に失敗しました")
+	}
+	if periods == nil {
+		periods = []models.SchedulePeriod{}
+	}
+	return c.JSON(http.StatusOK, periods)
+}
+
+// CreateSchedulePeriod シフト期間を作成（作成中の状態で作成する）
+func (h *Handler) CreateSchedulePeriod(c echo.Context) error {
+	var req models.CreateSchedulePeriodRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "リクエストの解析に失敗しました",
+		})
+	}
+
+	_, _, message := parseDateRange(req.StartDate, req.EndDate, maxRangeDays)
+	if message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	period := models.SchedulePeriod{
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		Status:    models.SchedulePeriodDraft,
+	}
+
+	// シフトが属する期間を1つに決められるよう、期間どうしの重なりは認めない
+	err := h.repos.InTx(func(r *repository.Repositories) error {
+		periods, err := r.Periods.List()
+		if err != nil {
+			return err
+		}
+		for _, p := range periods {
+			if dateKey(p.StartDate) <= req.EndDate && req.StartDate <= dateKey(p.EndDate) {
+				return &httpError{http.StatusConflict, "期間が重なるシフト期間が既にあります（" +
+					dateKey(p.StartDate) + "〜" + dateKey(p.EndDate) + "）"}
+			}
+		}
+		created, err := r.Periods.Create(period)
+		if err != nil {
+			return err
+		}
+		period = created
+		return nil
+	})
+	if err != nil {
+		return respondError(c, err, "シフト期間の作成に失敗しました")
+	}
+
+	return c.JSON(http.StatusCreated, period)
+}
+
+// DeleteSchedulePeriod シフト期間を削除（期間内のシフトは削除せず、従業員には見えなくなる）
+func (h *Handler) DeleteSchedulePeriod(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効なIDです",
+		})
+	}
+
+	err = h.repos.Periods.Delete(id)
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "シフト期間が見つかりません",
+		})
+	}
+	if err != nil {
+		return serverError(c, err, "シフト期間の削除に失敗しました")
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "シフト期間が削除されました",
+	})
+}
+
+// GetSchedulePeriodDiff 公開済みの内容から現在のシフトへの変更点を取得（公開前の確認用）
+func (h *Handler) GetSchedulePeriodDiff(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効なIDです",
+		})
+	}
+
+	period, err := h.repos.Periods.Get(id)
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "シフト期間が見つかりません",
+		})
+	}
+	if err != nil {
+		return serverError(c, err, "シフト期間の取得に失敗しました")
+	}
+
+	published, err := publishedShiftsOf(h.repos, period)
+	if err != nil {
+		return serverError(c, err, "公開済みのシフトの取得に失敗しました")
+	}
+	current, err := periodShifts(h.repos, period)
+	if err != nil {
+		return serverError(c, err, "シフトデータの取得に失敗しました")
+	}
+
+	return c.JSON(http.StatusOK, diffShifts(published, current))
+}
+
+// PublishSchedulePeriod シフト期間を公開する
+// 期間内の現在のシフトをスナップショットとして保存し、前回公開した内容からの変更点を返す
+func (h *Handler) PublishSchedulePeriod(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効なIDです",
+		})
+	}
+
+	var resp models.PublishSchedulePeriodResponse
+	err = h.repos.InTx(func(r *repository.Repositories) error {
+		period, err := r.Periods.Get(id)
+		if err != nil {
+			return err
+		}
+		if period.Status == models.SchedulePeriodPublished {
+			return &httpError{http.StatusConflict, "このシフト期間は公開済みで、公開後の変更はありません"}
+		}
+
+		published, err := publishedShiftsOf(r, period)
+		if err != nil {
+			return err
+		}
+		current, err := periodShifts(r, period)
+		if err != nil {
+			return err
+		}
+
+		_, err = r.Periods.CreateSnapshot(models.SchedulePeriodSnapshot{
+			PeriodID: period.ID,
+			Version:  period.PublishedVersion + 1,
+			Shifts:   current,
+		})
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		period.Status = models.SchedulePeriodPublished
+		period.PublishedVersion++
+		period.PublishedAt = &now
+		if err := r.Periods.Update(period); err != nil {
+			return err
+		}
+
+		resp.Period, err = r.Periods.Get(id)
+		resp.Diff = diffShifts(published, current)
+		return err
+	})
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "シフト期間が見つかりません",
+		})
+	}
+	if err != nil {
+		return respondError(c, err, "シフト期間の公開に失敗しました")
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// GetSchedulePeriodSnapshots 公開したスナップショットの一覧を取得（シフトは含めない）
+func (h *Handler) GetSchedulePeriodSnapshots(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効なIDです",
+		})
+	}
+
+	if _, err := h.repos.Periods.Get(id); err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "シフト期間が見つかりません",
+		})
+	} else if err != nil {
+		return serverError(c, err, "シフト期間の取得に失敗しました")
+	}
+
+	snapshots, err := h.repos.Periods.ListSnapshots(id)
+	if err != nil {
+		return serverError(c, err, "スナップショットの取得に失敗しました")
+	}
+	if snapshots == nil {
+		snapshots = []models.SchedulePeriodSnapshot{}
+	}
+	return c.JSON(http.StatusOK, snapshots)
+}
+
+// GetSchedulePeriodSnapshot 指定した版のスナップショットをシフトとあわせて取得
+func (h *Handler) GetSchedulePeriodSnapshot(c echo.Context) error {
+	id, errID := strconv.Atoi(c.Param("id"))
+	version, errVersion := strconv.Atoi(c.Param("version"))
+	if errID != nil || errVersion != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効なIDです",
+		})
+	}
+
+	snapshot, err := h.repos.Periods.Snapshot(id, version)
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "スナップショットが見つかりません",
+		})
+	}
+	if err != nil {
+		return serverError(c, err, "スナップショットの取得に失敗しました")
+	}
+	return c.JSON(http.StatusOK, snapshot)
+}
+
+// periodShifts 期間内の現在のシフト
+func periodShifts(r *repository.Repositories, period models.SchedulePeriod) ([]models.Shift, error) {
+	return r.Shifts.List(repository.ShiftFilter{
+		StartDate: dateKey(period.StartDate),
+		EndDate:   dateKey(period.EndDate),
+	})
+}
+
+// publishedShiftsOf 期間内の公開済みのシフト（従業員に見せる内容）
+// 公開後に変更していない期間は現在のシフト、変更した期間は最後に公開したスナップショットを返す
+func publishedShiftsOf(r *repository.Repositories, period models.SchedulePeriod) ([]models.Shift, error) {
+	if period.Status == models.SchedulePeriodPublished {
+		return periodShifts(r, period)
+	}
+	if period.PublishedVersion == 0 {
+		return nil, nil
+	}
+	snapshot, err := r.Periods.Snapshot(period.ID, period.PublishedVersion)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.Shifts, nil
+}
+
+// touchSchedulePeriods シフトを変更する前に呼び、変更する日付を含む公開済みの期間を作成中に戻す
+// 公開時のスナップショットがない期間（移行前から公開されていた期間）は、変更前のシフトをスナップショットとして保存する
+// 作成中に戻した期間も、再公開するまで従業員にはスナップショットの内容を見せる
+func touchSchedulePeriods(r *repository.Repositories, dates ...string) error {
+	periods, err := r.Periods.List()
+	if err != nil {
+		return err
+	}
+
+	for _, period := range periods {
+		if period.Status != models.SchedulePeriodPublished {
+			continue
+		}
+		touched := false
+		for _, date := range dates {
+			if period.Contains(date) {
+				touched = true
+				break
+			}
+		}
+		if !touched {
+			continue
+		}
+
+		if period.PublishedVersion == 0 {
+			shifts, err := periodShifts(r, period)
+			if err != nil {
+				return err
+			}
+			_, err = r.Periods.CreateSnapshot(models.SchedulePeriodSnapshot{
+				PeriodID: period.ID,
+				Version:  1,
+				Shifts:   shifts,
+			})
+			if err != nil {
+				return err
+			}
+			period.PublishedVersion = 1
+		}
+		period.Status = models.SchedulePeriodDraft
+		if err := r.Periods.Update(period); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// visibleShifts ユーザーが閲覧できるシフト
+// オーナーには現在のシフト、従業員にはシフト期間ごとの公開済みのシフトを返す（期間に属さないシフトは見せない）
+func (h *Handler) visibleShifts(acc access, filter repository.ShiftFilter) ([]models.Shift, error) {
+	if acc.IsOwner() {
+		return h.repos.Shifts.List(filter)
+	}
+
+	periods, err := h.repos.Periods.ListOverlapping(filter.StartDate, filter.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	var shifts []models.Shift
+	for _, period := range periods {
+		published, err := publishedShiftsOf(h.repos, period)
+		if err != nil {
+			return nil, err
+		}
+		for _, shift := range published {
+			date := dateKey(shift.Date)
+			if filter.EmployeeID != nil && shift.EmployeeID != *filter.EmployeeID {
+				continue
+			}
+			if filter.OpenOnly && !shift.IsOpen() {
+				continue
+			}
+			if (filter.StartDate != "" && date < dateKey(filter.StartDate)) ||
+				(filter.EndDate != "" && date > dateKey(filter.EndDate)) {
+				continue
+			}
+			shifts = append(shifts, shift)
+		}
+	}
+
+	sort.SliceStable(shifts, func(i, j int) bool {
+		a, b := dateKey(shifts[i].Date), dateKey(shifts[j].Date)
+		if a != b {
+			if filter.NewestFirst {
+				return a > b
+			}
+			return a < b
+		}
+		return shifts[i].StartTime < shifts[j].StartTime
+	})
+	return shifts, nil
+}
+
+// visibleShift ユーザーが閲覧できる内容でシフトを取得（見せられない場合は ErrNotFound）
+// 現在の日付を含む期間から探し、見つからない場合は公開後に変更した期間のスナップショットから探す
+// （公開後に日付を変更・削除したシフトは、公開時のスナップショットにだけ残っている）
+func (h *Handler) visibleShift(acc access, id int) (models.Shift, error) {
+	if acc.IsOwner() {
+		return h.repos.Shifts.Get(id)
+	}
+
+	checked := make(map[int]bool)
+	current, err := h.repos.Shifts.Get(id)
+	if err != nil && err != repository.ErrNotFound {
+		return models.Shift{}, err
+	}
+	if err == nil {
+		date := dateKey(current.Date)
+		periods, err := h.repos.Periods.ListOverlapping(date, date)
+		if err != nil {
+			return models.Shift{}, err
+		}
+		for _, period := range periods {
+			checked[period.ID] = true
+			if shift, found, err := findPublishedShift(h.repos, period, id); err != nil || found {
+				return shift, err
+			}
+		}
+	}
+
+	periods, err := h.repos.Periods.List()
+	if err != nil {
+		return models.Shift{}, err
+	}
+	for _, period := range periods {
+		if checked[period.ID] || period.Status == models.SchedulePeriodPublished || period.PublishedVersion == 0 {
+			continue
+		}
+		if shift, found, err := findPublishedShift(h.repos, period, id); err != nil || found {
+			return shift, err
+		}
+	}
+	return models.Shift{}, repository.ErrNotFound
+}
+
+// findPublishedShift 期間の公開済みのシフトから ID が一致するシフトを探す
+func findPublishedShift(r *repository.Repositories, period models.SchedulePeriod, id int) (models.Shift, bool, error) {
+	published, err := publishedShiftsOf(r, period)
+	if err != nil {
+		return models.Shift{}, false, err
+	}
+	for _, shift := range published {
+		if shift.ID == id {
+			return shift, true, nil
+		}
+	}
+	return models.Shift{}, false, nil
+}
+
+// diffShifts 変更前後のシフトを ID で対応づけて、追加・削除・変更されたシフトを求める
+func diffShifts(before, after []models.Shift) models.ScheduleDiff {
+	diff := models.ScheduleDiff{
+		Added:   []models.Shift{},
+		Removed: []models.Shift{},
+		Changed: []models.ShiftChange{},
+	}
+
+	beforeByID := make(map[int]models.Shift, len(before))
+	for _, shift := range before {
+		beforeByID[shift.ID] = shift
+	}
+	afterIDs := make(map[int]bool, len(after))
+	for _, shift := range after {
+		afterIDs[shift.ID] = true
+		old, ok := beforeByID[shift.ID]
+		if !ok {
+			diff.Added = append(diff.Added, shift)
+			continue
+		}
+		if fields := changedShiftFields(old, shift); len(fields) > 0 {
+			diff.Changed = append(diff.Changed, models.ShiftChange{Before: old, After: shift, Fields: fields})
+		}
+	}
+	for _, shift := range before {
+		if !afterIDs[shift.ID] {
+			diff.Removed = append(diff.Removed, shift)
+		}
+	}
+	return diff
+}
+
+// changedShiftFields 変更された項目の一覧（日付・時刻は形式の違いを無視して比較する）
+func changedShiftFields(a, b models.Shift) []string {
+	var fields []string
+	if a.EmployeeID != b.EmployeeID {
+		fields = append(fields, "employee_id")
+	}
+	if dateKey(a.Date) != dateKey(b.Date) {
+		fields = append(fields, "date")
+	}
+	if !sameClock(a.StartTime, b.StartTime) {
+		fields = append(fields, "start_time")
+	}
+	if !sameClock(a.EndTime, b.EndTime) {
+		fields = append(fields, "end_time")
+	}
+	if a.EndsNextDay != b.EndsNextDay {
+		fields = append(fields, "ends_next_day")
+	}
+	if a.BreakTime != b.BreakTime {
+		fields = append(fields, "break_time")
+	}
+	if a.Position != b.Position {
+		fields = append(fields, "position")
+	}
+	if !sameSegments(a.Segments, b.Segments) {
+		fields = append(fields, "segments")
+	}
+	return fields
+}
+
+// sameClock 2つの時刻文字列が同じ時刻を表すか
+func sameClock(a, b string) bool {
+	ma, errA := models.ParseClock(a)
+	mb, errB := models.ParseClock(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return ma == mb
+}
+
+func sameSegments(a, b []models.ShiftSegment) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Position != b[i].Position || !sameClock(a[i].StartTime, b[i].StartTime) || !sameClock(a[i].EndTime, b[i].EndTime) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"shift-management-backend/models"
+)
+
+// publishTestPeriod シフト期間を作成して公開する
+func publishTestPeriod(t *testing.T, h *Handler, startDate, endDate string) models.SchedulePeriod {
+	t.Helper()
+	code, body := serve(t, h.CreateSchedulePeriod, http.MethodPost, "/api/schedule-periods",
+		models.CreateSchedulePeriodRequest{StartDate: startDate, EndDate: endDate}, testOwner)
+	if code != http.StatusCreated {
+		t.Fatalf("status = %d: %s", code, body)
+	}
+	var period models.SchedulePeriod
+	decodeBody(t, body, &period)
+
+	id := strconv.Itoa(period.ID)
+	code, body = serve(t, h.PublishSchedulePeriod, http.MethodPost, "/api/schedule-periods/"+id+"/publish", nil, testOwner, "id", id)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d: %s", code, body)
+	}
+	return period
+}
+
+func TestGetShiftShowsPublishedContentAfterMove(t *testing.T) {
+	h := newTestHandler(t)
+	employee := createTestEmployee(t, h, "山田")
+	shift := createTestShift(t, h, models.CreateShiftRequest{EmployeeID: employee.ID, Date: "2030-10-08", StartTime: "10:00", EndTime: "14:00"})
+	publishTestPeriod(t, h, "2030-10-07", "2030-10-13")
+	publishTestPeriod(t, h, "2030-10-21", "2030-10-27")
+
+	// 公開後に別の期間の日付に移したシフトは、再公開するまで移す前の内容を見せる
+	id := strconv.Itoa(shift.ID)
+	code, body := serve(t, h.UpdateShift, http.MethodPut, "/api/shifts/"+id, models.UpdateShiftRequest{Date: "2030-10-22"}, testOwner, "id", id)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d: %s", code, body)
+	}
+
+	code, body = serve(t, h.GetShift, http.MethodGet, "/api/shifts/"+id, nil, employeeUser(employee), "id", id)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d: %s", code, body)
+	}
+	var got models.Shift
+	decodeBody(t, body, &got)
+	if dateKey(got.Date) != "2030-10-08" {
+		t.Errorf("date = %s, want 2030-10-08", got.Date)
+	}
+}
+
+func TestGetShiftTradesShowsOnlyPublishedShifts(t *testing.T) {
+	h := newTestHandler(t)
+	offerer := createTestEmployee(t, h, "山田")
+	acceptor := createTestEmployee(t, h, "佐藤")
+	published := createTestShift(t, h, models.CreateShiftRequest{EmployeeID: offerer.ID, Date: "2030-10-08", StartTime: "10:00", EndTime: "14:00"})
+	unpublished := createTestShift(t, h, models.CreateShiftRequest{EmployeeID: offerer.ID, Date: "2030-10-15", StartTime: "10:00", EndTime: "14:00"})
+	publishTestPeriod(t, h, "2030-10-07", "2030-10-13")
+
+	for _, shift := range []models.Shift{published, unpublished} {
+		if _, err := h.repos.ShiftTrades.Create(models.ShiftTrade{
+			ShiftID:   shift.ID,
+			Type:      models.ShiftTradeGiveaway,
+			OfferedBy: offerer.ID,
+			Status:    models.ShiftTradeOpen,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	code, body := serve(t, h.GetShiftTrades, http.MethodGet, "/api/shift-trades", nil, employeeUser(acceptor))
+	if code != http.StatusOK {
+		t.Fatalf("status = %d: %s", code, body)
+	}
+	var trades []models.ShiftTrade
+	decodeBody(t, body, &trades)
+	if len(trades) != 1 || trades[0].ShiftID != published.ID || trades[0].Shift == nil {
+		t.Fatalf("公開済みのシフトの募集だけを返すはずです: %s", body)
+	}
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"shift-management-backend/models"
+	"shift-management-backend/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetScheduleTemplates シフトテンプレート一覧を取得（シフトは含めない）
+func (h *Handler) GetScheduleTemplates(c echo.Context) error {
+	templates, err := h.repos.Templates.List()
+	if err != nil {
+		return serverError(c, err, "シフトテンプレートの取得に失敗しました")
+	}
+	if templates == nil {
+		templates = []models.ScheduleTemplate{}
+	}
+	return c.JSON(http.StatusOK, templates)
+}
+
+// GetScheduleTemplate シフトテンプレートをシフトとあわせて取得
+func (h *Handler) GetScheduleTemplate(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効なIDです",
+		})
+	}
+
+	template, err := h.repos.Templates.Get(id)
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "シフトテンプレートが見つかりません",
+		})
+	}
+	if err != nil {
+		return serverError(c, err, "シフトテンプレートの取得に失敗しました")
+	}
+
+	return c.JSON(http.StatusOK, template)
+}
+
+// CreateScheduleTemplate 指定した週（日曜始まり）のシフトを名前を付けてテンプレートとして保存する
+func (h *Handler) CreateScheduleTemplate(c echo.Context) error {
+	var req models.CreateScheduleTemplateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "リクエストの解析に失敗しました",
+		})
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || req.WeekStart == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "テンプレート名と保存する週を指定してください",
+		})
+	}
+	day, err := models.ParseDate(req.WeekStart)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効な日付です",
+		})
+	}
+	weekStart := day.AddDate(0, 0, -int(day.Weekday()))
+
+	var template models.ScheduleTemplate
+	err = h.repos.InTx(func(r *repository.Repositories) error {
+		templates, err := r.Templates.List()
+		if err != nil {
+			return err
+		}
+		for _, t := range templates {
+			if t.Name == req.Name {
+				return &httpError{http.StatusConflict, "同じ名前のシフトテンプレートが既にあります"}
+			}
+		}
+
+		shifts, err := r.Shifts.List(repository.ShiftFilter{
+			StartDate: weekStart.Format(models.DateLayout),
+			EndDate:   weekStart.AddDate(0, 0, 6).Format(models.DateLayout),
+		})
+		if err != nil {
+			return err
+		}
+		if len(shifts) == 0 {
+			return &httpError{http.StatusBadRequest, "指定した週にシフトがありません"}
+		}
+
+		template = models.ScheduleTemplate{Name: req.Name}
+		for _, shift := range shifts {
+			date, err := models.ParseDate(shift.Date)
+			if err != nil {
+				return err
+			}
+			template.Shifts = append(template.Shifts, models.TemplateShift{
+				Weekday:      int(date.Weekday()),
+				EmployeeID:   shift.EmployeeID,
+				EmployeeName: shift.EmployeeName,
+				StartTime:    shift.StartTime,
+				EndTime:      shift.EndTime,
+				EndsNextDay:  shift.EndsNextDay,
+				BreakTime:    shift.BreakTime,
+				Position:     shift.Position,
+				Segments:     shift.Segments,
+			})
+		}
+		template, err = r.Templates.Create(template)
+		return err
+	})
+	if err != nil {
+		return respondError(c, err, "シフトテンプレートの作成に失敗しました")
+	}
+
+	return c.JSON(http.StatusCreated, template)
+}
+
+// DeleteScheduleTemplate シフトテンプレートを削除
+func (h *Handler) DeleteScheduleTemplate(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効なIDです",
+		})
+	}
+
+	err = h.repos.Templates.Delete(id)
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "シフトテンプレートが見つかりません",
+		})
+	}
+	if err != nil {
+		return serverError(c, err, "シフトテンプレートの削除に失敗しました")
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "シフトテンプレートが削除されました",
+	})
+}
+
+// ApplyScheduleTemplate シフトテンプレートを指定した週（日曜始まり）に適用してシフトを作成する
+// 作成できないシフトは CopyShifts と同じく競合として返す
+func (h *Handler) ApplyScheduleTemplate(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効なIDです",
+		})
+	}
+
+	var req models.ApplyScheduleTemplateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "リクエストの解析に失敗しました",
+		})
+	}
+	day, err := models.ParseDate(req.WeekStart)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "適用する週を指定してください",
+		})
+	}
+	weekStart := day.AddDate(0, 0, -int(day.Weekday()))
+
+	var res models.CopyShiftsResponse
+	err = h.repos.InTx(func(r *repository.Repositories) error {
+		template, err := r.Templates.Get(id)
+		if err != nil {
+			return err
+		}
+
+		shifts := make([]models.Shift, 0, len(template.Shifts))
+		for _, ts := range template.Shifts {
+			shifts = append(shifts, models.Shift{
+				EmployeeID:   ts.EmployeeID,
+				Date:         weekStart.AddDate(0, 0, ts.Weekday).Format(models.DateLayout),
+				StartTime:    ts.StartTime,
+				EndTime:      ts.EndTime,
+				EndsNextDay:  ts.EndsNextDay,
+				BreakTime:    ts.BreakTime,
+				Position:     ts.Position,
+				Segments:     ts.Segments,
+				EmployeeName: ts.EmployeeName,
+			})
+		}
+
+		if res, err = h.copyShifts(r, shifts); err != nil {
+			return err
+		}
+		if req.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "シフトテンプレートが見つかりません",
+		})
+	}
+	if err != nil && err != errDryRun {
+		return respondError(c, err, "シフトテンプレートの適用に失敗しました")
+	}
+
+	res.DryRun = req.DryRun
+	return c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"shift-management-backend/models"
+)
+
+func TestFailInterruptedScheduleJobs(t *testing.T) {
+	h := newTestHandler(t)
+
+	expired := time.Now().Add(-time.Minute)
+	leased := time.Now().Add(scheduleJobLease)
+	tests := []struct {
+		name           string
+		status         string
+		leaseExpiresAt *time.Time
+		want           string
+	}{
+		{"リースの期限が切れた待機中のジョブ", models.ScheduleJobPending, &expired, models.ScheduleJobFailed},
+		{"リースの期限が切れた実行中のジョブ", models.ScheduleJobRunning, &expired, models.ScheduleJobFailed},
+		{"リースの期限がないジョブ", models.ScheduleJobRunning, nil, models.ScheduleJobFailed},
+		{"他のサーバーが実行中のジョブ", models.ScheduleJobRunning, &leased, models.ScheduleJobRunning},
+		{"完了したジョブ", models.ScheduleJobCompleted, &expired, models.ScheduleJobCompleted},
+	}
+	ids := make([]int, len(tests))
+	for i, tt := range tests {
+		job, err := h.repos.ScheduleJobs.Create(models.ScheduleJob{
+			Status:         tt.status,
+			StartDate:      "2030-10-06",
+			EndDate:        "2030-10-12",
+			LeaseExpiresAt: tt.leaseExpiresAt,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = job.ID
+	}
+
+	failed, err := h.FailInterruptedScheduleJobs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failed != 3 {
+		t.Errorf("failed = %d, want 3", failed)
+	}
+
+	for i, tt := range tests {
+		job, err := h.repos.ScheduleJobs.Get(ids[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Status != tt.want {
+			t.Errorf("%s の状態 = %s, want %s", tt.name, job.Status, tt.want)
+		}
+		if job.Status == models.ScheduleJobFailed && (job.Error == "" || job.FinishedAt == nil) {
+			t.Errorf("%s にはエラーと終了日時が必要です: %+v", tt.name, job)
+		}
+	}
+}
+
+func TestApplyScheduleJobOnlyOnce(t *testing.T) {
+	h := newTestHandler(t)
+	employee := createTestEmployee(t, h, "山田")
+	job, err := h.repos.ScheduleJobs.Create(models.ScheduleJob{
+		Status:    models.ScheduleJobCompleted,
+		StartDate: "2030-10-06",
+		EndDate:   "2030-10-12",
+		Result: &models.ScheduleResult{Shifts: []models.Shift{{
+			EmployeeID:   employee.ID,
+			EmployeeName: employee.Name,
+			Date:         "2030-10-07",
+			StartTime:    "10:00",
+			EndTime:      "14:00",
+		}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 同時に2回反映しても、シフトが作成されるのは1回だけ
+	id := strconv.Itoa(job.ID)
+	codes := make([]int, 2)
+	var wg sync.WaitGroup
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i], _ = serve(t, h.ApplyScheduleJob, http.MethodPost, "/api/schedule-jobs/"+id+"/apply", nil, testOwner, "id", id)
+		}(i)
+	}
+	wg.Wait()
+
+	if !(codes[0] == http.StatusCreated && codes[1] == http.StatusConflict) &&
+		!(codes[0] == http.StatusConflict && codes[1] == http.StatusCreated) {
+		t.Fatalf("status = %v, want 201 と 409", codes)
+	}
+	if got := len(listTestShifts(t, h)); got != 1 {
+		t.Errorf("シフトの件数 = %d, want 1", got)
+	}
+	got, err := h.repos.ScheduleJobs.Get(job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != models.ScheduleJobApplied {
+		t.Errorf("ジョブの状態 = %s, want %s", got.Status, models.ScheduleJobApplied)
+	}
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"shift-management-backend/database"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetSessions 有効なセッション一覧を取得
+// オーナーは user_id クエリで他のユーザーのセッションを指定できる
+func (h *Handler) GetSessions(c echo.Context) error {
+	userID, status, message := sessionTargetUserID(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+
+	sessions, err := h.sessions.ListByUser(userID)
+	if err != nil {
+		log.Printf("セッション一覧取得エラー: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "セッション一覧の取得に失敗しました",
+		})
+	}
+
+	currentID, _ := c.Get(contextKeySessionID).(int)
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentID
+	}
+
+	return c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession セッションを失効させる
+func (h *Handler) RevokeSession(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効なIDです",
+		})
+	}
+
+	userID, status, message := sessionTargetUserID(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+
+	err = h.sessions.RevokeByID(userID, id)
+	if err == database.ErrSessionNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "セッションが見つかりません",
+		})
+	}
+	if err != nil {
+		log.Printf("セッション失効エラー: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "セッションの失効に失敗しました",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "セッションを失効させました",
+	})
+}
+
+// sessionTargetUserID 操作対象のユーザーIDを取得
+// 対象が不正な場合はHTTPステータスとエラーメッセージを返す
+func sessionTargetUserID(c echo.Context) (int, int, string) {
+	user, ok := CurrentUser(c)
+	if !ok {
+		return 0, http.StatusUnauthorized, "認証が必要です"
+	}
+
+	userIDParam := c.QueryParam("user_id")
+	if userIDParam == "" {
+		return user.ID, 0, ""
+	}
+
+	userID, err := strconv.Atoi(userIDParam)
+	if err != nil {
+		return 0, http.StatusBadRequest, "ユーザーIDは数値で指定してください"
+	}
+
+	if userID != user.ID && user.Role != RoleOwner {
+		return 0, http.StatusForbidden, "この操作を行う権限がありません"
+	}
+
+	return userID, 0, ""
+}
@@ -1,50 +1,42 @@
 package handlers
 
 import (
-	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
-	"shift-management-backend/database"
 	"shift-management-backend/models"
+	"shift-management-backend/repository"
 
 	"github.com/labstack/echo/v4"
 )
 
 // GetShifts シフト一覧を取得
-func GetShifts(c echo.Context) error {
-	rows, err := database.DB.Query(`
-		SELECT s.id, s.employee_id, s.date, s.start_time, s.end_time, s.break_time, 
-		       s.created_at, s.updated_at, e.name as employee_name
-		FROM shifts s
-		JOIN employees e ON s.employee_id = e.id
-		ORDER BY s.date DESC, s.start_time
-	`)
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "シフト一覧の取得に失敗しました",
-		})
+func (h *Handler) GetShifts(c echo.Context) error {
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
 	}
-	defer rows.Close()
 
-	var shifts []models.Shift
-	for rows.Next() {
-		var shift models.Shift
-		err := rows.Scan(&shift.ID, &shift.EmployeeID, &shift.Date, &shift.StartTime,
-			&shift.EndTime, &shift.BreakTime, &shift.CreatedAt, &shift.UpdatedAt, &shift.EmployeeName)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{
-				"error": "データの読み込みに失敗しました",
-			})
-		}
-		shifts = append(shifts, shift)
+	filter := repository.ShiftFilter{NewestFirst: true}
+
+	// 他の従業員のシフト閲覧権限がない場合は自分のシフトのみ
+	if employeeID, ok := acc.ShiftFilter(); ok {
+		filter.EmployeeID = &employeeID
+	}
+
+	// 従業員には公開済みのシフトだけを見せる
+	shifts, err := h.visibleShifts(acc, filter)
+	if err != nil {
+		return serverError(c, err, "シフト一覧の取得に失敗しました")
 	}
 
 	return c.JSON(http.StatusOK, shifts)
 }
 
 // GetShift シフト詳細を取得
-func GetShift(c echo.Context) error {
+func (h *Handler) GetShift(c echo.Context) error {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
@@ -52,27 +44,31 @@ func GetShift(c echo.Context) error {
 		})
 	}
 
-	var shift models.Shift
-	err = database.DB.QueryRow(`
-		SELECT s.id, s.employee_id, s.date, s.start_time, s.end_time, s.break_time, 
-		       s.created_at, s.updated_at, e.name as employee_name
-		FROM shifts s
-		JOIN employees e ON s.employee_id = e.id
-		WHERE s.id = $1
-	`, id).Scan(&shift.ID, &shift.EmployeeID, &shift.Date, &shift.StartTime,
-		&shift.EndTime, &shift.BreakTime, &shift.CreatedAt, &shift.UpdatedAt, &shift.EmployeeName)
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
+	}
 
-	if err != nil {
+	// 従業員には公開済みの内容を見せる
+	shift, err := h.visibleShift(acc, id)
+	if err == repository.ErrNotFound {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "シフトが見つかりません",
 		})
 	}
+	if err != nil {
+		return serverError(c, err, "シフトの取得に失敗しました")
+	}
+	// 担当者が決まっていないシフトは応募できるよう誰でも閲覧できる
+	if !shift.IsOpen() && !acc.CanViewShiftsOf(shift.EmployeeID) {
+		return forbidden(c)
+	}
 
 	return c.JSON(http.StatusOK, shift)
 }
 
 // CreateShift シフトを作成
-func CreateShift(c echo.Context) error {
+func (h *Handler) CreateShift(c echo.Context) error {
 	var req models.CreateShiftRequest
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
@@ -81,61 +77,39 @@ func CreateShift(c echo.Context) error {
 	}
 
 	// バリデーション
-	if req.EmployeeID == 0 || req.Date == "" || req.StartTime == "" || req.EndTime == "" {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "必須項目が不足しています",
-		})
-	}
-
-	// 従業員の存在確認
-	var employeeExists bool
-	err := database.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM employees WHERE id = $1)", req.EmployeeID).Scan(&employeeExists)
-	if err != nil || !employeeExists {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "指定された従業員が存在しません",
-		})
-	}
-
-	// 重複チェック（同じ従業員の同じ日付のシフトが既に存在するか）
-	var duplicateExists bool
-	err = database.DB.QueryRow(`
-		SELECT EXISTS(
-			SELECT 1 FROM shifts 
-			WHERE employee_id = $1 AND date = $2
-		)
-	`, req.EmployeeID, req.Date).Scan(&duplicateExists)
-
+	shift, err := newShift(req)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "重複チェックに失敗しました",
-		})
+		return respondError(c, err, "シフトの作成に失敗しました")
 	}
 
-	if duplicateExists {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "この従業員は既に同じ日付のシフトが設定されています",
-		})
+	// 従業員の存在確認
+	if req.EmployeeID != 0 {
+		employeeExists, err := h.repos.Employees.Exists(req.EmployeeID)
+		if err != nil || !employeeExists {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "指定された従業員が存在しません",
+			})
+		}
 	}
 
-	var shift models.Shift
-	err = database.DB.QueryRow(`
-		INSERT INTO shifts (employee_id, date, start_time, end_time, break_time) 
-		VALUES ($1, $2, $3, $4, $5) 
-		RETURNING id, employee_id, date, start_time, end_time, break_time, created_at, updated_at
-	`, req.EmployeeID, req.Date, req.StartTime, req.EndTime, req.BreakTime).Scan(
-		&shift.ID, &shift.EmployeeID, &shift.Date, &shift.StartTime, &shift.EndTime, &shift.BreakTime, &shift.CreatedAt, &shift.UpdatedAt)
-
+	// 担当ポジションと、同じ従業員の勤務時間が重なるシフトがないかを確認して作成する
+	err = h.repos.InTx(func(r *repository.Repositories) error {
+		created, err := h.createShift(r, shift)
+		if err != nil {
+			return err
+		}
+		shift = created
+		return nil
+	})
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "シフトの作成に失敗しました",
-		})
+		return respondError(c, err, "シフトの作成に失敗しました")
 	}
 
 	return c.JSON(http.StatusCreated, shift)
 }
 
 // UpdateShift シフトを更新
-func UpdateShift(c echo.Context) error {
+func (h *Handler) UpdateShift(c echo.Context) error {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
@@ -152,8 +126,7 @@ func UpdateShift(c echo.Context) error {
 
 	// 従業員の存在確認（employee_idが指定されている場合）
 	if req.EmployeeID != 0 {
-		var employeeExists bool
-		err := database.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM employees WHERE id = $1)", req.EmployeeID).Scan(&employeeExists)
+		employeeExists, err := h.repos.Employees.Exists(req.EmployeeID)
 		if err != nil || !employeeExists {
 			return c.JSON(http.StatusBadRequest, map[string]string{
 				"error": "指定された従業員が存在しません",
@@ -161,37 +134,361 @@ func UpdateShift(c echo.Context) error {
 		}
 	}
 
-	result, err := database.DB.Exec(`
-		UPDATE shifts 
-		SET employee_id = COALESCE($1, employee_id),
-		    date = COALESCE($2, date),
-		    start_time = COALESCE($3, start_time),
-		    end_time = COALESCE($4, end_time),
-		    break_time = COALESCE($5, break_time),
-		    updated_at = CURRENT_TIMESTAMP
-		WHERE id = $6
-	`, req.EmployeeID, req.Date, req.StartTime, req.EndTime, req.BreakTime, id)
-
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "シフトの更新に失敗しました",
-		})
-	}
-
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
+	var updated models.Shift
+	err = h.repos.InTx(func(r *repository.Repositories) error {
+		var err error
+		updated, err = h.updateShift(r, id, req)
+		return err
+	})
+	if err == repository.ErrNotFound {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "シフトが見つかりません",
 		})
 	}
+	if err != nil {
+		return respondError(c, err, "シフトの更新に失敗しました")
+	}
 
-	return c.JSON(http.StatusOK, map[string]string{
+	res := map[string]interface{}{
 		"message": "シフトが更新されました",
+	}
+	if len(updated.Warnings) > 0 {
+		res["warnings"] = updated.Warnings
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// newShift 作成リクエストからシフトを組み立て、日付・時刻と担当ポジションの区間を検証する
+func newShift(req models.CreateShiftRequest) (models.Shift, error) {
+	// employee_id を省略した場合は担当者を募集するシフトになる
+	if req.Date == "" || req.StartTime == "" || req.EndTime == "" {
+		return models.Shift{}, &httpError{http.StatusBadRequest, "必須項目が不足しています"}
+	}
+
+	shift := models.Shift{
+		EmployeeID:  req.EmployeeID,
+		Date:        req.Date,
+		StartTime:   req.StartTime,
+		EndTime:     req.EndTime,
+		EndsNextDay: req.EndsNextDay,
+		BreakTime:   req.BreakTime,
+		Position:    req.Position,
+		Segments:    req.Segments,
+	}
+	trimShiftPositions(&shift)
+	if err := validateShiftSpans(shift); err != nil {
+		return models.Shift{}, &httpError{http.StatusBadRequest, spanErrorMessage(err)}
+	}
+	return shift, nil
+}
+
+// createShift 担当ポジションと、同じ従業員の勤務時間が重なるシフトがないかを確認してシフトを作成する
+// 作成前に労働基準法に基づくルールと担当者の勤務可能な時間帯で確認し、警告を作成したシフトに含めて返す
+func (h *Handler) createShift(r *repository.Repositories, shift models.Shift) (models.Shift, error) {
+	if !shift.IsOpen() {
+		if err := checkShiftAssignment(r, &shift); err != nil {
+			return models.Shift{}, err
+		}
+	}
+	warnings, err := h.checkShiftRules(r, shift)
+	if err != nil {
+		return models.Shift{}, err
+	}
+	created, err := h.insertShift(r, shift)
+	if err != nil {
+		return models.Shift{}, err
+	}
+	setWarningShiftID(warnings, created.ID)
+	created.Warnings = warnings
+	return created, nil
+}
+
+// updateShift 更新リクエストで指定された項目を検証してシフトに反映し、更新後のシフトを返す
+// 保存前に労働基準法に基づくルールと担当者の勤務可能な時間帯で確認し、警告を更新後のシフトに含めて返す
+func (h *Handler) updateShift(r *repository.Repositories, id int, req models.UpdateShiftRequest) (models.Shift, error) {
+	shift, err := r.Shifts.Get(id)
+	if err != nil {
+		return models.Shift{}, err
+	}
+
+	previous := shift
+	applyShiftUpdate(&shift, req)
+	trimShiftPositions(&shift)
+	if err := validateShiftSpans(shift); err != nil {
+		return models.Shift{}, &httpError{http.StatusBadRequest, spanErrorMessage(err)}
+	}
+
+	// 担当者を募集中のシフトは、同時に応募で担当者が決まっていないか確認してから変更する
+	if previous.IsOpen() {
+		if err := r.Shifts.Lock(id); err != nil {
+			return models.Shift{}, err
+		}
+		current, err := r.Shifts.Get(id)
+		if err != nil {
+			return models.Shift{}, err
+		}
+		if !current.IsOpen() {
+			return models.Shift{}, &httpError{http.StatusConflict, "このシフトは応募により担当者が決まりました。内容を確認してください"}
+		}
+	}
+	if !shift.IsOpen() {
+		if err := checkShiftAssignment(r, &shift); err != nil {
+			return models.Shift{}, err
+		}
+	}
+	warnings, err := h.checkShiftRules(r, shift)
+	if err != nil {
+		return models.Shift{}, err
+	}
+	if err := h.saveShift(r, previous, shift, models.ShiftHolderChanged, nil); err != nil {
+		return models.Shift{}, err
+	}
+	updated, err := r.Shifts.Get(id)
+	if err != nil {
+		return models.Shift{}, err
+	}
+	updated.Warnings = warnings
+	return updated, nil
+}
+
+// checkShiftRules 保存前のシフトを労働基準法に基づくルールと担当者の勤務可能な時間帯で確認し、警告を返す
+// エラーとするルールに違反した場合は httpError を返すため、シフトを保存する前に呼び出す
+// exclude に指定したシフトは担当者のシフトに含めずに確認する
+func (h *Handler) checkShiftRules(r *repository.Repositories, shift models.Shift, exclude ...int) ([]models.LaborViolation, error) {
+	warnings, err := h.checkLaborRules(r, shift, exclude...)
+	if err != nil {
+		return nil, err
+	}
+	availability, err := h.checkAvailability(r, shift)
+	if err != nil {
+		return nil, err
+	}
+	return append(warnings, availability...), nil
+}
+
+// ruleConflict ルールへの違反を、既存のシフトとの組み合わせで保存できない409の httpError にする
+func ruleConflict(err error) error {
+	if he, ok := err.(*httpError); ok {
+		return &httpError{http.StatusConflict, he.message}
+	}
+	return err
+}
+
+// setWarningShiftID 作成前に確認した警告の対象のシフト（ID が0）を、作成したシフトの ID にする
+func setWarningShiftID(warnings []models.LaborViolation, id int) {
+	for i := range warnings {
+		for j, shiftID := range warnings[i].ShiftIDs {
+			if shiftID == 0 {
+				warnings[i].ShiftIDs[j] = id
+			}
+		}
+	}
+}
+
+// validateShiftSpans シフトの勤務時間、担当ポジションの区間と休憩時間を検証する
+func validateShiftSpans(shift models.Shift) error {
+	span, err := shift.Span()
+	if err != nil {
+		return err
+	}
+	if _, err := shift.PositionSpans(); err != nil {
+		return err
+	}
+	return models.CheckBreakTime(shift.BreakTime, span)
+}
+
+// applyShiftUpdate 更新リクエストで指定された項目をシフトに反映する
+func applyShiftUpdate(shift *models.Shift, req models.UpdateShiftRequest) {
+	if req.EmployeeID != 0 {
+		shift.EmployeeID = req.EmployeeID
+	}
+	if req.Date != "" {
+		shift.Date = req.Date
+	}
+	if req.StartTime != "" {
+		shift.StartTime = req.StartTime
+	}
+	if req.EndTime != "" {
+		shift.EndTime = req.EndTime
+	}
+	if req.EndsNextDay != nil {
+		shift.EndsNextDay = *req.EndsNextDay
+	}
+	if req.BreakTime != nil {
+		shift.BreakTime = *req.BreakTime
+	}
+	if req.Position != nil {
+		shift.Position = *req.Position
+	}
+	if req.Segments != nil {
+		shift.Segments = *req.Segments
+	}
+}
+
+// trimShiftPositions ポジション名の前後の空白を取り除く
+func trimShiftPositions(shift *models.Shift) {
+	shift.Position = strings.TrimSpace(shift.Position)
+	segments := make([]models.ShiftSegment, len(shift.Segments))
+	for i, seg := range shift.Segments {
+		seg.Position = strings.TrimSpace(seg.Position)
+		segments[i] = seg
+	}
+	shift.Segments = segments
+}
+
+// checkShiftAssignment 従業員をロックしたうえで、シフトを割り当てられるか確認する
+//   - 担当ポジションが未設定で、従業員が担当できるポジションが1つだけの場合はそのポジションを設定する
+//   - 従業員が担当できないポジションが含まれる場合は400の httpError を返す
+//   - 同じ従業員の勤務時間が重なるシフトがある場合は409の httpError を返す
+func checkShiftAssignment(r *repository.Repositories, shift *models.Shift) error {
+	if err := r.Employees.Lock(shift.EmployeeID); err != nil {
+		if err == repository.ErrNotFound {
+			return &httpError{http.StatusBadRequest, "指定された従業員が存在しません"}
+		}
+		return err
+	}
+	employee, err := r.Employees.Get(shift.EmployeeID)
+	if err != nil {
+		return err
+	}
+
+	shift.EmployeeName = employee.Name
+	if shift.Position == "" && len(shift.Segments) == 0 && len(employee.Positions) == 1 {
+		shift.Position = employee.Positions[0]
+	}
+	for _, position := range shift.Positions() {
+		if !employee.IsQualifiedFor(position) {
+			return &httpError{http.StatusBadRequest, employee.Name + "さんは「" + position + "」を担当できません"}
+		}
+	}
+
+	overlapping, found, err := findOverlappingShift(r.Shifts, *shift)
+	if err != nil {
+		return err
+	}
+	if found {
+		span, _ := overlapping.Span()
+		return &httpError{http.StatusConflict, "この従業員には勤務時間が重なるシフトが既に設定されています（" + span.String() + "）"}
+	}
+	return nil
+}
+
+// insertShift 検証済みのシフトを作成する
+// シフトの日付を含む公開済みのシフト期間を作成中に戻し、担当者の履歴を記録して出退勤記録を照合し直す
+func (h *Handler) insertShift(r *repository.Repositories, shift models.Shift) (models.Shift, error) {
+	if err := touchSchedulePeriods(r, shift.Date); err != nil {
+		return models.Shift{}, err
+	}
+	created, err := r.Shifts.Create(shift)
+	if err != nil {
+		return models.Shift{}, err
+	}
+	if created.IsOpen() {
+		return created, nil
+	}
+	err = r.Shifts.AddHolder(models.ShiftHolder{
+		ShiftID:    created.ID,
+		EmployeeID: created.EmployeeID,
+		Reason:     models.ShiftHolderAssigned,
+	})
+	if err != nil {
+		return models.Shift{}, err
+	}
+	return created, h.syncShiftAttendance(r, created)
+}
+
+// saveShift 検証済みのシフトの変更を保存する
+// 繰り返しシフトから作成したシフトは繰り返しから外し、以降の繰り返しの変更の対象にしない
+func (h *Handler) saveShift(r *repository.Repositories, previous, shift models.Shift, reason string, tradeID *int) error {
+	if previous.PatternID != nil {
+		if err := addPatternException(r, *previous.PatternID, previous.Date); err != nil {
+			return err
+		}
+		shift.PatternID = nil
+	}
+	return h.storeShift(r, previous, shift, reason, tradeID)
+}
+
+// storeShift 検証済みのシフトの変更を保存する
+// 変更前後の日付を含む公開済みのシフト期間を作成中に戻し、変更前後の担当者の出退勤記録を照合し直す
+// 担当者が変わった場合は reason で履歴を記録し、担当者を募集中のシフトの担当者が決まった場合は承認待ちの応募を締め切る
+func (h *Handler) storeShift(r *repository.Repositories, previous, shift models.Shift, reason string, tradeID *int) error {
+	if err := touchSchedulePeriods(r, previous.Date, shift.Date); err != nil {
+		return err
+	}
+	if err := r.Shifts.Update(shift); err != nil {
+		return err
+	}
+	if err := h.syncShiftAttendance(r, previous, shift); err != nil {
+		return err
+	}
+	if previous.EmployeeID == shift.EmployeeID || shift.IsOpen() {
+		return nil
+	}
+	if previous.IsOpen() {
+		if err := closePendingClaims(r, shift); err != nil {
+			return err
+		}
+		if reason == models.ShiftHolderChanged {
+			reason = models.ShiftHolderAssigned
+		}
+	}
+	return r.Shifts.AddHolder(models.ShiftHolder{
+		ShiftID:    shift.ID,
+		EmployeeID: shift.EmployeeID,
+		Reason:     reason,
+		TradeID:    tradeID,
+	})
+}
+
+// findOverlappingShift 同じ従業員のシフトのうち勤務時間が重なるものを探す（shift 自身は除く）
+// 日付をまたぐシフトを考慮して前後1日のシフトも確認する
+func findOverlappingShift(shifts repository.ShiftRepository, shift models.Shift) (models.Shift, bool, error) {
+	span, err := shift.Span()
+	if err != nil {
+		return models.Shift{}, false, err
+	}
+	day, err := models.ParseDate(shift.Date)
+	if err != nil {
+		return models.Shift{}, false, err
+	}
+
+	candidates, err := shifts.List(repository.ShiftFilter{
+		EmployeeID: &shift.EmployeeID,
+		StartDate:  day.AddDate(0, 0, -1).Format(models.DateLayout),
+		EndDate:    day.AddDate(0, 0, 1).Format(models.DateLayout),
 	})
+	if err != nil {
+		return models.Shift{}, false, err
+	}
+
+	for _, candidate := range candidates {
+		if candidate.ID == shift.ID {
+			continue
+		}
+		candidateSpan, err := candidate.Span()
+		if err != nil {
+			continue
+		}
+		if span.Overlaps(candidateSpan) {
+			return candidate, true, nil
+		}
+	}
+	return models.Shift{}, false, nil
+}
+
+// spanErrorMessage 勤務区間の検証エラーをレスポンス用のメッセージに変換
+func spanErrorMessage(err error) string {
+	switch err {
+	case models.ErrInvertedRange, models.ErrNotOvernight, models.ErrBreakTime,
+		models.ErrSegmentOutOfShift, models.ErrSegmentOverlap, models.ErrSegmentPosition, models.ErrWindowOverlap:
+		return err.Error()
+	}
+	return "日付または時刻の形式が正しくありません"
 }
 
 // DeleteShift シフトを削除
-func DeleteShift(c echo.Context) error {
+func (h *Handler) DeleteShift(c echo.Context) error {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
@@ -199,27 +496,47 @@ func DeleteShift(c echo.Context) error {
 		})
 	}
 
-	result, err := database.DB.Exec("DELETE FROM shifts WHERE id = $1", id)
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "シフトの削除に失敗しました",
-		})
-	}
-
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
+	err = h.repos.InTx(func(r *repository.Repositories) error {
+		_, err := h.deleteShift(r, id)
+		return err
+	})
+	if err == repository.ErrNotFound {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "シフトが見つかりません",
 		})
 	}
+	if err != nil {
+		return serverError(c, err, "シフトの削除に失敗しました")
+	}
 
 	return c.JSON(http.StatusOK, map[string]string{
 		"message": "シフトが削除されました",
 	})
 }
 
+// deleteShift シフトを削除して担当者の出退勤記録を照合し直し、削除したシフトを返す
+func (h *Handler) deleteShift(r *repository.Repositories, id int) (models.Shift, error) {
+	shift, err := r.Shifts.Get(id)
+	if err != nil {
+		return models.Shift{}, err
+	}
+	if err := touchSchedulePeriods(r, shift.Date); err != nil {
+		return models.Shift{}, err
+	}
+	// 繰り返しシフトから作成したシフトは、次に繰り返しからシフトを作成するときに作り直さない
+	if shift.PatternID != nil {
+		if err := addPatternException(r, *shift.PatternID, shift.Date); err != nil {
+			return models.Shift{}, err
+		}
+	}
+	if err := r.Shifts.Delete(id); err != nil {
+		return models.Shift{}, err
+	}
+	return shift, h.syncShiftAttendance(r, shift)
+}
+
 // GetShiftsByMonth 月別シフトを取得
-func GetShiftsByMonth(c echo.Context) error {
+func (h *Handler) GetShiftsByMonth(c echo.Context) error {
 	year := c.QueryParam("year")
 	month := c.QueryParam("month")
 
@@ -229,62 +546,48 @@ func GetShiftsByMonth(c echo.Context) error {
 		})
 	}
 
-	// デバッグ用ログ
-	fmt.Printf("月別シフト取得: year=%s, month=%s\n", year, month)
+	startDate, endDate, err := monthRange(year, month)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "年と月は数値で指定してください",
+		})
+	}
 
-	// 月の開始日と終了日を計算
-	startDate := year + "-" + month + "-01"
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
+	}
 
-	// 月の終了日を正確に計算
-	var endDate string
-	switch month {
-	case "02":
-		// 2月の処理（うるう年を考慮）
-		yearInt, _ := strconv.Atoi(year)
-		if (yearInt%4 == 0 && yearInt%100 != 0) || (yearInt%400 == 0) {
-			endDate = year + "-02-29" // うるう年
-		} else {
-			endDate = year + "-02-28" // 平年
-		}
-	case "04", "06", "09", "11":
-		// 30日の月
-		endDate = year + "-" + month + "-30"
-	default:
-		// 31日の月
-		endDate = year + "-" + month + "-31"
-	}
-
-	// デバッグ用ログ
-	fmt.Printf("検索期間: %s から %s\n", startDate, endDate)
-
-	rows, err := database.DB.Query(`
-		SELECT s.id, s.employee_id, s.date, s.start_time, s.end_time, s.break_time, 
-		       s.created_at, s.updated_at, e.name as employee_name
-		FROM shifts s
-		JOIN employees e ON s.employee_id = e.id
-		WHERE s.date >= $1 AND s.date <= $2
-		ORDER BY s.date ASC, s.start_time ASC
-	`, startDate, endDate)
+	filter := repository.ShiftFilter{
+		StartDate: startDate.Format(models.DateLayout),
+		EndDate:   endDate.Format(models.DateLayout),
+	}
 
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "月別シフトの取得に失敗しました",
-		})
+	// 他の従業員のシフト閲覧権限がない場合は自分のシフトのみ
+	if employeeID, ok := acc.ShiftFilter(); ok {
+		filter.EmployeeID = &employeeID
 	}
-	defer rows.Close()
 
-	var shifts []models.Shift
-	for rows.Next() {
-		var shift models.Shift
-		err := rows.Scan(&shift.ID, &shift.EmployeeID, &shift.Date, &shift.StartTime,
-			&shift.EndTime, &shift.BreakTime, &shift.CreatedAt, &shift.UpdatedAt, &shift.EmployeeName)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{
-				"error": "データの読み込みに失敗しました",
-			})
-		}
-		shifts = append(shifts, shift)
+	// 従業員には公開済みのシフトだけを見せる
+	shifts, err := h.visibleShifts(acc, filter)
+	if err != nil {
+		return serverError(c, err, "月別シフトの取得に失敗しました")
 	}
 
 	return c.JSON(http.StatusOK, shifts)
 }
+
+// monthRange 年・月の文字列から月初日と月末日を計算
+func monthRange(year, month string) (time.Time, time.Time, error) {
+	yearInt, err := strconv.Atoi(year)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	monthInt, err := strconv.Atoi(month)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	startDate := time.Date(yearInt, time.Month(monthInt), 1, 0, 0, 0, 0, time.UTC)
+	return startDate, startDate.AddDate(0, 1, -1), nil
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"shift-management-backend/models"
+	"shift-management-backend/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxBulkShiftOperations 一括操作で一度に指定できる最大件数
+const maxBulkShiftOperations = 1000
+
+// errBulkFailed all_or_nothing で失敗した操作がある場合に変更を取り消すためのエラー
+var errBulkFailed = errors.New("bulk operation failed")
+
+// BulkShifts シフトを一括で作成・更新・削除する
+// すべての操作を1つのトランザクションで順に適用し、操作ごとの結果を返す
+//   - all_or_nothing: 1件でも失敗した場合はすべて取り消し、422を返す（残りの操作も検証して結果を返す）
+//   - best_effort: 失敗した操作だけを除いて反映する
+func (h *Handler) BulkShifts(c echo.Context) error {
+	var req models.BulkShiftRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "リクエストの解析に失敗しました",
+		})
+	}
+
+	if req.Mode == "" {
+		req.Mode = models.BulkAllOrNothing
+	}
+	if req.Mode != models.BulkAllOrNothing && req.Mode != models.BulkBestEffort {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "mode は all_or_nothing または best_effort を指定してください",
+		})
+	}
+	if len(req.Operations) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "操作を1件以上指定してください",
+		})
+	}
+	if len(req.Operations) > maxBulkShiftOperations {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "一度に指定できる操作は" + strconv.Itoa(maxBulkShiftOperations) + "件までです",
+		})
+	}
+
+	var res models.BulkShiftResponse
+	err := h.repos.InTx(func(r *repository.Repositories) error {
+		res = models.BulkShiftResponse{
+			Mode:    req.Mode,
+			Results: make([]models.BulkShiftResult, 0, len(req.Operations)),
+		}
+		for i, op := range req.Operations {
+			result, err := h.applyBulkShiftOperation(r, op)
+			if err != nil {
+				return err
+			}
+			result.Index = i
+			if result.Error == "" {
+				res.Succeeded++
+			} else {
+				res.Failed++
+			}
+			res.Results = append(res.Results, result)
+		}
+		if res.Failed > 0 && req.Mode == models.BulkAllOrNothing {
+			return errBulkFailed
+		}
+		return nil
+	})
+	if err == errBulkFailed {
+		// 取り消したシフトは返さない
+		for i := range res.Results {
+			res.Results[i].Shift = nil
+		}
+		return c.JSON(http.StatusUnprocessableEntity, res)
+	}
+	if err != nil {
+		return serverError(c, err, "シフトの一括操作に失敗しました")
+	}
+
+	res.Applied = true
+	return c.JSON(http.StatusOK, res)
+}
+
+// applyBulkShiftOperation 一括操作の1件を適用する
+// 検証で失敗した操作は変更を行わずに結果のエラーとして返し、それ以外のエラーの場合は一括操作全体を中断する
+func (h *Handler) applyBulkShiftOperation(r *repository.Repositories, op models.BulkShiftOperation) (models.BulkShiftResult, error) {
+	result := models.BulkShiftResult{Action: op.Action}
+
+	var shift models.Shift
+	var err error
+	switch op.Action {
+	case models.BulkActionCreate:
+		if op.Shift == nil {
+			err = &httpError{http.StatusBadRequest, "作成するシフトを指定してください"}
+			break
+		}
+		if shift, err = newShift(*op.Shift); err != nil {
+			break
+		}
+		shift, err = h.createShift(r, shift)
+		result.Status = http.StatusCreated
+	case models.BulkActionUpdate:
+		if op.ID == 0 || op.Changes == nil {
+			err = &httpError{http.StatusBadRequest, "更新するシフトのIDと変更する項目を指定してください"}
+			break
+		}
+		shift, err = h.updateShift(r, op.ID, *op.Changes)
+		result.Status = http.StatusOK
+	case models.BulkActionDelete:
+		if op.ID == 0 {
+			err = &httpError{http.StatusBadRequest, "削除するシフトのIDを指定してください"}
+			break
+		}
+		shift, err = h.deleteShift(r, op.ID)
+		result.Status = http.StatusOK
+	default:
+		err = &httpError{http.StatusBadRequest, "action は create、update、delete のいずれかを指定してください"}
+	}
+
+	var he *httpError
+	switch {
+	case err == nil:
+		result.Shift = &shift
+		return result, nil
+	case err == repository.ErrNotFound:
+		result.Status = http.StatusNotFound
+		result.Error = "シフトが見つかりません"
+		return result, nil
+	case errors.As(err, &he):
+		result.Status = he.status
+		result.Error = he.message
+		return result, nil
+	}
+	return result, err
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"shift-management-backend/models"
+)
+
+// bulkCreate シフトを作成する一括操作
+func bulkCreate(employeeID int, date, start, end string, breakTime int) models.BulkShiftOperation {
+	return models.BulkShiftOperation{
+		Action: models.BulkActionCreate,
+		Shift: &models.CreateShiftRequest{
+			EmployeeID: employeeID,
+			Date:       date,
+			StartTime:  start,
+			EndTime:    end,
+			BreakTime:  breakTime,
+		},
+	}
+}
+
+func TestBulkShiftsBestEffort(t *testing.T) {
+	h := newTestHandler(t)
+	employee := createTestEmployee(t, h, "山田")
+
+	// 2件目は休憩が足りない（6時間超で45分未満）
+	code, body := serve(t, h.BulkShifts, http.MethodPost, "/api/shifts/bulk", models.BulkShiftRequest{
+		Mode: models.BulkBestEffort,
+		Operations: []models.BulkShiftOperation{
+			bulkCreate(employee.ID, "2030-10-07", "09:00", "15:00", 0),
+			bulkCreate(employee.ID, "2030-10-08", "09:00", "17:00", 0),
+		},
+	}, testOwner)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", code, http.StatusOK, body)
+	}
+
+	var res models.BulkShiftResponse
+	decodeBody(t, body, &res)
+	if !res.Applied || res.Succeeded != 1 || res.Failed != 1 {
+		t.Fatalf("1件だけ反映されるはずです: %+v", res)
+	}
+	if r := res.Results[1]; r.Status != http.StatusUnprocessableEntity || r.Error == "" || r.Shift != nil {
+		t.Errorf("休憩が足りない操作は422で失敗するはずです: %+v", r)
+	}
+
+	// 失敗した操作のシフトは保存しない
+	shifts := listTestShifts(t, h)
+	if len(shifts) != 1 || shifts[0].ID != res.Results[0].Shift.ID {
+		t.Errorf("成功した操作のシフトだけが保存されるはずです: %+v", shifts)
+	}
+}
+
+func TestBulkShiftsAllOrNothingRollsBack(t *testing.T) {
+	h := newTestHandler(t)
+	employee := createTestEmployee(t, h, "山田")
+
+	existing := createTestShift(t, h, *bulkCreate(employee.ID, "2030-10-06", "10:00", "14:00", 0).Shift)
+
+	// 作成・削除は成功するが、最後の操作が休憩不足で失敗する
+	code, body := serve(t, h.BulkShifts, http.MethodPost, "/api/shifts/bulk", models.BulkShiftRequest{
+		Operations: []models.BulkShiftOperation{
+			bulkCreate(employee.ID, "2030-10-07", "09:00", "15:00", 0),
+			{Action: models.BulkActionDelete, ID: existing.ID},
+			bulkCreate(employee.ID, "2030-10-08", "09:00", "17:00", 0),
+		},
+	}, testOwner)
+	if code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want %d: %s", code, http.StatusUnprocessableEntity, body)
+	}
+
+	var res models.BulkShiftResponse
+	decodeBody(t, body, &res)
+	if res.Mode != models.BulkAllOrNothing || res.Applied || res.Succeeded != 2 || res.Failed != 1 {
+		t.Fatalf("変更は反映されないはずです: %+v", res)
+	}
+	for _, r := range res.Results {
+		if r.Shift != nil {
+			t.Errorf("取り消したシフトは返さないはずです: %+v", r)
+		}
+	}
+
+	// 作成したシフトは取り消し、削除したシフトは元に戻る
+	shifts := listTestShifts(t, h)
+	if len(shifts) != 1 || shifts[0].ID != existing.ID {
+		t.Errorf("一括操作の前の状態に戻るはずです: %+v", shifts)
+	}
+}
+
+func TestBulkShiftsSeesEarlierOperations(t *testing.T) {
+	h := newTestHandler(t)
+	employee := createTestEmployee(t, h, "山田")
+
+	// 同じリクエストで先に作成したシフトとの重なりも確認する
+	code, body := serve(t, h.BulkShifts, http.MethodPost, "/api/shifts/bulk", models.BulkShiftRequest{
+		Mode: models.BulkBestEffort,
+		Operations: []models.BulkShiftOperation{
+			bulkCreate(employee.ID, "2030-10-07", "09:00", "13:00", 0),
+			bulkCreate(employee.ID, "2030-10-07", "12:00", "16:00", 0),
+		},
+	}, testOwner)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", code, http.StatusOK, body)
+	}
+
+	var res models.BulkShiftResponse
+	decodeBody(t, body, &res)
+	if res.Succeeded != 1 || res.Failed != 1 || res.Results[1].Status != http.StatusConflict {
+		t.Errorf("先に作成したシフトと重なる操作は409で失敗するはずです: %+v", res)
+	}
+	if shifts := listTestShifts(t, h); len(shifts) != 1 {
+		t.Errorf("重なるシフトは保存しないはずです: %+v", shifts)
+	}
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"shift-management-backend/models"
+	"shift-management-backend/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// errDryRun dry_run の場合に作成したシフトをロールバックするためのエラー
+var errDryRun = errors.New("dry run")
+
+// CopyShifts 期間のシフトを別の期間にコピーする
+// コピー元と同じ日数の期間に、日付をずらして同じ従業員・時刻のシフトを作成する
+// 従業員が削除されている、勤務時間が重なるシフトがある、ポジションを担当できない、
+// エラーとするルールに違反するシフトは作成せずに競合として返す
+func (h *Handler) CopyShifts(c echo.Context) error {
+	var req models.CopyShiftsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "リクエストの解析に失敗しました",
+		})
+	}
+
+	if req.SourceStart == "" || req.SourceEnd == "" || req.TargetStart == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "コピー元の期間とコピー先の開始日を指定してください",
+		})
+	}
+	sourceStart, _, message := parseDateRange(req.SourceStart, req.SourceEnd, maxRangeDays)
+	if message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+	targetStart, errTarget := time.Parse(models.DateLayout, req.TargetStart)
+	if errTarget != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効な日付です",
+		})
+	}
+	if targetStart.Equal(sourceStart) {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "コピー先の開始日はコピー元の開始日と異なる日にしてください",
+		})
+	}
+	offset := int(targetStart.Sub(sourceStart).Hours() / 24)
+
+	var res models.CopyShiftsResponse
+	err := h.repos.InTx(func(r *repository.Repositories) error {
+		sources, err := r.Shifts.List(repository.ShiftFilter{
+			StartDate: req.SourceStart,
+			EndDate:   req.SourceEnd,
+		})
+		if err != nil {
+			return err
+		}
+
+		shifts := make([]models.Shift, 0, len(sources))
+		for _, source := range sources {
+			day, err := models.ParseDate(source.Date)
+			if err != nil {
+				return err
+			}
+			shifts = append(shifts, copiedShift(source, day.AddDate(0, 0, offset)))
+		}
+
+		if res, err = h.copyShifts(r, shifts); err != nil {
+			return err
+		}
+		if req.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && err != errDryRun {
+		return respondError(c, err, "シフトのコピーに失敗しました")
+	}
+
+	res.DryRun = req.DryRun
+	return c.JSON(http.StatusOK, res)
+}
+
+// copiedShift シフトの担当者・時刻・ポジションを別の日のシフトとして複製する（繰り返しシフトからは外す）
+func copiedShift(source models.Shift, day time.Time) models.Shift {
+	return models.Shift{
+		EmployeeID:   source.EmployeeID,
+		Date:         day.Format(models.DateLayout),
+		StartTime:    source.StartTime,
+		EndTime:      source.EndTime,
+		EndsNextDay:  source.EndsNextDay,
+		BreakTime:    source.BreakTime,
+		Position:     source.Position,
+		Segments:     source.Segments,
+		EmployeeName: source.EmployeeName,
+	}
+}
+
+// copyShifts 複製したシフトを順に作成し、作成できないシフトを競合として返す
+// 先に作成したシフトとの重なりも競合として扱う
+func (h *Handler) copyShifts(r *repository.Repositories, shifts []models.Shift) (models.CopyShiftsResponse, error) {
+	res := models.CopyShiftsResponse{
+		Created:   []models.Shift{},
+		Conflicts: []models.ShiftConflict{},
+	}
+	for _, shift := range shifts {
+		conflict, warnings, err := h.checkCopiedShift(r, &shift)
+		if err != nil {
+			return res, err
+		}
+		if conflict != nil {
+			res.Conflicts = append(res.Conflicts, *conflict)
+			continue
+		}
+		created, err := h.insertShift(r, shift)
+		if err != nil {
+			return res, err
+		}
+		setWarningShiftID(warnings, created.ID)
+		created.Warnings = warnings
+		res.Created = append(res.Created, created)
+	}
+	return res, nil
+}
+
+// checkCopiedShift 複製したシフトを作成できるか確認し、作成できない場合は競合を、作成できる場合は警告を返す
+func (h *Handler) checkCopiedShift(r *repository.Repositories, shift *models.Shift) (*models.ShiftConflict, []models.LaborViolation, error) {
+	if shift.IsOpen() {
+		return nil, nil, nil
+	}
+
+	exists, err := r.Employees.Exists(shift.EmployeeID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !exists {
+		return &models.ShiftConflict{
+			Shift:   *shift,
+			Reason:  models.ShiftConflictEmployeeDeleted,
+			Message: shift.EmployeeName + "さんは削除されています",
+		}, nil, nil
+	}
+
+	overlapping, found, err := findOverlappingShift(r.Shifts, *shift)
+	if err != nil {
+		return nil, nil, err
+	}
+	if found {
+		span, _ := overlapping.Span()
+		return &models.ShiftConflict{
+			Shift:    *shift,
+			Reason:   models.ShiftConflictOverlap,
+			Message:  overlapping.EmployeeName + "さんには勤務時間が重なるシフトが既に設定されています（" + span.String() + "）",
+			Existing: &overlapping,
+		}, nil, nil
+	}
+
+	if err := checkShiftAssignment(r, shift); err != nil {
+		he, ok := err.(*httpError)
+		if !ok {
+			return nil, nil, err
+		}
+		reason := models.ShiftConflictNotQualified
+		if he.status == http.StatusConflict {
+			reason = models.ShiftConflictOverlap
+		}
+		return &models.ShiftConflict{
+			Shift:   *shift,
+			Reason:  reason,
+			Message: he.message,
+		}, nil, nil
+	}
+
+	warnings, err := h.checkShiftRules(r, *shift)
+	if err != nil {
+		he, ok := err.(*httpError)
+		if !ok {
+			return nil, nil, err
+		}
+		return &models.ShiftConflict{
+			Shift:   *shift,
+			Reason:  models.ShiftConflictRuleViolation,
+			Message: he.message,
+		}, nil, nil
+	}
+	return nil, warnings, nil
+}
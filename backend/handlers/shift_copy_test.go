@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"shift-management-backend/models"
+)
+
+func TestCopyShiftsChecksRestInterval(t *testing.T) {
+	h := newTestHandler(t)
+	employee := createTestEmployee(t, h, "山田")
+	createTestShift(t, h, models.CreateShiftRequest{EmployeeID: employee.ID, Date: "2030-10-08", StartTime: "09:00", EndTime: "13:00"})
+	// コピー先の前日の勤務の終了から10時間しか空かない（必要な休息時間は11時間）
+	createTestShift(t, h, models.CreateShiftRequest{EmployeeID: employee.ID, Date: "2030-10-14", StartTime: "17:00", EndTime: "23:00"})
+
+	code, body := serve(t, h.CopyShifts, http.MethodPost, "/api/shifts/copy", models.CopyShiftsRequest{
+		SourceStart: "2030-10-08",
+		SourceEnd:   "2030-10-08",
+		TargetStart: "2030-10-15",
+	}, testOwner)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d: %s", code, body)
+	}
+	var res models.CopyShiftsResponse
+	decodeBody(t, body, &res)
+	if len(res.Created) != 0 || len(res.Conflicts) != 1 || res.Conflicts[0].Reason != models.ShiftConflictRuleViolation {
+		t.Fatalf("休息時間が足りないシフトは競合として返すはずです: %s", body)
+	}
+	if got := len(listTestShifts(t, h)); got != 2 {
+		t.Errorf("シフトの件数 = %d, want 2", got)
+	}
+}
+
+func TestApplyScheduleTemplateChecksRestInterval(t *testing.T) {
+	h := newTestHandler(t)
+	employee := createTestEmployee(t, h, "山田")
+	template, err := h.repos.Templates.Create(models.ScheduleTemplate{
+		Name: "平日",
+		Shifts: []models.TemplateShift{
+			{Weekday: 2, EmployeeID: employee.ID, EmployeeName: employee.Name, StartTime: "09:00", EndTime: "13:00"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 適用する週の月曜の勤務の終了から、火曜のシフトまで10時間しか空かない
+	createTestShift(t, h, models.CreateShiftRequest{EmployeeID: employee.ID, Date: "2030-10-14", StartTime: "17:00", EndTime: "23:00"})
+
+	id := strconv.Itoa(template.ID)
+	code, body := serve(t, h.ApplyScheduleTemplate, http.MethodPost, "/api/schedule-templates/"+id+"/apply",
+		models.ApplyScheduleTemplateRequest{WeekStart: "2030-10-13"}, testOwner, "id", id)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d: %s", code, body)
+	}
+	var res models.CopyShiftsResponse
+	decodeBody(t, body, &res)
+	if len(res.Created) != 0 || len(res.Conflicts) != 1 || res.Conflicts[0].Reason != models.ShiftConflictRuleViolation {
+		t.Fatalf("休息時間が足りないシフトは競合として返すはずです: %s", body)
+	}
+	if got := len(listTestShifts(t, h)); got != 1 {
+		t.Errorf("シフトの件数 = %d, want 1", got)
+	}
+}
@@ -0,0 +1,645 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"shift-management-backend/models"
+	"shift-management-backend/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetShiftPatterns 繰り返しシフトの一覧を取得（employee_id で絞り込み可）
+func (h *Handler) GetShiftPatterns(c echo.Context) error {
+	var employeeID *int
+	if v := c.QueryParam("employee_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "無効な従業員IDです",
+			})
+		}
+		employeeID = &id
+	}
+
+	patterns, err := h.repos.ShiftPatterns.List(employeeID)
+	if err != nil {
+		return serverError(c, err, "繰り返しシフトの取得に失敗しました")
+	}
+	if patterns == nil {
+		patterns = []models.ShiftPattern{}
+	}
+	return c.JSON(http.StatusOK, patterns)
+}
+
+// GetShiftPattern 繰り返しシフトを取得
+func (h *Handler) GetShiftPattern(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効なIDです",
+		})
+	}
+
+	pattern, err := h.repos.ShiftPatterns.Get(id)
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "繰り返しシフトが見つかりません",
+		})
+	}
+	if err != nil {
+		return serverError(c, err, "繰り返しシフトの取得に失敗しました")
+	}
+
+	return c.JSON(http.StatusOK, pattern)
+}
+
+// CreateShiftPattern 繰り返しシフトを作成
+// シフトは作成しない。MaterializeShiftPatterns で期間を指定して作成する
+func (h *Handler) CreateShiftPattern(c echo.Context) error {
+	var req models.CreateShiftPatternRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "リクエストの解析に失敗しました",
+		})
+	}
+
+	if req.EmployeeID == 0 || req.StartDate == "" || req.StartTime == "" || req.EndTime == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "必須項目が不足しています",
+		})
+	}
+
+	pattern := models.ShiftPattern{
+		EmployeeID:    req.EmployeeID,
+		Weekdays:      req.Weekdays,
+		IntervalWeeks: req.IntervalWeeks,
+		StartDate:     req.StartDate,
+		EndDate:       req.EndDate,
+		StartTime:     req.StartTime,
+		EndTime:       req.EndTime,
+		EndsNextDay:   req.EndsNextDay,
+		BreakTime:     req.BreakTime,
+		Position:      strings.TrimSpace(req.Position),
+		Exceptions:    req.Exceptions,
+	}
+	pattern.Normalize()
+	if err := pattern.Validate(); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": patternErrorMessage(err),
+		})
+	}
+
+	err := h.repos.InTx(func(r *repository.Repositories) error {
+		if err := checkPatternEmployee(r, pattern); err != nil {
+			return err
+		}
+		created, err := r.ShiftPatterns.Create(pattern)
+		if err != nil {
+			return err
+		}
+		pattern = created
+		return nil
+	})
+	if err != nil {
+		return respondError(c, err, "繰り返しシフトの作成に失敗しました")
+	}
+
+	return c.JSON(http.StatusCreated, pattern)
+}
+
+// UpdateShiftPattern 繰り返しシフトを from_date 以降の回について変更する
+//   - from_date が開始日以前の場合は定義をそのまま変更する
+//   - from_date が開始日より後の場合は、元の定義を from_date の前日で終わらせ、from_date から始まる新しい定義を作る
+//
+// from_date 以降に作成済みのシフトのうち、変更後も繰り返しの対象になる日のシフトは変更後の内容に更新し、
+// 対象から外れた日のシフトは削除する。作成済みの期間内で新たに対象になった日のシフトは作成する
+// 更新・作成するシフトが他のシフトと重なるなどして割り当てられない場合は、何も変更せずにエラーを返す
+func (h *Handler) UpdateShiftPattern(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効なIDです",
+		})
+	}
+
+	var req models.UpdateShiftPatternRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "リクエストの解析に失敗しました",
+		})
+	}
+
+	from := today()
+	if req.FromDate != "" {
+		from, err = models.ParseDate(req.FromDate)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "無効な日付です",
+			})
+		}
+	}
+
+	var change models.ShiftPatternChange
+	err = h.repos.InTx(func(r *repository.Repositories) error {
+		pattern, err := r.ShiftPatterns.Get(id)
+		if err != nil {
+			return err
+		}
+
+		updated := pattern
+		applyShiftPatternUpdate(&updated, req)
+		updated.Normalize()
+
+		start, err := models.ParseDate(pattern.StartDate)
+		if err != nil {
+			return err
+		}
+		if from.After(start) {
+			if pattern.EndDate != nil && dateKey(*pattern.EndDate) < from.Format(models.DateLayout) {
+				return &httpError{http.StatusBadRequest, "変更を適用する日が繰り返しの終了日より後です"}
+			}
+			updated.StartDate = splitStartDate(pattern, updated, from).Format(models.DateLayout)
+			if req.Exceptions == nil {
+				updated.Exceptions = exceptionsFrom(pattern.Exceptions, from)
+			}
+			if err := updated.Validate(); err != nil {
+				return &httpError{http.StatusBadRequest, patternErrorMessage(err)}
+			}
+
+			// 元の定義は from_date の前日で終わらせる
+			ended := pattern
+			end := from.AddDate(0, 0, -1).Format(models.DateLayout)
+			ended.EndDate = &end
+			ended.Exceptions = exceptionsBefore(pattern.Exceptions, from)
+			if err := r.ShiftPatterns.Update(ended); err != nil {
+				return err
+			}
+			if err := checkPatternEmployee(r, updated); err != nil {
+				return err
+			}
+			if updated, err = r.ShiftPatterns.Create(updated); err != nil {
+				return err
+			}
+		} else {
+			if err := updated.Validate(); err != nil {
+				return &httpError{http.StatusBadRequest, patternErrorMessage(err)}
+			}
+			if err := checkPatternEmployee(r, updated); err != nil {
+				return err
+			}
+			if err := r.ShiftPatterns.Update(updated); err != nil {
+				return err
+			}
+			if updated, err = r.ShiftPatterns.Get(id); err != nil {
+				return err
+			}
+		}
+		change.Pattern = updated
+
+		existing, err := r.Shifts.List(repository.ShiftFilter{
+			PatternID: &pattern.ID,
+			StartDate: from.Format(models.DateLayout),
+		})
+		if err != nil {
+			return err
+		}
+
+		// 作成済みの最後の日まで、変更後の定義でシフトを揃える
+		last := from
+		materialized := make(map[string]bool, len(existing))
+		for _, shift := range existing {
+			day, err := models.ParseDate(shift.Date)
+			if err != nil {
+				return err
+			}
+			if day.After(last) {
+				last = day
+			}
+			if !updated.OccursOn(day) {
+				if err := touchSchedulePeriods(r, shift.Date); err != nil {
+					return err
+				}
+				if err := r.Shifts.Delete(shift.ID); err != nil {
+					return err
+				}
+				if err := h.syncShiftAttendance(r, shift); err != nil {
+					return err
+				}
+				change.Deleted = append(change.Deleted, shift)
+				continue
+			}
+
+			materialized[day.Format(models.DateLayout)] = true
+			next := updated.ShiftOn(day)
+			next.ID = shift.ID
+			if err := checkShiftAssignment(r, &next); err != nil {
+				return occurrenceError(err, next.Date)
+			}
+			warnings, err := h.checkShiftRules(r, next)
+			if err != nil {
+				return occurrenceError(ruleConflict(err), next.Date)
+			}
+			if err := h.storeShift(r, shift, next, models.ShiftHolderChanged, nil); err != nil {
+				return err
+			}
+			saved, err := r.Shifts.Get(shift.ID)
+			if err != nil {
+				return err
+			}
+			saved.Warnings = warnings
+			change.Updated = append(change.Updated, saved)
+		}
+
+		if len(existing) > 0 {
+			for _, day := range updated.Occurrences(from, last) {
+				if materialized[day.Format(models.DateLayout)] {
+					continue
+				}
+				shift := updated.ShiftOn(day)
+				if err := checkShiftAssignment(r, &shift); err != nil {
+					return occurrenceError(err, shift.Date)
+				}
+				warnings, err := h.checkShiftRules(r, shift)
+				if err != nil {
+					return occurrenceError(ruleConflict(err), shift.Date)
+				}
+				created, err := h.insertShift(r, shift)
+				if err != nil {
+					return err
+				}
+				setWarningShiftID(warnings, created.ID)
+				created.Warnings = warnings
+				change.Created = append(change.Created, created)
+			}
+		}
+		return nil
+	})
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "繰り返しシフトが見つかりません",
+		})
+	}
+	if err != nil {
+		return respondError(c, err, "繰り返しシフトの更新に失敗しました")
+	}
+
+	if change.Updated == nil {
+		change.Updated = []models.Shift{}
+	}
+	if change.Created == nil {
+		change.Created = []models.Shift{}
+	}
+	if change.Deleted == nil {
+		change.Deleted = []models.Shift{}
+	}
+	return c.JSON(http.StatusOK, change)
+}
+
+// DeleteShiftPattern 繰り返しシフトを削除し、from_date（省略した場合は今日）以降に作成済みのシフトを削除する
+// from_date より前のシフトは残し、繰り返しから外す
+func (h *Handler) DeleteShiftPattern(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効なIDです",
+		})
+	}
+
+	from := today()
+	if v := c.QueryParam("from_date"); v != "" {
+		from, err = models.ParseDate(v)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "無効な日付です",
+			})
+		}
+	}
+
+	err = h.repos.InTx(func(r *repository.Repositories) error {
+		if _, err := r.ShiftPatterns.Get(id); err != nil {
+			return err
+		}
+		shifts, err := r.Shifts.List(repository.ShiftFilter{
+			PatternID: &id,
+			StartDate: from.Format(models.DateLayout),
+		})
+		if err != nil {
+			return err
+		}
+		for _, shift := range shifts {
+			if err := touchSchedulePeriods(r, shift.Date); err != nil {
+				return err
+			}
+			if err := r.Shifts.Delete(shift.ID); err != nil {
+				return err
+			}
+			if err := h.syncShiftAttendance(r, shift); err != nil {
+				return err
+			}
+		}
+		return r.ShiftPatterns.Delete(id)
+	})
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "繰り返しシフトが見つかりません",
+		})
+	}
+	if err != nil {
+		return serverError(c, err, "繰り返しシフトの削除に失敗しました")
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "繰り返しシフトが削除されました",
+	})
+}
+
+// DeleteShiftPatternOccurrence 繰り返しシフトの1回分を取りやめる
+// 指定した日を例外日に加え、作成済みのシフトがあれば削除する
+// 1回分の時刻などを変更する場合は、作成済みのシフトを UpdateShift で変更する（変更したシフトは繰り返しから外れる）
+func (h *Handler) DeleteShiftPatternOccurrence(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効なIDです",
+		})
+	}
+	day, err := models.ParseDate(c.Param("date"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効な日付です",
+		})
+	}
+	date := day.Format(models.DateLayout)
+
+	err = h.repos.InTx(func(r *repository.Repositories) error {
+		pattern, err := r.ShiftPatterns.Get(id)
+		if err != nil {
+			return err
+		}
+		if !pattern.OccursOn(day) {
+			return &httpError{http.StatusBadRequest, "指定した日は繰り返しの対象ではありません"}
+		}
+
+		shifts, err := r.Shifts.List(repository.ShiftFilter{PatternID: &id, StartDate: date, EndDate: date})
+		if err != nil {
+			return err
+		}
+		for _, shift := range shifts {
+			if err := touchSchedulePeriods(r, shift.Date); err != nil {
+				return err
+			}
+			if err := r.Shifts.Delete(shift.ID); err != nil {
+				return err
+			}
+			if err := h.syncShiftAttendance(r, shift); err != nil {
+				return err
+			}
+		}
+		return addPatternException(r, id, date)
+	})
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "繰り返しシフトが見つかりません",
+		})
+	}
+	if err != nil {
+		return respondError(c, err, "繰り返しシフトの更新に失敗しました")
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": date + " のシフトを取りやめました",
+	})
+}
+
+// MaterializeShiftPatterns 期間内の繰り返しシフトの回をシフトとして作成する
+// 作成済みの回は作成しない。他のシフトと重なる、エラーとするルールに違反するなどで割り当てられない回は作成せず、理由を返す
+func (h *Handler) MaterializeShiftPatterns(c echo.Context) error {
+	var req models.MaterializeShiftPatternsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "リクエストの解析に失敗しました",
+		})
+	}
+
+	startDate, endDate, message := parseDateRange(req.StartDate, req.EndDate, maxRangeDays)
+	if message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	res := models.MaterializeShiftPatternsResponse{
+		Created: []models.Shift{},
+		Skipped: []models.SkippedOccurrence{},
+	}
+	err := h.repos.InTx(func(r *repository.Repositories) error {
+		var patterns []models.ShiftPattern
+		if len(req.PatternIDs) > 0 {
+			for _, id := range req.PatternIDs {
+				pattern, err := r.ShiftPatterns.Get(id)
+				if err == repository.ErrNotFound {
+					return &httpError{http.StatusNotFound, "繰り返しシフトが見つかりません（ID: " + strconv.Itoa(id) + "）"}
+				}
+				if err != nil {
+					return err
+				}
+				patterns = append(patterns, pattern)
+			}
+		} else {
+			var err error
+			if patterns, err = r.ShiftPatterns.List(nil); err != nil {
+				return err
+			}
+		}
+
+		for _, pattern := range patterns {
+			existing, err := r.Shifts.List(repository.ShiftFilter{
+				PatternID: &pattern.ID,
+				StartDate: req.StartDate,
+				EndDate:   req.EndDate,
+			})
+			if err != nil {
+				return err
+			}
+			materialized := make(map[string]bool, len(existing))
+			for _, shift := range existing {
+				materialized[dateKey(shift.Date)] = true
+			}
+
+			for _, day := range pattern.Occurrences(startDate, endDate) {
+				shift := pattern.ShiftOn(day)
+				if materialized[shift.Date] {
+					continue
+				}
+				var warnings []models.LaborViolation
+				err := checkShiftAssignment(r, &shift)
+				if err == nil {
+					warnings, err = h.checkShiftRules(r, shift)
+				}
+				if err != nil {
+					he, ok := err.(*httpError)
+					if !ok {
+						return err
+					}
+					res.Skipped = append(res.Skipped, models.SkippedOccurrence{
+						PatternID: pattern.ID,
+						Date:      shift.Date,
+						Reason:    he.message,
+					})
+					continue
+				}
+				created, err := h.insertShift(r, shift)
+				if err != nil {
+					return err
+				}
+				setWarningShiftID(warnings, created.ID)
+				created.Warnings = warnings
+				res.Created = append(res.Created, created)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return respondError(c, err, "繰り返しシフトからのシフト作成に失敗しました")
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// applyShiftPatternUpdate 更新リクエストで指定された項目を繰り返しシフトに反映する
+func applyShiftPatternUpdate(pattern *models.ShiftPattern, req models.UpdateShiftPatternRequest) {
+	if req.EmployeeID != 0 {
+		pattern.EmployeeID = req.EmployeeID
+	}
+	if req.Weekdays != nil {
+		pattern.Weekdays = *req.Weekdays
+	}
+	if req.IntervalWeeks != nil {
+		pattern.IntervalWeeks = *req.IntervalWeeks
+	}
+	if req.EndDate != nil {
+		if *req.EndDate == "" {
+			pattern.EndDate = nil
+		} else {
+			end := *req.EndDate
+			pattern.EndDate = &end
+		}
+	}
+	if req.StartTime != "" {
+		pattern.StartTime = req.StartTime
+	}
+	if req.EndTime != "" {
+		pattern.EndTime = req.EndTime
+	}
+	if req.EndsNextDay != nil {
+		pattern.EndsNextDay = *req.EndsNextDay
+	}
+	if req.BreakTime != nil {
+		pattern.BreakTime = *req.BreakTime
+	}
+	if req.Position != nil {
+		pattern.Position = strings.TrimSpace(*req.Position)
+	}
+	if req.Exceptions != nil {
+		pattern.Exceptions = *req.Exceptions
+	}
+}
+
+// splitStartDate 定義を分ける場合の新しい定義の開始日
+// 隔週のまま変更する場合は、from を含む週が元の定義で休みの週なら次の週から始めて隔週の周期を保つ
+func splitStartDate(original, updated models.ShiftPattern, from time.Time) time.Time {
+	if original.IntervalWeeks != updated.IntervalWeeks || updated.IntervalWeeks == 1 {
+		return from
+	}
+	start, err := models.ParseDate(original.StartDate)
+	if err != nil {
+		return from
+	}
+	week := from.AddDate(0, 0, -int(from.Weekday()))
+	weeks := int(week.Sub(start.AddDate(0, 0, -int(start.Weekday()))).Hours()/24) / 7
+	if weeks%updated.IntervalWeeks == 0 {
+		return from
+	}
+	return week.AddDate(0, 0, 7*(updated.IntervalWeeks-weeks%updated.IntervalWeeks))
+}
+
+// exceptionsBefore from より前の例外日
+func exceptionsBefore(exceptions []string, from time.Time) []string {
+	result := []string{}
+	for _, date := range exceptions {
+		if dateKey(date) < from.Format(models.DateLayout) {
+			result = append(result, date)
+		}
+	}
+	return result
+}
+
+// exceptionsFrom from 以降の例外日
+func exceptionsFrom(exceptions []string, from time.Time) []string {
+	result := []string{}
+	for _, date := range exceptions {
+		if dateKey(date) >= from.Format(models.DateLayout) {
+			result = append(result, date)
+		}
+	}
+	return result
+}
+
+// addPatternException 繰り返しシフトの例外日を追加する（繰り返しシフトが削除済みの場合は何もしない）
+func addPatternException(r *repository.Repositories, patternID int, date string) error {
+	pattern, err := r.ShiftPatterns.Get(patternID)
+	if err == repository.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	pattern.Exceptions = append(pattern.Exceptions, date)
+	pattern.Normalize()
+	return r.ShiftPatterns.Update(pattern)
+}
+
+// checkPatternEmployee 従業員をロックしたうえで、繰り返しシフトのポジションを担当できるか確認する
+func checkPatternEmployee(r *repository.Repositories, pattern models.ShiftPattern) error {
+	if err := r.Employees.Lock(pattern.EmployeeID); err != nil {
+		if err == repository.ErrNotFound {
+			return &httpError{http.StatusBadRequest, "指定された従業員が存在しません"}
+		}
+		return err
+	}
+	employee, err := r.Employees.Get(pattern.EmployeeID)
+	if err != nil {
+		return err
+	}
+	if pattern.Position != "" && !employee.IsQualifiedFor(pattern.Position) {
+		return &httpError{http.StatusBadRequest, employee.Name + "さんは「" + pattern.Position + "」を担当できません"}
+	}
+	return nil
+}
+
+// occurrenceError シフトを割り当てられない理由に対象の日付を加える
+func occurrenceError(err error, date string) error {
+	if he, ok := err.(*httpError); ok {
+		return &httpError{he.status, dateKey(date) + " のシフト: " + he.message}
+	}
+	return err
+}
+
+// patternErrorMessage 繰り返しシフトの検証エラーをレスポンス用のメッセージに変換
+func patternErrorMessage(err error) string {
+	switch err {
+	case models.ErrPatternWeekdays, models.ErrPatternInterval, models.ErrPatternDateRange:
+		return err.Error()
+	}
+	return spanErrorMessage(err)
+}
+
+// today サーバーのローカル時刻の今日の日付
+func today() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"shift-management-backend/models"
+)
+
+func TestCreateShiftPatternBreakTime(t *testing.T) {
+	h := newTestHandler(t)
+	employee := createTestEmployee(t, h, "山田")
+
+	tests := []struct {
+		name       string
+		breakTime  int
+		wantStatus int
+	}{
+		{"休憩あり", 45, http.StatusCreated},
+		{"休憩が負", -1, http.StatusBadRequest},
+		{"休憩が勤務時間と同じ", 360, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, body := serve(t, h.CreateShiftPattern, http.MethodPost, "/api/shift-patterns", models.CreateShiftPatternRequest{
+				EmployeeID: employee.ID,
+				Weekdays:   []int{1, 3},
+				StartDate:  "2030-10-07",
+				StartTime:  "09:00",
+				EndTime:    "15:00",
+				BreakTime:  tt.breakTime,
+			}, testOwner)
+			if code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", code, tt.wantStatus, body)
+			}
+		})
+	}
+}
+
+func createTestPattern(t *testing.T, h *Handler, req models.CreateShiftPatternRequest) models.ShiftPattern {
+	t.Helper()
+	code, body := serve(t, h.CreateShiftPattern, http.MethodPost, "/api/shift-patterns", req, testOwner)
+	if code != http.StatusCreated {
+		t.Fatalf("status = %d: %s", code, body)
+	}
+	var pattern models.ShiftPattern
+	decodeBody(t, body, &pattern)
+	return pattern
+}
+
+func TestMaterializeShiftPatternsChecksRestInterval(t *testing.T) {
+	h := newTestHandler(t)
+	employee := createTestEmployee(t, h, "山田")
+	pattern := createTestPattern(t, h, models.CreateShiftPatternRequest{
+		EmployeeID: employee.ID,
+		Weekdays:   []int{2},
+		StartDate:  "2030-10-07",
+		StartTime:  "09:00",
+		EndTime:    "13:00",
+	})
+	// 2回目の火曜は、前日の勤務の終了から10時間しか空かない（必要な休息時間は11時間）
+	createTestShift(t, h, models.CreateShiftRequest{EmployeeID: employee.ID, Date: "2030-10-14", StartTime: "17:00", EndTime: "23:00"})
+
+	code, body := serve(t, h.MaterializeShiftPatterns, http.MethodPost, "/api/shift-patterns/materialize", models.MaterializeShiftPatternsRequest{
+		StartDate: "2030-10-07",
+		EndDate:   "2030-10-20",
+	}, testOwner)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d: %s", code, body)
+	}
+	var res models.MaterializeShiftPatternsResponse
+	decodeBody(t, body, &res)
+	if len(res.Created) != 1 || dateKey(res.Created[0].Date) != "2030-10-08" {
+		t.Errorf("休息時間を確保できる回だけ作成するはずです: %s", body)
+	}
+	if len(res.Skipped) != 1 || res.Skipped[0].PatternID != pattern.ID || res.Skipped[0].Date != "2030-10-15" {
+		t.Errorf("休息時間が足りない回は作成せずに返すはずです: %s", body)
+	}
+}
+
+func TestUpdateShiftPatternChecksRestInterval(t *testing.T) {
+	h := newTestHandler(t)
+	employee := createTestEmployee(t, h, "山田")
+	pattern := createTestPattern(t, h, models.CreateShiftPatternRequest{
+		EmployeeID: employee.ID,
+		Weekdays:   []int{2},
+		StartDate:  "2030-10-07",
+		StartTime:  "12:00",
+		EndTime:    "16:00",
+	})
+	code, body := serve(t, h.MaterializeShiftPatterns, http.MethodPost, "/api/shift-patterns/materialize", models.MaterializeShiftPatternsRequest{
+		StartDate: "2030-10-07",
+		EndDate:   "2030-10-13",
+	}, testOwner)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d: %s", code, body)
+	}
+	var materialized models.MaterializeShiftPatternsResponse
+	decodeBody(t, body, &materialized)
+	if len(materialized.Created) != 1 {
+		t.Fatalf("created = %d, want 1", len(materialized.Created))
+	}
+	before := materialized.Created[0]
+	createTestShift(t, h, models.CreateShiftRequest{EmployeeID: employee.ID, Date: "2030-10-07", StartTime: "17:00", EndTime: "23:00"})
+
+	// 開始時刻を早めると、前日の勤務の終了から10時間しか空かない
+	id := strconv.Itoa(pattern.ID)
+	code, body = serve(t, h.UpdateShiftPattern, http.MethodPut, "/api/shift-patterns/"+id, models.UpdateShiftPatternRequest{
+		FromDate:  "2030-10-07",
+		StartTime: "09:00",
+		EndTime:   "13:00",
+	}, testOwner, "id", id)
+	if code != http.StatusConflict {
+		t.Fatalf("休息時間が足りない場合は変更できないはずです: status = %d: %s", code, body)
+	}
+	if got, err := h.repos.Shifts.Get(before.ID); err != nil || got.StartTime != before.StartTime {
+		t.Errorf("作成済みのシフトは変更されないはずです: %+v, %v", got, err)
+	}
+}
@@ -3,47 +3,45 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
-	"shift-management-backend/database"
 	"shift-management-backend/models"
+	"shift-management-backend/repository"
 
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	// maxShiftRequestWindows 1件のシフト希望に指定できる時間帯の数
+	maxShiftRequestWindows = 10
+	// maxShiftRequestNoteLength シフト希望のメモの文字数
+	maxShiftRequestNoteLength = 500
+)
+
 // GetShiftRequests シフト希望一覧を取得
-func GetShiftRequests(c echo.Context) error {
-	rows, err := database.DB.Query(`
-		SELECT sr.id, sr.employee_id, sr.date, sr.preferred_start_time, sr.preferred_end_time, 
-		       sr.status, sr.created_at, sr.updated_at, e.name as employee_name
-		FROM shift_requests sr
-		JOIN employees e ON sr.employee_id = e.id
-		ORDER BY sr.date DESC, sr.created_at DESC
-	`)
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "シフト希望一覧の取得に失敗しました",
-		})
+func (h *Handler) GetShiftRequests(c echo.Context) error {
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
 	}
-	defer rows.Close()
 
-	var requests []models.ShiftRequest
-	for rows.Next() {
-		var req models.ShiftRequest
-		err := rows.Scan(&req.ID, &req.EmployeeID, &req.Date, &req.PreferredStartTime,
-			&req.PreferredEndTime, &req.Status, &req.CreatedAt, &req.UpdatedAt, &req.EmployeeName)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{
-				"error": "データの読み込みに失敗しました",
-			})
-		}
-		requests = append(requests, req)
+	// 従業員は自分のシフト希望のみ取得できる
+	var employeeID *int
+	if !acc.IsOwner() {
+		employeeID = &acc.EmployeeID
+	}
+
+	requests, err := h.repos.ShiftRequests.List(employeeID)
+	if err != nil {
+		return serverError(c, err, "シフト希望一覧の取得に失敗しました")
 	}
 
 	return c.JSON(http.StatusOK, requests)
 }
 
 // GetShiftRequest シフト希望詳細を取得
-func GetShiftRequest(c echo.Context) error {
+func (h *Handler) GetShiftRequest(c echo.Context) error {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
@@ -51,27 +49,29 @@ func GetShiftRequest(c echo.Context) error {
 		})
 	}
 
-	var req models.ShiftRequest
-	err = database.DB.QueryRow(`
-		SELECT sr.id, sr.employee_id, sr.date, sr.preferred_start_time, sr.preferred_end_time, 
-		       sr.status, sr.created_at, sr.updated_at, e.name as employee_name
-		FROM shift_requests sr
-		JOIN employees e ON sr.employee_id = e.id
-		WHERE sr.id = $1
-	`, id).Scan(&req.ID, &req.EmployeeID, &req.Date, &req.PreferredStartTime,
-		&req.PreferredEndTime, &req.Status, &req.CreatedAt, &req.UpdatedAt, &req.EmployeeName)
-
-	if err != nil {
+	req, err := h.repos.ShiftRequests.Get(id)
+	if err == repository.ErrNotFound {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "シフト希望が見つかりません",
 		})
 	}
+	if err != nil {
+		return serverError(c, err, "シフト希望の取得に失敗しました")
+	}
+
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	if !acc.IsOwner() && !acc.IsSelf(req.EmployeeID) {
+		return forbidden(c)
+	}
 
 	return c.JSON(http.StatusOK, req)
 }
 
 // CreateShiftRequest シフト希望を作成
-func CreateShiftRequest(c echo.Context) error {
+func (h *Handler) CreateShiftRequest(c echo.Context) error {
 	var req models.CreateShiftRequestRequest
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
@@ -80,41 +80,74 @@ func CreateShiftRequest(c echo.Context) error {
 	}
 
 	// バリデーション
-	if req.EmployeeID == 0 || req.Date == "" || req.PreferredStartTime == "" || req.PreferredEndTime == "" {
+	if req.EmployeeID == 0 || req.Date == "" {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"error": "必須項目が不足しています",
 		})
 	}
 
+	// シフト希望提出権限の確認
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	if !acc.CanSubmitShiftRequestFor(req.EmployeeID) {
+		return forbidden(c)
+	}
+
 	// 従業員の存在確認
-	var employeeExists bool
-	err := database.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM employees WHERE id = $1)", req.EmployeeID).Scan(&employeeExists)
+	employeeExists, err := h.repos.Employees.Exists(req.EmployeeID)
 	if err != nil || !employeeExists {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"error": "指定された従業員が存在しません",
 		})
 	}
 
-	var shiftReq models.ShiftRequest
-	err = database.DB.QueryRow(`
-		INSERT INTO shift_requests (employee_id, date, preferred_start_time, preferred_end_time, status) 
-		VALUES ($1, $2, $3, $4, 'submitted') 
-		RETURNING id, employee_id, date, preferred_start_time, preferred_end_time, status, created_at, updated_at
-	`, req.EmployeeID, req.Date, req.PreferredStartTime, req.PreferredEndTime).Scan(
-		&shiftReq.ID, &shiftReq.EmployeeID, &shiftReq.Date, &shiftReq.PreferredStartTime,
-		&shiftReq.PreferredEndTime, &shiftReq.Status, &shiftReq.CreatedAt, &shiftReq.UpdatedAt)
+	shiftReq := models.ShiftRequest{
+		EmployeeID:   req.EmployeeID,
+		Date:         req.Date,
+		Availability: req.Availability,
+		Windows:      req.Windows,
+		Note:         strings.TrimSpace(req.Note),
+		Status:       models.ShiftRequestSubmitted,
+	}
+	if shiftReq.Availability == "" {
+		shiftReq.Availability = models.ShiftRequestPreferred
+	}
+	// 以前の形式（希望する開始・終了時刻）
+	if len(shiftReq.Windows) == 0 && (req.PreferredStartTime != "" || req.PreferredEndTime != "") {
+		shiftReq.Windows = []models.ShiftRequestWindow{{
+			StartTime:   req.PreferredStartTime,
+			EndTime:     req.PreferredEndTime,
+			EndsNextDay: req.EndsNextDay,
+		}}
+	}
+	if err := validateShiftRequest(shiftReq); err != nil {
+		return respondError(c, err, "シフト希望の作成に失敗しました")
+	}
 
+	err = h.repos.InTx(func(r *repository.Repositories) error {
+		// 提出期間の確認（締切後の提出は遅れての提出として記録する）
+		var err error
+		shiftReq.PeriodID, shiftReq.Late, err = checkSubmissionPeriod(r, acc, shiftReq.Date, time.Now())
+		if err != nil {
+			return err
+		}
+		if err := checkShiftRequestConflict(r, shiftReq); err != nil {
+			return err
+		}
+		shiftReq, err = r.ShiftRequests.Create(shiftReq)
+		return err
+	})
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "シフト希望の作成に失敗しました",
-		})
+		return respondError(c, err, "シフト希望の作成に失敗しました")
 	}
 
 	return c.JSON(http.StatusCreated, shiftReq)
 }
 
 // UpdateShiftRequest シフト希望を更新
-func UpdateShiftRequest(c echo.Context) error {
+func (h *Handler) UpdateShiftRequest(c echo.Context) error {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
@@ -129,43 +162,165 @@ func UpdateShiftRequest(c echo.Context) error {
 		})
 	}
 
-	// ステータスのバリデーション
-	if req.Status != "" && req.Status != "submitted" && req.Status != "processed" {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "無効なステータスです",
-		})
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
 	}
 
-	result, err := database.DB.Exec(`
-		UPDATE shift_requests 
-		SET date = COALESCE($1, date),
-		    preferred_start_time = COALESCE($2, preferred_start_time),
-		    preferred_end_time = COALESCE($3, preferred_end_time),
-		    status = COALESCE($4, status),
-		    updated_at = CURRENT_TIMESTAMP
-		WHERE id = $5
-	`, req.Date, req.PreferredStartTime, req.PreferredEndTime, req.Status, id)
+	err = h.repos.InTx(func(r *repository.Repositories) error {
+		// 従業員は提出権限がある場合のみ自分のシフト希望を修正できる
+		existing, err := r.ShiftRequests.Get(id)
+		if err != nil {
+			return err
+		}
+		if !acc.CanSubmitShiftRequestFor(existing.EmployeeID) {
+			return &httpError{http.StatusForbidden, "この操作を行う権限がありません"}
+		}
+		// 承認・却下したシフト希望は変更できない（ステータスの変更は承認・却下で行う）
+		if existing.Status != models.ShiftRequestSubmitted {
+			return &httpError{http.StatusConflict, "承認・却下されたシフト希望は変更できません"}
+		}
 
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "シフト希望の更新に失敗しました",
-		})
-	}
+		// 従業員は受付期間外の日付のシフト希望を修正できない
+		now := time.Now()
+		if !acc.IsOwner() {
+			if _, _, err := checkSubmissionPeriod(r, acc, existing.Date, now); err != nil {
+				return err
+			}
+		}
+
+		applyShiftRequestUpdate(&existing, req)
+		if err := validateShiftRequest(existing); err != nil {
+			return err
+		}
+
+		periodID, late, err := checkSubmissionPeriod(r, acc, existing.Date, now)
+		if err != nil {
+			return err
+		}
+		existing.PeriodID = periodID
+		// 締切後に従業員が修正した場合は遅れての提出とする（オーナーの修正では変えない）
+		if !acc.IsOwner() && late {
+			existing.Late = true
+		}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
+		if err := checkShiftRequestConflict(r, existing); err != nil {
+			return err
+		}
+		return r.ShiftRequests.Update(existing)
+	})
+	if err == repository.ErrNotFound {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "シフト希望が見つかりません",
 		})
 	}
+	if err != nil {
+		return respondError(c, err, "シフト希望の更新に失敗しました")
+	}
 
 	return c.JSON(http.StatusOK, map[string]string{
 		"message": "シフト希望が更新されました",
 	})
 }
 
+// applyShiftRequestUpdate 更新リクエストで指定された項目をシフト希望に反映する
+// 終日勤務できる・勤務できないに変更して時間帯を指定しない場合は、時間帯をなくして1日全体を対象にする
+func applyShiftRequestUpdate(request *models.ShiftRequest, req models.UpdateShiftRequestRequest) {
+	if req.Date != "" {
+		request.Date = req.Date
+	}
+	if req.Availability != "" && req.Availability != request.Availability {
+		request.Availability = req.Availability
+		if req.Availability == models.ShiftRequestAllDay || req.Availability == models.ShiftRequestUnavailable {
+			request.Windows = nil
+		}
+	}
+	if req.Windows != nil {
+		request.Windows = req.Windows
+	} else if req.PreferredStartTime != "" || req.PreferredEndTime != "" || req.EndsNextDay != nil {
+		// 以前の形式（最初の時間帯を変更する）
+		var w models.ShiftRequestWindow
+		if len(request.Windows) > 0 {
+			w = request.Windows[0]
+		}
+		if req.PreferredStartTime != "" {
+			w.StartTime = req.PreferredStartTime
+		}
+		if req.PreferredEndTime != "" {
+			w.EndTime = req.PreferredEndTime
+		}
+		if req.EndsNextDay != nil {
+			w.EndsNextDay = *req.EndsNextDay
+		}
+		request.Windows = []models.ShiftRequestWindow{w}
+	}
+	if req.Note != nil {
+		request.Note = strings.TrimSpace(*req.Note)
+	}
+}
+
+// validateShiftRequest シフト希望の種類・時間帯・メモを検証する
+func validateShiftRequest(request models.ShiftRequest) error {
+	if !models.IsValidAvailability(request.Availability) {
+		return &httpError{http.StatusBadRequest, "無効なシフト希望の種類です"}
+	}
+	switch request.Availability {
+	case models.ShiftRequestAllDay:
+		if len(request.Windows) > 0 {
+			return &httpError{http.StatusBadRequest, "終日勤務できる場合は時間帯を指定しないでください"}
+		}
+	case models.ShiftRequestPreferred, models.ShiftRequestIfNeeded:
+		if len(request.Windows) == 0 {
+			return &httpError{http.StatusBadRequest, "時間帯を1つ以上指定してください"}
+		}
+	}
+	if len(request.Windows) > maxShiftRequestWindows {
+		return &httpError{http.StatusBadRequest, "時間帯は" + strconv.Itoa(maxShiftRequestWindows) + "個以内で指定してください"}
+	}
+	if len([]rune(request.Note)) > maxShiftRequestNoteLength {
+		return &httpError{http.StatusBadRequest, "メモは" + strconv.Itoa(maxShiftRequestNoteLength) + "文字以内で入力してください"}
+	}
+	if _, err := request.Spans(); err != nil {
+		return &httpError{http.StatusBadRequest, spanErrorMessage(err)}
+	}
+	return nil
+}
+
+// checkShiftRequestConflict 同じ従業員の同じ日のシフト希望と時間帯が重ならないか確認する
+// 1日全体を対象にするシフト希望（終日勤務できる・勤務できない）は、その日の他のシフト希望とは両立しない
+func checkShiftRequestConflict(r *repository.Repositories, request models.ShiftRequest) error {
+	spans, err := request.Spans()
+	if err != nil {
+		return err
+	}
+	requests, err := r.ShiftRequests.List(&request.EmployeeID)
+	if err != nil {
+		return err
+	}
+
+	date := dateKey(request.Date)
+	for _, other := range requests {
+		if other.ID == request.ID || dateKey(other.Date) != date {
+			continue
+		}
+		otherSpans, err := other.Spans()
+		if err != nil {
+			continue
+		}
+		for _, span := range spans {
+			for _, otherSpan := range otherSpans {
+				if span.Overlaps(otherSpan) {
+					return &httpError{http.StatusConflict, date + " には時間帯が重なるシフト希望（" +
+						models.ShiftRequestAvailabilityLabels[other.Availability] + "）が既にあります"}
+				}
+			}
+		}
+	}
+	return nil
+}
+
 // DeleteShiftRequest シフト希望を削除
-func DeleteShiftRequest(c echo.Context) error {
+func (h *Handler) DeleteShiftRequest(c echo.Context) error {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
@@ -173,19 +328,46 @@ func DeleteShiftRequest(c echo.Context) error {
 		})
 	}
 
-	result, err := database.DB.Exec("DELETE FROM shift_requests WHERE id = $1", id)
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "シフト希望の削除に失敗しました",
+	// 従業員は提出権限がある場合のみ自分のシフト希望を削除できる
+	existing, err := h.repos.ShiftRequests.Get(id)
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "シフト希望が見つかりません",
 		})
 	}
+	if err != nil {
+		return serverError(c, err, "シフト希望の取得に失敗しました")
+	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	if !acc.CanSubmitShiftRequestFor(existing.EmployeeID) {
+		return forbidden(c)
+	}
+	// 従業員は承認・却下されたシフト希望と、受付期間外の日付のシフト希望を削除できない
+	// （オーナーが削除した場合も、承認して作成したシフトは残す）
+	if !acc.IsOwner() {
+		if existing.Status != models.ShiftRequestSubmitted {
+			return c.JSON(http.StatusConflict, map[string]string{
+				"error": "承認・却下されたシフト希望は削除できません",
+			})
+		}
+		if _, _, err := checkSubmissionPeriod(h.repos, acc, existing.Date, time.Now()); err != nil {
+			return respondError(c, err, "シフト希望の削除に失敗しました")
+		}
+	}
+
+	err = h.repos.ShiftRequests.Delete(id)
+	if err == repository.ErrNotFound {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "シフト希望が見つかりません",
 		})
 	}
+	if err != nil {
+		return serverError(c, err, "シフト希望の削除に失敗しました")
+	}
 
 	return c.JSON(http.StatusOK, map[string]string{
 		"message": "シフト希望が削除されました",
@@ -0,0 +1,388 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"shift-management-backend/models"
+	"shift-management-backend/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// submissionTimeLayout 提出期間の日時の表示形式
+const submissionTimeLayout = "2006-01-02 15:04"
+
+// GetShiftRequestPeriods シフト希望の提出期間一覧を取得
+func (h *Handler) GetShiftRequestPeriods(c echo.Context) error {
+	periods, err := h.repos.RequestPeriods.List()
+	if err != nil {
+		return serverError(c, err, "提出期間一覧の取得に失敗しました")
+	}
+	if periods == nil {
+		periods = []models.ShiftRequestPeriod{}
+	}
+
+	now := time.Now()
+	for i := range periods {
+		periods[i].Status = periods[i].StatusAt(now)
+	}
+	return c.JSON(http.StatusOK, periods)
+}
+
+// GetCurrentShiftRequestPeriod 現在の提出期間を取得
+// 受付中（締切後の受付を含む）の提出期間のうち締切が最も早いもの、なければ次に受付を始める提出期間を返す
+func (h *Handler) GetCurrentShiftRequestPeriod(c echo.Context) error {
+	period, err := h.currentShiftRequestPeriod(time.Now())
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "受付中または受付予定の提出期間がありません",
+		})
+	}
+	if err != nil {
+		return serverError(c, err, "提出期間の取得に失敗しました")
+	}
+	return c.JSON(http.StatusOK, period)
+}
+
+// CreateShiftRequestPeriod シフト希望の提出期間を作成
+func (h *Handler) CreateShiftRequestPeriod(c echo.Context) error {
+	var req models.ShiftRequestPeriodRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "リクエストの解析に失敗しました",
+		})
+	}
+
+	period, err := newShiftRequestPeriod(req)
+	if err != nil {
+		return respondError(c, err, "提出期間の作成に失敗しました")
+	}
+
+	err = h.repos.InTx(func(r *repository.Repositories) error {
+		if err := checkShiftRequestPeriodOverlap(r, period); err != nil {
+			return err
+		}
+		created, err := r.RequestPeriods.Create(period)
+		if err != nil {
+			return err
+		}
+		period = created
+		return nil
+	})
+	if err != nil {
+		return respondError(c, err, "提出期間の作成に失敗しました")
+	}
+
+	period.Status = period.StatusAt(time.Now())
+	return c.JSON(http.StatusCreated, period)
+}
+
+// UpdateShiftRequestPeriod シフト希望の提出期間を更新（すべての項目を置き換える）
+func (h *Handler) UpdateShiftRequestPeriod(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効なIDです",
+		})
+	}
+
+	var req models.ShiftRequestPeriodRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "リクエストの解析に失敗しました",
+		})
+	}
+
+	period, err := newShiftRequestPeriod(req)
+	if err != nil {
+		return respondError(c, err, "提出期間の更新に失敗しました")
+	}
+	period.ID = id
+
+	err = h.repos.InTx(func(r *repository.Repositories) error {
+		existing, err := r.RequestPeriods.Get(id)
+		if err != nil {
+			return err
+		}
+		if err := checkShiftRequestPeriodOverlap(r, period); err != nil {
+			return err
+		}
+		// 締切を変更した場合は、新しい締切に対するリマインドを改めて行う
+		if existing.ClosesAt.Equal(period.ClosesAt) {
+			period.RemindedAt = existing.RemindedAt
+		}
+		return r.RequestPeriods.Update(period)
+	})
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "提出期間が見つかりません",
+		})
+	}
+	if err != nil {
+		return respondError(c, err, "提出期間の更新に失敗しました")
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "提出期間が更新されました",
+	})
+}
+
+// DeleteShiftRequestPeriod シフト希望の提出期間を削除（提出済みのシフト希望は残す）
+func (h *Handler) DeleteShiftRequestPeriod(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効なIDです",
+		})
+	}
+
+	err = h.repos.RequestPeriods.Delete(id)
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "提出期間が見つかりません",
+		})
+	}
+	if err != nil {
+		return serverError(c, err, "提出期間の削除に失敗しました")
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "提出期間が削除されました",
+	})
+}
+
+// GetMissingShiftRequests 提出期間にシフト希望を提出していない従業員を取得
+// period_id を省略した場合は現在の提出期間を対象にする
+func (h *Handler) GetMissingShiftRequests(c echo.Context) error {
+	var period models.ShiftRequestPeriod
+	var err error
+	if periodIDStr := c.QueryParam("period_id"); periodIDStr != "" {
+		periodID, convErr := strconv.Atoi(periodIDStr)
+		if convErr != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "無効な提出期間IDです",
+			})
+		}
+		period, err = h.repos.RequestPeriods.Get(periodID)
+		period.Status = period.StatusAt(time.Now())
+	} else {
+		period, err = h.currentShiftRequestPeriod(time.Now())
+	}
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "提出期間が見つかりません",
+		})
+	}
+	if err != nil {
+		return serverError(c, err, "提出期間の取得に失敗しました")
+	}
+
+	missing, submitted, err := h.missingShiftRequestEmployees(period)
+	if err != nil {
+		return serverError(c, err, "提出状況の取得に失敗しました")
+	}
+
+	return c.JSON(http.StatusOK, models.MissingShiftRequestsResponse{
+		Period:         period,
+		SubmittedCount: submitted,
+		Missing:        missing,
+	})
+}
+
+// RemindShiftRequests リマインドする日時を迎えた受付中の提出期間について、未提出の従業員を通知する
+// 定期的に呼び出す（通知はサーバーログへの出力）
+func (h *Handler) RemindShiftRequests(now time.Time) error {
+	periods, err := h.repos.RequestPeriods.List()
+	if err != nil {
+		return err
+	}
+
+	for _, period := range periods {
+		if !period.DueReminder(now) {
+			continue
+		}
+		missing, _, err := h.missingShiftRequestEmployees(period)
+		if err != nil {
+			return err
+		}
+		if len(missing) > 0 {
+			names := make([]string, len(missing))
+			for i, e := range missing {
+				names[i] = e.Name
+			}
+			log.Printf("シフト希望の提出リマインド（%s〜%s、締切 %s）: 未提出 %d名（%s）",
+				dateKey(period.StartDate), dateKey(period.EndDate), period.ClosesAt.Format(submissionTimeLayout),
+				len(missing), strings.Join(names, "、"))
+		}
+		period.RemindedAt = &now
+		if err := h.repos.RequestPeriods.Update(period); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newShiftRequestPeriod 作成・更新リクエストから提出期間を組み立てて検証する
+func newShiftRequestPeriod(req models.ShiftRequestPeriodRequest) (models.ShiftRequestPeriod, error) {
+	if req.StartDate == "" || req.EndDate == "" || req.OpensAt.IsZero() || req.ClosesAt.IsZero() {
+		return models.ShiftRequestPeriod{}, &httpError{http.StatusBadRequest, "対象の日付の範囲と受付の開始・締切日時を指定してください"}
+	}
+	start, errStart := models.ParseDate(req.StartDate)
+	end, errEnd := models.ParseDate(req.EndDate)
+	if errStart != nil || errEnd != nil {
+		return models.ShiftRequestPeriod{}, &httpError{http.StatusBadRequest, "無効な日付です"}
+	}
+	if end.Before(start) {
+		return models.ShiftRequestPeriod{}, &httpError{http.StatusBadRequest, "終了日は開始日以降である必要があります"}
+	}
+	if !req.ClosesAt.After(req.OpensAt) {
+		return models.ShiftRequestPeriod{}, &httpError{http.StatusBadRequest, "締切は受付開始より後にしてください"}
+	}
+	if req.LateUntil != nil && req.LateUntil.Before(req.ClosesAt) {
+		return models.ShiftRequestPeriod{}, &httpError{http.StatusBadRequest, "締切後の受付の終了は締切以降にしてください"}
+	}
+
+	hours := []int{}
+	seen := make(map[int]bool)
+	for _, h := range req.ReminderHours {
+		if h <= 0 {
+			return models.ShiftRequestPeriod{}, &httpError{http.StatusBadRequest, "リマインドは締切の1時間以上前で指定してください"}
+		}
+		if !seen[h] {
+			seen[h] = true
+			hours = append(hours, h)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(hours)))
+
+	return models.ShiftRequestPeriod{
+		StartDate:     start.Format(models.DateLayout),
+		EndDate:       end.Format(models.DateLayout),
+		OpensAt:       req.OpensAt,
+		ClosesAt:      req.ClosesAt,
+		LateUntil:     req.LateUntil,
+		ReminderHours: hours,
+	}, nil
+}
+
+// checkShiftRequestPeriodOverlap 対象の日付の範囲が他の提出期間と重ならないか確認する
+func checkShiftRequestPeriodOverlap(r *repository.Repositories, period models.ShiftRequestPeriod) error {
+	periods, err := r.RequestPeriods.List()
+	if err != nil {
+		return err
+	}
+	for _, p := range periods {
+		if p.ID == period.ID {
+			continue
+		}
+		if p.Contains(period.StartDate) || p.Contains(period.EndDate) || period.Contains(p.StartDate) {
+			return &httpError{http.StatusConflict, "対象の日付が他の提出期間（" + dateKey(p.StartDate) + "〜" + dateKey(p.EndDate) + "）と重なっています"}
+		}
+	}
+	return nil
+}
+
+// currentShiftRequestPeriod 現在の提出期間（状態を設定して返す）
+func (h *Handler) currentShiftRequestPeriod(now time.Time) (models.ShiftRequestPeriod, error) {
+	periods, err := h.repos.RequestPeriods.List()
+	if err != nil {
+		return models.ShiftRequestPeriod{}, err
+	}
+
+	var current, upcoming *models.ShiftRequestPeriod
+	for i := range periods {
+		p := &periods[i]
+		p.Status = p.StatusAt(now)
+		switch p.Status {
+		case models.ShiftRequestPeriodOpen, models.ShiftRequestPeriodLate:
+			if current == nil || p.ClosesAt.Before(current.ClosesAt) {
+				current = p
+			}
+		case models.ShiftRequestPeriodUpcoming:
+			if upcoming == nil || p.OpensAt.Before(upcoming.OpensAt) {
+				upcoming = p
+			}
+		}
+	}
+	if current != nil {
+		return *current, nil
+	}
+	if upcoming != nil {
+		return *upcoming, nil
+	}
+	return models.ShiftRequestPeriod{}, repository.ErrNotFound
+}
+
+// missingShiftRequestEmployees シフト希望を提出できる従業員のうち、提出期間の対象の日付のシフト希望を提出していない従業員と、
+// 提出済みの従業員の数を返す
+func (h *Handler) missingShiftRequestEmployees(period models.ShiftRequestPeriod) ([]models.Employee, int, error) {
+	employees, err := h.repos.Employees.List()
+	if err != nil {
+		return nil, 0, err
+	}
+	permissions, err := h.repos.Permissions.List()
+	if err != nil {
+		return nil, 0, err
+	}
+	requests, err := h.repos.ShiftRequests.List(nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	canSubmit := make(map[int]bool, len(permissions))
+	for _, p := range permissions {
+		canSubmit[p.EmployeeID] = p.CanSubmitShiftRequests
+	}
+	submitted := make(map[int]bool)
+	for _, req := range requests {
+		if period.Contains(req.Date) {
+			submitted[req.EmployeeID] = true
+		}
+	}
+
+	missing := []models.Employee{}
+	count := 0
+	for _, e := range employees {
+		if allowed, ok := canSubmit[e.ID]; ok && !allowed {
+			continue
+		}
+		if submitted[e.ID] {
+			count++
+			continue
+		}
+		missing = append(missing, e)
+	}
+	return missing, count, nil
+}
+
+// checkSubmissionPeriod シフト希望の日付を対象とする提出期間の受付状況を確認し、提出期間と締切後の提出かを返す
+//   - 従業員は受付中（締切後の受付を含む）の提出期間の日付にだけ提出できる
+//   - オーナーは受付期間外でも提出できる（締切後の場合は締切後の提出として記録する）
+func checkSubmissionPeriod(r *repository.Repositories, acc access, date string, now time.Time) (*int, bool, error) {
+	period, err := r.RequestPeriods.FindByDate(date)
+	if err == repository.ErrNotFound {
+		if acc.IsOwner() {
+			return nil, false, nil
+		}
+		return nil, false, &httpError{http.StatusBadRequest, dateKey(date) + " のシフト希望の提出期間はありません"}
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	status := period.StatusAt(now)
+	if !acc.IsOwner() {
+		switch status {
+		case models.ShiftRequestPeriodUpcoming:
+			return nil, false, &httpError{http.StatusBadRequest, dateKey(date) + " のシフト希望の受付は " + period.OpensAt.Format(submissionTimeLayout) + " からです"}
+		case models.ShiftRequestPeriodClosed:
+			return nil, false, &httpError{http.StatusBadRequest, dateKey(date) + " のシフト希望の受付は終了しました（締切: " + period.ClosesAt.Format(submissionTimeLayout) + "）"}
+		}
+	}
+	return &period.ID, !now.Before(period.ClosesAt), nil
+}
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"shift-management-backend/models"
+	"shift-management-backend/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ApproveShiftRequest シフト希望を承認し、シフト希望の日付にシフトを作成する
+// シフトの時刻を指定しない場合は希望の時間帯どおりに作成して承認、希望と異なる時刻で作成した場合は一部承認とする
+// 勤務できない希望はシフトを作成せずに承認する。シフトを1件でも作成できない場合は承認しない
+func (h *Handler) ApproveShiftRequest(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効なIDです",
+		})
+	}
+
+	var req models.ApproveShiftRequestRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "リクエストの解析に失敗しました",
+		})
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if len([]rune(req.Reason)) > maxShiftRequestNoteLength {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "理由は" + strconv.Itoa(maxShiftRequestNoteLength) + "文字以内で入力してください",
+		})
+	}
+
+	res := models.ApproveShiftRequestResponse{Shifts: []models.Shift{}}
+	err = h.repos.InTx(func(r *repository.Repositories) error {
+		request, err := r.ShiftRequests.Get(id)
+		if err != nil {
+			return err
+		}
+		if request.Status != models.ShiftRequestSubmitted {
+			return &httpError{http.StatusConflict, "このシフト希望は既に承認・却下されています"}
+		}
+
+		approved, err := approvedShifts(request, req.Shifts)
+		if err != nil {
+			return err
+		}
+		for i, a := range approved {
+			shift, err := newShift(models.CreateShiftRequest{
+				EmployeeID:  request.EmployeeID,
+				Date:        request.Date,
+				StartTime:   a.StartTime,
+				EndTime:     a.EndTime,
+				EndsNextDay: a.EndsNextDay,
+				BreakTime:   a.BreakTime,
+				Position:    a.Position,
+				Segments:    a.Segments,
+			})
+			if err != nil {
+				return shiftRequestShiftError(err, i, len(approved))
+			}
+			shift.RequestID = &request.ID
+			created, err := h.createShift(r, shift)
+			if err != nil {
+				return shiftRequestShiftError(err, i, len(approved))
+			}
+			res.Shifts = append(res.Shifts, created)
+		}
+
+		request.Status = models.ShiftRequestApproved
+		if len(req.Shifts) > 0 && !matchesWindows(request, req.Shifts) {
+			request.Status = models.ShiftRequestPartiallyApproved
+		}
+		return reviewShiftRequest(r, &request, req.Reason, &res.ShiftRequest)
+	})
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "シフト希望が見つかりません",
+		})
+	}
+	if err != nil {
+		return respondError(c, err, "シフト希望の承認に失敗しました")
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// RejectShiftRequest シフト希望を理由を付けて却下
+func (h *Handler) RejectShiftRequest(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効なIDです",
+		})
+	}
+
+	var req models.RejectShiftRequestRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "リクエストの解析に失敗しました",
+		})
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "却下の理由を入力してください",
+		})
+	}
+	if len([]rune(req.Reason)) > maxShiftRequestNoteLength {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "理由は" + strconv.Itoa(maxShiftRequestNoteLength) + "文字以内で入力してください",
+		})
+	}
+
+	var rejected models.ShiftRequest
+	err = h.repos.InTx(func(r *repository.Repositories) error {
+		request, err := r.ShiftRequests.Get(id)
+		if err != nil {
+			return err
+		}
+		if request.Status != models.ShiftRequestSubmitted {
+			return &httpError{http.StatusConflict, "このシフト希望は既に承認・却下されています"}
+		}
+		request.Status = models.ShiftRequestRejected
+		return reviewShiftRequest(r, &request, req.Reason, &rejected)
+	})
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "シフト希望が見つかりません",
+		})
+	}
+	if err != nil {
+		return respondError(c, err, "シフト希望の却下に失敗しました")
+	}
+
+	return c.JSON(http.StatusOK, rejected)
+}
+
+// approvedShifts 承認して作成するシフトの時刻を決める
+// 指定がない場合は希望の時間帯ごとに休憩なし・ポジション未設定のシフトにする
+func approvedShifts(request models.ShiftRequest, shifts []models.ApprovedShift) ([]models.ApprovedShift, error) {
+	if request.Availability == models.ShiftRequestUnavailable {
+		if len(shifts) > 0 {
+			return nil, &httpError{http.StatusBadRequest, "勤務できない希望を承認する場合はシフトを指定しないでください"}
+		}
+		return nil, nil
+	}
+	if len(shifts) > 0 {
+		return shifts, nil
+	}
+	if request.Availability == models.ShiftRequestAllDay {
+		return nil, &httpError{http.StatusBadRequest, "終日勤務できる希望を承認する場合は、作成するシフトの時刻を指定してください"}
+	}
+
+	approved := make([]models.ApprovedShift, len(request.Windows))
+	for i, w := range request.Windows {
+		approved[i] = models.ApprovedShift{
+			StartTime:   w.StartTime,
+			EndTime:     w.EndTime,
+			EndsNextDay: w.EndsNextDay,
+		}
+	}
+	return approved, nil
+}
+
+// matchesWindows 指定したシフトの時刻が希望の時間帯と一致するか（終日勤務できる希望は常に一致とする）
+func matchesWindows(request models.ShiftRequest, shifts []models.ApprovedShift) bool {
+	if request.Availability == models.ShiftRequestAllDay {
+		return true
+	}
+	if len(shifts) != len(request.Windows) {
+		return false
+	}
+
+	type clockRange struct {
+		start, end  int
+		endsNextDay bool
+	}
+	toRange := func(start, end string, endsNextDay bool) (clockRange, bool) {
+		s, errStart := models.ParseClock(start)
+		e, errEnd := models.ParseClock(end)
+		return clockRange{s, e, endsNextDay}, errStart == nil && errEnd == nil
+	}
+
+	windows := make(map[clockRange]bool, len(request.Windows))
+	for _, w := range request.Windows {
+		if cr, ok := toRange(w.StartTime, w.EndTime, w.EndsNextDay); ok {
+			windows[cr] = true
+		}
+	}
+	for _, s := range shifts {
+		cr, ok := toRange(s.StartTime, s.EndTime, s.EndsNextDay)
+		if !ok || !windows[cr] {
+			return false
+		}
+	}
+	return true
+}
+
+// reviewShiftRequest 承認・却下の理由と日時を記録し、更新後のシフト希望を reviewed に設定する
+func reviewShiftRequest(r *repository.Repositories, request *models.ShiftRequest, reason string, reviewed *models.ShiftRequest) error {
+	now := time.Now()
+	request.ReviewReason = reason
+	request.ReviewedAt = &now
+	if err := r.ShiftRequests.Update(*request); err != nil {
+		return err
+	}
+	updated, err := r.ShiftRequests.Get(request.ID)
+	if err != nil {
+		return err
+	}
+	*reviewed = updated
+	return nil
+}
+
+// shiftRequestShiftError 作成できなかったシフトが何件目かをエラーメッセージに含める
+func shiftRequestShiftError(err error, index, total int) error {
+	he, ok := err.(*httpError)
+	if !ok || total == 1 {
+		return err
+	}
+	return &httpError{he.status, strconv.Itoa(index+1) + "件目のシフト: " + he.message}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"shift-management-backend/models"
+)
+
+func TestNewShift(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        models.CreateShiftRequest
+		wantStatus int // 0 の場合は作成できる
+	}{
+		{"休憩なし", models.CreateShiftRequest{Date: "2030-10-07", StartTime: "09:00", EndTime: "15:00"}, 0},
+		{"休憩あり", models.CreateShiftRequest{Date: "2030-10-07", StartTime: "09:00", EndTime: "18:00", BreakTime: 60}, 0},
+		{"日付をまたぐシフトの休憩", models.CreateShiftRequest{Date: "2030-10-07", StartTime: "22:00", EndTime: "06:00", EndsNextDay: true, BreakTime: 60}, 0},
+		{"勤務時間より1分短い休憩", models.CreateShiftRequest{Date: "2030-10-07", StartTime: "09:00", EndTime: "10:00", BreakTime: 59}, 0},
+		{"必須項目がない", models.CreateShiftRequest{Date: "2030-10-07", StartTime: "09:00"}, http.StatusBadRequest},
+		{"終了時刻が開始時刻より前", models.CreateShiftRequest{Date: "2030-10-07", StartTime: "15:00", EndTime: "09:00"}, http.StatusBadRequest},
+		{"休憩が負", models.CreateShiftRequest{Date: "2030-10-07", StartTime: "09:00", EndTime: "15:00", BreakTime: -1}, http.StatusBadRequest},
+		{"休憩が勤務時間と同じ", models.CreateShiftRequest{Date: "2030-10-07", StartTime: "09:00", EndTime: "10:00", BreakTime: 60}, http.StatusBadRequest},
+		{"休憩が勤務時間より長い", models.CreateShiftRequest{Date: "2030-10-07", StartTime: "22:00", EndTime: "02:00", EndsNextDay: true, BreakTime: 300}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newShift(tt.req)
+			var he *httpError
+			switch {
+			case tt.wantStatus == 0 && err != nil:
+				t.Errorf("作成できるはずです: %v", err)
+			case tt.wantStatus != 0 && (!errors.As(err, &he) || he.status != tt.wantStatus):
+				t.Errorf("err = %v, want status %d", err, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestUpdateShiftBreakTime(t *testing.T) {
+	h := newTestHandler(t)
+	employee := createTestEmployee(t, h, "山田")
+	shift := createTestShift(t, h, models.CreateShiftRequest{
+		EmployeeID: employee.ID,
+		Date:       "2030-10-07",
+		StartTime:  "09:00",
+		EndTime:    "13:00",
+	})
+	id := strconv.Itoa(shift.ID)
+
+	for _, breakTime := range []int{-15, 240, 300} {
+		breakTime := breakTime
+		code, body := serve(t, h.UpdateShift, http.MethodPut, "/api/shifts/"+id,
+			models.UpdateShiftRequest{BreakTime: &breakTime}, testOwner, "id", id)
+		if code != http.StatusBadRequest {
+			t.Errorf("休憩%d分: status = %d, want %d: %s", breakTime, code, http.StatusBadRequest, body)
+		}
+	}
+
+	// 終了時刻だけを変更して休憩時間が勤務時間以上になる場合も受け付けない
+	withBreak := 30
+	code, body := serve(t, h.UpdateShift, http.MethodPut, "/api/shifts/"+id,
+		models.UpdateShiftRequest{BreakTime: &withBreak}, testOwner, "id", id)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", code, http.StatusOK, body)
+	}
+	code, body = serve(t, h.UpdateShift, http.MethodPut, "/api/shifts/"+id,
+		models.UpdateShiftRequest{EndTime: "09:30"}, testOwner, "id", id)
+	if code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d: %s", code, http.StatusBadRequest, body)
+	}
+}
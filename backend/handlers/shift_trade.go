@@ -0,0 +1,659 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"shift-management-backend/models"
+	"shift-management-backend/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetShiftTrades シフト交代の一覧を取得
+// オーナーにはすべて（status で絞り込み可）、従業員には自分が募集・引き受けたものと、自分が引き受けられる募集中のものを返す
+func (h *Handler) GetShiftTrades(c echo.Context) error {
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+
+	var trades []models.ShiftTrade
+	var visible map[int]models.Shift
+	if acc.IsOwner() {
+		list, err := h.repos.ShiftTrades.List(repository.ShiftTradeFilter{Status: c.QueryParam("status")})
+		if err != nil {
+			return serverError(c, err, "シフト交代の取得に失敗しました")
+		}
+		trades = list
+	} else {
+		own, err := h.repos.ShiftTrades.List(repository.ShiftTradeFilter{EmployeeID: &acc.EmployeeID})
+		if err != nil {
+			return serverError(c, err, "シフト交代の取得に失敗しました")
+		}
+		open, err := h.repos.ShiftTrades.List(repository.ShiftTradeFilter{Status: models.ShiftTradeOpen})
+		if err != nil {
+			return serverError(c, err, "シフト交代の取得に失敗しました")
+		}
+
+		// 従業員には公開済みの内容のシフトを見せ、公開されていないシフトの募集は見せない
+		// 公開済みの内容は、対象のシフトの日付の範囲に重なる期間だけから読み込む
+		current := make(map[int]models.Shift)
+		var filter repository.ShiftFilter
+		for _, list := range [][]models.ShiftTrade{own, open} {
+			for _, trade := range list {
+				if _, ok := current[trade.ShiftID]; ok {
+					continue
+				}
+				shift, err := h.repos.Shifts.Get(trade.ShiftID)
+				if err == repository.ErrNotFound {
+					continue
+				}
+				if err != nil {
+					return serverError(c, err, "シフト交代の取得に失敗しました")
+				}
+				current[shift.ID] = shift
+				date := dateKey(shift.Date)
+				if filter.StartDate == "" || date < filter.StartDate {
+					filter.StartDate = date
+				}
+				if date > filter.EndDate {
+					filter.EndDate = date
+				}
+			}
+		}
+		visible = make(map[int]models.Shift, len(current))
+		if len(current) > 0 {
+			shifts, err := h.visibleShifts(acc, filter)
+			if err != nil {
+				return serverError(c, err, "シフト交代の取得に失敗しました")
+			}
+			for _, shift := range shifts {
+				visible[shift.ID] = shift
+			}
+		}
+
+		trades = own
+		for _, trade := range open {
+			if _, ok := visible[trade.ShiftID]; !ok || trade.OfferedBy == acc.EmployeeID {
+				continue
+			}
+			// 引き受けられるかは現在の内容で判定する
+			shift := current[trade.ShiftID]
+			// 交換の場合に手放すシフトは引き受けるときに選ぶため、ここでは考慮しない
+			_, err = h.checkShiftEligibility(h.repos, shift, acc.EmployeeID, 0)
+			if _, rejected := err.(*httpError); rejected {
+				continue
+			}
+			if err != nil {
+				return serverError(c, err, "シフト交代の取得に失敗しました")
+			}
+			trades = append(trades, trade)
+		}
+		sort.SliceStable(trades, func(i, j int) bool {
+			return trades[i].CreatedAt.After(trades[j].CreatedAt)
+		})
+	}
+
+	for i := range trades {
+		if !acc.IsOwner() {
+			if shift, ok := visible[trades[i].ShiftID]; ok {
+				trades[i].Shift = &shift
+			}
+			continue
+		}
+		shift, err := h.repos.Shifts.Get(trades[i].ShiftID)
+		if err != nil {
+			return serverError(c, err, "シフト交代の取得に失敗しました")
+		}
+		trades[i].Shift = &shift
+	}
+	if trades == nil {
+		trades = []models.ShiftTrade{}
+	}
+	return c.JSON(http.StatusOK, trades)
+}
+
+// CreateShiftTrade シフト交代（交換・譲渡）を募集する
+// 従業員は自分のシフトのみ募集できる。オーナーは従業員に代わって募集できる
+func (h *Handler) CreateShiftTrade(c echo.Context) error {
+	var req models.CreateShiftTradeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "リクエストの解析に失敗しました",
+		})
+	}
+
+	if req.ShiftID == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "シフトを指定してください",
+		})
+	}
+	if req.Type != models.ShiftTradeSwap && req.Type != models.ShiftTradeGiveaway {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "type は swap / giveaway のいずれかを指定してください",
+		})
+	}
+
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+
+	var trade models.ShiftTrade
+	err := h.repos.InTx(func(r *repository.Repositories) error {
+		shift, err := r.Shifts.Get(req.ShiftID)
+		if err == repository.ErrNotFound {
+			return &httpError{http.StatusNotFound, "シフトが見つかりません"}
+		}
+		if err != nil {
+			return err
+		}
+		if shift.IsOpen() {
+			return &httpError{http.StatusBadRequest, "担当者が決まっていないシフトは交代を募集できません"}
+		}
+		if !acc.IsOwner() && !acc.IsSelf(shift.EmployeeID) {
+			return &httpError{http.StatusForbidden, "自分のシフトのみ交代を募集できます"}
+		}
+		if err := checkShiftNotStarted(shift); err != nil {
+			return err
+		}
+
+		// 同時に募集しようとした場合に備えて担当者をロックしてから重複を確認する
+		if err := r.Employees.Lock(shift.EmployeeID); err != nil {
+			return err
+		}
+		if _, err := r.ShiftTrades.Active(shift.ID); err == nil {
+			return &httpError{http.StatusConflict, "このシフトは既に交代を募集しています"}
+		} else if err != repository.ErrNotFound {
+			return err
+		}
+
+		created, err := r.ShiftTrades.Create(models.ShiftTrade{
+			ShiftID:   shift.ID,
+			Type:      req.Type,
+			OfferedBy: shift.EmployeeID,
+			Status:    models.ShiftTradeOpen,
+			Note:      req.Note,
+		})
+		if err != nil {
+			return err
+		}
+		trade = created
+		return nil
+	})
+	if err != nil {
+		return respondError(c, err, "シフト交代の募集に失敗しました")
+	}
+
+	return c.JSON(http.StatusCreated, trade)
+}
+
+// AcceptShiftTrade 募集中のシフト交代を引き受ける
+// 交換の場合は手放す自分のシフトを指定する。自動承認が有効な場合はそのまま担当者を変更する
+func (h *Handler) AcceptShiftTrade(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効なIDです",
+		})
+	}
+
+	var req models.AcceptShiftTradeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "リクエストの解析に失敗しました",
+		})
+	}
+
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+	if acc.IsOwner() {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": "シフト交代は従業員のみ引き受けられます",
+		})
+	}
+
+	var trade models.ShiftTrade
+	err = h.repos.InTx(func(r *repository.Repositories) error {
+		// 同じ募集への並行した操作を直列化し、ロックした後の状態で確認する
+		if err := r.ShiftTrades.Lock(id); err != nil {
+			return err
+		}
+		var err error
+		trade, err = r.ShiftTrades.Get(id)
+		if err != nil {
+			return err
+		}
+		if trade.Status != models.ShiftTradeOpen {
+			return &httpError{http.StatusConflict, "このシフト交代は募集中ではありません"}
+		}
+		if trade.OfferedBy == acc.EmployeeID {
+			return &httpError{http.StatusBadRequest, "自分が募集したシフト交代は引き受けられません"}
+		}
+
+		trade.AcceptedBy = &acc.EmployeeID
+		trade.SwapShiftID = nil
+		if trade.Type == models.ShiftTradeSwap {
+			if req.SwapShiftID == nil {
+				return &httpError{http.StatusBadRequest, "交換する自分のシフトを指定してください"}
+			}
+			trade.SwapShiftID = req.SwapShiftID
+		}
+
+		warnings, err := h.checkTrade(r, trade)
+		if err != nil {
+			return err
+		}
+		trade.Status = models.ShiftTradeAccepted
+		if h.cfg.ShiftTrades.AutoApprove {
+			if err := h.executeTrade(r, &trade); err != nil {
+				return err
+			}
+		}
+		if err := r.ShiftTrades.Update(trade); err != nil {
+			return err
+		}
+		if trade, err = r.ShiftTrades.Get(id); err != nil {
+			return err
+		}
+		trade.Warnings = warnings
+		return nil
+	})
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "シフト交代が見つかりません",
+		})
+	}
+	if err != nil {
+		return respondError(c, err, "シフト交代の引き受けに失敗しました")
+	}
+
+	return c.JSON(http.StatusOK, trade)
+}
+
+// ApproveShiftTrade 引き受けられたシフト交代を承認し、シフトの担当者を変更する
+func (h *Handler) ApproveShiftTrade(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効なIDです",
+		})
+	}
+
+	var trade models.ShiftTrade
+	err = h.repos.InTx(func(r *repository.Repositories) error {
+		// 同じ募集への並行した操作を直列化し、ロックした後の状態で確認する
+		if err := r.ShiftTrades.Lock(id); err != nil {
+			return err
+		}
+		var err error
+		trade, err = r.ShiftTrades.Get(id)
+		if err != nil {
+			return err
+		}
+		if trade.Status != models.ShiftTradeAccepted {
+			return &httpError{http.StatusConflict, "承認待ちのシフト交代ではありません"}
+		}
+
+		// 引き受け後にシフトが変更されている場合があるため、改めて条件を確認する
+		warnings, err := h.checkTrade(r, trade)
+		if err != nil {
+			return err
+		}
+		if err := h.executeTrade(r, &trade); err != nil {
+			return err
+		}
+		if err := r.ShiftTrades.Update(trade); err != nil {
+			return err
+		}
+		if trade, err = r.ShiftTrades.Get(id); err != nil {
+			return err
+		}
+		trade.Warnings = warnings
+		return nil
+	})
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "シフト交代が見つかりません",
+		})
+	}
+	if err != nil {
+		return respondError(c, err, "シフト交代の承認に失敗しました")
+	}
+
+	return c.JSON(http.StatusOK, trade)
+}
+
+// RejectShiftTrade シフト交代を却下する
+func (h *Handler) RejectShiftTrade(c echo.Context) error {
+	return h.closeShiftTrade(c, models.ShiftTradeRejected)
+}
+
+// CancelShiftTrade シフト交代の募集を取り下げる（募集した従業員またはオーナー）
+func (h *Handler) CancelShiftTrade(c echo.Context) error {
+	return h.closeShiftTrade(c, models.ShiftTradeCancelled)
+}
+
+// closeShiftTrade 進行中のシフト交代を却下または取り下げる
+func (h *Handler) closeShiftTrade(c echo.Context, status string) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効なIDです",
+		})
+	}
+
+	var req models.DecideShiftTradeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "リクエストの解析に失敗しました",
+		})
+	}
+
+	acc, code, message := h.callerAccess(c)
+	if code != 0 {
+		return c.JSON(code, map[string]string{"error": message})
+	}
+
+	var trade models.ShiftTrade
+	err = h.repos.InTx(func(r *repository.Repositories) error {
+		// 同じ募集への並行した操作を直列化し、ロックした後の状態で確認する
+		if err := r.ShiftTrades.Lock(id); err != nil {
+			return err
+		}
+		var err error
+		trade, err = r.ShiftTrades.Get(id)
+		if err != nil {
+			return err
+		}
+		if !acc.IsOwner() && !acc.IsSelf(trade.OfferedBy) {
+			return &httpError{http.StatusForbidden, "この操作を行う権限がありません"}
+		}
+		if !trade.IsActive() {
+			return &httpError{http.StatusConflict, "このシフト交代は既に終了しています"}
+		}
+
+		now := time.Now()
+		trade.Status = status
+		trade.DecisionReason = req.Reason
+		trade.DecidedAt = &now
+		if err := r.ShiftTrades.Update(trade); err != nil {
+			return err
+		}
+		trade, err = r.ShiftTrades.Get(id)
+		return err
+	})
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "シフト交代が見つかりません",
+		})
+	}
+	if err != nil {
+		return respondError(c, err, "シフト交代の更新に失敗しました")
+	}
+
+	return c.JSON(http.StatusOK, trade)
+}
+
+// GetShiftHolders シフトの担当者の履歴を取得
+func (h *Handler) GetShiftHolders(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "無効なIDです",
+		})
+	}
+
+	acc, status, message := h.callerAccess(c)
+	if status != 0 {
+		return c.JSON(status, map[string]string{"error": message})
+	}
+
+	shift, err := h.visibleShift(acc, id)
+	if err == repository.ErrNotFound {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "シフトが見つかりません",
+		})
+	}
+	if err != nil {
+		return serverError(c, err, "シフトの取得に失敗しました")
+	}
+	if !shift.IsOpen() && !acc.CanViewShiftsOf(shift.EmployeeID) {
+		return forbidden(c)
+	}
+
+	holders, err := h.repos.Shifts.Holders(id)
+	if err != nil {
+		return serverError(c, err, "担当者の履歴の取得に失敗しました")
+	}
+	if holders == nil {
+		holders = []models.ShiftHolder{}
+	}
+	return c.JSON(http.StatusOK, holders)
+}
+
+// checkTrade 引き受けた従業員と募集した従業員をロックしたうえで、シフト交代が成立するか確認する
+//   - 募集したシフトの担当者が変わっていない
+//   - 交換の場合、差し出すシフトが引き受けた従業員のものである
+//   - 引き受けた従業員（交換の場合は募集した従業員も）が相手のシフトを担当できる
+//
+// 成立する場合は担当者を変更した後のシフトへの警告を返す
+func (h *Handler) checkTrade(r *repository.Repositories, trade models.ShiftTrade) ([]models.LaborViolation, error) {
+	if trade.AcceptedBy == nil {
+		return nil, &httpError{http.StatusConflict, "シフト交代を引き受けた従業員が削除されています"}
+	}
+	if err := lockEmployees(r, trade.OfferedBy, *trade.AcceptedBy); err != nil {
+		return nil, err
+	}
+
+	shift, err := r.Shifts.Get(trade.ShiftID)
+	if err != nil {
+		return nil, err
+	}
+	if shift.EmployeeID != trade.OfferedBy {
+		return nil, &httpError{http.StatusConflict, "募集後にシフトの担当者が変更されています"}
+	}
+	if err := checkShiftNotStarted(shift); err != nil {
+		return nil, err
+	}
+
+	if trade.Type != models.ShiftTradeSwap {
+		return h.checkShiftEligibility(r, shift, *trade.AcceptedBy, 0)
+	}
+
+	if trade.SwapShiftID == nil {
+		return nil, &httpError{http.StatusConflict, "交換するシフトが削除されています"}
+	}
+	swapShift, err := r.Shifts.Get(*trade.SwapShiftID)
+	if err == repository.ErrNotFound {
+		return nil, &httpError{http.StatusBadRequest, "交換するシフトが見つかりません"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if swapShift.ID == shift.ID || swapShift.EmployeeID != *trade.AcceptedBy {
+		return nil, &httpError{http.StatusBadRequest, "交換には自分のシフトを指定してください"}
+	}
+	if err := checkShiftNotStarted(swapShift); err != nil {
+		return nil, err
+	}
+	warnings, err := h.checkShiftEligibility(r, shift, *trade.AcceptedBy, swapShift.ID)
+	if err != nil {
+		return nil, err
+	}
+	swapWarnings, err := h.checkShiftEligibility(r, swapShift, trade.OfferedBy, shift.ID)
+	if err != nil {
+		return nil, err
+	}
+	return append(warnings, swapWarnings...), nil
+}
+
+// executeTrade シフトの担当者を変更し、シフト交代を承認済みにする（checkTrade で確認した後に呼ぶ）
+func (h *Handler) executeTrade(r *repository.Repositories, trade *models.ShiftTrade) error {
+	shift, err := r.Shifts.Get(trade.ShiftID)
+	if err != nil {
+		return err
+	}
+	reassigned := shift
+	reassigned.EmployeeID = *trade.AcceptedBy
+	if err := h.saveShift(r, shift, reassigned, trade.Type, &trade.ID); err != nil {
+		return err
+	}
+
+	if trade.Type == models.ShiftTradeSwap {
+		swapShift, err := r.Shifts.Get(*trade.SwapShiftID)
+		if err != nil {
+			return err
+		}
+		reassigned := swapShift
+		reassigned.EmployeeID = trade.OfferedBy
+		if err := h.saveShift(r, swapShift, reassigned, trade.Type, &trade.ID); err != nil {
+			return err
+		}
+
+		// 差し出したシフトで募集していた交代は担当者が変わったため取り下げる
+		other, err := r.ShiftTrades.Active(swapShift.ID)
+		if err != nil && err != repository.ErrNotFound {
+			return err
+		}
+		if err == nil {
+			now := time.Now()
+			other.Status = models.ShiftTradeCancelled
+			other.DecisionReason = "シフト交換で担当者が変わったため取り下げました"
+			other.DecidedAt = &now
+			if err := r.ShiftTrades.Update(other); err != nil {
+				return err
+			}
+		}
+	}
+
+	now := time.Now()
+	trade.Status = models.ShiftTradeApproved
+	trade.DecidedAt = &now
+	return nil
+}
+
+// checkShiftEligibility 従業員がシフトを引き受けられるか確認し、警告を返す（引き受けられない場合は httpError を返す）
+//   - シフトのポジションをすべて担当できる
+//   - 勤務時間が重なるシフトがない
+//   - 引き受けた後の週（日曜始まり）の勤務時間（休憩を除く）が上限以内
+//   - 引き受けた後のシフトが労働基準法に基づくルール（休息時間など）と勤務可能な時間帯でエラーにならない（409）
+//
+// exclude に指定したシフト（交換で手放すシフト）は従業員のシフトに含めずに判定する
+func (h *Handler) checkShiftEligibility(r *repository.Repositories, shift models.Shift, employeeID, exclude int) ([]models.LaborViolation, error) {
+	employee, err := r.Employees.Get(employeeID)
+	if err == repository.ErrNotFound {
+		return nil, &httpError{http.StatusBadRequest, "指定された従業員が存在しません"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, position := range shift.Positions() {
+		if !employee.IsQualifiedFor(position) {
+			return nil, &httpError{http.StatusBadRequest, employee.Name + "さんは「" + position + "」を担当できません"}
+		}
+	}
+
+	span, err := shift.Span()
+	if err != nil {
+		return nil, err
+	}
+	day, err := models.ParseDate(shift.Date)
+	if err != nil {
+		return nil, err
+	}
+	weekStart := day.AddDate(0, 0, -int(day.Weekday()))
+	weekEnd := weekStart.AddDate(0, 0, 6)
+
+	// 週全体と、日付をまたぐシフトを考慮して前後1日のシフトを確認する
+	shifts, err := r.Shifts.List(repository.ShiftFilter{
+		EmployeeID: &employeeID,
+		StartDate:  weekStart.AddDate(0, 0, -1).Format(models.DateLayout),
+		EndDate:    weekEnd.AddDate(0, 0, 1).Format(models.DateLayout),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hours := shiftNetHours(shift, span)
+	for _, other := range shifts {
+		if other.ID == shift.ID || other.ID == exclude {
+			continue
+		}
+		otherSpan, err := other.Span()
+		if err != nil {
+			continue
+		}
+		if span.Overlaps(otherSpan) {
+			return nil, &httpError{http.StatusConflict, employee.Name + "さんには勤務時間が重なるシフトがあります（" + otherSpan.String() + "）"}
+		}
+		otherDay, err := models.ParseDate(other.Date)
+		if err == nil && !otherDay.Before(weekStart) && !otherDay.After(weekEnd) {
+			hours += shiftNetHours(other, otherSpan)
+		}
+	}
+
+	if limit := h.cfg.Scheduling.MaxWeeklyHours; limit > 0 && hours > float64(limit) {
+		return nil, &httpError{http.StatusConflict, fmt.Sprintf("%sさんの週の勤務時間が上限（%d時間）を超えます（%.1f時間）", employee.Name, limit, hours)}
+	}
+
+	reassigned := shift
+	reassigned.EmployeeID = employeeID
+	reassigned.EmployeeName = employee.Name
+	warnings, err := h.checkShiftRules(r, reassigned, exclude)
+	if he, ok := err.(*httpError); ok {
+		return nil, &httpError{http.StatusConflict, employee.Name + "さんが担当すると次のルールに違反します: " + he.message}
+	}
+	return warnings, err
+}
+
+// shiftNetHours 休憩を除く勤務時間
+func shiftNetHours(shift models.Shift, span models.Span) float64 {
+	return span.Hours() - float64(shift.BreakTime)/60
+}
+
+// lockEmployees 従業員の行をID順にロックする（デッドロックを避けるため順序を揃える）
+func lockEmployees(r *repository.Repositories, ids ...int) error {
+	sorted := append([]int(nil), ids...)
+	sort.Ints(sorted)
+	for i, id := range sorted {
+		if i > 0 && id == sorted[i-1] {
+			continue
+		}
+		if err := r.Employees.Lock(id); err != nil {
+			if err == repository.ErrNotFound {
+				return &httpError{http.StatusConflict, "シフト交代の対象の従業員が削除されています"}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// checkShiftNotStarted 開始済みのシフトは交代できない
+func checkShiftNotStarted(shift models.Shift) error {
+	started, err := shiftStarted(shift)
+	if err != nil {
+		return err
+	}
+	if started {
+		span, _ := shift.Span()
+		return &httpError{http.StatusConflict, "開始済みのシフトは交代できません（" + span.String() + "）"}
+	}
+	return nil
+}
+
+// shiftStarted シフトの開始時刻を過ぎているか
+func shiftStarted(shift models.Shift) (bool, error) {
+	span, err := shift.Span()
+	if err != nil {
+		return false, err
+	}
+	return !span.Start.After(wallClock(time.Now())), nil
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+
+	"shift-management-backend/models"
+)
+
+// employeeUser 従業員としてログインしたユーザー
+func employeeUser(employee models.Employee) models.User {
+	id := employee.ID
+	return models.User{ID: 100 + employee.ID, Role: "employee", EmployeeID: &id}
+}
+
+func TestAcceptShiftTradeOnlyOnce(t *testing.T) {
+	h := newTestHandler(t)
+	offerer := createTestEmployee(t, h, "山田")
+	shift := createTestShift(t, h, models.CreateShiftRequest{
+		EmployeeID: offerer.ID,
+		Date:       "2030-10-07",
+		StartTime:  "10:00",
+		EndTime:    "14:00",
+	})
+	trade, err := h.repos.ShiftTrades.Create(models.ShiftTrade{
+		ShiftID:   shift.ID,
+		Type:      models.ShiftTradeGiveaway,
+		OfferedBy: offerer.ID,
+		Status:    models.ShiftTradeOpen,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 2人が同時に引き受けても、引き受けられるのは1人だけ
+	acceptors := []models.Employee{createTestEmployee(t, h, "佐藤"), createTestEmployee(t, h, "鈴木")}
+	codes := make([]int, len(acceptors))
+	var wg sync.WaitGroup
+	for i, acceptor := range acceptors {
+		wg.Add(1)
+		go func(i int, acceptor models.Employee) {
+			defer wg.Done()
+			path := "/api/shift-trades/" + strconv.Itoa(trade.ID) + "/accept"
+			codes[i], _ = serve(t, h.AcceptShiftTrade, http.MethodPost, path, models.AcceptShiftTradeRequest{},
+				employeeUser(acceptor), "id", strconv.Itoa(trade.ID))
+		}(i, acceptor)
+	}
+	wg.Wait()
+
+	winner := -1
+	for i, code := range codes {
+		switch code {
+		case http.StatusOK:
+			if winner >= 0 {
+				t.Fatalf("2人とも引き受けられました: %v", codes)
+			}
+			winner = i
+		case http.StatusConflict:
+		default:
+			t.Fatalf("status = %v, want 200 と 409", codes)
+		}
+	}
+	if winner < 0 {
+		t.Fatalf("どちらも引き受けられませんでした: %v", codes)
+	}
+
+	got, err := h.repos.ShiftTrades.Get(trade.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != models.ShiftTradeAccepted || got.AcceptedBy == nil || *got.AcceptedBy != acceptors[winner].ID {
+		t.Errorf("先に引き受けた従業員のまま変わらないはずです: %+v", got)
+	}
+}
+
+func TestAcceptShiftTradeChecksRestInterval(t *testing.T) {
+	h := newTestHandler(t)
+	offerer := createTestEmployee(t, h, "山田")
+	acceptor := createTestEmployee(t, h, "佐藤")
+
+	// 引き受けると前日の勤務の終了から10時間しか空かない（必要な休息時間は11時間）
+	offered := createTestShift(t, h, models.CreateShiftRequest{EmployeeID: offerer.ID, Date: "2030-10-08", StartTime: "09:00", EndTime: "13:00"})
+	own := createTestShift(t, h, models.CreateShiftRequest{EmployeeID: acceptor.ID, Date: "2030-10-07", StartTime: "17:00", EndTime: "23:00"})
+
+	accept := func(tradeType string, swapShiftID *int) (int, []byte) {
+		t.Helper()
+		trade, err := h.repos.ShiftTrades.Create(models.ShiftTrade{
+			ShiftID:   offered.ID,
+			Type:      tradeType,
+			OfferedBy: offerer.ID,
+			Status:    models.ShiftTradeOpen,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		id := strconv.Itoa(trade.ID)
+		return serve(t, h.AcceptShiftTrade, http.MethodPost, "/api/shift-trades/"+id+"/accept",
+			models.AcceptShiftTradeRequest{SwapShiftID: swapShiftID}, employeeUser(acceptor), "id", id)
+	}
+
+	code, body := accept(models.ShiftTradeGiveaway, nil)
+	if code != http.StatusConflict {
+		t.Fatalf("休息時間が足りない場合は引き受けられないはずです: status = %d: %s", code, body)
+	}
+	if got, err := h.repos.Shifts.Get(offered.ID); err != nil || got.EmployeeID != offerer.ID {
+		t.Fatalf("担当者は変わらないはずです: %+v, %v", got, err)
+	}
+
+	// 交換で手放すシフトは休息時間の確認に含めない
+	code, body = accept(models.ShiftTradeSwap, &own.ID)
+	if code != http.StatusOK {
+		t.Fatalf("手放すシフトとの間の休息時間は確認しないはずです: status = %d: %s", code, body)
+	}
+}
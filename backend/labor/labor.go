@@ -0,0 +1,334 @@
+// Package labor 労働基準法に基づいてシフトを確認する
+//
+// 休憩時間（6時間超で45分、8時間超で60分）、1日・1週間の勤務時間の上限、
+// 毎週1日の休日、勤務日が異なるシフトの間の休息時間（勤務間インターバル）をルールとして、
+// ルールごとに設定した扱い（エラー・警告）で違反を返す。
+package labor
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"shift-management-backend/models"
+)
+
+// Rules 確認するルールと上限
+type Rules struct {
+	// DailyHours 1日の勤務時間（休憩を除く）の上限
+	DailyHours int
+	// WeeklyHours 1週間（日曜始まり）の勤務時間（休憩を除く）の上限
+	WeeklyHours int
+	// MinRest 勤務日が異なるシフトの間に必要な休息時間（同じ日の分割シフトには適用しない）
+	MinRest time.Duration
+	// Severity ルールごとの扱い（指定がないルールは確認しない）
+	Severity map[string]string
+}
+
+// severity ルールの扱い。確認しないルールは空文字
+func (r Rules) severity(rule string) string {
+	s := r.Severity[rule]
+	if s == models.LaborSeverityOff {
+		return ""
+	}
+	return s
+}
+
+// work 従業員の勤務区間
+type work struct {
+	shift models.Shift
+	date  string // 勤務日（"2006-01-02"）
+	span  models.Span
+}
+
+// minutes 休憩を除く勤務時間（分）
+func (w work) minutes() int {
+	return int(w.span.Duration().Minutes()) - w.shift.BreakTime
+}
+
+// Check シフトのルール違反を返す
+// 週のルールは日曜から土曜までのシフトがすべて渡されている前提で判定する
+// （日付をまたぐシフトを考慮し、前週の土曜のシフトも渡す）
+// 休息時間は渡されたシフトのうち連続するものの間で判定する
+// 担当者が決まっていないシフトと、日付・時刻が不正なシフトは対象にしない
+func Check(rules Rules, shifts []models.Shift) []models.LaborViolation {
+	employeeIDs, byEmployee := worksByEmployee(shifts)
+
+	var violations []models.LaborViolation
+	for _, id := range employeeIDs {
+		works := byEmployee[id]
+		violations = append(violations, checkBreaks(rules, works)...)
+		violations = append(violations, checkDailyHours(rules, works)...)
+		violations = append(violations, checkWeeks(rules, works)...)
+		violations = append(violations, checkRestIntervals(rules, works)...)
+	}
+	return violations
+}
+
+// worksByEmployee シフトを従業員ごとの開始日時順の勤務区間にまとめる（従業員IDは昇順で返す）
+// 担当者が決まっていないシフトと、日付・時刻が不正なシフトは含めない
+func worksByEmployee(shifts []models.Shift) ([]int, map[int][]work) {
+	byEmployee := make(map[int][]work)
+	var employeeIDs []int
+	for _, shift := range shifts {
+		if shift.IsOpen() {
+			continue
+		}
+		span, err := shift.Span()
+		if err != nil {
+			continue
+		}
+		if _, ok := byEmployee[shift.EmployeeID]; !ok {
+			employeeIDs = append(employeeIDs, shift.EmployeeID)
+		}
+		byEmployee[shift.EmployeeID] = append(byEmployee[shift.EmployeeID], work{
+			shift: shift,
+			date:  span.Start.Format(models.DateLayout),
+			span:  span,
+		})
+	}
+	sort.Ints(employeeIDs)
+	for _, works := range byEmployee {
+		sort.Slice(works, func(i, j int) bool {
+			return works[i].span.Start.Before(works[j].span.Start)
+		})
+	}
+	return employeeIDs, byEmployee
+}
+
+// checkBreaks シフトごとの休憩時間を確認する
+func checkBreaks(rules Rules, works []work) []models.LaborViolation {
+	var violations []models.LaborViolation
+	for _, w := range works {
+		minutes := w.minutes()
+		var rule string
+		var required int
+		switch {
+		case minutes > 8*60 && w.shift.BreakTime < 60:
+			rule, required = models.LaborRuleBreakOver8h, 60
+		case minutes > 6*60 && w.shift.BreakTime < 45:
+			rule, required = models.LaborRuleBreakOver6h, 45
+		default:
+			continue
+		}
+		severity := rules.severity(rule)
+		if severity == "" {
+			continue
+		}
+		violations = append(violations, violation(rule, severity, w.date, []work{w},
+			fmt.Sprintf("%s の勤務（%s）は休憩が%d分以上必要です（休憩%d分）",
+				w.date, formatMinutes(minutes), required, w.shift.BreakTime)))
+	}
+	return violations
+}
+
+// checkDailyHours 勤務日ごとの勤務時間を確認する
+// 日付をまたぐシフトは開始した日の勤務として数える
+func checkDailyHours(rules Rules, works []work) []models.LaborViolation {
+	severity := rules.severity(models.LaborRuleDailyLimit)
+	if severity == "" || rules.DailyHours <= 0 {
+		return nil
+	}
+
+	var violations []models.LaborViolation
+	for _, group := range groupBy(works, func(w work) string { return w.date }) {
+		total := 0
+		for _, w := range group.works {
+			total += w.minutes()
+		}
+		if total > rules.DailyHours*60 {
+			violations = append(violations, violation(models.LaborRuleDailyLimit, severity, group.key, group.works,
+				fmt.Sprintf("%s の勤務時間（%s）が1日の上限（%d時間）を超えています",
+					group.key, formatMinutes(total), rules.DailyHours)))
+		}
+	}
+	return violations
+}
+
+// checkWeeks 週（日曜始まり）ごとの勤務時間と休日を確認する
+func checkWeeks(rules Rules, works []work) []models.LaborViolation {
+	limitSeverity := rules.severity(models.LaborRuleWeeklyLimit)
+	if rules.WeeklyHours <= 0 {
+		limitSeverity = ""
+	}
+	restSeverity := rules.severity(models.LaborRuleWeeklyRestDay)
+	if limitSeverity == "" && restSeverity == "" {
+		return nil
+	}
+
+	var violations []models.LaborViolation
+	for _, group := range groupBy(works, func(w work) string { return WeekStart(w.span.Start).Format(models.DateLayout) }) {
+		if limitSeverity != "" {
+			total := 0
+			for _, w := range group.works {
+				total += w.minutes()
+			}
+			if total > rules.WeeklyHours*60 {
+				violations = append(violations, violation(models.LaborRuleWeeklyLimit, limitSeverity, group.key, group.works,
+					fmt.Sprintf("%s からの週の勤務時間（%s）が1週間の上限（%d時間）を超えています",
+						group.key, formatMinutes(total), rules.WeeklyHours)))
+			}
+		}
+		if restSeverity != "" && !hasRestDay(group.key, works) {
+			// 前週から日付をまたいで勤務したシフトも休日をなくす原因になる
+			weekWorks := touching(group.key, works)
+			violations = append(violations, violation(models.LaborRuleWeeklyRestDay, restSeverity, group.key, weekWorks,
+				fmt.Sprintf("%s からの週に休日（勤務のない日）がありません", group.key)))
+		}
+	}
+	return violations
+}
+
+// checkRestIntervals 勤務日が異なる連続したシフトの間の休息時間を確認する
+func checkRestIntervals(rules Rules, works []work) []models.LaborViolation {
+	severity := rules.severity(models.LaborRuleRestInterval)
+	if severity == "" || rules.MinRest <= 0 {
+		return nil
+	}
+
+	var violations []models.LaborViolation
+	for _, pair := range shortRests(rules.MinRest, works) {
+		prev, next := pair[0], pair[1]
+		rest := int(next.span.Start.Sub(prev.span.End).Minutes())
+		violations = append(violations, violation(models.LaborRuleRestInterval, severity, next.date, pair[:],
+			fmt.Sprintf("%s の勤務の前の休息時間（%s）が必要な休息時間（%s）より短くなっています（前の勤務: %s）",
+				next.date, formatMinutes(rest), formatMinutes(int(rules.MinRest.Minutes())), prev.span.String())))
+	}
+	return violations
+}
+
+// ShortRests 従業員ごとに、勤務日が異なる連続したシフトのうち間の休息時間が minRest に満たない組を返す
+// 担当者が決まっていないシフトと、日付・時刻が不正なシフトは対象にしない
+func ShortRests(minRest time.Duration, shifts []models.Shift) []models.RestIntervalViolation {
+	if minRest <= 0 {
+		return nil
+	}
+
+	employeeIDs, byEmployee := worksByEmployee(shifts)
+
+	var result []models.RestIntervalViolation
+	for _, id := range employeeIDs {
+		works := byEmployee[id]
+		for _, pair := range shortRests(minRest, works) {
+			prev, next := pair[0], pair[1]
+			result = append(result, models.RestIntervalViolation{
+				EmployeeID:   id,
+				EmployeeName: next.shift.EmployeeName,
+				Previous:     prev.shift,
+				Next:         next.shift,
+				RestMinutes:  int(next.span.Start.Sub(prev.span.End).Minutes()),
+			})
+		}
+	}
+	return result
+}
+
+// shortRests 開始日時順の勤務区間のうち、勤務日が異なり間の休息時間が minRest に満たない連続した組
+// 重なっている勤務区間は対象にしない
+func shortRests(minRest time.Duration, works []work) [][2]work {
+	var pairs [][2]work
+	for i := 1; i < len(works); i++ {
+		prev, next := works[i-1], works[i]
+		if prev.date == next.date || next.span.Start.Before(prev.span.End) {
+			continue
+		}
+		if next.span.Start.Sub(prev.span.End) < minRest {
+			pairs = append(pairs, [2]work{prev, next})
+		}
+	}
+	return pairs
+}
+
+// hasRestDay 週（日曜始まり）に勤務のない日（0時から24時まで）があるか
+func hasRestDay(week string, works []work) bool {
+	start, err := models.ParseDate(week)
+	if err != nil {
+		return true
+	}
+	for i := 0; i < 7; i++ {
+		day := models.Span{Start: start.AddDate(0, 0, i), End: start.AddDate(0, 0, i+1)}
+		worked := false
+		for _, w := range works {
+			if w.span.Overlaps(day) {
+				worked = true
+				break
+			}
+		}
+		if !worked {
+			return true
+		}
+	}
+	return false
+}
+
+// touching 週（日曜始まり）の日に勤務しているシフト
+func touching(week string, works []work) []work {
+	start, err := models.ParseDate(week)
+	if err != nil {
+		return nil
+	}
+	weekSpan := models.Span{Start: start, End: start.AddDate(0, 0, 7)}
+	var result []work
+	for _, w := range works {
+		if w.span.Overlaps(weekSpan) {
+			result = append(result, w)
+		}
+	}
+	return result
+}
+
+// workGroup キーごとにまとめた勤務区間
+type workGroup struct {
+	key   string
+	works []work
+}
+
+// groupBy 勤務区間をキーごとにまとめる（開始日時順の勤務区間を渡すとキーも昇順になる）
+func groupBy(works []work, key func(work) string) []workGroup {
+	var groups []workGroup
+	index := make(map[string]int)
+	for _, w := range works {
+		k := key(w)
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, workGroup{key: k})
+		}
+		groups[i].works = append(groups[i].works, w)
+	}
+	return groups
+}
+
+// violation 違反を作る
+func violation(rule, severity, date string, works []work, message string) models.LaborViolation {
+	v := models.LaborViolation{
+		Rule:     rule,
+		Severity: severity,
+		Date:     date,
+		ShiftIDs: make([]int, 0, len(works)),
+		Message:  message,
+	}
+	for _, w := range works {
+		v.EmployeeID = w.shift.EmployeeID
+		if w.shift.EmployeeName != "" {
+			v.EmployeeName = w.shift.EmployeeName
+		}
+		v.ShiftIDs = append(v.ShiftIDs, w.shift.ID)
+	}
+	return v
+}
+
+// WeekStart 日時を含む週の日曜日
+func WeekStart(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return day.AddDate(0, 0, -int(day.Weekday()))
+}
+
+// formatMinutes 分を "8時間30分" の形式にする
+func formatMinutes(minutes int) string {
+	if minutes%60 == 0 {
+		return fmt.Sprintf("%d時間", minutes/60)
+	}
+	return fmt.Sprintf("%d時間%d分", minutes/60, minutes%60)
+}
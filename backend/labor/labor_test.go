@@ -0,0 +1,243 @@
+package labor
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"shift-management-backend/models"
+)
+
+// allRules すべてのルールをエラーとして確認する
+func allRules() Rules {
+	return Rules{
+		DailyHours:  8,
+		WeeklyHours: 40,
+		MinRest:     11 * time.Hour,
+		Severity: map[string]string{
+			models.LaborRuleBreakOver6h:   models.LaborSeverityError,
+			models.LaborRuleBreakOver8h:   models.LaborSeverityError,
+			models.LaborRuleDailyLimit:    models.LaborSeverityError,
+			models.LaborRuleWeeklyLimit:   models.LaborSeverityError,
+			models.LaborRuleWeeklyRestDay: models.LaborSeverityError,
+			models.LaborRuleRestInterval:  models.LaborSeverityError,
+		},
+	}
+}
+
+// only 指定したルールだけを確認する
+func only(rules Rules, rule string) Rules {
+	rules.Severity = map[string]string{rule: models.LaborSeverityError}
+	return rules
+}
+
+// shift テスト用のシフト（従業員1）
+func shift(id int, date, start, end string, endsNextDay bool, breakTime int) models.Shift {
+	return models.Shift{
+		ID:          id,
+		EmployeeID:  1,
+		Date:        date,
+		StartTime:   start,
+		EndTime:     end,
+		EndsNextDay: endsNextDay,
+		BreakTime:   breakTime,
+	}
+}
+
+// rulesOf 違反したルール（昇順）
+func rulesOf(violations []models.LaborViolation) []string {
+	rules := []string{}
+	for _, v := range violations {
+		rules = append(rules, v.Rule)
+	}
+	sort.Strings(rules)
+	return rules
+}
+
+func TestCheckBreaks(t *testing.T) {
+	rules := allRules()
+	rules.Severity = map[string]string{
+		models.LaborRuleBreakOver6h: models.LaborSeverityError,
+		models.LaborRuleBreakOver8h: models.LaborSeverityError,
+	}
+
+	tests := []struct {
+		name  string
+		shift models.Shift
+		want  []string
+	}{
+		{"ちょうど6時間は休憩不要", shift(1, "2030-10-07", "09:00", "15:00", false, 0), []string{}},
+		{"6時間1分は45分必要", shift(1, "2030-10-07", "09:00", "15:01", false, 0), []string{models.LaborRuleBreakOver6h}},
+		{"6時間超で44分は不足", shift(1, "2030-10-07", "09:00", "15:45", false, 44), []string{models.LaborRuleBreakOver6h}},
+		{"6時間超で45分なら足りる", shift(1, "2030-10-07", "09:00", "16:00", false, 45), []string{}},
+		{"休憩を除いてちょうど8時間なら45分で足りる", shift(1, "2030-10-07", "09:00", "17:45", false, 45), []string{}},
+		{"休憩を除いて8時間超は60分必要", shift(1, "2030-10-07", "09:00", "17:46", false, 45), []string{models.LaborRuleBreakOver8h}},
+		{"8時間超で60分なら足りる", shift(1, "2030-10-07", "09:00", "18:30", false, 60), []string{}},
+		{"日付をまたぐシフトも勤務時間で判定する", shift(1, "2030-10-07", "22:00", "07:00", true, 0), []string{models.LaborRuleBreakOver8h}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rulesOf(Check(rules, []models.Shift{tt.shift}))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("違反 = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckDailyHours(t *testing.T) {
+	rules := only(allRules(), models.LaborRuleDailyLimit)
+
+	tests := []struct {
+		name   string
+		shifts []models.Shift
+		want   []string
+	}{
+		{"休憩を除いてちょうど8時間", []models.Shift{shift(1, "2030-10-07", "09:00", "18:00", false, 60)}, []string{}},
+		{"休憩を除いて8時間1分", []models.Shift{shift(1, "2030-10-07", "09:00", "18:01", false, 60)}, []string{models.LaborRuleDailyLimit}},
+		{"同じ日の分割シフトは合計で判定する", []models.Shift{
+			shift(1, "2030-10-07", "08:00", "13:00", false, 0),
+			shift(2, "2030-10-07", "17:00", "21:30", false, 0),
+		}, []string{models.LaborRuleDailyLimit}},
+		{"日付をまたぐシフトは開始した日の勤務として数える", []models.Shift{
+			shift(1, "2030-10-07", "20:00", "02:00", true, 0),
+			shift(2, "2030-10-08", "10:00", "16:00", false, 0),
+		}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rulesOf(Check(rules, tt.shifts))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("違反 = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// week 日曜から指定した曜日に同じ時刻のシフトを作る（2030-10-06 は日曜日）
+func week(weekdays []int, start, end string, breakTime int) []models.Shift {
+	base, _ := models.ParseDate("2030-10-06")
+	var shifts []models.Shift
+	for i, d := range weekdays {
+		shifts = append(shifts, shift(i+1, base.AddDate(0, 0, d).Format(models.DateLayout), start, end, false, breakTime))
+	}
+	return shifts
+}
+
+func TestCheckWeeklyHours(t *testing.T) {
+	rules := only(allRules(), models.LaborRuleWeeklyLimit)
+
+	tests := []struct {
+		name   string
+		shifts []models.Shift
+		want   []string
+	}{
+		{"週5日8時間でちょうど40時間", week([]int{1, 2, 3, 4, 5}, "09:00", "18:00", 60), []string{}},
+		{"40時間を超える", week([]int{1, 2, 3, 4, 5, 6}, "09:00", "18:00", 60), []string{models.LaborRuleWeeklyLimit}},
+		{"土曜と翌週の日曜は別の週として数える", append(
+			week([]int{1, 2, 3, 4, 5}, "09:00", "18:00", 60),
+			shift(10, "2030-10-13", "09:00", "18:00", false, 60),
+		), []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rulesOf(Check(rules, tt.shifts))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("違反 = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	violations := Check(rules, week([]int{1, 2, 3, 4, 5, 6}, "09:00", "18:00", 60))
+	if len(violations) != 1 || violations[0].Date != "2030-10-06" || len(violations[0].ShiftIDs) != 6 {
+		t.Errorf("週の違反は週の開始日（日曜）と週のシフトすべてを返すはずです: %+v", violations)
+	}
+}
+
+func TestCheckWeeklyRestDay(t *testing.T) {
+	rules := only(allRules(), models.LaborRuleWeeklyRestDay)
+
+	tests := []struct {
+		name   string
+		shifts []models.Shift
+		want   []string
+	}{
+		{"日曜から土曜まで毎日勤務", week([]int{0, 1, 2, 3, 4, 5, 6}, "10:00", "14:00", 0), []string{models.LaborRuleWeeklyRestDay}},
+		{"週に1日休みがある", week([]int{0, 1, 2, 3, 4, 5}, "10:00", "14:00", 0), []string{}},
+		{"前週の土曜から日付をまたいだ勤務で日曜の休みがなくなる", append(
+			week([]int{1, 2, 3, 4, 5, 6}, "10:00", "14:00", 0),
+			shift(10, "2030-10-05", "22:00", "02:00", true, 0),
+		), []string{models.LaborRuleWeeklyRestDay}},
+		{"土曜から日付をまたぐ勤務で土曜の休みがなくなる", append(
+			week([]int{0, 1, 2, 3, 4, 5}, "10:00", "14:00", 0),
+			shift(10, "2030-10-12", "22:00", "02:00", true, 0),
+		), []string{models.LaborRuleWeeklyRestDay}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rulesOf(Check(rules, tt.shifts))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("違反 = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckRestIntervals(t *testing.T) {
+	rules := only(allRules(), models.LaborRuleRestInterval)
+
+	tests := []struct {
+		name   string
+		shifts []models.Shift
+		want   []string
+	}{
+		{"ちょうど11時間空いている", []models.Shift{
+			shift(1, "2030-10-07", "13:00", "22:00", false, 60),
+			shift(2, "2030-10-08", "09:00", "13:00", false, 0),
+		}, []string{}},
+		{"11時間に1分足りない", []models.Shift{
+			shift(1, "2030-10-07", "13:00", "22:01", false, 60),
+			shift(2, "2030-10-08", "09:00", "13:00", false, 0),
+		}, []string{models.LaborRuleRestInterval}},
+		{"同じ日の分割シフトには適用しない", []models.Shift{
+			shift(1, "2030-10-07", "08:00", "11:00", false, 0),
+			shift(2, "2030-10-07", "17:00", "20:00", false, 0),
+		}, []string{}},
+		{"日付をまたぐシフトの終了から数える", []models.Shift{
+			shift(1, "2030-10-07", "22:00", "06:00", true, 60),
+			shift(2, "2030-10-08", "15:00", "20:00", false, 0),
+		}, []string{models.LaborRuleRestInterval}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rulesOf(Check(rules, tt.shifts))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("違反 = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckSeverity(t *testing.T) {
+	shifts := []models.Shift{shift(1, "2030-10-07", "09:00", "19:00", false, 0)}
+
+	rules := allRules()
+	rules.Severity[models.LaborRuleDailyLimit] = models.LaborSeverityWarning
+	rules.Severity[models.LaborRuleBreakOver8h] = models.LaborSeverityOff
+
+	violations := Check(rules, shifts)
+	if len(violations) != 1 {
+		t.Fatalf("確認しないルールの違反は返さないはずです: %+v", violations)
+	}
+	if v := violations[0]; v.Rule != models.LaborRuleDailyLimit || v.Severity != models.LaborSeverityWarning {
+		t.Errorf("ルールごとの扱いで違反を返すはずです: %+v", v)
+	}
+
+	// 担当者が決まっていないシフトは対象にしない
+	open := shifts[0]
+	open.EmployeeID = 0
+	if violations := Check(allRules(), []models.Shift{open}); len(violations) != 0 {
+		t.Errorf("担当者が決まっていないシフトは確認しないはずです: %+v", violations)
+	}
+}
@@ -1,31 +1,89 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"shift-management-backend/config"
 	"shift-management-backend/database"
 	"shift-management-backend/handlers"
+	"shift-management-backend/repository"
+	"shift-management-backend/token"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
 
 func main() {
+	// 設定の読み込み
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "設定ファイル（YAML）のパス")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	// データベース接続の初期化
 	log.Println("データベース接続を初期化中...")
-	if err := database.InitDB(); err != nil {
+	db, err := database.Connect(cfg.Database)
+	if err != nil {
 		log.Fatal("データベース接続エラー:", err)
 	}
-	defer database.CloseDB()
+	defer db.Close()
 
-	// テーブルの作成
-	log.Println("データベーステーブルを作成中...")
-	if err := database.CreateTables(); err != nil {
-		log.Fatal("テーブル作成エラー:", err)
+	// マイグレーションサブコマンド（migrate status|up|down [steps]）
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrateCommand(db, flag.Args()[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// マイグレーションの適用
+	log.Println("データベースマイグレーションを適用中...")
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		log.Fatal("マイグレーション読み込みエラー:", err)
+	}
+	applied, err := migrator.Up(context.Background())
+	if err != nil {
+		log.Fatal("マイグレーションエラー:", err)
 	}
-	log.Println("データベーステーブルの作成が完了しました")
+	log.Printf("マイグレーションの適用が完了しました（%d件）", applied)
+
+	// セッションストアの設定
+	sessionStore := database.NewPostgresSessionStore(db, database.SessionOptions{
+		IdleTimeout:     cfg.Auth.SessionIdleTimeout.Duration,
+		AbsoluteTimeout: cfg.Auth.SessionAbsoluteTimeout.Duration,
+	})
+	go purgeExpiredSessions(sessionStore)
+
+	// アクセストークン署名鍵の設定
+	signer, err := token.NewSigner(cfg.Auth.SigningKeyBytes(), cfg.Auth.ActiveKeyID, cfg.Auth.AccessTokenTTL.Duration)
+	if err != nil {
+		log.Fatal("署名鍵の設定エラー:", err)
+	}
+
+	// ハンドラーの作成（データアクセスはリポジトリ経由）
+	h := handlers.New(repository.NewPostgres(db), sessionStore, signer, cfg)
+
+	// 停止したサーバーで中断した自動シフト作成ジョブを失敗にする
+	if failed, err := h.FailInterruptedScheduleJobs(); err != nil {
+		log.Fatal("自動シフト作成ジョブの更新エラー:", err)
+	} else if failed > 0 {
+		log.Printf("中断した自動シフト作成ジョブを%d件失敗にしました", failed)
+	}
+	go failInterruptedScheduleJobs(h)
+
+	// シフト希望の提出リマインド
+	go remindShiftRequests(h)
 
 	// Echoインスタンスの作成
 	e := echo.New()
@@ -36,7 +94,7 @@ func main() {
 
 	// CORS設定を詳細に設定
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins:     []string{"http://localhost:3000", "http://127.0.0.1:3000", "null"},
+		AllowOrigins:     cfg.Server.AllowedOrigins,
 		AllowMethods:     []string{http.MethodGet, http.MethodHead, http.MethodPut, http.MethodPatch, http.MethodPost, http.MethodDelete, http.MethodOptions},
 		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
 		AllowCredentials: true,
@@ -56,99 +114,255 @@ func main() {
 	})
 
 	// APIルートの設定
+	// /health と /api/auth/login, /api/auth/refresh 以外は認証が必要
 	api := e.Group("/api")
+	owner := handlers.RequireOwner
+	employee := handlers.RequireEmployee
 
 	// 従業員管理API
-	employees := api.Group("/employees")
-	employees.GET("", handlers.GetEmployees)          // 従業員一覧取得
-	employees.GET("/:id", handlers.GetEmployee)       // 従業員詳細取得
-	employees.POST("", handlers.CreateEmployee)       // 従業員作成
-	employees.PUT("/:id", handlers.UpdateEmployee)    // 従業員更新
-	employees.DELETE("/:id", handlers.DeleteEmployee) // 従業員削除
+	employees := api.Group("/employees", h.RequireAuth)
+	employees.GET("", h.GetEmployees, employee)       // 従業員一覧取得
+	employees.GET("/:id", h.GetEmployee, employee)    // 従業員詳細取得
+	employees.POST("", h.CreateEmployee, owner)       // 従業員作成
+	employees.PUT("/:id", h.UpdateEmployee, owner)    // 従業員更新
+	employees.DELETE("/:id", h.DeleteEmployee, owner) // 従業員削除
 
 	// シフト管理API
-	shifts := api.Group("/shifts")
-	shifts.GET("", handlers.GetShifts)              // シフト一覧取得
-	shifts.GET("/month", handlers.GetShiftsByMonth) // 月別シフト取得
-	shifts.GET("/:id", handlers.GetShift)           // シフト詳細取得
-	shifts.POST("", handlers.CreateShift)           // シフト作成
-	shifts.PUT("/:id", handlers.UpdateShift)        // シフト更新
-	shifts.DELETE("/:id", handlers.DeleteShift)     // シフト削除
+	shifts := api.Group("/shifts", h.RequireAuth)
+	shifts.GET("", h.GetShifts, employee)                   // シフト一覧取得
+	shifts.GET("/month", h.GetShiftsByMonth, employee)      // 月別シフト取得
+	shifts.GET("/:id", h.GetShift, employee)                // シフト詳細取得
+	shifts.POST("", h.CreateShift, owner)                   // シフト作成
+	shifts.PUT("/:id", h.UpdateShift, owner)                // シフト更新
+	shifts.DELETE("/:id", h.DeleteShift, owner)             // シフト削除
+	shifts.GET("/:id/holders", h.GetShiftHolders, employee) // シフトの担当者の履歴取得
+	shifts.POST("/copy", h.CopyShifts, owner)               // 期間のシフトを別の期間にコピー
+	shifts.POST("/bulk", h.BulkShifts, owner)               // シフトの一括作成・更新・削除
 
 	// 認証API
 	auth := api.Group("/auth")
-	auth.POST("/login", handlers.Login)                             // ログイン
-	auth.POST("/logout", handlers.Logout)                           // ログアウト
-	auth.GET("/profile", handlers.GetProfile)                       // プロフィール取得
-	auth.POST("/register", handlers.Register)                       // ユーザー登録
-	auth.POST("/create-test-user", handlers.CreateTestUser)         // テストユーザー作成
-	auth.POST("/create-test-employee", handlers.CreateTestEmployee) // テスト従業員作成
-	auth.DELETE("/delete-test-user", handlers.DeleteTestUser)       // テストユーザー削除
+	auth.POST("/login", h.Login)                             // ログイン（公開）
+	auth.POST("/refresh", h.Refresh)                         // トークン更新（公開）
+	auth.POST("/logout", h.Logout, h.RequireAuth)            // ログアウト
+	auth.GET("/profile", h.GetProfile, h.RequireAuth)        // プロフィール取得
+	auth.POST("/register", h.Register, h.RequireAuth, owner) // ユーザー登録
+	// テストアカウント作成用エンドポイントは認証なしで使えるため、明示的に有効にした場合のみ公開する
+	if cfg.Server.EnableTestEndpoints {
+		auth.POST("/create-test-user", h.CreateTestUser)         // テストユーザー作成（開発用）
+		auth.POST("/create-test-employee", h.CreateTestEmployee) // テスト従業員作成（開発用）
+	}
+	auth.DELETE("/delete-test-user", h.DeleteTestUser, h.RequireAuth, owner) // テストユーザー削除
+	auth.GET("/sessions", h.GetSessions, h.RequireAuth)                      // 有効なセッション一覧取得
+	auth.DELETE("/sessions/:id", h.RevokeSession, h.RequireAuth)             // セッション失効
 
 	// シフト希望API
-	shiftRequests := api.Group("/shift-requests")
-	shiftRequests.GET("", handlers.GetShiftRequests)          // シフト希望一覧取得
-	shiftRequests.GET("/:id", handlers.GetShiftRequest)       // シフト希望詳細取得
-	shiftRequests.POST("", handlers.CreateShiftRequest)       // シフト希望作成
-	shiftRequests.PUT("/:id", handlers.UpdateShiftRequest)    // シフト希望更新
-	shiftRequests.DELETE("/:id", handlers.DeleteShiftRequest) // シフト希望削除
+	shiftRequests := api.Group("/shift-requests", h.RequireAuth, employee)
+	shiftRequests.GET("", h.GetShiftRequests)                                 // シフト希望一覧取得
+	shiftRequests.GET("/:id", h.GetShiftRequest)                              // シフト希望詳細取得
+	shiftRequests.POST("", h.CreateShiftRequest)                              // シフト希望作成
+	shiftRequests.POST("/prefill", h.PrefillShiftRequests)                    // 勤務可能な時間帯からシフト希望を作成
+	shiftRequests.PUT("/:id", h.UpdateShiftRequest)                           // シフト希望更新
+	shiftRequests.DELETE("/:id", h.DeleteShiftRequest)                        // シフト希望削除
+	shiftRequests.POST("/:id/approve", h.ApproveShiftRequest, owner)          // シフト希望の承認（シフトを作成）
+	shiftRequests.POST("/:id/reject", h.RejectShiftRequest, owner)            // シフト希望の却下
+	shiftRequests.GET("/desired-hours", h.GetDesiredHours)                    // 希望する勤務時間一覧取得
+	shiftRequests.PUT("/desired-hours/:employee_id", h.SaveDesiredHours)      // 希望する勤務時間設定
+	shiftRequests.DELETE("/desired-hours/:employee_id", h.DeleteDesiredHours) // 希望する勤務時間削除
+
+	// シフト希望の提出期間API
+	requestPeriods := api.Group("/shift-request-periods", h.RequireAuth, employee)
+	requestPeriods.GET("", h.GetShiftRequestPeriods)                 // 提出期間一覧取得
+	requestPeriods.GET("/current", h.GetCurrentShiftRequestPeriod)   // 現在の提出期間取得
+	requestPeriods.GET("/missing", h.GetMissingShiftRequests, owner) // 未提出の従業員取得
+	requestPeriods.POST("", h.CreateShiftRequestPeriod, owner)       // 提出期間作成
+	requestPeriods.PUT("/:id", h.UpdateShiftRequestPeriod, owner)    // 提出期間更新
+	requestPeriods.DELETE("/:id", h.DeleteShiftRequestPeriod, owner) // 提出期間削除
+
+	// 勤務可能な時間帯API
+	availability := api.Group("/availability-profiles", h.RequireAuth, employee)
+	availability.GET("", h.GetAvailabilityProfiles)          // 勤務可能な時間帯一覧取得
+	availability.GET("/:id", h.GetAvailabilityProfile)       // 勤務可能な時間帯詳細取得
+	availability.POST("", h.CreateAvailabilityProfile)       // 勤務可能な時間帯作成
+	availability.PUT("/:id", h.UpdateAvailabilityProfile)    // 勤務可能な時間帯更新
+	availability.DELETE("/:id", h.DeleteAvailabilityProfile) // 勤務可能な時間帯削除
 
 	// 出退勤API
-	attendance := api.Group("/attendance")
-	attendance.GET("", handlers.GetAttendances)          // 出退勤記録一覧取得
-	attendance.GET("/:id", handlers.GetAttendance)       // 出退勤記録詳細取得
-	attendance.POST("", handlers.CreateAttendance)       // 出退勤記録作成
-	attendance.PUT("/:id", handlers.UpdateAttendance)    // 出退勤記録更新
-	attendance.DELETE("/:id", handlers.DeleteAttendance) // 出退勤記録削除
-	attendance.POST("/clock-in", handlers.ClockIn)       // 出勤記録
-	attendance.POST("/clock-out", handlers.ClockOut)     // 退勤記録
+	attendance := api.Group("/attendance", h.RequireAuth)
+	attendance.GET("", h.GetAttendances, employee)                 // 出退勤記録一覧取得
+	attendance.GET("/variance", h.GetAttendanceVariance, employee) // シフトと出退勤記録の差異（遅刻・早退・残業・無断欠勤）
+	attendance.GET("/:id", h.GetAttendance, employee)              // 出退勤記録詳細取得
+	attendance.POST("", h.CreateAttendance, employee)              // 出退勤記録作成
+	attendance.PUT("/:id", h.UpdateAttendance, employee)           // 出退勤記録更新
+	attendance.DELETE("/:id", h.DeleteAttendance, owner)           // 出退勤記録削除
+	attendance.POST("/clock-in", h.ClockIn, employee)              // 出勤記録
+	attendance.POST("/clock-out", h.ClockOut, employee)            // 退勤記録
 
 	// 時給管理API
-	hourlyWages := api.Group("/hourly-wages")
-	hourlyWages.GET("", handlers.GetHourlyWages)               // 時給設定一覧取得
-	hourlyWages.GET("/:id", handlers.GetHourlyWage)            // 時給設定詳細取得
-	hourlyWages.POST("", handlers.CreateHourlyWage)            // 時給設定作成
-	hourlyWages.PUT("/:id", handlers.UpdateHourlyWage)         // 時給設定更新
-	hourlyWages.DELETE("/:id", handlers.DeleteHourlyWage)      // 時給設定削除
-	hourlyWages.GET("/history", handlers.GetHourlyWageHistory) // 時給履歴取得
-	hourlyWages.GET("/current", handlers.GetCurrentHourlyWage) // 現在の時給取得
+	hourlyWages := api.Group("/hourly-wages", h.RequireAuth)
+	hourlyWages.GET("", h.GetHourlyWages, owner)                  // 時給設定一覧取得
+	hourlyWages.GET("/:id", h.GetHourlyWage, owner)               // 時給設定詳細取得
+	hourlyWages.POST("", h.CreateHourlyWage, owner)               // 時給設定作成
+	hourlyWages.PUT("/:id", h.UpdateHourlyWage, owner)            // 時給設定更新
+	hourlyWages.DELETE("/:id", h.DeleteHourlyWage, owner)         // 時給設定削除
+	hourlyWages.GET("/history", h.GetHourlyWageHistory, employee) // 時給履歴取得
+	hourlyWages.GET("/current", h.GetCurrentHourlyWage, employee) // 現在の時給取得
 
 	// 時間帯設定API
-	timeSlots := api.Group("/time-slots")
-	timeSlots.GET("", handlers.GetTimeSlots)                // 時間帯設定一覧取得
-	timeSlots.GET("/:id", handlers.GetTimeSlot)             // 時間帯設定詳細取得
-	timeSlots.POST("", handlers.CreateTimeSlot)             // 時間帯設定作成
-	timeSlots.PUT("/:id", handlers.UpdateTimeSlot)          // 時間帯設定更新
-	timeSlots.DELETE("/:id", handlers.DeleteTimeSlot)       // 時間帯設定削除
-	timeSlots.GET("/coverage", handlers.GetCoverageSummary) // カバレッジサマリー取得
+	timeSlots := api.Group("/time-slots", h.RequireAuth)
+	timeSlots.GET("", h.GetTimeSlots, employee)                // 時間帯設定一覧取得
+	timeSlots.GET("/:id", h.GetTimeSlot, employee)             // 時間帯設定詳細取得
+	timeSlots.POST("", h.CreateTimeSlot, owner)                // 時間帯設定作成
+	timeSlots.PUT("/:id", h.UpdateTimeSlot, owner)             // 時間帯設定更新
+	timeSlots.DELETE("/:id", h.DeleteTimeSlot, owner)          // 時間帯設定削除
+	timeSlots.GET("/coverage", h.GetCoverageSummary, employee) // カバレッジサマリー取得
 
 	// 給与計算API
-	payroll := api.Group("/payroll")
-	payroll.GET("/calculate", handlers.CalculatePayroll)      // 給与計算
-	payroll.GET("/employee/:id", handlers.GetEmployeePayroll) // 従業員給与取得
+	payroll := api.Group("/payroll", h.RequireAuth)
+	payroll.GET("/calculate", h.CalculatePayroll, owner)         // 給与計算
+	payroll.GET("/employee/:id", h.GetEmployeePayroll, employee) // 従業員給与取得
 
 	// 権限管理API
-	permissions := api.Group("/permissions")
-	permissions.GET("", handlers.GetPermissions)                     // 権限設定一覧取得
-	permissions.GET("/employee/:id", handlers.GetEmployeePermission) // 従業員権限取得
-	permissions.POST("", handlers.CreatePermission)                  // 権限設定作成・更新
-	permissions.DELETE("/:id", handlers.DeletePermission)            // 権限設定削除
+	permissions := api.Group("/permissions", h.RequireAuth)
+	permissions.GET("", h.GetPermissions, owner)                        // 権限設定一覧取得
+	permissions.GET("/employee/:id", h.GetEmployeePermission, employee) // 従業員権限取得
+	permissions.POST("", h.CreatePermission, owner)                     // 権限設定作成・更新
+	permissions.DELETE("/:id", h.DeletePermission, owner)               // 権限設定削除
 
 	// ガントチャート設定API
-	ganttSettings := api.Group("/gantt-settings")
-	ganttSettings.GET("", handlers.GetGanttSettings)       // ガントチャート設定取得
-	ganttSettings.POST("", handlers.CreateGanttSettings)   // ガントチャート設定作成・更新
-	ganttSettings.GET("/test", handlers.TestGanttSettings) // テスト用エンドポイント
+	ganttSettings := api.Group("/gantt-settings", h.RequireAuth)
+	ganttSettings.GET("", h.GetGanttSettings, employee)    // ガントチャート設定取得
+	ganttSettings.POST("", h.CreateGanttSettings, owner)   // ガントチャート設定作成・更新
+	ganttSettings.GET("/test", h.TestGanttSettings, owner) // テスト用エンドポイント
+
+	// 自動シフト作成API
+	scheduleJobs := api.Group("/schedule-jobs", h.RequireAuth, owner)
+	scheduleJobs.GET("", h.GetScheduleJobs)             // 自動シフト作成ジョブ一覧取得
+	scheduleJobs.GET("/:id", h.GetScheduleJob)          // 自動シフト作成ジョブ取得（作成案を含む）
+	scheduleJobs.POST("", h.CreateScheduleJob)          // 自動シフト作成ジョブ登録
+	scheduleJobs.POST("/:id/apply", h.ApplyScheduleJob) // 作成案をシフトに反映
+
+	// 繰り返しシフトAPI
+	shiftPatterns := api.Group("/shift-patterns", h.RequireAuth, owner)
+	shiftPatterns.GET("", h.GetShiftPatterns)                                      // 繰り返しシフト一覧取得
+	shiftPatterns.GET("/:id", h.GetShiftPattern)                                   // 繰り返しシフト取得
+	shiftPatterns.POST("", h.CreateShiftPattern)                                   // 繰り返しシフト作成
+	shiftPatterns.PUT("/:id", h.UpdateShiftPattern)                                // 繰り返しシフト更新（from_date 以降の回に適用）
+	shiftPatterns.DELETE("/:id", h.DeleteShiftPattern)                             // 繰り返しシフト削除
+	shiftPatterns.DELETE("/:id/occurrences/:date", h.DeleteShiftPatternOccurrence) // 1回分の取りやめ
+	shiftPatterns.POST("/materialize", h.MaterializeShiftPatterns)                 // 期間内のシフトを作成
+
+	// シフトテンプレートAPI
+	templates := api.Group("/schedule-templates", h.RequireAuth, owner)
+	templates.GET("", h.GetScheduleTemplates)             // シフトテンプレート一覧取得
+	templates.GET("/:id", h.GetScheduleTemplate)          // シフトテンプレート取得
+	templates.POST("", h.CreateScheduleTemplate)          // 週のシフトをテンプレートとして保存
+	templates.DELETE("/:id", h.DeleteScheduleTemplate)    // シフトテンプレート削除
+	templates.POST("/:id/apply", h.ApplyScheduleTemplate) // シフトテンプレートを週に適用
+
+	// シフト交代API
+	shiftTrades := api.Group("/shift-trades", h.RequireAuth, employee)
+	shiftTrades.GET("", h.GetShiftTrades)                        // シフト交代一覧取得
+	shiftTrades.POST("", h.CreateShiftTrade)                     // シフト交代の募集
+	shiftTrades.POST("/:id/accept", h.AcceptShiftTrade)          // シフト交代の引き受け
+	shiftTrades.POST("/:id/approve", h.ApproveShiftTrade, owner) // シフト交代の承認
+	shiftTrades.POST("/:id/reject", h.RejectShiftTrade, owner)   // シフト交代の却下
+	shiftTrades.POST("/:id/cancel", h.CancelShiftTrade)          // シフト交代の取り下げ
+
+	// 募集中のシフトAPI（担当者が決まっていないシフトへの応募）
+	openShifts := api.Group("/open-shifts", h.RequireAuth, employee)
+	openShifts.GET("", h.GetOpenShifts)                                    // 募集中のシフト一覧取得
+	openShifts.POST("/:id/claim", h.ClaimOpenShift)                        // 募集中のシフトへの応募
+	openShifts.GET("/claims", h.GetOpenShiftClaims)                        // 応募一覧取得
+	openShifts.POST("/claims/:id/approve", h.ApproveOpenShiftClaim, owner) // 応募の採用
+	openShifts.POST("/claims/:id/reject", h.RejectOpenShiftClaim, owner)   // 応募の不採用
+	openShifts.POST("/claims/:id/cancel", h.CancelOpenShiftClaim)          // 応募の取り下げ
+
+	// シフト期間API（従業員には公開済みのシフトだけを見せる）
+	periods := api.Group("/schedule-periods", h.RequireAuth, owner)
+	periods.GET("", h.GetSchedulePeriods)                               // シフト期間一覧取得
+	periods.POST("", h.CreateSchedulePeriod)                            // シフト期間作成
+	periods.DELETE("/:id", h.DeleteSchedulePeriod)                      // シフト期間削除
+	periods.GET("/:id/diff", h.GetSchedulePeriodDiff)                   // 公開済みの内容からの変更点取得
+	periods.POST("/:id/publish", h.PublishSchedulePeriod)               // シフト期間公開
+	periods.GET("/:id/snapshots", h.GetSchedulePeriodSnapshots)         // 公開履歴取得
+	periods.GET("/:id/snapshots/:version", h.GetSchedulePeriodSnapshot) // 公開時のシフト取得
+
+	// 労働基準法に基づくシフトの確認API
+	laborAudit := api.Group("/labor", h.RequireAuth, owner)
+	laborAudit.GET("/audit", h.GetLaborAudit)                  // 期間のシフトのルール違反一覧取得
+	laborAudit.GET("/rest-intervals", h.GetRestIntervalReport) // 休息時間（勤務間インターバル）が足りないシフト一覧取得
 
 	// サーバーの起動
-	port := ":8080"
-	if envPort := os.Getenv("PORT"); envPort != "" {
-		port = ":" + envPort
-	}
 	log.Println("サーバーを起動しています...")
-	log.Printf("http://localhost%s でアクセスできます", port)
+	log.Printf("%s で待ち受けます", cfg.Server.ListenAddr)
+
+	go func() {
+		if err := e.Start(cfg.Server.ListenAddr); err != nil && err != http.ErrServerClosed {
+			log.Fatal("サーバーの起動に失敗しました:", err)
+		}
+	}()
+
+	// 終了シグナルを受けたらリクエストの受け付けを止め、実行中の自動シフト作成ジョブの完了を待つ
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	<-quit
+	log.Println("サーバーを停止しています...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := e.Shutdown(ctx); err != nil {
+		log.Printf("サーバーの停止エラー: %v", err)
+	}
+	if err := h.WaitScheduleJobs(ctx); err != nil {
+		log.Printf("実行中の自動シフト作成ジョブの完了を待てませんでした（次回の起動時に失敗にします）: %v", err)
+	}
+}
+
+// shutdownTimeout 停止時に処理中のリクエストと自動シフト作成ジョブの完了を待つ時間
+const shutdownTimeout = 30 * time.Second
+
+// remindShiftRequests 提出期間の締切前に、シフト希望を提出していない従業員を定期的に通知
+func remindShiftRequests(h *handlers.Handler) {
+	ticker := time.NewTicker(10 * time.Minute)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		if err := h.RemindShiftRequests(now); err != nil {
+			log.Printf("シフト希望の提出リマインドエラー: %v", err)
+		}
+	}
+}
+
+// failInterruptedScheduleJobs 他のサーバーの停止で中断した自動シフト作成ジョブを定期的に失敗にする
+func failInterruptedScheduleJobs(h *handlers.Handler) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		failed, err := h.FailInterruptedScheduleJobs()
+		if err != nil {
+			log.Printf("自動シフト作成ジョブの更新エラー: %v", err)
+			continue
+		}
+		if failed > 0 {
+			log.Printf("中断した自動シフト作成ジョブを%d件失敗にしました", failed)
+		}
+	}
+}
+
+// purgeExpiredSessions 期限切れセッションを定期的に削除
+func purgeExpiredSessions(store database.SessionStore) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
 
-	if err := e.Start(port); err != nil {
-		log.Fatal("サーバーの起動に失敗しました:", err)
+	for range ticker.C {
+		deleted, err := store.DeleteExpired()
+		if err != nil {
+			log.Printf("期限切れセッション削除エラー: %v", err)
+			continue
+		}
+		if deleted > 0 {
+			log.Printf("期限切れセッションを%d件削除しました", deleted)
+		}
 	}
 }
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"shift-management-backend/database"
+)
+
+// runMigrateCommand migrate サブコマンドを実行
+//
+//	migrate status        適用状況を表示
+//	migrate up            未適用のマイグレーションをすべて適用
+//	migrate down [steps]  新しいものから steps 件（デフォルト1件）ロールバック
+func runMigrateCommand(db *sql.DB, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("使い方: migrate status|up|down [steps]")
+	}
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "未適用"
+			if status.Applied {
+				state = "適用済み " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if status.Modified {
+				state += "（適用後に変更されています）"
+			}
+			fmt.Printf("%04d_%-30s %s\n", status.Version, status.Name, state)
+		}
+		return nil
+
+	case "up":
+		count, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d件のマイグレーションを適用しました\n", count)
+		return nil
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("ロールバック件数は1以上の整数で指定してください: %q", args[1])
+			}
+		}
+		count, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d件のマイグレーションをロールバックしました\n", count)
+		return nil
+
+	default:
+		return fmt.Errorf("不明なサブコマンドです: %q（status|up|down を指定してください）", args[0])
+	}
+}
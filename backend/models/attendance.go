@@ -2,35 +2,82 @@ package models
 
 import "time"
 
+// 出退勤記録のステータス
+// 出勤時刻のある記録は予定のシフトとの照合結果から決める（欠勤は出勤時刻のない記録にだけ指定できる）
+const (
+	AttendancePresent     = "present"     // 出勤
+	AttendanceLate        = "late"        // 遅刻（早退もした場合を含む）
+	AttendanceEarlyLeave  = "early_leave" // 早退
+	AttendanceAbsent      = "absent"      // 欠勤
+	AttendanceUnscheduled = "unscheduled" // シフトのない勤務
+)
+
 // Attendance 出退勤記録モデル
 type Attendance struct {
-	ID           int       `json:"id"`
-	EmployeeID   int       `json:"employee_id"`
-	Date         string    `json:"date"`
-	ClockInTime  *string   `json:"clock_in_time,omitempty"`
-	ClockOutTime *string   `json:"clock_out_time,omitempty"`
-	ActualHours  *float64  `json:"actual_hours,omitempty"`
-	Status       string    `json:"status"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID           int     `json:"id"`
+	EmployeeID   int     `json:"employee_id"`
+	Date         string  `json:"date"`
+	ClockInTime  *string `json:"clock_in_time,omitempty"`
+	ClockOutTime *string `json:"clock_out_time,omitempty"`
+	// ClockOutNextDay 退勤が出勤日の翌日
+	ClockOutNextDay bool     `json:"clock_out_next_day"`
+	ActualHours     *float64 `json:"actual_hours,omitempty"`
+	Status          string   `json:"status"`
+	// ShiftID 照合した予定のシフト（シフトのない勤務・欠勤は nil）
+	ShiftID *int `json:"shift_id"`
+	// LateMinutes 遅刻した時間（分）。シフトの最初の出勤の記録に設定する
+	LateMinutes int `json:"late_minutes"`
+	// EarlyLeaveMinutes 早退した時間（分）、OvertimeMinutes シフトの終了後に勤務した時間（分）
+	// シフトの最後の退勤の記録に設定する（シフトのない勤務は勤務時間全体を OvertimeMinutes とする）
+	EarlyLeaveMinutes int       `json:"early_leave_minutes"`
+	OvertimeMinutes   int       `json:"overtime_minutes"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
 	// 関連データ
 	EmployeeName string `json:"employee_name,omitempty"`
 }
 
+// WorkedSpan 出勤から退勤までの区間（出退勤のどちらかが未記録の場合は ok が false）
+// 同じ時刻の出退勤は長さ0の区間として扱う
+func (a Attendance) WorkedSpan() (span Span, ok bool, err error) {
+	if a.ClockInTime == nil || a.ClockOutTime == nil {
+		return Span{}, false, nil
+	}
+	if !a.ClockOutNextDay {
+		in, errIn := ParseClock(*a.ClockInTime)
+		out, errOut := ParseClock(*a.ClockOutTime)
+		if errIn == nil && errOut == nil && in == out {
+			day, err := ParseDate(a.Date)
+			if err != nil {
+				return Span{}, false, err
+			}
+			at := day.Add(time.Duration(in) * time.Minute)
+			return Span{Start: at, End: at}, true, nil
+		}
+	}
+	span, err = NewSpan(a.Date, *a.ClockInTime, *a.ClockOutTime, a.ClockOutNextDay)
+	if err != nil {
+		return Span{}, false, err
+	}
+	return span, true, nil
+}
+
 // CreateAttendanceRequest 出退勤記録作成リクエスト
 type CreateAttendanceRequest struct {
-	EmployeeID   int     `json:"employee_id" validate:"required"`
-	Date         string  `json:"date" validate:"required"`
-	ClockInTime  *string `json:"clock_in_time"`
-	ClockOutTime *string `json:"clock_out_time"`
-	Status       string  `json:"status"`
+	EmployeeID      int     `json:"employee_id" validate:"required"`
+	Date            string  `json:"date" validate:"required"`
+	ClockInTime     *string `json:"clock_in_time"`
+	ClockOutTime    *string `json:"clock_out_time"`
+	ClockOutNextDay bool    `json:"clock_out_next_day"`
+	Status          string  `json:"status"`
 }
 
 // UpdateAttendanceRequest 出退勤記録更新リクエスト
 type UpdateAttendanceRequest struct {
-	ClockInTime  *string `json:"clock_in_time"`
-	ClockOutTime *string `json:"clock_out_time"`
-	Status       string  `json:"status"`
+	ClockInTime     *string `json:"clock_in_time"`
+	ClockOutTime    *string `json:"clock_out_time"`
+	ClockOutNextDay *bool   `json:"clock_out_next_day"`
+	Status          string  `json:"status"`
 }
 
 // ClockInRequest 出勤記録リクエスト
@@ -47,6 +94,45 @@ type ClockOutRequest struct {
 	Time       string `json:"time" validate:"required"`
 }
 
+// 出退勤記録と予定のシフトの差異の種類
+const (
+	VarianceLate            = "late"              // 遅刻
+	VarianceEarlyLeave      = "early_leave"       // 早退
+	VarianceOvertime        = "overtime"          // シフトの終了後の勤務（残業）
+	VarianceNoShow          = "no_show"           // 無断欠勤（シフトの開始から猶予時間を過ぎても出勤がない）
+	VarianceAbsent          = "absent"            // 欠勤（欠勤の記録がある）
+	VarianceUnscheduled     = "unscheduled"       // シフトのない勤務
+	VarianceMissingClockOut = "missing_clock_out" // 退勤が記録されていない
+)
+
+// AttendanceVariance 従業員の1日分の予定のシフトと出退勤記録の差異
+type AttendanceVariance struct {
+	EmployeeID   int    `json:"employee_id"`
+	EmployeeName string `json:"employee_name"`
+	// Date 勤務日（日付をまたぐ勤務は開始日）
+	Date string `json:"date"`
+	// ScheduledMinutes 予定のシフトの勤務時間（分、休憩を含む）
+	ScheduledMinutes int `json:"scheduled_minutes"`
+	// ActualMinutes 出勤から退勤までの時間（分、退勤が記録されていない記録は含まない）
+	ActualMinutes int `json:"actual_minutes"`
+	// DifferenceMinutes ActualMinutes - ScheduledMinutes
+	DifferenceMinutes int `json:"difference_minutes"`
+	LateMinutes       int `json:"late_minutes"`
+	EarlyLeaveMinutes int `json:"early_leave_minutes"`
+	OvertimeMinutes   int `json:"overtime_minutes"`
+	// Flags 差異の種類（差異がない場合は空）
+	Flags         []string `json:"flags"`
+	ShiftIDs      []int    `json:"shift_ids"`
+	AttendanceIDs []int    `json:"attendance_ids"`
+}
+
+// AttendanceVarianceReport 期間の予定のシフトと出退勤記録の差異
+type AttendanceVarianceReport struct {
+	StartDate string               `json:"start_date"`
+	EndDate   string               `json:"end_date"`
+	Days      []AttendanceVariance `json:"days"`
+}
+
 // PayrollData 給与データ
 type PayrollData struct {
 	EmployeeID     int     `json:"employee_id"`
@@ -57,6 +143,8 @@ type PayrollData struct {
 	HourlyWage     int     `json:"hourly_wage"`
 	TotalSalary    int     `json:"total_salary"`
 	ShiftCount     int     `json:"shift_count"`
+	// ActualHours 出退勤記録から集計した実労働時間（1日に複数回の出退勤がある場合は合計）
+	ActualHours float64 `json:"actual_hours"`
 }
 
 // EmployeePermission 従業員権限
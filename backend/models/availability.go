@@ -0,0 +1,185 @@
+package models
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// ShiftRuleOutsideAvailability シフトが担当者の勤務可能な時間帯の外にある
+// 労働基準法に基づくルールへの違反と同じ形式で、シフトの作成・更新時の警告として返す
+const ShiftRuleOutsideAvailability = "outside_availability"
+
+// AvailabilityProfile 従業員の勤務可能な時間帯（1週間分）
+// 適用期間内の日付は、その曜日の時間帯のみ勤務できるものとして扱う（時間帯のない曜日は勤務できない）
+type AvailabilityProfile struct {
+	ID         int    `json:"id"`
+	EmployeeID int    `json:"employee_id"`
+	Name       string `json:"name"`
+	ValidFrom  string `json:"valid_from"`
+	// ValidTo 適用終了日（nil は終了日なし）
+	ValidTo *string `json:"valid_to"`
+	// Windows 曜日ごとの勤務可能な時間帯（曜日・開始時刻順）
+	Windows   []AvailabilityWindow `json:"windows"`
+	Note      string               `json:"note"`
+	CreatedAt time.Time            `json:"created_at"`
+	UpdatedAt time.Time            `json:"updated_at"`
+	// 関連データ
+	EmployeeName string `json:"employee_name,omitempty"`
+}
+
+// AvailabilityWindow 勤務可能な時間帯
+type AvailabilityWindow struct {
+	// Weekday 曜日（0: 日曜 - 6: 土曜）
+	Weekday     int    `json:"weekday"`
+	StartTime   string `json:"start_time"`
+	EndTime     string `json:"end_time"`
+	EndsNextDay bool   `json:"ends_next_day"`
+}
+
+// AvailabilityProfileRequest 勤務可能な時間帯の作成・更新リクエスト（更新時は全項目を置き換える）
+type AvailabilityProfileRequest struct {
+	EmployeeID int                  `json:"employee_id" validate:"required"`
+	Name       string               `json:"name"`
+	ValidFrom  string               `json:"valid_from" validate:"required"`
+	ValidTo    *string              `json:"valid_to"`
+	Windows    []AvailabilityWindow `json:"windows"`
+	Note       string               `json:"note"`
+}
+
+// PrefillShiftRequestsRequest 勤務可能な時間帯からシフト希望を作成するリクエスト
+type PrefillShiftRequestsRequest struct {
+	EmployeeID int    `json:"employee_id" validate:"required"`
+	StartDate  string `json:"start_date" validate:"required"`
+	EndDate    string `json:"end_date" validate:"required"`
+	// Availability 作成するシフト希望の種類（preferred または if_needed。省略した場合は preferred）
+	Availability string `json:"availability"`
+}
+
+// PrefillSkippedDate シフト希望を作成しなかった日
+type PrefillSkippedDate struct {
+	Date   string `json:"date"`
+	Reason string `json:"reason"`
+}
+
+// PrefillShiftRequestsResponse 勤務可能な時間帯からのシフト希望の作成結果
+type PrefillShiftRequestsResponse struct {
+	Created []ShiftRequest       `json:"created"`
+	Skipped []PrefillSkippedDate `json:"skipped"`
+}
+
+// ErrAvailabilityWeekday 曜日の指定が不正
+var ErrAvailabilityWeekday = errors.New("曜日は0（日曜）-6（土曜）の範囲で指定してください")
+
+// ErrAvailabilityDateRange 適用終了日が適用開始日より前
+var ErrAvailabilityDateRange = errors.New("適用終了日は適用開始日以降である必要があります")
+
+// ValidOn 日付が適用期間に含まれるか
+func (p AvailabilityProfile) ValidOn(day time.Time) bool {
+	from, err := ParseDate(p.ValidFrom)
+	if err != nil || day.Before(from) {
+		return false
+	}
+	if p.ValidTo == nil {
+		return true
+	}
+	to, err := ParseDate(*p.ValidTo)
+	return err == nil && !day.After(to)
+}
+
+// Overlaps 適用期間が重なるか
+func (p AvailabilityProfile) Overlaps(o AvailabilityProfile) bool {
+	from, err := ParseDate(p.ValidFrom)
+	if err != nil {
+		return false
+	}
+	otherFrom, err := ParseDate(o.ValidFrom)
+	if err != nil {
+		return false
+	}
+	return o.ValidOn(from) || p.ValidOn(otherFrom)
+}
+
+// Validate 適用期間と時間帯を検証し、時間帯を曜日・開始時刻順に並べる
+func (p *AvailabilityProfile) Validate() error {
+	from, err := ParseDate(p.ValidFrom)
+	if err != nil {
+		return err
+	}
+	if p.ValidTo != nil {
+		to, err := ParseDate(*p.ValidTo)
+		if err != nil {
+			return err
+		}
+		if to.Before(from) {
+			return ErrAvailabilityDateRange
+		}
+	}
+
+	for _, w := range p.Windows {
+		if w.Weekday < 0 || w.Weekday > 6 {
+			return ErrAvailabilityWeekday
+		}
+	}
+	sort.SliceStable(p.Windows, func(i, j int) bool {
+		a, b := p.Windows[i], p.Windows[j]
+		if a.Weekday != b.Weekday {
+			return a.Weekday < b.Weekday
+		}
+		return a.StartTime < b.StartTime
+	})
+
+	// 同じ曜日の時間帯が重ならないか、基準の週に並べて確認する
+	base := from.AddDate(0, 0, -int(from.Weekday()))
+	var spans []Span
+	for _, w := range p.Windows {
+		span, err := NewSpan(base.AddDate(0, 0, w.Weekday).Format(DateLayout), w.StartTime, w.EndTime, w.EndsNextDay)
+		if err != nil {
+			return err
+		}
+		for _, other := range spans {
+			if other.Overlaps(span) {
+				return ErrWindowOverlap
+			}
+		}
+		spans = append(spans, span)
+	}
+	return nil
+}
+
+// WindowsOn 日付の曜日の時間帯
+func (p AvailabilityProfile) WindowsOn(day time.Time) []AvailabilityWindow {
+	var windows []AvailabilityWindow
+	for _, w := range p.Windows {
+		if w.Weekday == int(day.Weekday()) {
+			windows = append(windows, w)
+		}
+	}
+	return windows
+}
+
+// Spans 日付の曜日の時間帯の区間（適用期間外の日付は空）
+func (p AvailabilityProfile) Spans(day time.Time) ([]Span, error) {
+	if !p.ValidOn(day) {
+		return nil, nil
+	}
+	var spans []Span
+	for _, w := range p.WindowsOn(day) {
+		span, err := NewSpan(day.Format(DateLayout), w.StartTime, w.EndTime, w.EndsNextDay)
+		if err != nil {
+			return nil, err
+		}
+		spans = append(spans, span)
+	}
+	return spans, nil
+}
+
+// ActiveProfile 日付に適用する勤務可能な時間帯（ない場合は false）
+func ActiveProfile(profiles []AvailabilityProfile, day time.Time) (AvailabilityProfile, bool) {
+	for _, p := range profiles {
+		if p.ValidOn(day) {
+			return p, true
+		}
+	}
+	return AvailabilityProfile{}, false
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// DateLayout リクエストで受け取る日付の形式
+const DateLayout = "2006-01-02"
+
+// 日付・時刻文字列の解析
+// DB から読み込んだ値は "2006-01-02T00:00:00Z"（DATE）や "0000-01-01T15:04:05Z"（TIME）の形式になるため、
+// リクエストで受け取る "2006-01-02" / "15:04" / "2006-01-02T15:04:05" 形式とあわせて受け付ける
+
+// clockLayouts ParseClock で受け付ける形式
+var clockLayouts = []string{"15:04", "15:04:05", time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04"}
+
+// ParseDate 日付文字列を解析
+func ParseDate(s string) (time.Time, error) {
+	if t, err := time.Parse(DateLayout, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("無効な日付です: %s", s)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+}
+
+// ParseClock 時刻文字列を解析し、0時からの経過分を返す
+func ParseClock(s string) (int, error) {
+	for _, layout := range clockLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Hour()*60 + t.Minute(), nil
+		}
+	}
+	return 0, fmt.Errorf("無効な時刻です: %s", s)
+}
@@ -1,24 +1,58 @@
 package models
 
-import "time"
+import (
+	"sort"
+	"strings"
+	"time"
+)
 
 // Employee 従業員モデル
 type Employee struct {
-	ID         int       `json:"id"`
-	Name       string    `json:"name"`
-	HourlyWage int       `json:"hourly_wage"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	HourlyWage int    `json:"hourly_wage"`
+	// Positions 担当できるポジション
+	Positions []string  `json:"positions"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 // CreateEmployeeRequest 従業員作成リクエスト
 type CreateEmployeeRequest struct {
-	Name       string `json:"name" validate:"required"`
-	HourlyWage int    `json:"hourly_wage" validate:"required,min=1"`
+	Name       string   `json:"name" validate:"required"`
+	HourlyWage int      `json:"hourly_wage" validate:"required,min=1"`
+	Positions  []string `json:"positions"`
 }
 
-// UpdateEmployeeRequest 従業員更新リクエスト
+// UpdateEmployeeRequest 従業員更新リクエスト（Positions を指定しない場合は変更しない）
 type UpdateEmployeeRequest struct {
-	Name       string `json:"name" validate:"required"`
-	HourlyWage int    `json:"hourly_wage" validate:"required,min=1"`
+	Name       string    `json:"name" validate:"required"`
+	HourlyWage int       `json:"hourly_wage" validate:"required,min=1"`
+	Positions  *[]string `json:"positions"`
+}
+
+// IsQualifiedFor 指定したポジションを担当できるか
+func (e Employee) IsQualifiedFor(position string) bool {
+	for _, p := range e.Positions {
+		if p == position {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizePositions 前後の空白と空文字・重複を取り除き、並べ替えたポジション一覧を返す
+func NormalizePositions(positions []string) []string {
+	seen := make(map[string]bool, len(positions))
+	result := []string{}
+	for _, p := range positions {
+		p = strings.TrimSpace(p)
+		if p == "" || seen[p] {
+			continue
+		}
+		seen[p] = true
+		result = append(result, p)
+	}
+	sort.Strings(result)
+	return result
 }
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// GanttSettings ガントチャート設定モデル
+type GanttSettings struct {
+	ID        int       `json:"id"`
+	StartHour int       `json:"start_hour"`
+	EndHour   int       `json:"end_hour"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
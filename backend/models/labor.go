@@ -0,0 +1,61 @@
+package models
+
+// 労働基準法に基づくシフトの確認のルール
+const (
+	LaborRuleBreakOver6h   = "break_over_6h"   // 6時間を超える勤務には45分以上の休憩
+	LaborRuleBreakOver8h   = "break_over_8h"   // 8時間を超える勤務には60分以上の休憩
+	LaborRuleDailyLimit    = "daily_limit"     // 1日の勤務時間の上限
+	LaborRuleWeeklyLimit   = "weekly_limit"    // 1週間（日曜始まり）の勤務時間の上限
+	LaborRuleWeeklyRestDay = "weekly_rest_day" // 1週間（日曜始まり）に1日以上の休日
+	LaborRuleRestInterval  = "rest_interval"   // 勤務日が異なるシフトの間の休息時間（勤務間インターバル）
+)
+
+// ルール違反の扱い
+const (
+	LaborSeverityError   = "error"   // シフトを保存しない
+	LaborSeverityWarning = "warning" // シフトは保存し、警告として返す
+	LaborSeverityOff     = "off"     // 確認しない
+)
+
+// LaborViolation 労働基準法に基づくルールへの違反
+type LaborViolation struct {
+	Rule         string `json:"rule"`
+	Severity     string `json:"severity"`
+	EmployeeID   int    `json:"employee_id"`
+	EmployeeName string `json:"employee_name,omitempty"`
+	// Date 勤務日（週のルールの場合は週の開始日（日曜））
+	Date string `json:"date"`
+	// ShiftIDs 違反の対象のシフト
+	ShiftIDs []int  `json:"shift_ids"`
+	Message  string `json:"message"`
+}
+
+// LaborAuditResponse 期間のシフトの確認結果
+type LaborAuditResponse struct {
+	StartDate  string           `json:"start_date"`
+	EndDate    string           `json:"end_date"`
+	Errors     int              `json:"errors"`
+	Warnings   int              `json:"warnings"`
+	Violations []LaborViolation `json:"violations"`
+}
+
+// RestIntervalViolation 休息時間（勤務間インターバル）が足りない連続したシフト
+type RestIntervalViolation struct {
+	EmployeeID   int    `json:"employee_id"`
+	EmployeeName string `json:"employee_name,omitempty"`
+	// Previous 先のシフト
+	Previous Shift `json:"previous"`
+	// Next 後のシフト
+	Next Shift `json:"next"`
+	// RestMinutes 先のシフトの終了から後のシフトの開始までの時間（分）
+	RestMinutes int `json:"rest_minutes"`
+}
+
+// RestIntervalReport 期間の休息時間（勤務間インターバル）の確認結果
+// 後のシフトの勤務日が期間に含まれるものを返す
+type RestIntervalReport struct {
+	StartDate      string                  `json:"start_date"`
+	EndDate        string                  `json:"end_date"`
+	MinRestMinutes int                     `json:"min_rest_minutes"`
+	Violations     []RestIntervalViolation `json:"violations"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// 募集中のシフトの応募方法
+const (
+	OpenShiftFirstCome = "first_come" // 先着順（応募した時点で担当者に決まる）
+	OpenShiftApproval  = "approval"   // オーナーが応募者から選ぶ
+)
+
+// 募集中のシフトへの応募のステータス
+const (
+	OpenShiftClaimPending   = "pending"   // オーナーの承認待ち
+	OpenShiftClaimApproved  = "approved"  // 採用（シフトの担当者に決定）
+	OpenShiftClaimRejected  = "rejected"  // 不採用
+	OpenShiftClaimCancelled = "cancelled" // 取り下げ
+)
+
+// OpenShiftClaim 担当者が決まっていないシフトへの応募
+type OpenShiftClaim struct {
+	ID         int    `json:"id"`
+	ShiftID    int    `json:"shift_id"`
+	EmployeeID int    `json:"employee_id"`
+	Status     string `json:"status"`
+	// DecisionReason 不採用の理由など
+	DecisionReason string     `json:"decision_reason"`
+	DecidedAt      *time.Time `json:"decided_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	// 関連データ
+	EmployeeName string `json:"employee_name,omitempty"`
+	Shift        *Shift `json:"shift,omitempty"`
+	// Warnings 応募・承認時の担当者を変更した後のシフトへの警告（保存しない）
+	Warnings []LaborViolation `json:"warnings,omitempty"`
+}
+
+// DecideOpenShiftClaimRequest 応募の不採用リクエスト
+type DecideOpenShiftClaimRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ClaimOpenShiftResponse 応募のレスポンス（先着順の場合は担当者に決まったシフトを含む）
+type ClaimOpenShiftResponse struct {
+	Claim OpenShiftClaim `json:"claim"`
+	Shift *Shift         `json:"shift,omitempty"`
+}
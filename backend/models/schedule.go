@@ -0,0 +1,104 @@
+package models
+
+import "time"
+
+// 自動シフト作成ジョブのステータス
+const (
+	ScheduleJobPending   = "pending"
+	ScheduleJobRunning   = "running"
+	ScheduleJobCompleted = "completed"
+	ScheduleJobFailed    = "failed"
+	ScheduleJobApplied   = "applied" // 作成案をシフトに反映済み
+)
+
+// ScheduleJob 自動シフト作成ジョブ
+// 同じ入力データと Seed からは同じ作成案が得られる
+type ScheduleJob struct {
+	ID             int             `json:"id"`
+	Status         string          `json:"status"`
+	StartDate      string          `json:"start_date"`
+	EndDate        string          `json:"end_date"`
+	Seed           int64           `json:"seed"`
+	MaxWeeklyHours int             `json:"max_weekly_hours"`
+	MinRestMinutes int             `json:"min_rest_minutes"`
+	Result         *ScheduleResult `json:"result,omitempty"`
+	Error          string          `json:"error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	FinishedAt     *time.Time      `json:"finished_at"`
+	// LeaseExpiresAt 実行するサーバーが延長し続ける期限（期限が切れた待機中・実行中のジョブは中断したものとみなす）
+	LeaseExpiresAt *time.Time `json:"lease_expires_at"`
+}
+
+// CreateScheduleJobRequest 自動シフト作成ジョブの作成リクエスト
+// Seed・MaxWeeklyHours・MinRestMinutes を省略した場合は設定値（Seed はランダム）を使う
+// MaxWeeklyHours・MinRestMinutes は作成案を作るときの条件で、反映するときは設定の労働基準法に基づくルールで確認する
+type CreateScheduleJobRequest struct {
+	StartDate      string `json:"start_date" validate:"required"`
+	EndDate        string `json:"end_date" validate:"required"`
+	Seed           *int64 `json:"seed"`
+	MaxWeeklyHours *int   `json:"max_weekly_hours"`
+	MinRestMinutes *int   `json:"min_rest_minutes"`
+}
+
+// ScheduleResult 自動シフト作成の結果（作成案）
+type ScheduleResult struct {
+	// Shifts 作成案のシフト（ID は未採番）
+	Shifts []Shift `json:"shifts"`
+	// Unfilled 必要人数を満たせなかった時間帯
+	Unfilled []UnfilledSlot `json:"unfilled"`
+	// Employees 従業員ごとの割り当て時間（従業員ID順）
+	Employees     []EmployeeScheduleSummary `json:"employees"`
+	TotalHours    float64                   `json:"total_hours"`
+	EstimatedCost int                       `json:"estimated_cost"`
+}
+
+// 時間帯に割り当てられなかった理由
+const (
+	UnfilledNotQualified     = "not_qualified"     // ポジションを担当できない
+	UnfilledUnavailable      = "unavailable"       // 勤務できない日時として希望が出ている
+	UnfilledNoRequest        = "no_request"        // 時間帯を含むシフト希望・勤務可能な時間帯がない
+	UnfilledOverlap          = "overlap"           // 勤務時間が重なるシフトがある
+	UnfilledWeeklyLimit      = "weekly_limit"      // 週の勤務時間の上限を超える
+	UnfilledInsufficientRest = "insufficient_rest" // 前後のシフトとの休息時間が足りない
+)
+
+// UnfilledReasonLabels 割り当てられなかった理由の日本語名
+var UnfilledReasonLabels = map[string]string{
+	UnfilledNotQualified:     "ポジションを担当できない",
+	UnfilledUnavailable:      "勤務できない希望がある",
+	UnfilledNoRequest:        "シフト希望・勤務可能な時間帯がない",
+	UnfilledOverlap:          "他のシフトと重なる",
+	UnfilledWeeklyLimit:      "週の勤務時間の上限を超える",
+	UnfilledInsufficientRest: "休息時間が足りない",
+}
+
+// UnfilledReason 割り当てられなかった理由ごとの従業員数
+type UnfilledReason struct {
+	Reason string `json:"reason"`
+	Label  string `json:"label"`
+	Count  int    `json:"count"`
+}
+
+// UnfilledSlot 必要人数を満たせなかった時間帯
+type UnfilledSlot struct {
+	Date          string           `json:"date"`
+	TimeSlotID    int              `json:"time_slot_id"`
+	Position      string           `json:"position"`
+	StartTime     string           `json:"start_time"`
+	EndTime       string           `json:"end_time"`
+	EndsNextDay   bool             `json:"ends_next_day"`
+	RequiredCount int              `json:"required_count"`
+	AssignedCount int              `json:"assigned_count"`
+	Shortage      int              `json:"shortage"`
+	Reasons       []UnfilledReason `json:"reasons"`
+	Explanation   string           `json:"explanation"`
+}
+
+// EmployeeScheduleSummary 作成案での従業員ごとの割り当て
+type EmployeeScheduleSummary struct {
+	EmployeeID    int     `json:"employee_id"`
+	EmployeeName  string  `json:"employee_name"`
+	ShiftCount    int     `json:"shift_count"`
+	Hours         float64 `json:"hours"`
+	EstimatedCost int     `json:"estimated_cost"`
+}
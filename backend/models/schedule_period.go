@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// シフト期間のステータス
+const (
+	SchedulePeriodDraft     = "draft"     // 作成中（公開後に変更した場合も含む）
+	SchedulePeriodPublished = "published" // 公開済み
+)
+
+// SchedulePeriod シフト期間
+// 期間内の日付のシフトはこの期間に属し、従業員には公開したときの内容だけを見せる
+type SchedulePeriod struct {
+	ID        int    `json:"id"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	Status    string `json:"status"`
+	// PublishedVersion 最後に公開したスナップショットの版（未公開の場合は0）
+	PublishedVersion int        `json:"published_version"`
+	PublishedAt      *time.Time `json:"published_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// CreateSchedulePeriodRequest シフト期間作成リクエスト
+type CreateSchedulePeriodRequest struct {
+	StartDate string `json:"start_date" validate:"required"`
+	EndDate   string `json:"end_date" validate:"required"`
+}
+
+// Contains 日付が期間内か
+func (p SchedulePeriod) Contains(date string) bool {
+	day, err := ParseDate(date)
+	if err != nil {
+		return false
+	}
+	start, errStart := ParseDate(p.StartDate)
+	end, errEnd := ParseDate(p.EndDate)
+	if errStart != nil || errEnd != nil {
+		return false
+	}
+	return !day.Before(start) && !day.After(end)
+}
+
+// SchedulePeriodSnapshot 公開時点の期間内のシフト
+type SchedulePeriodSnapshot struct {
+	ID        int       `json:"id"`
+	PeriodID  int       `json:"period_id"`
+	Version   int       `json:"version"`
+	Shifts    []Shift   `json:"shifts,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ScheduleDiff 公開済みの内容からの変更点
+type ScheduleDiff struct {
+	Added   []Shift       `json:"added"`
+	Removed []Shift       `json:"removed"`
+	Changed []ShiftChange `json:"changed"`
+}
+
+// ShiftChange 変更されたシフトの変更前後
+type ShiftChange struct {
+	Before Shift `json:"before"`
+	After  Shift `json:"after"`
+	// Fields 変更された項目（JSON の項目名）
+	Fields []string `json:"fields"`
+}
+
+// PublishSchedulePeriodResponse シフト期間公開のレスポンス
+type PublishSchedulePeriodResponse struct {
+	Period SchedulePeriod `json:"period"`
+	Diff   ScheduleDiff   `json:"diff"`
+}
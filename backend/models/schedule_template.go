@@ -0,0 +1,82 @@
+package models
+
+import "time"
+
+// ScheduleTemplate 1週間分のシフトを曜日ごとに保存したテンプレート
+type ScheduleTemplate struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	// Shifts 一覧では含めない
+	Shifts     []TemplateShift `json:"shifts,omitempty"`
+	ShiftCount int             `json:"shift_count"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+// TemplateShift テンプレートのシフト
+type TemplateShift struct {
+	ID int `json:"id"`
+	// Weekday 週の何曜日のシフトか（0: 日曜 - 6: 土曜）
+	Weekday int `json:"weekday"`
+	// EmployeeID 保存時の担当者（0 の場合は担当者を募集するシフト）。従業員が削除されても残る
+	EmployeeID int `json:"employee_id"`
+	// EmployeeName 保存時の担当者名
+	EmployeeName string         `json:"employee_name"`
+	StartTime    string         `json:"start_time"`
+	EndTime      string         `json:"end_time"`
+	EndsNextDay  bool           `json:"ends_next_day"`
+	BreakTime    int            `json:"break_time"`
+	Position     string         `json:"position"`
+	Segments     []ShiftSegment `json:"segments,omitempty"`
+}
+
+// CreateScheduleTemplateRequest テンプレート作成リクエスト
+type CreateScheduleTemplateRequest struct {
+	Name string `json:"name" validate:"required"`
+	// WeekStart 保存する週に含まれる日（その日を含む日曜始まりの週のシフトを保存する）
+	WeekStart string `json:"week_start" validate:"required"`
+}
+
+// ApplyScheduleTemplateRequest テンプレート適用リクエスト
+type ApplyScheduleTemplateRequest struct {
+	// WeekStart 適用する週に含まれる日（その日を含む日曜始まりの週に適用する）
+	WeekStart string `json:"week_start" validate:"required"`
+	// DryRun true の場合はシフトを作成せず、作成されるシフトと競合だけを返す
+	DryRun bool `json:"dry_run"`
+}
+
+// CopyShiftsRequest 期間のシフトのコピーリクエスト
+type CopyShiftsRequest struct {
+	SourceStart string `json:"source_start" validate:"required"`
+	SourceEnd   string `json:"source_end" validate:"required"`
+	// TargetStart コピー先の開始日（コピー元と同じ日数の期間にコピーする）
+	TargetStart string `json:"target_start" validate:"required"`
+	// DryRun true の場合はシフトを作成せず、作成されるシフトと競合だけを返す
+	DryRun bool `json:"dry_run"`
+}
+
+// シフトをコピーできない理由
+const (
+	ShiftConflictOverlap         = "overlap"          // 同じ従業員の勤務時間が重なるシフトがある
+	ShiftConflictEmployeeDeleted = "employee_deleted" // 従業員が削除されている
+	ShiftConflictNotQualified    = "not_qualified"    // 従業員がポジションを担当できない
+	ShiftConflictRuleViolation   = "rule_violation"   // 労働基準法に基づくルール・勤務可能な時間帯に違反する
+)
+
+// ShiftConflict コピーできなかったシフト
+type ShiftConflict struct {
+	// Shift 作成しようとしたシフト
+	Shift   Shift  `json:"shift"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+	// Existing 勤務時間が重なる既存のシフト（reason が overlap の場合）
+	Existing *Shift `json:"existing,omitempty"`
+}
+
+// CopyShiftsResponse シフトのコピー・テンプレート適用の結果
+// 競合したシフトは作成せず、それ以外のシフトを作成する
+type CopyShiftsResponse struct {
+	Created   []Shift         `json:"created"`
+	Conflicts []ShiftConflict `json:"conflicts"`
+	DryRun    bool            `json:"dry_run"`
+}
@@ -1,35 +1,237 @@
 package models
 
-import "time"
+import (
+	"errors"
+	"sort"
+	"time"
+)
 
 // Shift シフトモデル
 type Shift struct {
-	ID         int       `json:"id"`
-	EmployeeID int       `json:"employee_id"`
-	Date       string    `json:"date"`
-	StartTime  string    `json:"start_time"`
-	EndTime    string    `json:"end_time"`
-	BreakTime  int       `json:"break_time"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID int `json:"id"`
+	// EmployeeID 担当する従業員（0 の場合は担当者が決まっていない募集中のシフト）
+	EmployeeID int    `json:"employee_id"`
+	Date       string `json:"date"`
+	StartTime  string `json:"start_time"`
+	EndTime    string `json:"end_time"`
+	// EndsNextDay 終了時刻が翌日（日付をまたぐ勤務）
+	EndsNextDay bool `json:"ends_next_day"`
+	BreakTime   int  `json:"break_time"`
+	// Position 担当ポジション（未設定の場合は空文字）
+	Position string `json:"position"`
+	// Segments シフト内で担当ポジションを変える区間（区間外の時間は Position を担当する）
+	Segments []ShiftSegment `json:"segments,omitempty"`
+	// PatternID 繰り返しシフトから作成した場合の繰り返しシフトID（個別に変更すると繰り返しから外れる）
+	PatternID *int `json:"pattern_id"`
+	// RequestID シフト希望を承認して作成した場合のシフト希望ID
+	RequestID *int      `json:"request_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 	// 関連データ
 	EmployeeName string `json:"employee_name,omitempty"`
+	// Warnings 作成・更新時に見つかった労働基準法に基づくルールへの違反・勤務可能な時間帯の外のシフト（警告とするもの）
+	Warnings []LaborViolation `json:"warnings,omitempty"`
 }
 
 // CreateShiftRequest シフト作成リクエスト
 type CreateShiftRequest struct {
-	EmployeeID int    `json:"employee_id" validate:"required"`
-	Date       string `json:"date" validate:"required"`
-	StartTime  string `json:"start_time" validate:"required"`
-	EndTime    string `json:"end_time" validate:"required"`
-	BreakTime  int    `json:"break_time"`
+	// EmployeeID 省略した場合は担当者を募集するシフトとして作成する
+	EmployeeID  int    `json:"employee_id"`
+	Date        string `json:"date" validate:"required"`
+	StartTime   string `json:"start_time" validate:"required"`
+	EndTime     string `json:"end_time" validate:"required"`
+	EndsNextDay bool   `json:"ends_next_day"`
+	BreakTime   int    `json:"break_time"`
+	Position    string `json:"position"`
+	// Segments シフト内で担当ポジションを変える場合の区間
+	Segments []ShiftSegment `json:"segments"`
 }
 
-// UpdateShiftRequest シフト更新リクエスト
+// UpdateShiftRequest シフト更新リクエスト（指定されなかった項目は変更しない）
 type UpdateShiftRequest struct {
-	EmployeeID int    `json:"employee_id"`
-	Date       string `json:"date"`
-	StartTime  string `json:"start_time"`
-	EndTime    string `json:"end_time"`
-	BreakTime  int    `json:"break_time"`
+	EmployeeID  int             `json:"employee_id"`
+	Date        string          `json:"date"`
+	StartTime   string          `json:"start_time"`
+	EndTime     string          `json:"end_time"`
+	EndsNextDay *bool           `json:"ends_next_day"`
+	BreakTime   *int            `json:"break_time"`
+	Position    *string         `json:"position"`
+	Segments    *[]ShiftSegment `json:"segments"`
+}
+
+// IsOpen 担当者が決まっていない募集中のシフトか
+func (s Shift) IsOpen() bool {
+	return s.EmployeeID == 0
+}
+
+// Span シフトの勤務区間
+func (s Shift) Span() (Span, error) {
+	return NewSpan(s.Date, s.StartTime, s.EndTime, s.EndsNextDay)
+}
+
+// ErrBreakTime 休憩時間が負、または勤務時間以上になっている
+var ErrBreakTime = errors.New("休憩時間は0分以上、勤務時間より短く指定してください")
+
+// CheckBreakTime 休憩時間（分）が0分以上で、勤務区間の長さより短いか確認する
+func CheckBreakTime(breakTime int, span Span) error {
+	if breakTime < 0 || breakTime >= int(span.Duration().Minutes()) {
+		return ErrBreakTime
+	}
+	return nil
+}
+
+// ShiftSegment シフト内の担当ポジションの区間
+// 時刻はシフトの勤務時間内で解釈し、シフト開始より前の時刻は翌日の時刻とみなす
+type ShiftSegment struct {
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+	Position  string `json:"position"`
+}
+
+// PositionSpan ポジションを担当する区間
+type PositionSpan struct {
+	Span
+	Position string
+}
+
+// ErrSegmentOutOfShift 区間がシフトの勤務時間外にはみ出している
+var ErrSegmentOutOfShift = errors.New("ポジションの区間はシフトの勤務時間内で指定してください")
+
+// ErrSegmentOverlap 区間どうしが重なっている
+var ErrSegmentOverlap = errors.New("ポジションの区間が重なっています")
+
+// ErrSegmentPosition 区間のポジションが指定されていない
+var ErrSegmentPosition = errors.New("ポジションの区間にはポジションを指定してください")
+
+// PositionSpans シフトの勤務時間をポジションごとの区間に分ける（開始時刻順）
+// 区間が指定されていない時間は Position を担当し、Position が未設定の場合は含めない
+func (s Shift) PositionSpans() ([]PositionSpan, error) {
+	shiftSpan, err := s.Span()
+	if err != nil {
+		return nil, err
+	}
+
+	segments := make([]PositionSpan, 0, len(s.Segments))
+	for _, seg := range s.Segments {
+		if seg.Position == "" {
+			return nil, ErrSegmentPosition
+		}
+		span, err := seg.spanWithin(shiftSpan)
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, PositionSpan{Span: span, Position: seg.Position})
+	}
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].Start.Before(segments[j].Start)
+	})
+
+	var result []PositionSpan
+	cursor := shiftSpan.Start
+	for i, seg := range segments {
+		if i > 0 && seg.Overlaps(segments[i-1].Span) {
+			return nil, ErrSegmentOverlap
+		}
+		if s.Position != "" && cursor.Before(seg.Start) {
+			result = append(result, PositionSpan{Span: Span{Start: cursor, End: seg.Start}, Position: s.Position})
+		}
+		result = append(result, seg)
+		cursor = seg.End
+	}
+	if s.Position != "" && cursor.Before(shiftSpan.End) {
+		result = append(result, PositionSpan{Span: Span{Start: cursor, End: shiftSpan.End}, Position: s.Position})
+	}
+	return result, nil
+}
+
+// Positions シフトで担当するポジションの一覧（重複なし）
+func (s Shift) Positions() []string {
+	positions := []string{}
+	if s.Position != "" {
+		positions = append(positions, s.Position)
+	}
+	for _, seg := range s.Segments {
+		positions = append(positions, seg.Position)
+	}
+	return NormalizePositions(positions)
+}
+
+// spanWithin シフトの勤務区間内での区間を求める
+func (seg ShiftSegment) spanWithin(shift Span) (Span, error) {
+	start, err := ParseClock(seg.StartTime)
+	if err != nil {
+		return Span{}, err
+	}
+	end, err := ParseClock(seg.EndTime)
+	if err != nil {
+		return Span{}, err
+	}
+
+	day := time.Date(shift.Start.Year(), shift.Start.Month(), shift.Start.Day(), 0, 0, 0, 0, shift.Start.Location())
+	startAt := day.Add(time.Duration(start) * time.Minute)
+	if startAt.Before(shift.Start) {
+		startAt = startAt.AddDate(0, 0, 1)
+	}
+	endAt := day.Add(time.Duration(end) * time.Minute)
+	for !endAt.After(startAt) {
+		endAt = endAt.AddDate(0, 0, 1)
+	}
+
+	if endAt.After(shift.End) {
+		return Span{}, ErrSegmentOutOfShift
+	}
+	return Span{Start: startAt, End: endAt}, nil
+}
+
+// 一括操作のモード
+const (
+	BulkAllOrNothing = "all_or_nothing" // 1件でも失敗した場合はすべて取り消す
+	BulkBestEffort   = "best_effort"    // 失敗した操作だけを除いて反映する
+)
+
+// 一括操作の種類
+const (
+	BulkActionCreate = "create"
+	BulkActionUpdate = "update"
+	BulkActionDelete = "delete"
+)
+
+// BulkShiftRequest シフトの一括作成・更新・削除リクエスト
+// 操作は指定した順に適用し、先の操作の結果（作成したシフトとの重なりなど）を後の操作の検証に含める
+type BulkShiftRequest struct {
+	// Mode 省略した場合は all_or_nothing
+	Mode       string               `json:"mode"`
+	Operations []BulkShiftOperation `json:"operations" validate:"required"`
+}
+
+// BulkShiftOperation 一括操作の1件
+type BulkShiftOperation struct {
+	Action string `json:"action" validate:"required"`
+	// ID 更新・削除するシフト
+	ID int `json:"id"`
+	// Shift 作成するシフト（action が create の場合）
+	Shift *CreateShiftRequest `json:"shift"`
+	// Changes 変更する項目（action が update の場合）
+	Changes *UpdateShiftRequest `json:"changes"`
+}
+
+// BulkShiftResult 一括操作の1件の結果
+type BulkShiftResult struct {
+	Index  int    `json:"index"`
+	Action string `json:"action"`
+	// Status 個別に操作した場合のHTTPステータス
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// Shift 作成・更新後、または削除したシフト（変更を取り消した場合は含めない）
+	Shift *Shift `json:"shift,omitempty"`
+}
+
+// BulkShiftResponse シフトの一括操作の結果
+type BulkShiftResponse struct {
+	Mode string `json:"mode"`
+	// Applied 変更を反映したか（all_or_nothing で失敗した操作がある場合は false）
+	Applied   bool              `json:"applied"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Results   []BulkShiftResult `json:"results"`
 }
@@ -0,0 +1,250 @@
+package models
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// ShiftPattern 繰り返しシフトの定義
+// 開始日から終了日まで、指定した曜日に同じ時刻のシフトを作る（隔週の場合は開始日を含む週から1週おき）
+type ShiftPattern struct {
+	ID         int `json:"id"`
+	EmployeeID int `json:"employee_id"`
+	// Weekdays シフトを作る曜日（0: 日曜 - 6: 土曜）
+	Weekdays []int `json:"weekdays"`
+	// IntervalWeeks 1: 毎週 / 2: 隔週
+	IntervalWeeks int     `json:"interval_weeks"`
+	StartDate     string  `json:"start_date"`
+	EndDate       *string `json:"end_date"`
+	StartTime     string  `json:"start_time"`
+	EndTime       string  `json:"end_time"`
+	EndsNextDay   bool    `json:"ends_next_day"`
+	BreakTime     int     `json:"break_time"`
+	Position      string  `json:"position"`
+	// Exceptions シフトを作らない日（YYYY-MM-DD）
+	Exceptions []string  `json:"exceptions"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	// 関連データ
+	EmployeeName string `json:"employee_name,omitempty"`
+}
+
+// CreateShiftPatternRequest 繰り返しシフト作成リクエスト
+type CreateShiftPatternRequest struct {
+	EmployeeID    int      `json:"employee_id" validate:"required"`
+	Weekdays      []int    `json:"weekdays" validate:"required"`
+	IntervalWeeks int      `json:"interval_weeks"` // 省略した場合は毎週
+	StartDate     string   `json:"start_date" validate:"required"`
+	EndDate       *string  `json:"end_date"`
+	StartTime     string   `json:"start_time" validate:"required"`
+	EndTime       string   `json:"end_time" validate:"required"`
+	EndsNextDay   bool     `json:"ends_next_day"`
+	BreakTime     int      `json:"break_time"`
+	Position      string   `json:"position"`
+	Exceptions    []string `json:"exceptions"`
+}
+
+// UpdateShiftPatternRequest 繰り返しシフト更新リクエスト（指定されなかった項目は変更しない）
+// FromDate 以降の回に適用し、それより前に作成済みのシフトは変更しない
+type UpdateShiftPatternRequest struct {
+	// FromDate 変更を適用する最初の日（省略した場合は今日）
+	FromDate      string    `json:"from_date"`
+	EmployeeID    int       `json:"employee_id"`
+	Weekdays      *[]int    `json:"weekdays"`
+	IntervalWeeks *int      `json:"interval_weeks"`
+	EndDate       *string   `json:"end_date"` // 空文字を指定すると終了日なしにする
+	StartTime     string    `json:"start_time"`
+	EndTime       string    `json:"end_time"`
+	EndsNextDay   *bool     `json:"ends_next_day"`
+	BreakTime     *int      `json:"break_time"`
+	Position      *string   `json:"position"`
+	Exceptions    *[]string `json:"exceptions"`
+}
+
+// MaterializeShiftPatternsRequest 繰り返しシフトからシフトを作成するリクエスト
+type MaterializeShiftPatternsRequest struct {
+	StartDate string `json:"start_date" validate:"required"`
+	EndDate   string `json:"end_date" validate:"required"`
+	// PatternIDs 対象の繰り返しシフト（省略した場合はすべて）
+	PatternIDs []int `json:"pattern_ids"`
+}
+
+// SkippedOccurrence シフトを作成できなかった回
+type SkippedOccurrence struct {
+	PatternID int    `json:"pattern_id"`
+	Date      string `json:"date"`
+	Reason    string `json:"reason"`
+}
+
+// MaterializeShiftPatternsResponse 繰り返しシフトからのシフト作成結果
+type MaterializeShiftPatternsResponse struct {
+	Created []Shift             `json:"created"`
+	Skipped []SkippedOccurrence `json:"skipped"`
+}
+
+// ShiftPatternChange 繰り返しシフトの変更結果
+type ShiftPatternChange struct {
+	// Pattern 変更後の定義（FromDate が開始日より後の場合は FromDate から始まる新しい定義）
+	Pattern ShiftPattern `json:"pattern"`
+	Updated []Shift      `json:"updated"`
+	Created []Shift      `json:"created"`
+	Deleted []Shift      `json:"deleted"`
+}
+
+// ErrPatternWeekdays 曜日の指定が不正
+var ErrPatternWeekdays = errors.New("曜日は0（日曜）-6（土曜）の範囲で1つ以上指定してください")
+
+// ErrPatternInterval 繰り返しの間隔が不正
+var ErrPatternInterval = errors.New("interval_weeks は1（毎週）または2（隔週）を指定してください")
+
+// ErrPatternDateRange 終了日が開始日より前
+var ErrPatternDateRange = errors.New("終了日は開始日以降である必要があります")
+
+// Normalize 曜日・例外日を重複のない昇順にし、省略された間隔を毎週にする
+func (p *ShiftPattern) Normalize() {
+	if p.IntervalWeeks == 0 {
+		p.IntervalWeeks = 1
+	}
+
+	seen := make(map[int]bool, len(p.Weekdays))
+	weekdays := []int{}
+	for _, d := range p.Weekdays {
+		if !seen[d] {
+			seen[d] = true
+			weekdays = append(weekdays, d)
+		}
+	}
+	sort.Ints(weekdays)
+	p.Weekdays = weekdays
+
+	seenDates := make(map[string]bool, len(p.Exceptions))
+	exceptions := []string{}
+	for _, date := range p.Exceptions {
+		day, err := ParseDate(date)
+		if err != nil {
+			continue
+		}
+		key := day.Format(DateLayout)
+		if !seenDates[key] {
+			seenDates[key] = true
+			exceptions = append(exceptions, key)
+		}
+	}
+	sort.Strings(exceptions)
+	p.Exceptions = exceptions
+}
+
+// Validate 曜日・間隔・期間・時刻を検証する
+func (p ShiftPattern) Validate() error {
+	if len(p.Weekdays) == 0 {
+		return ErrPatternWeekdays
+	}
+	for _, d := range p.Weekdays {
+		if d < 0 || d > 6 {
+			return ErrPatternWeekdays
+		}
+	}
+	if p.IntervalWeeks != 1 && p.IntervalWeeks != 2 {
+		return ErrPatternInterval
+	}
+	start, err := ParseDate(p.StartDate)
+	if err != nil {
+		return err
+	}
+	if p.EndDate != nil {
+		end, err := ParseDate(*p.EndDate)
+		if err != nil {
+			return err
+		}
+		if end.Before(start) {
+			return ErrPatternDateRange
+		}
+	}
+	span, err := NewSpan(p.StartDate, p.StartTime, p.EndTime, p.EndsNextDay)
+	if err != nil {
+		return err
+	}
+	return CheckBreakTime(p.BreakTime, span)
+}
+
+// OccursOn 指定した日にシフトを作るか
+func (p ShiftPattern) OccursOn(day time.Time) bool {
+	start, err := ParseDate(p.StartDate)
+	if err != nil || day.Before(start) {
+		return false
+	}
+	if p.EndDate != nil {
+		end, err := ParseDate(*p.EndDate)
+		if err != nil || day.After(end) {
+			return false
+		}
+	}
+
+	weekday := int(day.Weekday())
+	found := false
+	for _, d := range p.Weekdays {
+		if d == weekday {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return false
+	}
+
+	key := day.Format(DateLayout)
+	for _, date := range p.Exceptions {
+		if dateOnly(date) == key {
+			return false
+		}
+	}
+
+	// 隔週は開始日を含む週（日曜始まり）からの週数で判定する
+	if p.IntervalWeeks > 1 {
+		weeks := int(weekStartOf(day).Sub(weekStartOf(start)).Hours()/24) / 7
+		if weeks%p.IntervalWeeks != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Occurrences 期間内にシフトを作る日（昇順）
+func (p ShiftPattern) Occurrences(from, to time.Time) []time.Time {
+	var days []time.Time
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if p.OccursOn(day) {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// ShiftOn 指定した日の回のシフト（保存前）
+func (p ShiftPattern) ShiftOn(day time.Time) Shift {
+	patternID := p.ID
+	return Shift{
+		EmployeeID:  p.EmployeeID,
+		Date:        day.Format(DateLayout),
+		StartTime:   p.StartTime,
+		EndTime:     p.EndTime,
+		EndsNextDay: p.EndsNextDay,
+		BreakTime:   p.BreakTime,
+		Position:    p.Position,
+		PatternID:   &patternID,
+	}
+}
+
+// weekStartOf 日付を含む週の日曜日
+func weekStartOf(day time.Time) time.Time {
+	return day.AddDate(0, 0, -int(day.Weekday()))
+}
+
+// dateOnly "2006-01-02T00:00:00Z" 形式の日付を YYYY-MM-DD にする
+func dateOnly(date string) string {
+	if len(date) >= len(DateLayout) {
+		return date[:len(DateLayout)]
+	}
+	return date
+}
@@ -1,33 +1,198 @@
 package models
 
-import "time"
+import (
+	"errors"
+	"time"
+)
 
-// ShiftRequest シフト希望モデル
+// シフト希望の種類
+const (
+	ShiftRequestUnavailable = "unavailable" // 勤務できない
+	ShiftRequestPreferred   = "preferred"   // 勤務したい
+	ShiftRequestIfNeeded    = "if_needed"   // 必要なら勤務できる
+	ShiftRequestAllDay      = "all_day"     // 終日勤務できる
+)
+
+// ShiftRequestAvailabilityLabels シフト希望の種類の表示名
+var ShiftRequestAvailabilityLabels = map[string]string{
+	ShiftRequestUnavailable: "勤務できない",
+	ShiftRequestPreferred:   "勤務したい",
+	ShiftRequestIfNeeded:    "必要なら勤務できる",
+	ShiftRequestAllDay:      "終日勤務できる",
+}
+
+// シフト希望のステータス
+const (
+	ShiftRequestSubmitted         = "submitted"          // 提出済み（未処理）
+	ShiftRequestApproved          = "approved"           // 承認
+	ShiftRequestPartiallyApproved = "partially_approved" // 時刻を変更して（一部を）承認
+	ShiftRequestRejected          = "rejected"           // 却下
+)
+
+// ShiftRequest シフト希望モデル（1日分）
 type ShiftRequest struct {
-	ID                 int       `json:"id"`
-	EmployeeID         int       `json:"employee_id"`
-	Date               string    `json:"date"`
-	PreferredStartTime string    `json:"preferred_start_time"`
-	PreferredEndTime   string    `json:"preferred_end_time"`
-	Status             string    `json:"status"`
-	CreatedAt          time.Time `json:"created_at"`
-	UpdatedAt          time.Time `json:"updated_at"`
+	ID         int    `json:"id"`
+	EmployeeID int    `json:"employee_id"`
+	Date       string `json:"date"`
+	// Availability シフト希望の種類
+	Availability string `json:"availability"`
+	// Windows 時間帯
+	// preferred・if_needed は1つ以上、all_day は指定しない。unavailable は指定しない場合は終日勤務できない
+	Windows []ShiftRequestWindow `json:"windows"`
+	// Note 従業員からのメモ
+	Note   string `json:"note"`
+	Status string `json:"status"`
+	// ReviewReason 承認・却下の理由
+	ReviewReason string     `json:"review_reason"`
+	ReviewedAt   *time.Time `json:"reviewed_at"`
+	// Shifts 承認して作成したシフト（シフトを削除した場合は含まれない）
+	Shifts []ShiftRequestShift `json:"shifts"`
+	// PeriodID 提出した提出期間（提出期間の設定前に提出したシフト希望は nil）
+	PeriodID *int `json:"period_id"`
+	// Late 締切後に提出（変更）した
+	Late      bool      `json:"late"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 	// 関連データ
 	EmployeeName string `json:"employee_name,omitempty"`
 }
 
+// ShiftRequestWindow シフト希望の時間帯
+type ShiftRequestWindow struct {
+	StartTime   string `json:"start_time"`
+	EndTime     string `json:"end_time"`
+	EndsNextDay bool   `json:"ends_next_day"`
+}
+
+// ShiftRequestShift シフト希望から作成したシフト
+type ShiftRequestShift struct {
+	ID          int    `json:"id"`
+	Date        string `json:"date"`
+	StartTime   string `json:"start_time"`
+	EndTime     string `json:"end_time"`
+	EndsNextDay bool   `json:"ends_next_day"`
+	Position    string `json:"position"`
+}
+
 // CreateShiftRequestRequest シフト希望作成リクエスト
 type CreateShiftRequestRequest struct {
-	EmployeeID         int    `json:"employee_id" validate:"required"`
-	Date               string `json:"date" validate:"required"`
-	PreferredStartTime string `json:"preferred_start_time" validate:"required"`
-	PreferredEndTime   string `json:"preferred_end_time" validate:"required"`
+	EmployeeID int    `json:"employee_id" validate:"required"`
+	Date       string `json:"date" validate:"required"`
+	// Availability 省略した場合は preferred
+	Availability string               `json:"availability"`
+	Windows      []ShiftRequestWindow `json:"windows"`
+	Note         string               `json:"note"`
+	// PreferredStartTime, PreferredEndTime, EndsNextDay 以前の形式（windows を省略した場合に1つの時間帯として扱う）
+	PreferredStartTime string `json:"preferred_start_time"`
+	PreferredEndTime   string `json:"preferred_end_time"`
+	EndsNextDay        bool   `json:"ends_next_day"`
 }
 
-// UpdateShiftRequestRequest シフト希望更新リクエスト
+// UpdateShiftRequestRequest シフト希望更新リクエスト（指定した項目だけ変更する）
 type UpdateShiftRequestRequest struct {
-	Date               string `json:"date"`
+	Date         string `json:"date"`
+	Availability string `json:"availability"`
+	// Windows 指定した場合は時間帯をすべて置き換える
+	Windows []ShiftRequestWindow `json:"windows"`
+	Note    *string              `json:"note"`
+	// PreferredStartTime, PreferredEndTime, EndsNextDay 以前の形式（指定した場合は最初の時間帯を変更して1つの時間帯にする）
 	PreferredStartTime string `json:"preferred_start_time"`
 	PreferredEndTime   string `json:"preferred_end_time"`
-	Status             string `json:"status"`
+	EndsNextDay        *bool  `json:"ends_next_day"`
+}
+
+// ApproveShiftRequestRequest シフト希望の承認リクエスト
+type ApproveShiftRequestRequest struct {
+	// Shifts 作成するシフト（シフト希望の日付に作成する）
+	// 省略した場合は希望の時間帯ごとにそのままシフトを作成する（終日勤務できる希望では省略できない）
+	Shifts []ApprovedShift `json:"shifts"`
+	Reason string          `json:"reason"`
+}
+
+// ApprovedShift 承認して作成するシフトの時刻・休憩・ポジション
+type ApprovedShift struct {
+	StartTime   string         `json:"start_time" validate:"required"`
+	EndTime     string         `json:"end_time" validate:"required"`
+	EndsNextDay bool           `json:"ends_next_day"`
+	BreakTime   int            `json:"break_time"`
+	Position    string         `json:"position"`
+	Segments    []ShiftSegment `json:"segments"`
+}
+
+// ApproveShiftRequestResponse シフト希望の承認結果
+type ApproveShiftRequestResponse struct {
+	ShiftRequest ShiftRequest `json:"shift_request"`
+	// Shifts 作成したシフト（労働基準法に基づくルールの警告を含む）
+	Shifts []Shift `json:"shifts"`
+}
+
+// RejectShiftRequestRequest シフト希望の却下リクエスト
+type RejectShiftRequestRequest struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+// ErrWindowOverlap シフト希望の時間帯が重なっている
+var ErrWindowOverlap = errors.New("時間帯が重ならないように指定してください")
+
+// IsValidAvailability シフト希望の種類として有効か
+func IsValidAvailability(availability string) bool {
+	_, ok := ShiftRequestAvailabilityLabels[availability]
+	return ok
+}
+
+// AllDay 時間帯を指定せず1日全体を対象にするシフト希望か（終日勤務できる・終日勤務できない）
+func (r ShiftRequest) AllDay() bool {
+	return r.Availability == ShiftRequestAllDay || (r.Availability == ShiftRequestUnavailable && len(r.Windows) == 0)
+}
+
+// Spans シフト希望の対象の区間（開始時刻順ではなく指定順）
+// 1日全体を対象にする場合は、その日の0時から翌日0時までの区間を返す
+func (r ShiftRequest) Spans() ([]Span, error) {
+	if r.AllDay() {
+		day, err := ParseDate(r.Date)
+		if err != nil {
+			return nil, err
+		}
+		return []Span{{Start: day, End: day.AddDate(0, 0, 1)}}, nil
+	}
+
+	spans := make([]Span, 0, len(r.Windows))
+	for _, w := range r.Windows {
+		span, err := NewSpan(r.Date, w.StartTime, w.EndTime, w.EndsNextDay)
+		if err != nil {
+			return nil, err
+		}
+		for _, other := range spans {
+			if other.Overlaps(span) {
+				return nil, ErrWindowOverlap
+			}
+		}
+		spans = append(spans, span)
+	}
+	return spans, nil
+}
+
+// 希望する勤務時間の単位
+const (
+	DesiredHoursWeek  = "week"  // 1週間（日曜始まり）
+	DesiredHoursMonth = "month" // 1か月
+)
+
+// DesiredHours 従業員が希望する1週間または1か月の勤務時間（休憩を除く）
+type DesiredHours struct {
+	EmployeeID int       `json:"employee_id"`
+	Unit       string    `json:"unit"`
+	Hours      float64   `json:"hours"`
+	Note       string    `json:"note"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	// 関連データ
+	EmployeeName string `json:"employee_name,omitempty"`
+}
+
+// DesiredHoursRequest 希望する勤務時間の設定リクエスト
+type DesiredHoursRequest struct {
+	Unit  string  `json:"unit" validate:"required"`
+	Hours float64 `json:"hours" validate:"required,gt=0"`
+	Note  string  `json:"note"`
 }
@@ -0,0 +1,98 @@
+package models
+
+import "time"
+
+// シフト希望の提出期間の状態
+const (
+	ShiftRequestPeriodUpcoming = "upcoming" // 受付開始前
+	ShiftRequestPeriodOpen     = "open"     // 受付中
+	ShiftRequestPeriodLate     = "late"     // 締切後（遅れての提出を受け付ける）
+	ShiftRequestPeriodClosed   = "closed"   // 受付終了
+)
+
+// ShiftRequestPeriod シフト希望の提出期間
+// 対象の日付のシフト希望は受付期間（OpensAt から ClosesAt まで）にだけ提出できる
+type ShiftRequestPeriod struct {
+	ID int `json:"id"`
+	// StartDate, EndDate シフト希望を提出する対象の日付の範囲
+	StartDate string    `json:"start_date"`
+	EndDate   string    `json:"end_date"`
+	OpensAt   time.Time `json:"opens_at"`
+	// ClosesAt 締切
+	ClosesAt time.Time `json:"closes_at"`
+	// LateUntil 締切後も遅れての提出として受け付ける日時（nil の場合は締切後は受け付けない）
+	LateUntil *time.Time `json:"late_until"`
+	// ReminderHours 未提出の従業員にリマインドする、締切の何時間前か
+	ReminderHours []int `json:"reminder_hours"`
+	// RemindedAt 最後にリマインドした日時
+	RemindedAt *time.Time `json:"reminded_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	// Status 現在の状態（保存はせず、取得時に設定する）
+	Status string `json:"status"`
+}
+
+// ShiftRequestPeriodRequest 提出期間の作成・更新リクエスト（更新の場合はすべての項目を置き換える）
+type ShiftRequestPeriodRequest struct {
+	StartDate     string     `json:"start_date" validate:"required"`
+	EndDate       string     `json:"end_date" validate:"required"`
+	OpensAt       time.Time  `json:"opens_at" validate:"required"`
+	ClosesAt      time.Time  `json:"closes_at" validate:"required"`
+	LateUntil     *time.Time `json:"late_until"`
+	ReminderHours []int      `json:"reminder_hours"`
+}
+
+// MissingShiftRequestsResponse 提出期間にシフト希望を提出していない従業員
+type MissingShiftRequestsResponse struct {
+	Period ShiftRequestPeriod `json:"period"`
+	// SubmittedCount シフト希望を提出した従業員の数
+	SubmittedCount int `json:"submitted_count"`
+	// Missing シフト希望を提出できる従業員のうち、対象の日付のシフト希望を1件も提出していない従業員
+	Missing []Employee `json:"missing"`
+}
+
+// StatusAt 指定した日時の状態
+func (p ShiftRequestPeriod) StatusAt(now time.Time) string {
+	switch {
+	case now.Before(p.OpensAt):
+		return ShiftRequestPeriodUpcoming
+	case now.Before(p.ClosesAt):
+		return ShiftRequestPeriodOpen
+	case p.LateUntil != nil && now.Before(*p.LateUntil):
+		return ShiftRequestPeriodLate
+	}
+	return ShiftRequestPeriodClosed
+}
+
+// Contains 日付が対象の日付の範囲内か
+func (p ShiftRequestPeriod) Contains(date string) bool {
+	day, err := ParseDate(date)
+	if err != nil {
+		return false
+	}
+	start, errStart := ParseDate(p.StartDate)
+	end, errEnd := ParseDate(p.EndDate)
+	if errStart != nil || errEnd != nil {
+		return false
+	}
+	return !day.Before(start) && !day.After(end)
+}
+
+// DueReminder 前回のリマインドの後、now までにリマインドする日時を迎えたか
+// 受付開始前と締切後はリマインドしない
+func (p ShiftRequestPeriod) DueReminder(now time.Time) bool {
+	if p.StatusAt(now) != ShiftRequestPeriodOpen {
+		return false
+	}
+	since := p.OpensAt
+	if p.RemindedAt != nil && p.RemindedAt.After(since) {
+		since = *p.RemindedAt
+	}
+	for _, hours := range p.ReminderHours {
+		at := p.ClosesAt.Add(-time.Duration(hours) * time.Hour)
+		if at.After(since) && !at.After(now) {
+			return true
+		}
+	}
+	return false
+}
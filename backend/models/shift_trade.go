@@ -0,0 +1,88 @@
+package models
+
+import "time"
+
+// シフト交代の種類
+const (
+	ShiftTradeSwap     = "swap"     // 交換（引き受ける従業員のシフトと入れ替える）
+	ShiftTradeGiveaway = "giveaway" // 譲渡
+)
+
+// シフト交代のステータス
+const (
+	ShiftTradeOpen      = "open"      // 募集中
+	ShiftTradeAccepted  = "accepted"  // 引き受け済み（オーナーの承認待ち）
+	ShiftTradeApproved  = "approved"  // 承認済み（シフトの担当者を変更済み）
+	ShiftTradeRejected  = "rejected"  // 却下
+	ShiftTradeCancelled = "cancelled" // 取り下げ
+)
+
+// ShiftTrade シフト交代の募集
+type ShiftTrade struct {
+	ID      int    `json:"id"`
+	ShiftID int    `json:"shift_id"`
+	Type    string `json:"type"`
+	// OfferedBy 募集した従業員（募集時のシフトの担当者）
+	OfferedBy int `json:"offered_by"`
+	// AcceptedBy 引き受けた従業員
+	AcceptedBy *int `json:"accepted_by"`
+	// SwapShiftID 交換の場合に引き受けた従業員が差し出すシフト
+	SwapShiftID *int   `json:"swap_shift_id"`
+	Status      string `json:"status"`
+	Note        string `json:"note"`
+	// DecisionReason 却下の理由など
+	DecisionReason string     `json:"decision_reason"`
+	DecidedAt      *time.Time `json:"decided_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	// 関連データ
+	OfferedByName  string `json:"offered_by_name,omitempty"`
+	AcceptedByName string `json:"accepted_by_name,omitempty"`
+	Shift          *Shift `json:"shift,omitempty"`
+	// Warnings 引き受け・承認時の担当者を変更した後のシフトへの警告（保存しない）
+	Warnings []LaborViolation `json:"warnings,omitempty"`
+}
+
+// CreateShiftTradeRequest シフト交代の募集リクエスト
+type CreateShiftTradeRequest struct {
+	ShiftID int    `json:"shift_id" validate:"required"`
+	Type    string `json:"type" validate:"required"`
+	Note    string `json:"note"`
+}
+
+// AcceptShiftTradeRequest シフト交代の引き受けリクエスト（交換の場合は差し出すシフトを指定する）
+type AcceptShiftTradeRequest struct {
+	SwapShiftID *int `json:"swap_shift_id"`
+}
+
+// DecideShiftTradeRequest シフト交代の却下リクエスト
+type DecideShiftTradeRequest struct {
+	Reason string `json:"reason"`
+}
+
+// IsActive 募集中または承認待ちか
+func (t ShiftTrade) IsActive() bool {
+	return t.Status == ShiftTradeOpen || t.Status == ShiftTradeAccepted
+}
+
+// シフトの担当者が変わった理由
+const (
+	ShiftHolderAssigned = "assigned" // シフト作成時の割り当て
+	ShiftHolderChanged  = "changed"  // オーナーによる変更
+	ShiftHolderSwap     = "swap"     // シフト交換
+	ShiftHolderGiveaway = "giveaway" // シフト譲渡
+	ShiftHolderClaimed  = "claimed"  // 募集中のシフトへの応募
+)
+
+// ShiftHolder シフトの担当者の履歴（CreatedAt 以降、EmployeeID の従業員が担当している）
+type ShiftHolder struct {
+	ID         int    `json:"id"`
+	ShiftID    int    `json:"shift_id"`
+	EmployeeID int    `json:"employee_id"`
+	Reason     string `json:"reason"`
+	// TradeID シフト交代で担当者が変わった場合の交代ID
+	TradeID   *int      `json:"trade_id"`
+	CreatedAt time.Time `json:"created_at"`
+	// 関連データ
+	EmployeeName string `json:"employee_name,omitempty"`
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvertedRange 終了時刻が開始時刻より前（または同じ）になっている
+var ErrInvertedRange = errors.New("終了時刻は開始時刻より後である必要があります（日付をまたぐ場合は ends_next_day を指定してください）")
+
+// ErrNotOvernight 翌日終了が指定されているが、終了時刻が開始時刻より後になっている（24時間を超える）
+var ErrNotOvernight = errors.New("翌日終了の場合、終了時刻は開始時刻以前である必要があります")
+
+// Span 日時の区間（Start を含み End を含まない）
+type Span struct {
+	Start time.Time
+	End   time.Time
+}
+
+// NewSpan 日付と開始・終了時刻から区間を作成
+// endsNextDay が true の場合、終了時刻は翌日の時刻として扱う
+func NewSpan(date, start, end string, endsNextDay bool) (Span, error) {
+	day, err := ParseDate(date)
+	if err != nil {
+		return Span{}, err
+	}
+	startMinutes, err := ParseClock(start)
+	if err != nil {
+		return Span{}, err
+	}
+	endMinutes, err := ParseClock(end)
+	if err != nil {
+		return Span{}, err
+	}
+	if err := ValidateClockRange(startMinutes, endMinutes, endsNextDay); err != nil {
+		return Span{}, err
+	}
+
+	if endsNextDay {
+		endMinutes += 24 * 60
+	}
+	return Span{
+		Start: day.Add(time.Duration(startMinutes) * time.Minute),
+		End:   day.Add(time.Duration(endMinutes) * time.Minute),
+	}, nil
+}
+
+// ValidateClockRange 開始・終了時刻（0時からの経過分）の組み合わせを検証
+// 日付をまたがない場合は終了が開始より後、またぐ場合は終了が開始以前（24時間以内）である必要がある
+func ValidateClockRange(startMinutes, endMinutes int, endsNextDay bool) error {
+	if !endsNextDay && endMinutes <= startMinutes {
+		return ErrInvertedRange
+	}
+	if endsNextDay && endMinutes > startMinutes {
+		return ErrNotOvernight
+	}
+	return nil
+}
+
+// String 表示用の文字列（例: "2024-01-05 22:00〜翌03:00"）
+func (s Span) String() string {
+	end := s.End.Format("15:04")
+	startDay := time.Date(s.Start.Year(), s.Start.Month(), s.Start.Day(), 0, 0, 0, 0, s.Start.Location())
+	if !s.End.Before(startDay.AddDate(0, 0, 1)) {
+		end = "翌" + end
+	}
+	return s.Start.Format(DateLayout+" 15:04") + "〜" + end
+}
+
+// Duration 区間の長さ
+func (s Span) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// Hours 区間の長さ（時間）
+func (s Span) Hours() float64 {
+	return s.Duration().Hours()
+}
+
+// Overlaps 2つの区間が重なっているか（端点が接しているだけの場合は重ならない）
+func (s Span) Overlaps(o Span) bool {
+	return s.Start.Before(o.End) && o.Start.Before(s.End)
+}
+
+// Intersect 2つの区間の重なり。重ならない場合は ok が false
+func (s Span) Intersect(o Span) (Span, bool) {
+	start, end := s.Start, s.End
+	if o.Start.After(start) {
+		start = o.Start
+	}
+	if o.End.Before(end) {
+		end = o.End
+	}
+	if !start.Before(end) {
+		return Span{}, false
+	}
+	return Span{Start: start, End: end}, true
+}
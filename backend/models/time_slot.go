@@ -8,6 +8,7 @@ type TimeSlot struct {
 	DayOfWeek     int       `json:"day_of_week"`
 	StartTime     string    `json:"start_time"`
 	EndTime       string    `json:"end_time"`
+	EndsNextDay   bool      `json:"ends_next_day"`
 	Position      string    `json:"position"`
 	RequiredCount int       `json:"required_count"`
 	CreatedAt     time.Time `json:"created_at"`
@@ -19,6 +20,7 @@ type CreateTimeSlotRequest struct {
 	DayOfWeek     int    `json:"day_of_week" validate:"required,min=0,max=6"`
 	StartTime     string `json:"start_time" validate:"required"`
 	EndTime       string `json:"end_time" validate:"required"`
+	EndsNextDay   bool   `json:"ends_next_day"`
 	Position      string `json:"position" validate:"required"`
 	RequiredCount int    `json:"required_count" validate:"required,min=1"`
 }
@@ -28,34 +30,24 @@ type UpdateTimeSlotRequest struct {
 	DayOfWeek     *int    `json:"day_of_week,omitempty"`
 	StartTime     *string `json:"start_time,omitempty"`
 	EndTime       *string `json:"end_time,omitempty"`
+	EndsNextDay   *bool   `json:"ends_next_day,omitempty"`
 	Position      *string `json:"position,omitempty"`
 	RequiredCount *int    `json:"required_count,omitempty"`
 }
 
-// ShiftCoverage シフトカバレッジモデル
-type ShiftCoverage struct {
-	ID            int       `json:"id"`
-	Date          string    `json:"date"`
-	TimeSlotID    int       `json:"time_slot_id"`
-	Position      string    `json:"position"`
-	RequiredCount int       `json:"required_count"`
-	ActualCount   int       `json:"actual_count"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
-	// 関連データ
-	TimeSlot *TimeSlot `json:"time_slot,omitempty"`
-}
-
-// CoverageSummary カバレッジサマリーモデル
+// CoverageSummary カバレッジサマリーモデル（指定日の時間帯ごとの充足状況）
 type CoverageSummary struct {
 	Date          string `json:"date"`
+	TimeSlotID    int    `json:"time_slot_id"`
 	DayOfWeek     int    `json:"day_of_week"`
 	StartTime     string `json:"start_time"`
 	EndTime       string `json:"end_time"`
+	EndsNextDay   bool   `json:"ends_next_day"`
 	Position      string `json:"position"`
 	RequiredCount int    `json:"required_count"`
 	ActualCount   int    `json:"actual_count"`
 	Shortage      int    `json:"shortage"`
+	Excess        int    `json:"excess"`
 	Status        string `json:"status"` // 'sufficient', 'shortage', 'excess'
 }
 
@@ -78,11 +70,19 @@ func GetDayOfWeekName(dayOfWeek int) string {
 	return "不明"
 }
 
+// SpanOn 指定日に適用した時間帯の区間
+func (ts TimeSlot) SpanOn(date string) (Span, error) {
+	return NewSpan(date, ts.StartTime, ts.EndTime, ts.EndsNextDay)
+}
+
 // GetStatus カバレッジのステータスを取得
 func (cs *CoverageSummary) GetStatus() string {
-	if cs.ActualCount >= cs.RequiredCount {
-		return "sufficient"
-	} else {
+	switch {
+	case cs.ActualCount < cs.RequiredCount:
 		return "shortage"
+	case cs.ActualCount > cs.RequiredCount:
+		return "excess"
+	default:
+		return "sufficient"
 	}
 }
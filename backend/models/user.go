@@ -31,6 +31,13 @@ type RegisterRequest struct {
 
 // LoginResponse ログインレスポンス
 type LoginResponse struct {
-	User  User   `json:"user"`
-	Token string `json:"token"`
+	User         User      `json:"user"`
+	Token        string    `json:"token"` // アクセストークン（JWT）
+	ExpiresAt    time.Time `json:"expires_at"`
+	RefreshToken string    `json:"refresh_token"`
+}
+
+// RefreshRequest トークン更新リクエスト
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
 }
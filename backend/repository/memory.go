@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"sync"
+	"time"
+
+	"shift-management-backend/models"
+)
+
+// memoryStore メモリ上に保持するデータ（テスト用）
+// 日付・時刻は PostgreSQL 実装と同じ形式（"2006-01-02T00:00:00Z" / "0000-01-01T15:04:05Z"）で保持する
+type memoryStore struct {
+	mu   sync.Mutex
+	txMu sync.Mutex // InTx を直列化する
+	data memoryData
+	now  func() time.Time
+}
+
+type memoryData struct {
+	lastID        int
+	employees     map[int]models.Employee
+	shifts        map[int]models.Shift
+	attendance    map[int]models.Attendance
+	shiftRequests map[int]models.ShiftRequest
+	wages         map[int]models.HourlyWage
+	timeSlots     map[int]models.TimeSlot
+	permissions   map[int]models.EmployeePermission
+	users         map[int]models.User
+	gantt         *models.GanttSettings
+	scheduleJobs  map[int]models.ScheduleJob
+	// schedulePeriods, snapshots シフト期間と公開時のスナップショット
+	schedulePeriods map[int]models.SchedulePeriod
+	snapshots       map[int]models.SchedulePeriodSnapshot
+	shiftTrades     map[int]models.ShiftTrade
+	shiftHolders    map[int]models.ShiftHolder
+	openShiftClaims map[int]models.OpenShiftClaim
+	shiftPatterns   map[int]models.ShiftPattern
+	templates       map[int]models.ScheduleTemplate
+	requestPeriods  map[int]models.ShiftRequestPeriod
+	// desiredHours 従業員IDごとの希望する勤務時間
+	desiredHours map[int]models.DesiredHours
+	availability map[int]models.AvailabilityProfile
+}
+
+// clone ロールバック用にデータを複製する
+func (d memoryData) clone() memoryData {
+	c := d
+	c.employees = cloneMap(d.employees)
+	c.shifts = cloneMap(d.shifts)
+	c.attendance = cloneMap(d.attendance)
+	c.shiftRequests = cloneMap(d.shiftRequests)
+	c.wages = cloneMap(d.wages)
+	c.timeSlots = cloneMap(d.timeSlots)
+	c.permissions = cloneMap(d.permissions)
+	c.users = cloneMap(d.users)
+	c.scheduleJobs = cloneMap(d.scheduleJobs)
+	c.schedulePeriods = cloneMap(d.schedulePeriods)
+	c.snapshots = cloneMap(d.snapshots)
+	c.shiftTrades = cloneMap(d.shiftTrades)
+	c.shiftHolders = cloneMap(d.shiftHolders)
+	c.openShiftClaims = cloneMap(d.openShiftClaims)
+	c.shiftPatterns = cloneMap(d.shiftPatterns)
+	c.templates = cloneMap(d.templates)
+	c.requestPeriods = cloneMap(d.requestPeriods)
+	c.desiredHours = cloneMap(d.desiredHours)
+	c.availability = cloneMap(d.availability)
+	if d.gantt != nil {
+		gantt := *d.gantt
+		c.gantt = &gantt
+	}
+	return c
+}
+
+func cloneMap[V any](m map[int]V) map[int]V {
+	c := make(map[int]V, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// NewMemory メモリ上にデータを保持するリポジトリ一式を作成（テスト用）
+func NewMemory() *Repositories {
+	s := &memoryStore{
+		data: memoryData{}.clone(),
+		now:  time.Now,
+	}
+
+	r := &Repositories{
+		Employees:       &memoryEmployeeRepository{s: s},
+		Shifts:          &memoryShiftRepository{s: s},
+		Attendance:      &memoryAttendanceRepository{s: s},
+		ShiftRequests:   &memoryShiftRequestRepository{s: s},
+		Wages:           &memoryWageRepository{s: s},
+		TimeSlots:       &memoryTimeSlotRepository{s: s},
+		Permissions:     &memoryPermissionRepository{s: s},
+		Users:           &memoryUserRepository{s: s},
+		GanttSettings:   &memoryGanttSettingsRepository{s: s},
+		ScheduleJobs:    &memoryScheduleJobRepository{s: s},
+		Periods:         &memorySchedulePeriodRepository{s: s},
+		ShiftTrades:     &memoryShiftTradeRepository{s: s},
+		OpenShiftClaims: &memoryOpenShiftClaimRepository{s: s},
+		ShiftPatterns:   &memoryShiftPatternRepository{s: s},
+		Templates:       &memoryScheduleTemplateRepository{s: s},
+		RequestPeriods:  &memoryShiftRequestPeriodRepository{s: s},
+		DesiredHours:    &memoryDesiredHoursRepository{s: s},
+		Availability:    &memoryAvailabilityProfileRepository{s: s},
+	}
+
+	txRepos := *r
+	txRepos.inTx = func(fn func(r *Repositories) error) error {
+		return fn(&txRepos)
+	}
+	r.inTx = func(fn func(r *Repositories) error) error {
+		s.txMu.Lock()
+		defer s.txMu.Unlock()
+
+		s.mu.Lock()
+		backup := s.data.clone()
+		s.mu.Unlock()
+
+		if err := fn(&txRepos); err != nil {
+			s.mu.Lock()
+			s.data = backup
+			s.mu.Unlock()
+			return err
+		}
+		return nil
+	}
+	return r
+}
+
+// nextID 新しいIDを採番する（呼び出し側でロックを取ること）
+func (s *memoryStore) nextID() int {
+	s.data.lastID++
+	return s.data.lastID
+}
+
+// employeeName 従業員名を取得する（呼び出し側でロックを取ること）
+func (s *memoryStore) employeeName(employeeID int) string {
+	return s.data.employees[employeeID].Name
+}
+
+// memoryDate 日付を PostgreSQL から読み込んだ場合と同じ形式にする
+func memoryDate(s string) string {
+	t, err := models.ParseDate(s)
+	if err != nil {
+		return s
+	}
+	return t.Format(time.RFC3339)
+}
+
+// memoryClock 時刻を PostgreSQL から読み込んだ場合と同じ形式にする
+func memoryClock(s string) string {
+	minutes, err := models.ParseClock(s)
+	if err != nil {
+		return s
+	}
+	return time.Date(0, 1, 1, minutes/60, minutes%60, 0, 0, time.UTC).Format(time.RFC3339)
+}
+
+// memoryClockPtr nil を許容する memoryClock
+func memoryClockPtr(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	v := memoryClock(*s)
+	return &v
+}
+
+// copyIntPtr 呼び出し元と値を共有しないようにコピーする
+func copyIntPtr(v *int) *int {
+	if v == nil {
+		return nil
+	}
+	copied := *v
+	return &copied
+}
+
+// copyTimePtr 呼び出し元と値を共有しないようにコピーする
+func copyTimePtr(v *time.Time) *time.Time {
+	if v == nil {
+		return nil
+	}
+	copied := *v
+	return &copied
+}
+
+// dateKey 日付の比較用に "2006-01-02" 形式にする
+func dateKey(s string) string {
+	t, err := models.ParseDate(s)
+	if err != nil {
+		return s
+	}
+	return t.Format(models.DateLayout)
+}
+
+// inDateRange start・end が空の場合はその条件を無視する
+func inDateRange(date, start, end string) bool {
+	key := dateKey(date)
+	if start != "" && key < dateKey(start) {
+		return false
+	}
+	if end != "" && key > dateKey(end) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"sort"
+
+	"shift-management-backend/models"
+)
+
+type memoryAttendanceRepository struct {
+	s *memoryStore
+}
+
+func (r *memoryAttendanceRepository) List(filter AttendanceFilter) ([]models.Attendance, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var attendances []models.Attendance
+	for _, att := range r.s.data.attendance {
+		if filter.EmployeeID != nil && att.EmployeeID != *filter.EmployeeID {
+			continue
+		}
+		if !inDateRange(att.Date, filter.StartDate, filter.EndDate) {
+			continue
+		}
+		att.EmployeeName = r.s.employeeName(att.EmployeeID)
+		attendances = append(attendances, att)
+	}
+
+	sort.Slice(attendances, func(i, j int) bool {
+		if attendances[i].Date != attendances[j].Date {
+			return attendances[i].Date > attendances[j].Date
+		}
+		return attendances[i].CreatedAt.After(attendances[j].CreatedAt)
+	})
+	return attendances, nil
+}
+
+func (r *memoryAttendanceRepository) Get(id int) (models.Attendance, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	att, ok := r.s.data.attendance[id]
+	if !ok {
+		return models.Attendance{}, ErrNotFound
+	}
+	att.EmployeeName = r.s.employeeName(att.EmployeeID)
+	return att, nil
+}
+
+func (r *memoryAttendanceRepository) Create(attendance models.Attendance) (models.Attendance, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := r.s.now()
+	attendance.ID = r.s.nextID()
+	attendance.Date = memoryDate(attendance.Date)
+	attendance.ClockInTime = memoryClockPtr(attendance.ClockInTime)
+	attendance.ClockOutTime = memoryClockPtr(attendance.ClockOutTime)
+	attendance.ActualHours = copyFloatPtr(attendance.ActualHours)
+	attendance.ShiftID = copyIntPtr(attendance.ShiftID)
+	attendance.EmployeeName = ""
+	attendance.CreatedAt = now
+	attendance.UpdatedAt = now
+	r.s.data.attendance[attendance.ID] = attendance
+	return attendance, nil
+}
+
+func (r *memoryAttendanceRepository) Update(attendance models.Attendance) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	existing, ok := r.s.data.attendance[attendance.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	attendance.EmployeeID = existing.EmployeeID
+	attendance.Date = existing.Date
+	attendance.ClockInTime = memoryClockPtr(attendance.ClockInTime)
+	attendance.ClockOutTime = memoryClockPtr(attendance.ClockOutTime)
+	attendance.ActualHours = copyFloatPtr(attendance.ActualHours)
+	attendance.ShiftID = copyIntPtr(attendance.ShiftID)
+	attendance.EmployeeName = ""
+	attendance.CreatedAt = existing.CreatedAt
+	attendance.UpdatedAt = r.s.now()
+	r.s.data.attendance[attendance.ID] = attendance
+	return nil
+}
+
+// copyFloatPtr 呼び出し元と値を共有しないようにコピーする
+func copyFloatPtr(v *float64) *float64 {
+	if v == nil {
+		return nil
+	}
+	copied := *v
+	return &copied
+}
+
+func (r *memoryAttendanceRepository) Delete(id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.data.attendance[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.s.data.attendance, id)
+	return nil
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"sort"
+
+	"shift-management-backend/models"
+)
+
+type memoryAvailabilityProfileRepository struct {
+	s *memoryStore
+}
+
+// memoryAvailabilityProfile 呼び出し元と共有しないように複製し、日付・時刻の形式と時間帯の並び順を揃える
+func memoryAvailabilityProfile(p models.AvailabilityProfile) models.AvailabilityProfile {
+	p.ValidFrom = memoryDate(p.ValidFrom)
+	if p.ValidTo != nil {
+		to := memoryDate(*p.ValidTo)
+		p.ValidTo = &to
+	}
+	windows := make([]models.AvailabilityWindow, len(p.Windows))
+	for i, w := range p.Windows {
+		w.StartTime = memoryClock(w.StartTime)
+		w.EndTime = memoryClock(w.EndTime)
+		windows[i] = w
+	}
+	sort.SliceStable(windows, func(i, j int) bool {
+		if windows[i].Weekday != windows[j].Weekday {
+			return windows[i].Weekday < windows[j].Weekday
+		}
+		return windows[i].StartTime < windows[j].StartTime
+	})
+	p.Windows = windows
+	p.EmployeeName = ""
+	return p
+}
+
+func (r *memoryAvailabilityProfileRepository) List(employeeID *int) ([]models.AvailabilityProfile, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var profiles []models.AvailabilityProfile
+	for _, p := range r.s.data.availability {
+		if employeeID != nil && p.EmployeeID != *employeeID {
+			continue
+		}
+		p = memoryAvailabilityProfile(p)
+		p.EmployeeName = r.s.employeeName(p.EmployeeID)
+		profiles = append(profiles, p)
+	}
+
+	sort.Slice(profiles, func(i, j int) bool {
+		if profiles[i].EmployeeID != profiles[j].EmployeeID {
+			return profiles[i].EmployeeID < profiles[j].EmployeeID
+		}
+		return profiles[i].ValidFrom < profiles[j].ValidFrom
+	})
+	return profiles, nil
+}
+
+func (r *memoryAvailabilityProfileRepository) Get(id int) (models.AvailabilityProfile, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	p, ok := r.s.data.availability[id]
+	if !ok {
+		return models.AvailabilityProfile{}, ErrNotFound
+	}
+	p = memoryAvailabilityProfile(p)
+	p.EmployeeName = r.s.employeeName(p.EmployeeID)
+	return p, nil
+}
+
+func (r *memoryAvailabilityProfileRepository) Create(profile models.AvailabilityProfile) (models.AvailabilityProfile, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := r.s.now()
+	profile = memoryAvailabilityProfile(profile)
+	profile.ID = r.s.nextID()
+	profile.CreatedAt = now
+	profile.UpdatedAt = now
+	r.s.data.availability[profile.ID] = profile
+	profile = memoryAvailabilityProfile(profile)
+	profile.EmployeeName = r.s.employeeName(profile.EmployeeID)
+	return profile, nil
+}
+
+func (r *memoryAvailabilityProfileRepository) Update(profile models.AvailabilityProfile) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	existing, ok := r.s.data.availability[profile.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	profile = memoryAvailabilityProfile(profile)
+	profile.CreatedAt = existing.CreatedAt
+	profile.UpdatedAt = r.s.now()
+	r.s.data.availability[profile.ID] = profile
+	return nil
+}
+
+func (r *memoryAvailabilityProfileRepository) Delete(id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.data.availability[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.s.data.availability, id)
+	return nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"sort"
+
+	"shift-management-backend/models"
+)
+
+type memoryDesiredHoursRepository struct {
+	s *memoryStore
+}
+
+func (r *memoryDesiredHoursRepository) List() ([]models.DesiredHours, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var list []models.DesiredHours
+	for _, hours := range r.s.data.desiredHours {
+		hours.EmployeeName = r.s.employeeName(hours.EmployeeID)
+		list = append(list, hours)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].EmployeeID < list[j].EmployeeID
+	})
+	return list, nil
+}
+
+func (r *memoryDesiredHoursRepository) GetByEmployee(employeeID int) (models.DesiredHours, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	hours, ok := r.s.data.desiredHours[employeeID]
+	if !ok {
+		return models.DesiredHours{}, ErrNotFound
+	}
+	hours.EmployeeName = r.s.employeeName(employeeID)
+	return hours, nil
+}
+
+func (r *memoryDesiredHoursRepository) Save(hours models.DesiredHours) (models.DesiredHours, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := r.s.now()
+	hours.CreatedAt = now
+	if existing, ok := r.s.data.desiredHours[hours.EmployeeID]; ok {
+		hours.CreatedAt = existing.CreatedAt
+	}
+	hours.UpdatedAt = now
+	hours.EmployeeName = ""
+	r.s.data.desiredHours[hours.EmployeeID] = hours
+
+	hours.EmployeeName = r.s.employeeName(hours.EmployeeID)
+	return hours, nil
+}
+
+func (r *memoryDesiredHoursRepository) Delete(employeeID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.data.desiredHours[employeeID]; !ok {
+		return ErrNotFound
+	}
+	delete(r.s.data.desiredHours, employeeID)
+	return nil
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"sort"
+
+	"shift-management-backend/models"
+)
+
+type memoryEmployeeRepository struct {
+	s *memoryStore
+}
+
+func (r *memoryEmployeeRepository) List() ([]models.Employee, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var employees []models.Employee
+	for _, emp := range r.s.data.employees {
+		employees = append(employees, emp)
+	}
+	sort.Slice(employees, func(i, j int) bool { return employees[i].ID < employees[j].ID })
+	return employees, nil
+}
+
+func (r *memoryEmployeeRepository) Get(id int) (models.Employee, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	emp, ok := r.s.data.employees[id]
+	if !ok {
+		return models.Employee{}, ErrNotFound
+	}
+	return emp, nil
+}
+
+func (r *memoryEmployeeRepository) Exists(id int) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	_, ok := r.s.data.employees[id]
+	return ok, nil
+}
+
+// Lock メモリ実装では InTx 全体が直列化されるため、存在確認のみ行う
+func (r *memoryEmployeeRepository) Lock(id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.data.employees[id]; !ok {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *memoryEmployeeRepository) Create(req models.CreateEmployeeRequest) (models.Employee, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := r.s.now()
+	emp := models.Employee{
+		ID:         r.s.nextID(),
+		Name:       req.Name,
+		HourlyWage: req.HourlyWage,
+		Positions:  models.NormalizePositions(req.Positions),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	r.s.data.employees[emp.ID] = emp
+	return emp, nil
+}
+
+func (r *memoryEmployeeRepository) Update(id int, req models.UpdateEmployeeRequest) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	emp, ok := r.s.data.employees[id]
+	if !ok {
+		return ErrNotFound
+	}
+	emp.Name = req.Name
+	emp.HourlyWage = req.HourlyWage
+	if req.Positions != nil {
+		emp.Positions = models.NormalizePositions(*req.Positions)
+	}
+	emp.UpdatedAt = r.s.now()
+	r.s.data.employees[id] = emp
+	return nil
+}
+
+func (r *memoryEmployeeRepository) Delete(id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.data.employees[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.s.data.employees, id)
+	// 権限設定・希望する勤務時間・勤務可能な時間帯は ON DELETE CASCADE
+	delete(r.s.data.desiredHours, id)
+	for aid, profile := range r.s.data.availability {
+		if profile.EmployeeID == id {
+			delete(r.s.data.availability, aid)
+		}
+	}
+	for pid, permission := range r.s.data.permissions {
+		if permission.EmployeeID == id {
+			delete(r.s.data.permissions, pid)
+		}
+	}
+	// シフトの担当者の履歴・募集したシフト交代・応募は ON DELETE CASCADE、引き受けたシフト交代は ON DELETE SET NULL
+	for hid, holder := range r.s.data.shiftHolders {
+		if holder.EmployeeID == id {
+			delete(r.s.data.shiftHolders, hid)
+		}
+	}
+	for cid, claim := range r.s.data.openShiftClaims {
+		if claim.EmployeeID == id {
+			delete(r.s.data.openShiftClaims, cid)
+		}
+	}
+	// 繰り返しシフトは ON DELETE CASCADE（作成済みのシフトの pattern_id は ON DELETE SET NULL）
+	for pid, pattern := range r.s.data.shiftPatterns {
+		if pattern.EmployeeID == id {
+			r.s.deleteShiftPattern(pid)
+		}
+	}
+	for tid, trade := range r.s.data.shiftTrades {
+		if trade.OfferedBy == id {
+			delete(r.s.data.shiftTrades, tid)
+		} else if trade.AcceptedBy != nil && *trade.AcceptedBy == id {
+			trade.AcceptedBy = nil
+			r.s.data.shiftTrades[tid] = trade
+		}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package repository
+
+import "shift-management-backend/models"
+
+type memoryGanttSettingsRepository struct {
+	s *memoryStore
+}
+
+func (r *memoryGanttSettingsRepository) Get() (models.GanttSettings, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if r.s.data.gantt == nil {
+		return models.GanttSettings{}, ErrNotFound
+	}
+	return *r.s.data.gantt, nil
+}
+
+func (r *memoryGanttSettingsRepository) Replace(startHour, endHour int) (models.GanttSettings, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := r.s.now()
+	settings := models.GanttSettings{
+		ID:        r.s.nextID(),
+		StartHour: startHour,
+		EndHour:   endHour,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	r.s.data.gantt = &settings
+	return settings, nil
+}
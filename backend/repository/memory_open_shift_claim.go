@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"sort"
+
+	"shift-management-backend/models"
+)
+
+type memoryOpenShiftClaimRepository struct {
+	s *memoryStore
+}
+
+func (r *memoryOpenShiftClaimRepository) List(filter OpenShiftClaimFilter) ([]models.OpenShiftClaim, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var claims []models.OpenShiftClaim
+	for _, c := range r.s.data.openShiftClaims {
+		if filter.ShiftID != nil && c.ShiftID != *filter.ShiftID {
+			continue
+		}
+		if filter.EmployeeID != nil && c.EmployeeID != *filter.EmployeeID {
+			continue
+		}
+		if filter.Status != "" && c.Status != filter.Status {
+			continue
+		}
+		c.EmployeeName = r.s.employeeName(c.EmployeeID)
+		claims = append(claims, c)
+	}
+
+	sort.Slice(claims, func(i, j int) bool {
+		if !claims[i].CreatedAt.Equal(claims[j].CreatedAt) {
+			return claims[i].CreatedAt.Before(claims[j].CreatedAt)
+		}
+		return claims[i].ID < claims[j].ID
+	})
+	return claims, nil
+}
+
+func (r *memoryOpenShiftClaimRepository) Get(id int) (models.OpenShiftClaim, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	c, ok := r.s.data.openShiftClaims[id]
+	if !ok {
+		return models.OpenShiftClaim{}, ErrNotFound
+	}
+	c.EmployeeName = r.s.employeeName(c.EmployeeID)
+	return c, nil
+}
+
+func (r *memoryOpenShiftClaimRepository) Create(claim models.OpenShiftClaim) (models.OpenShiftClaim, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := r.s.now()
+	claim.ID = r.s.nextID()
+	claim.Shift = nil
+	claim.CreatedAt = now
+	claim.UpdatedAt = now
+	r.s.data.openShiftClaims[claim.ID] = claim
+	claim.EmployeeName = r.s.employeeName(claim.EmployeeID)
+	return claim, nil
+}
+
+func (r *memoryOpenShiftClaimRepository) Update(claim models.OpenShiftClaim) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	existing, ok := r.s.data.openShiftClaims[claim.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	// 対象のシフトと応募した従業員は変更しない
+	existing.Status = claim.Status
+	existing.DecisionReason = claim.DecisionReason
+	existing.DecidedAt = claim.DecidedAt
+	existing.UpdatedAt = r.s.now()
+	r.s.data.openShiftClaims[claim.ID] = existing
+	return nil
+}
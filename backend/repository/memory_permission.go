@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"sort"
+	"time"
+
+	"shift-management-backend/models"
+)
+
+type memoryPermissionRepository struct {
+	s *memoryStore
+}
+
+func (r *memoryPermissionRepository) List() ([]models.EmployeePermission, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var permissions []models.EmployeePermission
+	for _, permission := range r.s.data.permissions {
+		permission.EmployeeName = r.s.employeeName(permission.EmployeeID)
+		permissions = append(permissions, permission)
+	}
+	sort.Slice(permissions, func(i, j int) bool {
+		return permissions[i].EmployeeID < permissions[j].EmployeeID
+	})
+	return permissions, nil
+}
+
+func (r *memoryPermissionRepository) GetByEmployee(employeeID int) (models.EmployeePermission, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	permission, ok := r.findByEmployee(employeeID)
+	if !ok {
+		return models.EmployeePermission{}, ErrNotFound
+	}
+	permission.EmployeeName = r.s.employeeName(employeeID)
+	return permission, nil
+}
+
+func (r *memoryPermissionRepository) Save(req models.CreatePermissionRequest) (models.EmployeePermission, bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := r.s.now().Format(time.RFC3339)
+	permission, exists := r.findByEmployee(req.EmployeeID)
+	if !exists {
+		permission = models.EmployeePermission{
+			ID:         r.s.nextID(),
+			EmployeeID: req.EmployeeID,
+			CreatedAt:  now,
+		}
+	}
+	permission.CanViewOtherShifts = req.CanViewOtherShifts
+	permission.CanViewPayroll = req.CanViewPayroll
+	permission.CanEditAttendance = req.CanEditAttendance
+	permission.CanSubmitShiftRequests = req.CanSubmitShiftRequests
+	permission.UpdatedAt = now
+	r.s.data.permissions[permission.ID] = permission
+
+	permission.EmployeeName = r.s.employeeName(req.EmployeeID)
+	return permission, !exists, nil
+}
+
+func (r *memoryPermissionRepository) Delete(id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.data.permissions[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.s.data.permissions, id)
+	return nil
+}
+
+// findByEmployee 呼び出し側でロックを取ること
+func (r *memoryPermissionRepository) findByEmployee(employeeID int) (models.EmployeePermission, bool) {
+	for _, permission := range r.s.data.permissions {
+		if permission.EmployeeID == employeeID {
+			return permission, true
+		}
+	}
+	return models.EmployeePermission{}, false
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"sort"
+	"time"
+
+	"shift-management-backend/models"
+)
+
+type memoryScheduleJobRepository struct {
+	s *memoryStore
+}
+
+func (r *memoryScheduleJobRepository) List() ([]models.ScheduleJob, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var jobs []models.ScheduleJob
+	for _, job := range r.s.data.scheduleJobs {
+		jobs = append(jobs, job)
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		if !jobs[i].CreatedAt.Equal(jobs[j].CreatedAt) {
+			return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
+		}
+		return jobs[i].ID > jobs[j].ID
+	})
+	return jobs, nil
+}
+
+func (r *memoryScheduleJobRepository) Get(id int) (models.ScheduleJob, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	job, ok := r.s.data.scheduleJobs[id]
+	if !ok {
+		return models.ScheduleJob{}, ErrNotFound
+	}
+	return job, nil
+}
+
+// Lock メモリ実装では InTx 全体が直列化されるため、存在確認のみ行う
+func (r *memoryScheduleJobRepository) Lock(id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.data.scheduleJobs[id]; !ok {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *memoryScheduleJobRepository) Create(job models.ScheduleJob) (models.ScheduleJob, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	job.ID = r.s.nextID()
+	job.StartDate = memoryDate(job.StartDate)
+	job.EndDate = memoryDate(job.EndDate)
+	job.CreatedAt = r.s.now()
+	r.s.data.scheduleJobs[job.ID] = job
+	return job, nil
+}
+
+func (r *memoryScheduleJobRepository) Update(job models.ScheduleJob) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	existing, ok := r.s.data.scheduleJobs[job.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	// 対象期間と条件は作成後に変更しない
+	existing.Status = job.Status
+	existing.Result = job.Result
+	existing.Error = job.Error
+	existing.FinishedAt = job.FinishedAt
+	r.s.data.scheduleJobs[job.ID] = existing
+	return nil
+}
+
+func (r *memoryScheduleJobRepository) RenewLease(id int, expiresAt time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	job, ok := r.s.data.scheduleJobs[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if job.Status == models.ScheduleJobPending || job.Status == models.ScheduleJobRunning {
+		job.LeaseExpiresAt = &expiresAt
+		r.s.data.scheduleJobs[id] = job
+	}
+	return nil
+}
+
+func (r *memoryScheduleJobRepository) FailExpired(now time.Time, message string) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	failed := 0
+	for id, job := range r.s.data.scheduleJobs {
+		if job.Status != models.ScheduleJobPending && job.Status != models.ScheduleJobRunning {
+			continue
+		}
+		if job.LeaseExpiresAt != nil && !job.LeaseExpiresAt.Before(now) {
+			continue
+		}
+		finishedAt := now
+		job.Status = models.ScheduleJobFailed
+		job.Error = message
+		job.FinishedAt = &finishedAt
+		r.s.data.scheduleJobs[id] = job
+		failed++
+	}
+	return failed, nil
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"sort"
+
+	"shift-management-backend/models"
+)
+
+type memorySchedulePeriodRepository struct {
+	s *memoryStore
+}
+
+func (r *memorySchedulePeriodRepository) List() ([]models.SchedulePeriod, error) {
+	return r.ListOverlapping("", "")
+}
+
+func (r *memorySchedulePeriodRepository) ListOverlapping(startDate, endDate string) ([]models.SchedulePeriod, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var periods []models.SchedulePeriod
+	for _, p := range r.s.data.schedulePeriods {
+		if (startDate != "" && dateKey(p.EndDate) < dateKey(startDate)) ||
+			(endDate != "" && dateKey(p.StartDate) > dateKey(endDate)) {
+			continue
+		}
+		periods = append(periods, p)
+	}
+
+	sort.Slice(periods, func(i, j int) bool {
+		return periods[i].StartDate > periods[j].StartDate
+	})
+	return periods, nil
+}
+
+func (r *memorySchedulePeriodRepository) Get(id int) (models.SchedulePeriod, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	p, ok := r.s.data.schedulePeriods[id]
+	if !ok {
+		return models.SchedulePeriod{}, ErrNotFound
+	}
+	return p, nil
+}
+
+func (r *memorySchedulePeriodRepository) Create(period models.SchedulePeriod) (models.SchedulePeriod, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := r.s.now()
+	period.ID = r.s.nextID()
+	period.StartDate = memoryDate(period.StartDate)
+	period.EndDate = memoryDate(period.EndDate)
+	period.PublishedVersion = 0
+	period.PublishedAt = nil
+	period.CreatedAt = now
+	period.UpdatedAt = now
+	r.s.data.schedulePeriods[period.ID] = period
+	return period, nil
+}
+
+func (r *memorySchedulePeriodRepository) Update(period models.SchedulePeriod) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	existing, ok := r.s.data.schedulePeriods[period.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	// 期間は作成後に変更しない
+	existing.Status = period.Status
+	existing.PublishedVersion = period.PublishedVersion
+	existing.PublishedAt = period.PublishedAt
+	existing.UpdatedAt = r.s.now()
+	r.s.data.schedulePeriods[period.ID] = existing
+	return nil
+}
+
+func (r *memorySchedulePeriodRepository) Delete(id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.data.schedulePeriods[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.s.data.schedulePeriods, id)
+	for snapshotID, snapshot := range r.s.data.snapshots {
+		if snapshot.PeriodID == id {
+			delete(r.s.data.snapshots, snapshotID)
+		}
+	}
+	return nil
+}
+
+func (r *memorySchedulePeriodRepository) CreateSnapshot(snapshot models.SchedulePeriodSnapshot) (models.SchedulePeriodSnapshot, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	snapshot.ID = r.s.nextID()
+	snapshot.Shifts = append([]models.Shift{}, snapshot.Shifts...)
+	snapshot.CreatedAt = r.s.now()
+	r.s.data.snapshots[snapshot.ID] = snapshot
+	return snapshot, nil
+}
+
+func (r *memorySchedulePeriodRepository) Snapshot(periodID, version int) (models.SchedulePeriodSnapshot, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, snapshot := range r.s.data.snapshots {
+		if snapshot.PeriodID == periodID && snapshot.Version == version {
+			snapshot.Shifts = append([]models.Shift{}, snapshot.Shifts...)
+			return snapshot, nil
+		}
+	}
+	return models.SchedulePeriodSnapshot{}, ErrNotFound
+}
+
+func (r *memorySchedulePeriodRepository) ListSnapshots(periodID int) ([]models.SchedulePeriodSnapshot, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var snapshots []models.SchedulePeriodSnapshot
+	for _, snapshot := range r.s.data.snapshots {
+		if snapshot.PeriodID == periodID {
+			snapshot.Shifts = nil
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Version > snapshots[j].Version
+	})
+	return snapshots, nil
+}
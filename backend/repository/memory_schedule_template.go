@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"sort"
+
+	"shift-management-backend/models"
+)
+
+type memoryScheduleTemplateRepository struct {
+	s *memoryStore
+}
+
+func (r *memoryScheduleTemplateRepository) List() ([]models.ScheduleTemplate, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var templates []models.ScheduleTemplate
+	for _, t := range r.s.data.templates {
+		t.ShiftCount = len(t.Shifts)
+		t.Shifts = nil
+		templates = append(templates, t)
+	}
+
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+	return templates, nil
+}
+
+func (r *memoryScheduleTemplateRepository) Get(id int) (models.ScheduleTemplate, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	t, ok := r.s.data.templates[id]
+	if !ok {
+		return models.ScheduleTemplate{}, ErrNotFound
+	}
+	t.Shifts = memoryTemplateShifts(t.Shifts)
+	t.ShiftCount = len(t.Shifts)
+	return t, nil
+}
+
+func (r *memoryScheduleTemplateRepository) Create(template models.ScheduleTemplate) (models.ScheduleTemplate, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := r.s.now()
+	template.ID = r.s.nextID()
+	template.Shifts = memoryTemplateShifts(template.Shifts)
+	for i := range template.Shifts {
+		template.Shifts[i].ID = r.s.nextID()
+	}
+	sort.SliceStable(template.Shifts, func(i, j int) bool {
+		a, b := template.Shifts[i], template.Shifts[j]
+		if a.Weekday != b.Weekday {
+			return a.Weekday < b.Weekday
+		}
+		return a.StartTime < b.StartTime
+	})
+	template.ShiftCount = len(template.Shifts)
+	template.CreatedAt = now
+	template.UpdatedAt = now
+	r.s.data.templates[template.ID] = template
+
+	template.Shifts = memoryTemplateShifts(template.Shifts)
+	return template, nil
+}
+
+func (r *memoryScheduleTemplateRepository) Delete(id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.data.templates[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.s.data.templates, id)
+	return nil
+}
+
+// memoryTemplateShifts 呼び出し元と共有しないようにテンプレートのシフトを複製し、時刻の形式を揃える
+func memoryTemplateShifts(shifts []models.TemplateShift) []models.TemplateShift {
+	copied := make([]models.TemplateShift, len(shifts))
+	for i, s := range shifts {
+		s.StartTime = memoryClock(s.StartTime)
+		s.EndTime = memoryClock(s.EndTime)
+		s.Segments = memorySegments(s.Segments)
+		copied[i] = s
+	}
+	return copied
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"sort"
+
+	"shift-management-backend/models"
+)
+
+type memoryShiftRepository struct {
+	s *memoryStore
+}
+
+func (r *memoryShiftRepository) List(filter ShiftFilter) ([]models.Shift, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var shifts []models.Shift
+	for _, shift := range r.s.data.shifts {
+		if filter.EmployeeID != nil && shift.EmployeeID != *filter.EmployeeID {
+			continue
+		}
+		if filter.OpenOnly && !shift.IsOpen() {
+			continue
+		}
+		if filter.PatternID != nil && (shift.PatternID == nil || *shift.PatternID != *filter.PatternID) {
+			continue
+		}
+		if !inDateRange(shift.Date, filter.StartDate, filter.EndDate) {
+			continue
+		}
+		shift.EmployeeName = r.s.employeeName(shift.EmployeeID)
+		shifts = append(shifts, shift)
+	}
+
+	sort.Slice(shifts, func(i, j int) bool {
+		if shifts[i].Date != shifts[j].Date {
+			if filter.NewestFirst {
+				return shifts[i].Date > shifts[j].Date
+			}
+			return shifts[i].Date < shifts[j].Date
+		}
+		return shifts[i].StartTime < shifts[j].StartTime
+	})
+	return shifts, nil
+}
+
+func (r *memoryShiftRepository) Get(id int) (models.Shift, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	shift, ok := r.s.data.shifts[id]
+	if !ok {
+		return models.Shift{}, ErrNotFound
+	}
+	shift.EmployeeName = r.s.employeeName(shift.EmployeeID)
+	return shift, nil
+}
+
+func (r *memoryShiftRepository) Create(shift models.Shift) (models.Shift, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := r.s.now()
+	shift.ID = r.s.nextID()
+	shift.Date = memoryDate(shift.Date)
+	shift.StartTime = memoryClock(shift.StartTime)
+	shift.EndTime = memoryClock(shift.EndTime)
+	shift.Segments = memorySegments(shift.Segments)
+	shift.PatternID = copyIntPtr(shift.PatternID)
+	shift.RequestID = copyIntPtr(shift.RequestID)
+	shift.EmployeeName = ""
+	shift.CreatedAt = now
+	shift.UpdatedAt = now
+	r.s.data.shifts[shift.ID] = shift
+	return shift, nil
+}
+
+func (r *memoryShiftRepository) Update(shift models.Shift) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	existing, ok := r.s.data.shifts[shift.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	shift.Date = memoryDate(shift.Date)
+	shift.StartTime = memoryClock(shift.StartTime)
+	shift.EndTime = memoryClock(shift.EndTime)
+	shift.Segments = memorySegments(shift.Segments)
+	shift.PatternID = copyIntPtr(shift.PatternID)
+	shift.RequestID = copyIntPtr(shift.RequestID)
+	shift.EmployeeName = ""
+	shift.CreatedAt = existing.CreatedAt
+	shift.UpdatedAt = r.s.now()
+	r.s.data.shifts[shift.ID] = shift
+	return nil
+}
+
+// Lock メモリ実装では InTx 全体が直列化されるため、存在確認のみ行う
+func (r *memoryShiftRepository) Lock(id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.data.shifts[id]; !ok {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *memoryShiftRepository) Delete(id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.data.shifts[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.s.data.shifts, id)
+	// 担当者の履歴・交代の募集・応募は ON DELETE CASCADE、交換で差し出すシフトは ON DELETE SET NULL
+	for hid, holder := range r.s.data.shiftHolders {
+		if holder.ShiftID == id {
+			delete(r.s.data.shiftHolders, hid)
+		}
+	}
+	for cid, claim := range r.s.data.openShiftClaims {
+		if claim.ShiftID == id {
+			delete(r.s.data.openShiftClaims, cid)
+		}
+	}
+	for tid, trade := range r.s.data.shiftTrades {
+		if trade.ShiftID == id {
+			delete(r.s.data.shiftTrades, tid)
+		} else if trade.SwapShiftID != nil && *trade.SwapShiftID == id {
+			trade.SwapShiftID = nil
+			r.s.data.shiftTrades[tid] = trade
+		}
+	}
+	// 照合した出退勤記録の shift_id は ON DELETE SET NULL
+	for aid, att := range r.s.data.attendance {
+		if att.ShiftID != nil && *att.ShiftID == id {
+			att.ShiftID = nil
+			r.s.data.attendance[aid] = att
+		}
+	}
+	return nil
+}
+
+func (r *memoryShiftRepository) AddHolder(holder models.ShiftHolder) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	holder.ID = r.s.nextID()
+	holder.TradeID = copyIntPtr(holder.TradeID)
+	holder.EmployeeName = ""
+	holder.CreatedAt = r.s.now()
+	r.s.data.shiftHolders[holder.ID] = holder
+	return nil
+}
+
+func (r *memoryShiftRepository) Holders(shiftID int) ([]models.ShiftHolder, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var holders []models.ShiftHolder
+	for _, holder := range r.s.data.shiftHolders {
+		if holder.ShiftID == shiftID {
+			holder.EmployeeName = r.s.employeeName(holder.EmployeeID)
+			holders = append(holders, holder)
+		}
+	}
+
+	sort.Slice(holders, func(i, j int) bool {
+		if !holders[i].CreatedAt.Equal(holders[j].CreatedAt) {
+			return holders[i].CreatedAt.Before(holders[j].CreatedAt)
+		}
+		return holders[i].ID < holders[j].ID
+	})
+	return holders, nil
+}
+
+// memorySegments 呼び出し元と共有しないようにポジション区間を複製し、時刻の形式を揃える
+func memorySegments(segments []models.ShiftSegment) []models.ShiftSegment {
+	if len(segments) == 0 {
+		return nil
+	}
+	copied := make([]models.ShiftSegment, len(segments))
+	for i, seg := range segments {
+		seg.StartTime = memoryClock(seg.StartTime)
+		seg.EndTime = memoryClock(seg.EndTime)
+		copied[i] = seg
+	}
+	return copied
+}
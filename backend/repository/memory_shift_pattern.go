@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"sort"
+
+	"shift-management-backend/models"
+)
+
+type memoryShiftPatternRepository struct {
+	s *memoryStore
+}
+
+// memoryShiftPattern 呼び出し元と共有しないように複製し、日付・時刻の形式を揃える
+func memoryShiftPattern(p models.ShiftPattern) models.ShiftPattern {
+	p.Weekdays = append([]int{}, p.Weekdays...)
+	p.StartDate = memoryDate(p.StartDate)
+	if p.EndDate != nil {
+		end := memoryDate(*p.EndDate)
+		p.EndDate = &end
+	}
+	p.StartTime = memoryClock(p.StartTime)
+	p.EndTime = memoryClock(p.EndTime)
+	// PostgreSQL の DATE[] は YYYY-MM-DD 形式で読み込まれる
+	exceptions := make([]string, len(p.Exceptions))
+	for i, date := range p.Exceptions {
+		if day, err := models.ParseDate(date); err == nil {
+			date = day.Format(models.DateLayout)
+		}
+		exceptions[i] = date
+	}
+	p.Exceptions = exceptions
+	p.EmployeeName = ""
+	return p
+}
+
+func (r *memoryShiftPatternRepository) List(employeeID *int) ([]models.ShiftPattern, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var patterns []models.ShiftPattern
+	for _, p := range r.s.data.shiftPatterns {
+		if employeeID != nil && p.EmployeeID != *employeeID {
+			continue
+		}
+		p = memoryShiftPattern(p)
+		p.EmployeeName = r.s.employeeName(p.EmployeeID)
+		patterns = append(patterns, p)
+	}
+
+	sort.Slice(patterns, func(i, j int) bool {
+		if patterns[i].StartDate != patterns[j].StartDate {
+			return patterns[i].StartDate < patterns[j].StartDate
+		}
+		return patterns[i].ID < patterns[j].ID
+	})
+	return patterns, nil
+}
+
+func (r *memoryShiftPatternRepository) Get(id int) (models.ShiftPattern, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	p, ok := r.s.data.shiftPatterns[id]
+	if !ok {
+		return models.ShiftPattern{}, ErrNotFound
+	}
+	p = memoryShiftPattern(p)
+	p.EmployeeName = r.s.employeeName(p.EmployeeID)
+	return p, nil
+}
+
+func (r *memoryShiftPatternRepository) Create(pattern models.ShiftPattern) (models.ShiftPattern, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := r.s.now()
+	pattern = memoryShiftPattern(pattern)
+	pattern.ID = r.s.nextID()
+	pattern.CreatedAt = now
+	pattern.UpdatedAt = now
+	r.s.data.shiftPatterns[pattern.ID] = pattern
+	pattern = memoryShiftPattern(pattern)
+	pattern.EmployeeName = r.s.employeeName(pattern.EmployeeID)
+	return pattern, nil
+}
+
+func (r *memoryShiftPatternRepository) Update(pattern models.ShiftPattern) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	existing, ok := r.s.data.shiftPatterns[pattern.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	pattern = memoryShiftPattern(pattern)
+	pattern.CreatedAt = existing.CreatedAt
+	pattern.UpdatedAt = r.s.now()
+	r.s.data.shiftPatterns[pattern.ID] = pattern
+	return nil
+}
+
+func (r *memoryShiftPatternRepository) Delete(id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.data.shiftPatterns[id]; !ok {
+		return ErrNotFound
+	}
+	r.s.deleteShiftPattern(id)
+	return nil
+}
+
+// deleteShiftPattern 繰り返しシフトを削除し、作成済みのシフトを繰り返しから外す（呼び出し側でロックを取ること）
+func (s *memoryStore) deleteShiftPattern(id int) {
+	delete(s.data.shiftPatterns, id)
+	for sid, shift := range s.data.shifts {
+		if shift.PatternID != nil && *shift.PatternID == id {
+			shift.PatternID = nil
+			s.data.shifts[sid] = shift
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"sort"
+
+	"shift-management-backend/models"
+)
+
+type memoryShiftRequestRepository struct {
+	s *memoryStore
+}
+
+func (r *memoryShiftRequestRepository) List(employeeID *int) ([]models.ShiftRequest, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var requests []models.ShiftRequest
+	for _, req := range r.s.data.shiftRequests {
+		if employeeID != nil && req.EmployeeID != *employeeID {
+			continue
+		}
+		req.Windows = memoryWindows(req.Windows)
+		req.Shifts = r.s.requestShifts(req.ID)
+		req.EmployeeName = r.s.employeeName(req.EmployeeID)
+		requests = append(requests, req)
+	}
+
+	sort.Slice(requests, func(i, j int) bool {
+		if requests[i].Date != requests[j].Date {
+			return requests[i].Date > requests[j].Date
+		}
+		return requests[i].CreatedAt.After(requests[j].CreatedAt)
+	})
+	return requests, nil
+}
+
+func (r *memoryShiftRequestRepository) Get(id int) (models.ShiftRequest, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	req, ok := r.s.data.shiftRequests[id]
+	if !ok {
+		return models.ShiftRequest{}, ErrNotFound
+	}
+	req.Windows = memoryWindows(req.Windows)
+	req.Shifts = r.s.requestShifts(req.ID)
+	req.EmployeeName = r.s.employeeName(req.EmployeeID)
+	return req, nil
+}
+
+func (r *memoryShiftRequestRepository) Create(request models.ShiftRequest) (models.ShiftRequest, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := r.s.now()
+	request.ID = r.s.nextID()
+	request.Date = memoryDate(request.Date)
+	request.Windows = memoryWindows(request.Windows)
+	request.PeriodID = copyIntPtr(request.PeriodID)
+	request.ReviewedAt = copyTimePtr(request.ReviewedAt)
+	request.Shifts = nil
+	request.EmployeeName = ""
+	request.CreatedAt = now
+	request.UpdatedAt = now
+	r.s.data.shiftRequests[request.ID] = request
+
+	request.Shifts = []models.ShiftRequestShift{}
+	return request, nil
+}
+
+func (r *memoryShiftRequestRepository) Update(request models.ShiftRequest) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	existing, ok := r.s.data.shiftRequests[request.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	request.EmployeeID = existing.EmployeeID
+	request.Date = memoryDate(request.Date)
+	request.Windows = memoryWindows(request.Windows)
+	request.PeriodID = copyIntPtr(request.PeriodID)
+	request.ReviewedAt = copyTimePtr(request.ReviewedAt)
+	request.Shifts = nil
+	request.EmployeeName = ""
+	request.CreatedAt = existing.CreatedAt
+	request.UpdatedAt = r.s.now()
+	r.s.data.shiftRequests[request.ID] = request
+	return nil
+}
+
+func (r *memoryShiftRequestRepository) Delete(id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.data.shiftRequests[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.s.data.shiftRequests, id)
+	// 承認して作成したシフトの request_id は ON DELETE SET NULL
+	for sid, shift := range r.s.data.shifts {
+		if shift.RequestID != nil && *shift.RequestID == id {
+			shift.RequestID = nil
+			r.s.data.shifts[sid] = shift
+		}
+	}
+	return nil
+}
+
+// requestShifts シフト希望を承認して作成したシフト（呼び出し側でロックを取ること）
+func (s *memoryStore) requestShifts(requestID int) []models.ShiftRequestShift {
+	shifts := []models.ShiftRequestShift{}
+	for _, shift := range s.data.shifts {
+		if shift.RequestID == nil || *shift.RequestID != requestID {
+			continue
+		}
+		shifts = append(shifts, models.ShiftRequestShift{
+			ID:          shift.ID,
+			Date:        shift.Date,
+			StartTime:   shift.StartTime,
+			EndTime:     shift.EndTime,
+			EndsNextDay: shift.EndsNextDay,
+			Position:    shift.Position,
+		})
+	}
+	sort.Slice(shifts, func(i, j int) bool {
+		if shifts[i].Date != shifts[j].Date {
+			return shifts[i].Date < shifts[j].Date
+		}
+		return shifts[i].StartTime < shifts[j].StartTime
+	})
+	return shifts
+}
+
+// memoryWindows 呼び出し元と共有しないように時間帯を複製し、時刻の形式と並び順（開始時刻順）を揃える
+func memoryWindows(windows []models.ShiftRequestWindow) []models.ShiftRequestWindow {
+	copied := make([]models.ShiftRequestWindow, len(windows))
+	for i, w := range windows {
+		w.StartTime = memoryClock(w.StartTime)
+		w.EndTime = memoryClock(w.EndTime)
+		copied[i] = w
+	}
+	sort.SliceStable(copied, func(i, j int) bool {
+		return copied[i].StartTime < copied[j].StartTime
+	})
+	return copied
+}
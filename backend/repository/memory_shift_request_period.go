@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"sort"
+
+	"shift-management-backend/models"
+)
+
+type memoryShiftRequestPeriodRepository struct {
+	s *memoryStore
+}
+
+func (r *memoryShiftRequestPeriodRepository) List() ([]models.ShiftRequestPeriod, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var periods []models.ShiftRequestPeriod
+	for _, p := range r.s.data.requestPeriods {
+		periods = append(periods, p)
+	}
+
+	sort.Slice(periods, func(i, j int) bool {
+		return periods[i].StartDate > periods[j].StartDate
+	})
+	return periods, nil
+}
+
+func (r *memoryShiftRequestPeriodRepository) Get(id int) (models.ShiftRequestPeriod, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	p, ok := r.s.data.requestPeriods[id]
+	if !ok {
+		return models.ShiftRequestPeriod{}, ErrNotFound
+	}
+	return p, nil
+}
+
+func (r *memoryShiftRequestPeriodRepository) FindByDate(date string) (models.ShiftRequestPeriod, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var found *models.ShiftRequestPeriod
+	for _, p := range r.s.data.requestPeriods {
+		if !p.Contains(date) {
+			continue
+		}
+		if found == nil || p.StartDate < found.StartDate {
+			p := p
+			found = &p
+		}
+	}
+	if found == nil {
+		return models.ShiftRequestPeriod{}, ErrNotFound
+	}
+	return *found, nil
+}
+
+func (r *memoryShiftRequestPeriodRepository) Create(period models.ShiftRequestPeriod) (models.ShiftRequestPeriod, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := r.s.now()
+	period.ID = r.s.nextID()
+	period.StartDate = memoryDate(period.StartDate)
+	period.EndDate = memoryDate(period.EndDate)
+	period.ReminderHours = append([]int{}, period.ReminderHours...)
+	period.RemindedAt = nil
+	period.Status = ""
+	period.CreatedAt = now
+	period.UpdatedAt = now
+	r.s.data.requestPeriods[period.ID] = period
+	return period, nil
+}
+
+func (r *memoryShiftRequestPeriodRepository) Update(period models.ShiftRequestPeriod) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	existing, ok := r.s.data.requestPeriods[period.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	period.StartDate = memoryDate(period.StartDate)
+	period.EndDate = memoryDate(period.EndDate)
+	period.ReminderHours = append([]int{}, period.ReminderHours...)
+	period.Status = ""
+	period.CreatedAt = existing.CreatedAt
+	period.UpdatedAt = r.s.now()
+	r.s.data.requestPeriods[period.ID] = period
+	return nil
+}
+
+func (r *memoryShiftRequestPeriodRepository) Delete(id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.data.requestPeriods[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.s.data.requestPeriods, id)
+	for rid, req := range r.s.data.shiftRequests {
+		if req.PeriodID != nil && *req.PeriodID == id {
+			req.PeriodID = nil
+			r.s.data.shiftRequests[rid] = req
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"sort"
+
+	"shift-management-backend/models"
+)
+
+type memoryShiftTradeRepository struct {
+	s *memoryStore
+}
+
+// withNames 関連データを設定する（呼び出し側でロックを取ること）
+func (r *memoryShiftTradeRepository) withNames(t models.ShiftTrade) models.ShiftTrade {
+	t.OfferedByName = r.s.employeeName(t.OfferedBy)
+	t.AcceptedByName = ""
+	if t.AcceptedBy != nil {
+		t.AcceptedByName = r.s.employeeName(*t.AcceptedBy)
+	}
+	return t
+}
+
+func (r *memoryShiftTradeRepository) List(filter ShiftTradeFilter) ([]models.ShiftTrade, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var trades []models.ShiftTrade
+	for _, t := range r.s.data.shiftTrades {
+		if filter.Status != "" && t.Status != filter.Status {
+			continue
+		}
+		if filter.EmployeeID != nil && t.OfferedBy != *filter.EmployeeID &&
+			(t.AcceptedBy == nil || *t.AcceptedBy != *filter.EmployeeID) {
+			continue
+		}
+		trades = append(trades, r.withNames(t))
+	}
+
+	sort.Slice(trades, func(i, j int) bool {
+		if !trades[i].CreatedAt.Equal(trades[j].CreatedAt) {
+			return trades[i].CreatedAt.After(trades[j].CreatedAt)
+		}
+		return trades[i].ID > trades[j].ID
+	})
+	return trades, nil
+}
+
+func (r *memoryShiftTradeRepository) Get(id int) (models.ShiftTrade, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	t, ok := r.s.data.shiftTrades[id]
+	if !ok {
+		return models.ShiftTrade{}, ErrNotFound
+	}
+	return r.withNames(t), nil
+}
+
+// Lock メモリ実装では InTx 全体が直列化されるため、存在確認のみ行う
+func (r *memoryShiftTradeRepository) Lock(id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.data.shiftTrades[id]; !ok {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *memoryShiftTradeRepository) Active(shiftID int) (models.ShiftTrade, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, t := range r.s.data.shiftTrades {
+		if t.ShiftID == shiftID && t.IsActive() {
+			return r.withNames(t), nil
+		}
+	}
+	return models.ShiftTrade{}, ErrNotFound
+}
+
+func (r *memoryShiftTradeRepository) Create(trade models.ShiftTrade) (models.ShiftTrade, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := r.s.now()
+	trade.ID = r.s.nextID()
+	trade.AcceptedBy = nil
+	trade.SwapShiftID = nil
+	trade.DecisionReason = ""
+	trade.DecidedAt = nil
+	trade.Shift = nil
+	trade.CreatedAt = now
+	trade.UpdatedAt = now
+	r.s.data.shiftTrades[trade.ID] = trade
+	return r.withNames(trade), nil
+}
+
+func (r *memoryShiftTradeRepository) Update(trade models.ShiftTrade) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	existing, ok := r.s.data.shiftTrades[trade.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	// 対象のシフト・種類・募集した従業員は変更しない
+	existing.AcceptedBy = copyIntPtr(trade.AcceptedBy)
+	existing.SwapShiftID = copyIntPtr(trade.SwapShiftID)
+	existing.Status = trade.Status
+	existing.Note = trade.Note
+	existing.DecisionReason = trade.DecisionReason
+	existing.DecidedAt = trade.DecidedAt
+	existing.UpdatedAt = r.s.now()
+	r.s.data.shiftTrades[trade.ID] = existing
+	return nil
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"sort"
+
+	"shift-management-backend/models"
+)
+
+type memoryTimeSlotRepository struct {
+	s *memoryStore
+}
+
+func (r *memoryTimeSlotRepository) List(dayOfWeek *int) ([]models.TimeSlot, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var timeSlots []models.TimeSlot
+	for _, ts := range r.s.data.timeSlots {
+		if dayOfWeek != nil && ts.DayOfWeek != *dayOfWeek {
+			continue
+		}
+		timeSlots = append(timeSlots, ts)
+	}
+
+	sort.Slice(timeSlots, func(i, j int) bool {
+		a, b := timeSlots[i], timeSlots[j]
+		if a.DayOfWeek != b.DayOfWeek {
+			return a.DayOfWeek < b.DayOfWeek
+		}
+		if a.StartTime != b.StartTime {
+			return a.StartTime < b.StartTime
+		}
+		return a.Position < b.Position
+	})
+	return timeSlots, nil
+}
+
+func (r *memoryTimeSlotRepository) Get(id int) (models.TimeSlot, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	ts, ok := r.s.data.timeSlots[id]
+	if !ok {
+		return models.TimeSlot{}, ErrNotFound
+	}
+	return ts, nil
+}
+
+func (r *memoryTimeSlotRepository) Create(slot models.TimeSlot) (models.TimeSlot, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := r.s.now()
+	slot.ID = r.s.nextID()
+	slot.StartTime = memoryClock(slot.StartTime)
+	slot.EndTime = memoryClock(slot.EndTime)
+	slot.CreatedAt = now
+	slot.UpdatedAt = now
+	r.s.data.timeSlots[slot.ID] = slot
+	return slot, nil
+}
+
+func (r *memoryTimeSlotRepository) Update(slot models.TimeSlot) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	existing, ok := r.s.data.timeSlots[slot.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	slot.StartTime = memoryClock(slot.StartTime)
+	slot.EndTime = memoryClock(slot.EndTime)
+	slot.CreatedAt = existing.CreatedAt
+	slot.UpdatedAt = r.s.now()
+	r.s.data.timeSlots[slot.ID] = slot
+	return nil
+}
+
+func (r *memoryTimeSlotRepository) Delete(id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.data.timeSlots[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.s.data.timeSlots, id)
+	return nil
+}
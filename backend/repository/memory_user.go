@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"fmt"
+
+	"shift-management-backend/models"
+)
+
+type memoryUserRepository struct {
+	s *memoryStore
+}
+
+func (r *memoryUserRepository) FindByEmail(email string) (models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, user := range r.s.data.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return models.User{}, ErrNotFound
+}
+
+func (r *memoryUserRepository) ExistsByEmail(email string) (bool, error) {
+	_, err := r.FindByEmail(email)
+	if err == ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *memoryUserRepository) Create(user models.User) (models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, existing := range r.s.data.users {
+		if existing.Email == user.Email {
+			return models.User{}, fmt.Errorf("メールアドレス %s は既に登録されています", user.Email)
+		}
+	}
+
+	now := r.s.now()
+	user.ID = r.s.nextID()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	r.s.data.users[user.ID] = user
+
+	// PostgreSQL 実装と同様にパスワードハッシュは返さない
+	user.PasswordHash = ""
+	return user, nil
+}
+
+func (r *memoryUserRepository) DeleteByEmail(email string) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var deleted int64
+	for id, user := range r.s.data.users {
+		if user.Email == email {
+			delete(r.s.data.users, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
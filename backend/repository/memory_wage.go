@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"sort"
+
+	"shift-management-backend/models"
+)
+
+type memoryWageRepository struct {
+	s *memoryStore
+}
+
+func (r *memoryWageRepository) List(employeeID *int) ([]models.HourlyWage, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var wages []models.HourlyWage
+	for _, hw := range r.s.data.wages {
+		if employeeID != nil && hw.EmployeeID != *employeeID {
+			continue
+		}
+		hw.EmployeeName = r.s.employeeName(hw.EmployeeID)
+		wages = append(wages, hw)
+	}
+	sortWagesNewestFirst(wages)
+	return wages, nil
+}
+
+func (r *memoryWageRepository) Get(id int) (models.HourlyWage, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	hw, ok := r.s.data.wages[id]
+	if !ok {
+		return models.HourlyWage{}, ErrNotFound
+	}
+	hw.EmployeeName = r.s.employeeName(hw.EmployeeID)
+	return hw, nil
+}
+
+func (r *memoryWageRepository) ExistsOnDate(employeeID int, effectiveDate string) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	key := dateKey(effectiveDate)
+	for _, hw := range r.s.data.wages {
+		if hw.EmployeeID == employeeID && dateKey(hw.EffectiveDate) == key {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memoryWageRepository) Current(employeeID int) (models.HourlyWage, error) {
+	wages, err := r.List(&employeeID)
+	if err != nil {
+		return models.HourlyWage{}, err
+	}
+	if len(wages) == 0 {
+		return models.HourlyWage{}, ErrNotFound
+	}
+	return wages[0], nil
+}
+
+func (r *memoryWageRepository) Create(wage models.HourlyWage) (models.HourlyWage, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := r.s.now()
+	wage.ID = r.s.nextID()
+	wage.EffectiveDate = memoryDate(wage.EffectiveDate)
+	wage.EmployeeName = ""
+	wage.CreatedAt = now
+	wage.UpdatedAt = now
+	r.s.data.wages[wage.ID] = wage
+	return wage, nil
+}
+
+func (r *memoryWageRepository) Update(id int, req models.UpdateHourlyWageRequest) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	hw, ok := r.s.data.wages[id]
+	if !ok {
+		return ErrNotFound
+	}
+	hw.HourlyWage = req.HourlyWage
+	hw.EffectiveDate = memoryDate(req.EffectiveDate)
+	hw.UpdatedAt = r.s.now()
+	r.s.data.wages[id] = hw
+	return nil
+}
+
+func (r *memoryWageRepository) Delete(id int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.data.wages[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.s.data.wages, id)
+	return nil
+}
+
+// sortWagesNewestFirst 適用日の新しい順（同日の場合は作成日時の新しい順）に並べる
+func sortWagesNewestFirst(wages []models.HourlyWage) {
+	sort.Slice(wages, func(i, j int) bool {
+		if wages[i].EffectiveDate != wages[j].EffectiveDate {
+			return wages[i].EffectiveDate > wages[j].EffectiveDate
+		}
+		return wages[i].CreatedAt.After(wages[j].CreatedAt)
+	})
+}
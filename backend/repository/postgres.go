@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"database/sql"
+	"strconv"
+)
+
+// dbtx *sql.DB と *sql.Tx の共通部分
+type dbtx interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// scanner *sql.Row と *sql.Rows の共通部分
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// NewPostgres PostgreSQL を使うリポジトリ一式を作成
+func NewPostgres(db *sql.DB) *Repositories {
+	r := newPostgresRepositories(db)
+	r.inTx = func(fn func(r *Repositories) error) error {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		txRepos := newPostgresRepositories(tx)
+		// トランザクション内でさらに InTx を呼んだ場合は同じトランザクションを使う
+		txRepos.inTx = func(fn func(r *Repositories) error) error {
+			return fn(txRepos)
+		}
+		if err := fn(txRepos); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+	return r
+}
+
+func newPostgresRepositories(db dbtx) *Repositories {
+	return &Repositories{
+		Employees:       &postgresEmployeeRepository{db: db},
+		Shifts:          &postgresShiftRepository{db: db},
+		Attendance:      &postgresAttendanceRepository{db: db},
+		ShiftRequests:   &postgresShiftRequestRepository{db: db},
+		Wages:           &postgresWageRepository{db: db},
+		TimeSlots:       &postgresTimeSlotRepository{db: db},
+		Permissions:     &postgresPermissionRepository{db: db},
+		Users:           &postgresUserRepository{db: db},
+		GanttSettings:   &postgresGanttSettingsRepository{db: db},
+		ScheduleJobs:    &postgresScheduleJobRepository{db: db},
+		Periods:         &postgresSchedulePeriodRepository{db: db},
+		ShiftTrades:     &postgresShiftTradeRepository{db: db},
+		OpenShiftClaims: &postgresOpenShiftClaimRepository{db: db},
+		ShiftPatterns:   &postgresShiftPatternRepository{db: db},
+		Templates:       &postgresScheduleTemplateRepository{db: db},
+		RequestPeriods:  &postgresShiftRequestPeriodRepository{db: db},
+		DesiredHours:    &postgresDesiredHoursRepository{db: db},
+		Availability:    &postgresAvailabilityProfileRepository{db: db},
+	}
+}
+
+// whereBuilder 動的な WHERE 句を組み立てる
+type whereBuilder struct {
+	clauses []string
+	args    []interface{}
+}
+
+// add 条件を追加する。column には "a.date >=" のように演算子まで含める
+func (w *whereBuilder) add(column string, value interface{}) {
+	w.args = append(w.args, value)
+	w.clauses = append(w.clauses, column+" $"+strconv.Itoa(len(w.args)))
+}
+
+// addEither いずれかの列が value と等しい条件を追加する
+func (w *whereBuilder) addEither(columns []string, value interface{}) {
+	w.args = append(w.args, value)
+	placeholder := "$" + strconv.Itoa(len(w.args))
+	clause := ""
+	for i, column := range columns {
+		if i > 0 {
+			clause += " OR "
+		}
+		clause += column + " = " + placeholder
+	}
+	w.clauses = append(w.clauses, "("+clause+")")
+}
+
+// String " AND ..." 形式の条件を返す（"WHERE 1=1" の後ろに続ける）
+func (w *whereBuilder) String() string {
+	s := ""
+	for _, clause := range w.clauses {
+		s += " AND " + clause
+	}
+	return s
+}
+
+// execAffected 更新・削除を実行し、対象が存在しない場合は ErrNotFound を返す
+func execAffected(db dbtx, query string, args ...interface{}) error {
+	result, err := db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// notFound sql.ErrNoRows を ErrNotFound に変換する
+func notFound(err error) error {
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,97 @@
+package repository
+
+import "shift-management-backend/models"
+
+type postgresAttendanceRepository struct {
+	db dbtx
+}
+
+const attendanceSelect = `
+	SELECT a.id, a.employee_id, a.date, a.clock_in_time, a.clock_out_time,
+	       a.clock_out_next_day, a.actual_hours, a.status, a.shift_id, a.late_minutes, a.early_leave_minutes, a.overtime_minutes,
+	       a.created_at, a.updated_at, e.name as employee_name
+	FROM attendance a
+	JOIN employees e ON a.employee_id = e.id
+`
+
+func scanAttendance(row scanner) (models.Attendance, error) {
+	var att models.Attendance
+	err := row.Scan(&att.ID, &att.EmployeeID, &att.Date, &att.ClockInTime,
+		&att.ClockOutTime, &att.ClockOutNextDay, &att.ActualHours, &att.Status, &att.ShiftID, &att.LateMinutes,
+		&att.EarlyLeaveMinutes, &att.OvertimeMinutes, &att.CreatedAt, &att.UpdatedAt, &att.EmployeeName)
+	return att, err
+}
+
+func (r *postgresAttendanceRepository) List(filter AttendanceFilter) ([]models.Attendance, error) {
+	var where whereBuilder
+	if filter.EmployeeID != nil {
+		where.add("a.employee_id =", *filter.EmployeeID)
+	}
+	if filter.StartDate != "" {
+		where.add("a.date >=", filter.StartDate)
+	}
+	if filter.EndDate != "" {
+		where.add("a.date <=", filter.EndDate)
+	}
+
+	rows, err := r.db.Query(attendanceSelect+" WHERE 1=1"+where.String()+" ORDER BY a.date DESC, a.created_at DESC", where.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attendances []models.Attendance
+	for rows.Next() {
+		att, err := scanAttendance(rows)
+		if err != nil {
+			return nil, err
+		}
+		attendances = append(attendances, att)
+	}
+	return attendances, rows.Err()
+}
+
+func (r *postgresAttendanceRepository) Get(id int) (models.Attendance, error) {
+	att, err := scanAttendance(r.db.QueryRow(attendanceSelect+" WHERE a.id = $1", id))
+	return att, notFound(err)
+}
+
+func (r *postgresAttendanceRepository) Create(attendance models.Attendance) (models.Attendance, error) {
+	var created models.Attendance
+	err := r.db.QueryRow(`
+		INSERT INTO attendance (employee_id, date, clock_in_time, clock_out_time, clock_out_next_day, actual_hours, status,
+		                        shift_id, late_minutes, early_leave_minutes, overtime_minutes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, employee_id, date, clock_in_time, clock_out_time, clock_out_next_day, actual_hours, status,
+		          shift_id, late_minutes, early_leave_minutes, overtime_minutes, created_at, updated_at
+	`, attendance.EmployeeID, attendance.Date, attendance.ClockInTime, attendance.ClockOutTime,
+		attendance.ClockOutNextDay, attendance.ActualHours, attendance.Status, attendance.ShiftID,
+		attendance.LateMinutes, attendance.EarlyLeaveMinutes, attendance.OvertimeMinutes).Scan(
+		&created.ID, &created.EmployeeID, &created.Date, &created.ClockInTime, &created.ClockOutTime,
+		&created.ClockOutNextDay, &created.ActualHours, &created.Status, &created.ShiftID, &created.LateMinutes,
+		&created.EarlyLeaveMinutes, &created.OvertimeMinutes, &created.CreatedAt, &created.UpdatedAt)
+	return created, err
+}
+
+func (r *postgresAttendanceRepository) Update(attendance models.Attendance) error {
+	return execAffected(r.db, `
+		UPDATE attendance
+		SET clock_in_time = $1,
+		    clock_out_time = $2,
+		    clock_out_next_day = $3,
+		    actual_hours = $4,
+		    status = $5,
+		    shift_id = $6,
+		    late_minutes = $7,
+		    early_leave_minutes = $8,
+		    overtime_minutes = $9,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $10
+	`, attendance.ClockInTime, attendance.ClockOutTime, attendance.ClockOutNextDay,
+		attendance.ActualHours, attendance.Status, attendance.ShiftID, attendance.LateMinutes,
+		attendance.EarlyLeaveMinutes, attendance.OvertimeMinutes, attendance.ID)
+}
+
+func (r *postgresAttendanceRepository) Delete(id int) error {
+	return execAffected(r.db, "DELETE FROM attendance WHERE id = $1", id)
+}
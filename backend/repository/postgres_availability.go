@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"shift-management-backend/models"
+
+	"github.com/lib/pq"
+)
+
+type postgresAvailabilityProfileRepository struct {
+	db dbtx
+}
+
+const availabilityProfileSelect = `
+	SELECT p.id, p.employee_id, p.name, p.valid_from, p.valid_to, p.note, p.created_at, p.updated_at, e.name as employee_name
+	FROM availability_profiles p
+	JOIN employees e ON p.employee_id = e.id
+`
+
+func scanAvailabilityProfile(row scanner) (models.AvailabilityProfile, error) {
+	var p models.AvailabilityProfile
+	err := row.Scan(&p.ID, &p.EmployeeID, &p.Name, &p.ValidFrom, &p.ValidTo, &p.Note, &p.CreatedAt, &p.UpdatedAt, &p.EmployeeName)
+	return p, err
+}
+
+func (r *postgresAvailabilityProfileRepository) List(employeeID *int) ([]models.AvailabilityProfile, error) {
+	var where whereBuilder
+	if employeeID != nil {
+		where.add("p.employee_id =", *employeeID)
+	}
+
+	rows, err := r.db.Query(availabilityProfileSelect+" WHERE 1=1"+where.String()+" ORDER BY p.employee_id, p.valid_from", where.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profiles []models.AvailabilityProfile
+	for rows.Next() {
+		p, err := scanAvailabilityProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return profiles, r.loadWindows(profiles)
+}
+
+func (r *postgresAvailabilityProfileRepository) Get(id int) (models.AvailabilityProfile, error) {
+	p, err := scanAvailabilityProfile(r.db.QueryRow(availabilityProfileSelect+" WHERE p.id = $1", id))
+	if err != nil {
+		return p, notFound(err)
+	}
+	profiles := []models.AvailabilityProfile{p}
+	err = r.loadWindows(profiles)
+	return profiles[0], err
+}
+
+func (r *postgresAvailabilityProfileRepository) Create(profile models.AvailabilityProfile) (models.AvailabilityProfile, error) {
+	var id int
+	err := r.db.QueryRow(`
+		INSERT INTO availability_profiles (employee_id, name, valid_from, valid_to, note)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, profile.EmployeeID, profile.Name, profile.ValidFrom, profile.ValidTo, profile.Note).Scan(&id)
+	if err != nil {
+		return models.AvailabilityProfile{}, err
+	}
+	if err := r.replaceWindows(id, profile.Windows); err != nil {
+		return models.AvailabilityProfile{}, err
+	}
+	return r.Get(id)
+}
+
+func (r *postgresAvailabilityProfileRepository) Update(profile models.AvailabilityProfile) error {
+	err := execAffected(r.db, `
+		UPDATE availability_profiles
+		SET employee_id = $1,
+		    name = $2,
+		    valid_from = $3,
+		    valid_to = $4,
+		    note = $5,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $6
+	`, profile.EmployeeID, profile.Name, profile.ValidFrom, profile.ValidTo, profile.Note, profile.ID)
+	if err != nil {
+		return err
+	}
+	return r.replaceWindows(profile.ID, profile.Windows)
+}
+
+func (r *postgresAvailabilityProfileRepository) Delete(id int) error {
+	return execAffected(r.db, "DELETE FROM availability_profiles WHERE id = $1", id)
+}
+
+// loadWindows 勤務可能な時間帯ごとの曜日の時間帯を読み込む
+func (r *postgresAvailabilityProfileRepository) loadWindows(profiles []models.AvailabilityProfile) error {
+	if len(profiles) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(profiles))
+	index := make(map[int]int, len(profiles))
+	for i, p := range profiles {
+		ids[i] = int64(p.ID)
+		index[p.ID] = i
+		profiles[i].Windows = []models.AvailabilityWindow{}
+	}
+
+	rows, err := r.db.Query(`
+		SELECT profile_id, weekday, start_time, end_time, ends_next_day
+		FROM availability_windows
+		WHERE profile_id = ANY($1)
+		ORDER BY profile_id, weekday, start_time, id
+	`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var profileID int
+		var w models.AvailabilityWindow
+		if err := rows.Scan(&profileID, &w.Weekday, &w.StartTime, &w.EndTime, &w.EndsNextDay); err != nil {
+			return err
+		}
+		i := index[profileID]
+		profiles[i].Windows = append(profiles[i].Windows, w)
+	}
+	return rows.Err()
+}
+
+// replaceWindows 勤務可能な時間帯の曜日の時間帯を置き換える
+func (r *postgresAvailabilityProfileRepository) replaceWindows(profileID int, windows []models.AvailabilityWindow) error {
+	if _, err := r.db.Exec("DELETE FROM availability_windows WHERE profile_id = $1", profileID); err != nil {
+		return err
+	}
+	for _, w := range windows {
+		_, err := r.db.Exec(`
+			INSERT INTO availability_windows (profile_id, weekday, start_time, end_time, ends_next_day)
+			VALUES ($1, $2, $3, $4, $5)
+		`, profileID, w.Weekday, w.StartTime, w.EndTime, w.EndsNextDay)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import "shift-management-backend/models"
+
+type postgresDesiredHoursRepository struct {
+	db dbtx
+}
+
+const desiredHoursSelect = `
+	SELECT d.employee_id, d.unit, d.hours, d.note, e.name as employee_name, d.created_at, d.updated_at
+	FROM desired_work_hours d
+	JOIN employees e ON d.employee_id = e.id
+`
+
+func scanDesiredHours(row scanner) (models.DesiredHours, error) {
+	var hours models.DesiredHours
+	err := row.Scan(&hours.EmployeeID, &hours.Unit, &hours.Hours, &hours.Note, &hours.EmployeeName,
+		&hours.CreatedAt, &hours.UpdatedAt)
+	return hours, err
+}
+
+func (r *postgresDesiredHoursRepository) List() ([]models.DesiredHours, error) {
+	rows, err := r.db.Query(desiredHoursSelect + " ORDER BY d.employee_id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.DesiredHours
+	for rows.Next() {
+		hours, err := scanDesiredHours(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, hours)
+	}
+	return list, rows.Err()
+}
+
+func (r *postgresDesiredHoursRepository) GetByEmployee(employeeID int) (models.DesiredHours, error) {
+	hours, err := scanDesiredHours(r.db.QueryRow(desiredHoursSelect+" WHERE d.employee_id = $1", employeeID))
+	return hours, notFound(err)
+}
+
+func (r *postgresDesiredHoursRepository) Save(hours models.DesiredHours) (models.DesiredHours, error) {
+	_, err := r.db.Exec(`
+		INSERT INTO desired_work_hours (employee_id, unit, hours, note)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (employee_id) DO UPDATE SET
+			unit = EXCLUDED.unit,
+			hours = EXCLUDED.hours,
+			note = EXCLUDED.note,
+			updated_at = CURRENT_TIMESTAMP
+	`, hours.EmployeeID, hours.Unit, hours.Hours, hours.Note)
+	if err != nil {
+		return models.DesiredHours{}, err
+	}
+	return r.GetByEmployee(hours.EmployeeID)
+}
+
+func (r *postgresDesiredHoursRepository) Delete(employeeID int) error {
+	return execAffected(r.db, "DELETE FROM desired_work_hours WHERE employee_id = $1", employeeID)
+}
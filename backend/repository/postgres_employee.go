@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"shift-management-backend/models"
+
+	"github.com/lib/pq"
+)
+
+type postgresEmployeeRepository struct {
+	db dbtx
+}
+
+const employeeSelect = `
+	SELECT e.id, e.name, e.hourly_wage, e.created_at, e.updated_at,
+	       ARRAY(SELECT p.position FROM employee_positions p WHERE p.employee_id = e.id ORDER BY p.position)
+	FROM employees e
+`
+
+func scanEmployee(row scanner) (models.Employee, error) {
+	var emp models.Employee
+	err := row.Scan(&emp.ID, &emp.Name, &emp.HourlyWage, &emp.CreatedAt, &emp.UpdatedAt, pq.Array(&emp.Positions))
+	if emp.Positions == nil {
+		emp.Positions = []string{}
+	}
+	return emp, err
+}
+
+func (r *postgresEmployeeRepository) List() ([]models.Employee, error) {
+	rows, err := r.db.Query(employeeSelect + ` ORDER BY e.id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var employees []models.Employee
+	for rows.Next() {
+		emp, err := scanEmployee(rows)
+		if err != nil {
+			return nil, err
+		}
+		employees = append(employees, emp)
+	}
+	return employees, rows.Err()
+}
+
+func (r *postgresEmployeeRepository) Get(id int) (models.Employee, error) {
+	emp, err := scanEmployee(r.db.QueryRow(employeeSelect+` WHERE e.id = $1`, id))
+	return emp, notFound(err)
+}
+
+func (r *postgresEmployeeRepository) Exists(id int) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow("SELECT EXISTS(SELECT 1 FROM employees WHERE id = $1)", id).Scan(&exists)
+	return exists, err
+}
+
+func (r *postgresEmployeeRepository) Lock(id int) error {
+	var locked int
+	err := r.db.QueryRow("SELECT id FROM employees WHERE id = $1 FOR UPDATE", id).Scan(&locked)
+	return notFound(err)
+}
+
+func (r *postgresEmployeeRepository) Create(req models.CreateEmployeeRequest) (models.Employee, error) {
+	var id int
+	err := r.db.QueryRow(`
+		INSERT INTO employees (name, hourly_wage)
+		VALUES ($1, $2)
+		RETURNING id
+	`, req.Name, req.HourlyWage).Scan(&id)
+	if err != nil {
+		return models.Employee{}, err
+	}
+	if err := r.setPositions(id, req.Positions); err != nil {
+		return models.Employee{}, err
+	}
+	return r.Get(id)
+}
+
+func (r *postgresEmployeeRepository) Update(id int, req models.UpdateEmployeeRequest) error {
+	err := execAffected(r.db, `
+		UPDATE employees
+		SET name = $1,
+		    hourly_wage = $2,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+	`, req.Name, req.HourlyWage, id)
+	if err != nil || req.Positions == nil {
+		return err
+	}
+	return r.setPositions(id, *req.Positions)
+}
+
+// setPositions 担当できるポジションを置き換える
+func (r *postgresEmployeeRepository) setPositions(id int, positions []string) error {
+	if _, err := r.db.Exec("DELETE FROM employee_positions WHERE employee_id = $1", id); err != nil {
+		return err
+	}
+	_, err := r.db.Exec(`
+		INSERT INTO employee_positions (employee_id, position)
+		SELECT $1, unnest($2::text[])
+	`, id, pq.Array(models.NormalizePositions(positions)))
+	return err
+}
+
+func (r *postgresEmployeeRepository) Delete(id int) error {
+	return execAffected(r.db, "DELETE FROM employees WHERE id = $1", id)
+}
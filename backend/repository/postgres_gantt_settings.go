@@ -0,0 +1,29 @@
+package repository
+
+import "shift-management-backend/models"
+
+type postgresGanttSettingsRepository struct {
+	db dbtx
+}
+
+const ganttSettingsColumns = `id, start_hour, end_hour, created_at, updated_at`
+
+func scanGanttSettings(row scanner) (models.GanttSettings, error) {
+	var settings models.GanttSettings
+	err := row.Scan(&settings.ID, &settings.StartHour, &settings.EndHour, &settings.CreatedAt, &settings.UpdatedAt)
+	return settings, err
+}
+
+func (r *postgresGanttSettingsRepository) Get() (models.GanttSettings, error) {
+	settings, err := scanGanttSettings(r.db.QueryRow(`SELECT ` + ganttSettingsColumns + ` FROM gantt_settings ORDER BY id DESC LIMIT 1`))
+	return settings, notFound(err)
+}
+
+func (r *postgresGanttSettingsRepository) Replace(startHour, endHour int) (models.GanttSettings, error) {
+	// 削除と挿入を1文で行い、設定が常に1件になるようにする
+	return scanGanttSettings(r.db.QueryRow(`
+		WITH deleted AS (DELETE FROM gantt_settings)
+		INSERT INTO gantt_settings (start_hour, end_hour, created_at, updated_at)
+		VALUES ($1, $2, NOW(), NOW())
+		RETURNING `+ganttSettingsColumns, startHour, endHour))
+}
@@ -0,0 +1,79 @@
+package repository
+
+import "shift-management-backend/models"
+
+type postgresOpenShiftClaimRepository struct {
+	db dbtx
+}
+
+const openShiftClaimSelect = `
+	SELECT c.id, c.shift_id, c.employee_id, c.status, c.decision_reason, c.decided_at,
+	       c.created_at, c.updated_at, e.name as employee_name
+	FROM open_shift_claims c
+	JOIN employees e ON c.employee_id = e.id
+`
+
+func scanOpenShiftClaim(row scanner) (models.OpenShiftClaim, error) {
+	var c models.OpenShiftClaim
+	err := row.Scan(&c.ID, &c.ShiftID, &c.EmployeeID, &c.Status, &c.DecisionReason, &c.DecidedAt,
+		&c.CreatedAt, &c.UpdatedAt, &c.EmployeeName)
+	return c, err
+}
+
+func (r *postgresOpenShiftClaimRepository) List(filter OpenShiftClaimFilter) ([]models.OpenShiftClaim, error) {
+	var where whereBuilder
+	if filter.ShiftID != nil {
+		where.add("c.shift_id =", *filter.ShiftID)
+	}
+	if filter.EmployeeID != nil {
+		where.add("c.employee_id =", *filter.EmployeeID)
+	}
+	if filter.Status != "" {
+		where.add("c.status =", filter.Status)
+	}
+
+	rows, err := r.db.Query(openShiftClaimSelect+" WHERE 1=1"+where.String()+" ORDER BY c.created_at, c.id", where.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var claims []models.OpenShiftClaim
+	for rows.Next() {
+		c, err := scanOpenShiftClaim(rows)
+		if err != nil {
+			return nil, err
+		}
+		claims = append(claims, c)
+	}
+	return claims, rows.Err()
+}
+
+func (r *postgresOpenShiftClaimRepository) Get(id int) (models.OpenShiftClaim, error) {
+	c, err := scanOpenShiftClaim(r.db.QueryRow(openShiftClaimSelect+" WHERE c.id = $1", id))
+	return c, notFound(err)
+}
+
+func (r *postgresOpenShiftClaimRepository) Create(claim models.OpenShiftClaim) (models.OpenShiftClaim, error) {
+	var id int
+	err := r.db.QueryRow(`
+		INSERT INTO open_shift_claims (shift_id, employee_id, status, decision_reason, decided_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, claim.ShiftID, claim.EmployeeID, claim.Status, claim.DecisionReason, claim.DecidedAt).Scan(&id)
+	if err != nil {
+		return models.OpenShiftClaim{}, err
+	}
+	return r.Get(id)
+}
+
+func (r *postgresOpenShiftClaimRepository) Update(claim models.OpenShiftClaim) error {
+	return execAffected(r.db, `
+		UPDATE open_shift_claims
+		SET status = $1,
+		    decision_reason = $2,
+		    decided_at = $3,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4
+	`, claim.Status, claim.DecisionReason, claim.DecidedAt, claim.ID)
+}
@@ -0,0 +1,82 @@
+package repository
+
+import "shift-management-backend/models"
+
+type postgresPermissionRepository struct {
+	db dbtx
+}
+
+const permissionSelect = `
+	SELECT p.id, p.employee_id, e.name as employee_name,
+	       p.can_view_other_shifts, p.can_view_payroll,
+	       p.can_edit_attendance, p.can_submit_shift_requests,
+	       p.created_at, p.updated_at
+	FROM permissions p
+	JOIN employees e ON p.employee_id = e.id
+`
+
+func scanPermission(row scanner) (models.EmployeePermission, error) {
+	var permission models.EmployeePermission
+	err := row.Scan(
+		&permission.ID,
+		&permission.EmployeeID,
+		&permission.EmployeeName,
+		&permission.CanViewOtherShifts,
+		&permission.CanViewPayroll,
+		&permission.CanEditAttendance,
+		&permission.CanSubmitShiftRequests,
+		&permission.CreatedAt,
+		&permission.UpdatedAt,
+	)
+	return permission, err
+}
+
+func (r *postgresPermissionRepository) List() ([]models.EmployeePermission, error) {
+	rows, err := r.db.Query(permissionSelect + " ORDER BY p.employee_id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var permissions []models.EmployeePermission
+	for rows.Next() {
+		permission, err := scanPermission(rows)
+		if err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, permission)
+	}
+	return permissions, rows.Err()
+}
+
+func (r *postgresPermissionRepository) GetByEmployee(employeeID int) (models.EmployeePermission, error) {
+	permission, err := scanPermission(r.db.QueryRow(permissionSelect+" WHERE p.employee_id = $1", employeeID))
+	return permission, notFound(err)
+}
+
+func (r *postgresPermissionRepository) Save(req models.CreatePermissionRequest) (models.EmployeePermission, bool, error) {
+	// xmax = 0 の行は INSERT された行（ON CONFLICT で UPDATE された行は xmax が設定される）
+	var id int
+	var created bool
+	err := r.db.QueryRow(`
+		INSERT INTO permissions (employee_id, can_view_other_shifts, can_view_payroll, can_edit_attendance, can_submit_shift_requests)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (employee_id) DO UPDATE SET
+			can_view_other_shifts = EXCLUDED.can_view_other_shifts,
+			can_view_payroll = EXCLUDED.can_view_payroll,
+			can_edit_attendance = EXCLUDED.can_edit_attendance,
+			can_submit_shift_requests = EXCLUDED.can_submit_shift_requests,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING id, (xmax = 0)
+	`, req.EmployeeID, req.CanViewOtherShifts, req.CanViewPayroll, req.CanEditAttendance, req.CanSubmitShiftRequests).Scan(&id, &created)
+	if err != nil {
+		return models.EmployeePermission{}, false, err
+	}
+
+	permission, err := scanPermission(r.db.QueryRow(permissionSelect+" WHERE p.id = $1", id))
+	return permission, created, notFound(err)
+}
+
+func (r *postgresPermissionRepository) Delete(id int) error {
+	return execAffected(r.db, "DELETE FROM permissions WHERE id = $1", id)
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"encoding/json"
+	"time"
+
+	"shift-management-backend/models"
+)
+
+type postgresScheduleJobRepository struct {
+	db dbtx
+}
+
+const scheduleJobColumns = `id, status, start_date, end_date, seed, max_weekly_hours, min_rest_minutes,
+	result, error, created_at, finished_at, lease_expires_at`
+
+func scanScheduleJob(row scanner) (models.ScheduleJob, error) {
+	var job models.ScheduleJob
+	var result []byte
+	err := row.Scan(&job.ID, &job.Status, &job.StartDate, &job.EndDate, &job.Seed, &job.MaxWeeklyHours,
+		&job.MinRestMinutes, &result, &job.Error, &job.CreatedAt, &job.FinishedAt, &job.LeaseExpiresAt)
+	if err != nil {
+		return job, err
+	}
+	if result != nil {
+		job.Result = &models.ScheduleResult{}
+		if err := json.Unmarshal(result, job.Result); err != nil {
+			return job, err
+		}
+	}
+	return job, nil
+}
+
+// marshalScheduleResult 作成案を JSONB に保存する形式にする（nil の場合は NULL）
+func marshalScheduleResult(result *models.ScheduleResult) (interface{}, error) {
+	if result == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (r *postgresScheduleJobRepository) List() ([]models.ScheduleJob, error) {
+	rows, err := r.db.Query(`SELECT ` + scheduleJobColumns + ` FROM schedule_jobs ORDER BY created_at DESC, id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []models.ScheduleJob
+	for rows.Next() {
+		job, err := scanScheduleJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+func (r *postgresScheduleJobRepository) Get(id int) (models.ScheduleJob, error) {
+	job, err := scanScheduleJob(r.db.QueryRow(`SELECT `+scheduleJobColumns+` FROM schedule_jobs WHERE id = $1`, id))
+	return job, notFound(err)
+}
+
+func (r *postgresScheduleJobRepository) Lock(id int) error {
+	var locked int
+	err := r.db.QueryRow("SELECT id FROM schedule_jobs WHERE id = $1 FOR UPDATE", id).Scan(&locked)
+	return notFound(err)
+}
+
+func (r *postgresScheduleJobRepository) Create(job models.ScheduleJob) (models.ScheduleJob, error) {
+	result, err := marshalScheduleResult(job.Result)
+	if err != nil {
+		return models.ScheduleJob{}, err
+	}
+	return scanScheduleJob(r.db.QueryRow(`
+		INSERT INTO schedule_jobs (status, start_date, end_date, seed, max_weekly_hours, min_rest_minutes, result, error, finished_at,
+			lease_expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING `+scheduleJobColumns,
+		job.Status, job.StartDate, job.EndDate, job.Seed, job.MaxWeeklyHours, job.MinRestMinutes,
+		result, job.Error, job.FinishedAt, job.LeaseExpiresAt))
+}
+
+func (r *postgresScheduleJobRepository) Update(job models.ScheduleJob) error {
+	result, err := marshalScheduleResult(job.Result)
+	if err != nil {
+		return err
+	}
+	return execAffected(r.db, `
+		UPDATE schedule_jobs
+		SET status = $1,
+		    result = $2,
+		    error = $3,
+		    finished_at = $4
+		WHERE id = $5
+	`, job.Status, result, job.Error, job.FinishedAt, job.ID)
+}
+
+func (r *postgresScheduleJobRepository) RenewLease(id int, expiresAt time.Time) error {
+	_, err := r.db.Exec(`
+		UPDATE schedule_jobs
+		SET lease_expires_at = $1
+		WHERE id = $2 AND status IN ('pending', 'running')
+	`, expiresAt, id)
+	return err
+}
+
+// FailExpired 1つの UPDATE で失敗にするため、複数のサーバーが同時に呼び出しても同じジョブを重ねて失敗にしない
+func (r *postgresScheduleJobRepository) FailExpired(now time.Time, message string) (int, error) {
+	result, err := r.db.Exec(`
+		UPDATE schedule_jobs
+		SET status = 'failed',
+		    error = $1,
+		    finished_at = $2
+		WHERE status IN ('pending', 'running')
+		  AND (lease_expires_at IS NULL OR lease_expires_at < $2)
+	`, message, now)
+	if err != nil {
+		return 0, err
+	}
+	failed, err := result.RowsAffected()
+	return int(failed), err
+}
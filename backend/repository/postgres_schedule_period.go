@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"encoding/json"
+
+	"shift-management-backend/models"
+)
+
+type postgresSchedulePeriodRepository struct {
+	db dbtx
+}
+
+const schedulePeriodColumns = `id, start_date, end_date, status, published_version, published_at, created_at, updated_at`
+
+func scanSchedulePeriod(row scanner) (models.SchedulePeriod, error) {
+	var p models.SchedulePeriod
+	err := row.Scan(&p.ID, &p.StartDate, &p.EndDate, &p.Status, &p.PublishedVersion,
+		&p.PublishedAt, &p.CreatedAt, &p.UpdatedAt)
+	return p, err
+}
+
+func (r *postgresSchedulePeriodRepository) List() ([]models.SchedulePeriod, error) {
+	return r.ListOverlapping("", "")
+}
+
+func (r *postgresSchedulePeriodRepository) ListOverlapping(startDate, endDate string) ([]models.SchedulePeriod, error) {
+	var where whereBuilder
+	if startDate != "" {
+		where.add("end_date >=", startDate)
+	}
+	if endDate != "" {
+		where.add("start_date <=", endDate)
+	}
+
+	rows, err := r.db.Query(`SELECT `+schedulePeriodColumns+` FROM schedule_periods WHERE 1=1`+where.String()+` ORDER BY start_date DESC`, where.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var periods []models.SchedulePeriod
+	for rows.Next() {
+		p, err := scanSchedulePeriod(rows)
+		if err != nil {
+			return nil, err
+		}
+		periods = append(periods, p)
+	}
+	return periods, rows.Err()
+}
+
+func (r *postgresSchedulePeriodRepository) Get(id int) (models.SchedulePeriod, error) {
+	p, err := scanSchedulePeriod(r.db.QueryRow(`SELECT `+schedulePeriodColumns+` FROM schedule_periods WHERE id = $1`, id))
+	return p, notFound(err)
+}
+
+func (r *postgresSchedulePeriodRepository) Create(period models.SchedulePeriod) (models.SchedulePeriod, error) {
+	return scanSchedulePeriod(r.db.QueryRow(`
+		INSERT INTO schedule_periods (start_date, end_date, status)
+		VALUES ($1, $2, $3)
+		RETURNING `+schedulePeriodColumns,
+		period.StartDate, period.EndDate, period.Status))
+}
+
+func (r *postgresSchedulePeriodRepository) Update(period models.SchedulePeriod) error {
+	return execAffected(r.db, `
+		UPDATE schedule_periods
+		SET status = $1,
+		    published_version = $2,
+		    published_at = $3,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4
+	`, period.Status, period.PublishedVersion, period.PublishedAt, period.ID)
+}
+
+func (r *postgresSchedulePeriodRepository) Delete(id int) error {
+	return execAffected(r.db, "DELETE FROM schedule_periods WHERE id = $1", id)
+}
+
+const snapshotColumns = `id, period_id, version, shifts, created_at`
+
+func scanSnapshot(row scanner) (models.SchedulePeriodSnapshot, error) {
+	var s models.SchedulePeriodSnapshot
+	var shifts []byte
+	if err := row.Scan(&s.ID, &s.PeriodID, &s.Version, &shifts, &s.CreatedAt); err != nil {
+		return s, err
+	}
+	err := json.Unmarshal(shifts, &s.Shifts)
+	return s, err
+}
+
+func (r *postgresSchedulePeriodRepository) CreateSnapshot(snapshot models.SchedulePeriodSnapshot) (models.SchedulePeriodSnapshot, error) {
+	if snapshot.Shifts == nil {
+		snapshot.Shifts = []models.Shift{}
+	}
+	shifts, err := json.Marshal(snapshot.Shifts)
+	if err != nil {
+		return models.SchedulePeriodSnapshot{}, err
+	}
+	return scanSnapshot(r.db.QueryRow(`
+		INSERT INTO schedule_period_snapshots (period_id, version, shifts)
+		VALUES ($1, $2, $3)
+		RETURNING `+snapshotColumns,
+		snapshot.PeriodID, snapshot.Version, string(shifts)))
+}
+
+func (r *postgresSchedulePeriodRepository) Snapshot(periodID, version int) (models.SchedulePeriodSnapshot, error) {
+	s, err := scanSnapshot(r.db.QueryRow(`
+		SELECT `+snapshotColumns+` FROM schedule_period_snapshots WHERE period_id = $1 AND version = $2
+	`, periodID, version))
+	return s, notFound(err)
+}
+
+func (r *postgresSchedulePeriodRepository) ListSnapshots(periodID int) ([]models.SchedulePeriodSnapshot, error) {
+	rows, err := r.db.Query(`
+		SELECT id, period_id, version, created_at FROM schedule_period_snapshots
+		WHERE period_id = $1
+		ORDER BY version DESC
+	`, periodID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []models.SchedulePeriodSnapshot
+	for rows.Next() {
+		var s models.SchedulePeriodSnapshot
+		if err := rows.Scan(&s.ID, &s.PeriodID, &s.Version, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, rows.Err()
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"encoding/json"
+
+	"shift-management-backend/models"
+)
+
+type postgresScheduleTemplateRepository struct {
+	db dbtx
+}
+
+func (r *postgresScheduleTemplateRepository) List() ([]models.ScheduleTemplate, error) {
+	rows, err := r.db.Query(`
+		SELECT t.id, t.name, COUNT(s.id), t.created_at, t.updated_at
+		FROM schedule_templates t
+		LEFT JOIN schedule_template_shifts s ON s.template_id = t.id
+		GROUP BY t.id
+		ORDER BY t.name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []models.ScheduleTemplate
+	for rows.Next() {
+		var t models.ScheduleTemplate
+		if err := rows.Scan(&t.ID, &t.Name, &t.ShiftCount, &t.CreatedAt, &t.UpdatedAt); err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	return templates, rows.Err()
+}
+
+func (r *postgresScheduleTemplateRepository) Get(id int) (models.ScheduleTemplate, error) {
+	var t models.ScheduleTemplate
+	err := r.db.QueryRow(`
+		SELECT id, name, created_at, updated_at FROM schedule_templates WHERE id = $1
+	`, id).Scan(&t.ID, &t.Name, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return t, notFound(err)
+	}
+
+	rows, err := r.db.Query(`
+		SELECT id, weekday, COALESCE(employee_id, 0), employee_name, start_time, end_time,
+		       ends_next_day, break_time, position, segments
+		FROM schedule_template_shifts
+		WHERE template_id = $1
+		ORDER BY weekday, start_time, id
+	`, id)
+	if err != nil {
+		return t, err
+	}
+	defer rows.Close()
+
+	t.Shifts = []models.TemplateShift{}
+	for rows.Next() {
+		var s models.TemplateShift
+		var segments []byte
+		if err := rows.Scan(&s.ID, &s.Weekday, &s.EmployeeID, &s.EmployeeName, &s.StartTime, &s.EndTime,
+			&s.EndsNextDay, &s.BreakTime, &s.Position, &segments); err != nil {
+			return t, err
+		}
+		if err := json.Unmarshal(segments, &s.Segments); err != nil {
+			return t, err
+		}
+		t.Shifts = append(t.Shifts, s)
+	}
+	t.ShiftCount = len(t.Shifts)
+	return t, rows.Err()
+}
+
+func (r *postgresScheduleTemplateRepository) Create(template models.ScheduleTemplate) (models.ScheduleTemplate, error) {
+	var id int
+	err := r.db.QueryRow(`
+		INSERT INTO schedule_templates (name) VALUES ($1) RETURNING id
+	`, template.Name).Scan(&id)
+	if err != nil {
+		return models.ScheduleTemplate{}, err
+	}
+
+	for _, s := range template.Shifts {
+		segments := s.Segments
+		if segments == nil {
+			segments = []models.ShiftSegment{}
+		}
+		encoded, err := json.Marshal(segments)
+		if err != nil {
+			return models.ScheduleTemplate{}, err
+		}
+		_, err = r.db.Exec(`
+			INSERT INTO schedule_template_shifts (template_id, weekday, employee_id, employee_name, start_time, end_time,
+			                                      ends_next_day, break_time, position, segments)
+			VALUES ($1, $2, NULLIF($3, 0), $4, $5, $6, $7, $8, $9, $10)
+		`, id, s.Weekday, s.EmployeeID, s.EmployeeName, s.StartTime, s.EndTime,
+			s.EndsNextDay, s.BreakTime, s.Position, string(encoded))
+		if err != nil {
+			return models.ScheduleTemplate{}, err
+		}
+	}
+	return r.Get(id)
+}
+
+func (r *postgresScheduleTemplateRepository) Delete(id int) error {
+	return execAffected(r.db, "DELETE FROM schedule_templates WHERE id = $1", id)
+}
@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"shift-management-backend/models"
+
+	"github.com/lib/pq"
+)
+
+type postgresShiftRepository struct {
+	db dbtx
+}
+
+const shiftSelect = `
+	SELECT s.id, COALESCE(s.employee_id, 0), s.date, s.start_time, s.end_time, s.ends_next_day,
+	       s.break_time, s.position, s.pattern_id, s.request_id, s.created_at, s.updated_at, COALESCE(e.name, '') as employee_name
+	FROM shifts s
+	LEFT JOIN employees e ON s.employee_id = e.id
+`
+
+func scanShift(row scanner) (models.Shift, error) {
+	var shift models.Shift
+	err := row.Scan(&shift.ID, &shift.EmployeeID, &shift.Date, &shift.StartTime,
+		&shift.EndTime, &shift.EndsNextDay, &shift.BreakTime, &shift.Position, &shift.PatternID, &shift.RequestID, &shift.CreatedAt, &shift.UpdatedAt, &shift.EmployeeName)
+	return shift, err
+}
+
+func (r *postgresShiftRepository) List(filter ShiftFilter) ([]models.Shift, error) {
+	var where whereBuilder
+	if filter.EmployeeID != nil {
+		where.add("s.employee_id =", *filter.EmployeeID)
+	}
+	if filter.PatternID != nil {
+		where.add("s.pattern_id =", *filter.PatternID)
+	}
+	if filter.OpenOnly {
+		where.clauses = append(where.clauses, "s.employee_id IS NULL")
+	}
+	if filter.StartDate != "" {
+		where.add("s.date >=", filter.StartDate)
+	}
+	if filter.EndDate != "" {
+		where.add("s.date <=", filter.EndDate)
+	}
+
+	order := " ORDER BY s.date ASC, s.start_time ASC"
+	if filter.NewestFirst {
+		order = " ORDER BY s.date DESC, s.start_time"
+	}
+
+	rows, err := r.db.Query(shiftSelect+" WHERE 1=1"+where.String()+order, where.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var shifts []models.Shift
+	for rows.Next() {
+		shift, err := scanShift(rows)
+		if err != nil {
+			return nil, err
+		}
+		shifts = append(shifts, shift)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return shifts, r.loadSegments(shifts)
+}
+
+func (r *postgresShiftRepository) Get(id int) (models.Shift, error) {
+	shift, err := scanShift(r.db.QueryRow(shiftSelect+" WHERE s.id = $1", id))
+	if err != nil {
+		return shift, notFound(err)
+	}
+	shifts := []models.Shift{shift}
+	err = r.loadSegments(shifts)
+	return shifts[0], err
+}
+
+func (r *postgresShiftRepository) Create(shift models.Shift) (models.Shift, error) {
+	var id int
+	err := r.db.QueryRow(`
+		INSERT INTO shifts (employee_id, date, start_time, end_time, ends_next_day, break_time, position, pattern_id, request_id)
+		VALUES (NULLIF($1, 0), $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`, shift.EmployeeID, shift.Date, shift.StartTime, shift.EndTime, shift.EndsNextDay, shift.BreakTime, shift.Position, shift.PatternID,
+		shift.RequestID).Scan(&id)
+	if err != nil {
+		return models.Shift{}, err
+	}
+	if err := r.replaceSegments(id, shift.Segments); err != nil {
+		return models.Shift{}, err
+	}
+	return r.Get(id)
+}
+
+func (r *postgresShiftRepository) Update(shift models.Shift) error {
+	err := execAffected(r.db, `
+		UPDATE shifts
+		SET employee_id = NULLIF($1, 0),
+		    date = $2,
+		    start_time = $3,
+		    end_time = $4,
+		    ends_next_day = $5,
+		    break_time = $6,
+		    position = $7,
+		    pattern_id = $8,
+		    request_id = $9,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $10
+	`, shift.EmployeeID, shift.Date, shift.StartTime, shift.EndTime, shift.EndsNextDay, shift.BreakTime, shift.Position, shift.PatternID,
+		shift.RequestID, shift.ID)
+	if err != nil {
+		return err
+	}
+	return r.replaceSegments(shift.ID, shift.Segments)
+}
+
+// loadSegments シフトごとのポジション区間を読み込む
+func (r *postgresShiftRepository) loadSegments(shifts []models.Shift) error {
+	if len(shifts) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(shifts))
+	index := make(map[int]int, len(shifts))
+	for i, shift := range shifts {
+		ids[i] = int64(shift.ID)
+		index[shift.ID] = i
+	}
+
+	rows, err := r.db.Query(`
+		SELECT shift_id, start_time, end_time, position
+		FROM shift_segments
+		WHERE shift_id = ANY($1)
+		ORDER BY shift_id, id
+	`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var shiftID int
+		var seg models.ShiftSegment
+		if err := rows.Scan(&shiftID, &seg.StartTime, &seg.EndTime, &seg.Position); err != nil {
+			return err
+		}
+		i := index[shiftID]
+		shifts[i].Segments = append(shifts[i].Segments, seg)
+	}
+	return rows.Err()
+}
+
+// replaceSegments シフトのポジション区間を置き換える
+func (r *postgresShiftRepository) replaceSegments(shiftID int, segments []models.ShiftSegment) error {
+	if _, err := r.db.Exec("DELETE FROM shift_segments WHERE shift_id = $1", shiftID); err != nil {
+		return err
+	}
+	for _, seg := range segments {
+		_, err := r.db.Exec(`
+			INSERT INTO shift_segments (shift_id, start_time, end_time, position)
+			VALUES ($1, $2, $3, $4)
+		`, shiftID, seg.StartTime, seg.EndTime, seg.Position)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *postgresShiftRepository) Lock(id int) error {
+	var locked int
+	err := r.db.QueryRow("SELECT id FROM shifts WHERE id = $1 FOR UPDATE", id).Scan(&locked)
+	return notFound(err)
+}
+
+func (r *postgresShiftRepository) Delete(id int) error {
+	return execAffected(r.db, "DELETE FROM shifts WHERE id = $1", id)
+}
+
+func (r *postgresShiftRepository) AddHolder(holder models.ShiftHolder) error {
+	_, err := r.db.Exec(`
+		INSERT INTO shift_holders (shift_id, employee_id, reason, trade_id)
+		VALUES ($1, $2, $3, $4)
+	`, holder.ShiftID, holder.EmployeeID, holder.Reason, holder.TradeID)
+	return err
+}
+
+func (r *postgresShiftRepository) Holders(shiftID int) ([]models.ShiftHolder, error) {
+	rows, err := r.db.Query(`
+		SELECT h.id, h.shift_id, h.employee_id, h.reason, h.trade_id, h.created_at, e.name
+		FROM shift_holders h
+		JOIN employees e ON h.employee_id = e.id
+		WHERE h.shift_id = $1
+		ORDER BY h.created_at, h.id
+	`, shiftID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var holders []models.ShiftHolder
+	for rows.Next() {
+		var h models.ShiftHolder
+		if err := rows.Scan(&h.ID, &h.ShiftID, &h.EmployeeID, &h.Reason, &h.TradeID, &h.CreatedAt, &h.EmployeeName); err != nil {
+			return nil, err
+		}
+		holders = append(holders, h)
+	}
+	return holders, rows.Err()
+}
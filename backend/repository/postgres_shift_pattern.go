@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"shift-management-backend/models"
+
+	"github.com/lib/pq"
+)
+
+type postgresShiftPatternRepository struct {
+	db dbtx
+}
+
+const shiftPatternSelect = `
+	SELECT p.id, p.employee_id, p.weekdays, p.interval_weeks, p.start_date, p.end_date, p.start_time, p.end_time,
+	       p.ends_next_day, p.break_time, p.position, p.exceptions, p.created_at, p.updated_at, e.name as employee_name
+	FROM shift_patterns p
+	JOIN employees e ON p.employee_id = e.id
+`
+
+func scanShiftPattern(row scanner) (models.ShiftPattern, error) {
+	var p models.ShiftPattern
+	var weekdays pq.Int64Array
+	err := row.Scan(&p.ID, &p.EmployeeID, &weekdays, &p.IntervalWeeks, &p.StartDate, &p.EndDate, &p.StartTime, &p.EndTime,
+		&p.EndsNextDay, &p.BreakTime, &p.Position, pq.Array(&p.Exceptions), &p.CreatedAt, &p.UpdatedAt, &p.EmployeeName)
+	p.Weekdays = make([]int, len(weekdays))
+	for i, d := range weekdays {
+		p.Weekdays[i] = int(d)
+	}
+	if p.Exceptions == nil {
+		p.Exceptions = []string{}
+	}
+	return p, err
+}
+
+func (r *postgresShiftPatternRepository) List(employeeID *int) ([]models.ShiftPattern, error) {
+	var where whereBuilder
+	if employeeID != nil {
+		where.add("p.employee_id =", *employeeID)
+	}
+
+	rows, err := r.db.Query(shiftPatternSelect+" WHERE 1=1"+where.String()+" ORDER BY p.start_date, p.id", where.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var patterns []models.ShiftPattern
+	for rows.Next() {
+		p, err := scanShiftPattern(rows)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, rows.Err()
+}
+
+func (r *postgresShiftPatternRepository) Get(id int) (models.ShiftPattern, error) {
+	p, err := scanShiftPattern(r.db.QueryRow(shiftPatternSelect+" WHERE p.id = $1", id))
+	return p, notFound(err)
+}
+
+func (r *postgresShiftPatternRepository) Create(pattern models.ShiftPattern) (models.ShiftPattern, error) {
+	var id int
+	err := r.db.QueryRow(`
+		INSERT INTO shift_patterns (employee_id, weekdays, interval_weeks, start_date, end_date, start_time, end_time,
+		                            ends_next_day, break_time, position, exceptions)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+	`, pattern.EmployeeID, pq.Array(pattern.Weekdays), pattern.IntervalWeeks, pattern.StartDate, pattern.EndDate,
+		pattern.StartTime, pattern.EndTime, pattern.EndsNextDay, pattern.BreakTime, pattern.Position,
+		pq.Array(pattern.Exceptions)).Scan(&id)
+	if err != nil {
+		return models.ShiftPattern{}, err
+	}
+	return r.Get(id)
+}
+
+func (r *postgresShiftPatternRepository) Update(pattern models.ShiftPattern) error {
+	return execAffected(r.db, `
+		UPDATE shift_patterns
+		SET employee_id = $1,
+		    weekdays = $2,
+		    interval_weeks = $3,
+		    start_date = $4,
+		    end_date = $5,
+		    start_time = $6,
+		    end_time = $7,
+		    ends_next_day = $8,
+		    break_time = $9,
+		    position = $10,
+		    exceptions = $11,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $12
+	`, pattern.EmployeeID, pq.Array(pattern.Weekdays), pattern.IntervalWeeks, pattern.StartDate, pattern.EndDate,
+		pattern.StartTime, pattern.EndTime, pattern.EndsNextDay, pattern.BreakTime, pattern.Position,
+		pq.Array(pattern.Exceptions), pattern.ID)
+}
+
+func (r *postgresShiftPatternRepository) Delete(id int) error {
+	return execAffected(r.db, "DELETE FROM shift_patterns WHERE id = $1", id)
+}
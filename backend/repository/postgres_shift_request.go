@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"shift-management-backend/models"
+
+	"github.com/lib/pq"
+)
+
+type postgresShiftRequestRepository struct {
+	db dbtx
+}
+
+const shiftRequestSelect = `
+	SELECT sr.id, sr.employee_id, sr.date, sr.availability, sr.note, sr.status, sr.review_reason, sr.reviewed_at,
+	       sr.period_id, sr.late, sr.created_at, sr.updated_at, e.name as employee_name
+	FROM shift_requests sr
+	JOIN employees e ON sr.employee_id = e.id
+`
+
+func scanShiftRequest(row scanner) (models.ShiftRequest, error) {
+	var req models.ShiftRequest
+	err := row.Scan(&req.ID, &req.EmployeeID, &req.Date, &req.Availability, &req.Note, &req.Status,
+		&req.ReviewReason, &req.ReviewedAt, &req.PeriodID, &req.Late, &req.CreatedAt, &req.UpdatedAt, &req.EmployeeName)
+	return req, err
+}
+
+func (r *postgresShiftRequestRepository) List(employeeID *int) ([]models.ShiftRequest, error) {
+	var where whereBuilder
+	if employeeID != nil {
+		where.add("sr.employee_id =", *employeeID)
+	}
+
+	rows, err := r.db.Query(shiftRequestSelect+" WHERE 1=1"+where.String()+" ORDER BY sr.date DESC, sr.created_at DESC", where.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var requests []models.ShiftRequest
+	for rows.Next() {
+		req, err := scanShiftRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, req)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := r.loadWindows(requests); err != nil {
+		return nil, err
+	}
+	return requests, r.loadShifts(requests)
+}
+
+func (r *postgresShiftRequestRepository) Get(id int) (models.ShiftRequest, error) {
+	req, err := scanShiftRequest(r.db.QueryRow(shiftRequestSelect+" WHERE sr.id = $1", id))
+	if err != nil {
+		return req, notFound(err)
+	}
+	requests := []models.ShiftRequest{req}
+	if err := r.loadWindows(requests); err != nil {
+		return req, err
+	}
+	err = r.loadShifts(requests)
+	return requests[0], err
+}
+
+func (r *postgresShiftRequestRepository) Create(request models.ShiftRequest) (models.ShiftRequest, error) {
+	var id int
+	err := r.db.QueryRow(`
+		INSERT INTO shift_requests (employee_id, date, availability, note, status, review_reason, reviewed_at, period_id, late)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`, request.EmployeeID, request.Date, request.Availability, request.Note, request.Status,
+		request.ReviewReason, request.ReviewedAt, request.PeriodID, request.Late).Scan(&id)
+	if err != nil {
+		return models.ShiftRequest{}, err
+	}
+	if err := r.replaceWindows(id, request.Windows); err != nil {
+		return models.ShiftRequest{}, err
+	}
+	return r.Get(id)
+}
+
+func (r *postgresShiftRequestRepository) Update(request models.ShiftRequest) error {
+	err := execAffected(r.db, `
+		UPDATE shift_requests
+		SET date = $1,
+		    availability = $2,
+		    note = $3,
+		    status = $4,
+		    review_reason = $5,
+		    reviewed_at = $6,
+		    period_id = $7,
+		    late = $8,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $9
+	`, request.Date, request.Availability, request.Note, request.Status,
+		request.ReviewReason, request.ReviewedAt, request.PeriodID, request.Late, request.ID)
+	if err != nil {
+		return err
+	}
+	return r.replaceWindows(request.ID, request.Windows)
+}
+
+func (r *postgresShiftRequestRepository) Delete(id int) error {
+	return execAffected(r.db, "DELETE FROM shift_requests WHERE id = $1", id)
+}
+
+// loadWindows シフト希望ごとの時間帯を読み込む
+func (r *postgresShiftRequestRepository) loadWindows(requests []models.ShiftRequest) error {
+	if len(requests) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(requests))
+	index := make(map[int]int, len(requests))
+	for i, req := range requests {
+		ids[i] = int64(req.ID)
+		index[req.ID] = i
+		requests[i].Windows = []models.ShiftRequestWindow{}
+	}
+
+	rows, err := r.db.Query(`
+		SELECT request_id, start_time, end_time, ends_next_day
+		FROM shift_request_windows
+		WHERE request_id = ANY($1)
+		ORDER BY request_id, start_time, id
+	`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var requestID int
+		var w models.ShiftRequestWindow
+		if err := rows.Scan(&requestID, &w.StartTime, &w.EndTime, &w.EndsNextDay); err != nil {
+			return err
+		}
+		i := index[requestID]
+		requests[i].Windows = append(requests[i].Windows, w)
+	}
+	return rows.Err()
+}
+
+// loadShifts シフト希望ごとに承認して作成したシフトを読み込む
+func (r *postgresShiftRequestRepository) loadShifts(requests []models.ShiftRequest) error {
+	if len(requests) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(requests))
+	index := make(map[int]int, len(requests))
+	for i, req := range requests {
+		ids[i] = int64(req.ID)
+		index[req.ID] = i
+		requests[i].Shifts = []models.ShiftRequestShift{}
+	}
+
+	rows, err := r.db.Query(`
+		SELECT request_id, id, date, start_time, end_time, ends_next_day, position
+		FROM shifts
+		WHERE request_id = ANY($1)
+		ORDER BY request_id, date, start_time
+	`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var requestID int
+		var s models.ShiftRequestShift
+		if err := rows.Scan(&requestID, &s.ID, &s.Date, &s.StartTime, &s.EndTime, &s.EndsNextDay, &s.Position); err != nil {
+			return err
+		}
+		i := index[requestID]
+		requests[i].Shifts = append(requests[i].Shifts, s)
+	}
+	return rows.Err()
+}
+
+// replaceWindows シフト希望の時間帯を置き換える
+func (r *postgresShiftRequestRepository) replaceWindows(requestID int, windows []models.ShiftRequestWindow) error {
+	if _, err := r.db.Exec("DELETE FROM shift_request_windows WHERE request_id = $1", requestID); err != nil {
+		return err
+	}
+	for _, w := range windows {
+		_, err := r.db.Exec(`
+			INSERT INTO shift_request_windows (request_id, start_time, end_time, ends_next_day)
+			VALUES ($1, $2, $3, $4)
+		`, requestID, w.StartTime, w.EndTime, w.EndsNextDay)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
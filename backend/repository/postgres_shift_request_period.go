@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"shift-management-backend/models"
+
+	"github.com/lib/pq"
+)
+
+type postgresShiftRequestPeriodRepository struct {
+	db dbtx
+}
+
+const shiftRequestPeriodColumns = `id, start_date, end_date, opens_at, closes_at, late_until, reminder_hours, reminded_at, created_at, updated_at`
+
+func scanShiftRequestPeriod(row scanner) (models.ShiftRequestPeriod, error) {
+	var p models.ShiftRequestPeriod
+	var hours pq.Int64Array
+	err := row.Scan(&p.ID, &p.StartDate, &p.EndDate, &p.OpensAt, &p.ClosesAt, &p.LateUntil,
+		&hours, &p.RemindedAt, &p.CreatedAt, &p.UpdatedAt)
+	p.ReminderHours = make([]int, len(hours))
+	for i, h := range hours {
+		p.ReminderHours[i] = int(h)
+	}
+	return p, err
+}
+
+func (r *postgresShiftRequestPeriodRepository) List() ([]models.ShiftRequestPeriod, error) {
+	rows, err := r.db.Query(`SELECT ` + shiftRequestPeriodColumns + ` FROM shift_request_periods ORDER BY start_date DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var periods []models.ShiftRequestPeriod
+	for rows.Next() {
+		p, err := scanShiftRequestPeriod(rows)
+		if err != nil {
+			return nil, err
+		}
+		periods = append(periods, p)
+	}
+	return periods, rows.Err()
+}
+
+func (r *postgresShiftRequestPeriodRepository) Get(id int) (models.ShiftRequestPeriod, error) {
+	p, err := scanShiftRequestPeriod(r.db.QueryRow(`SELECT `+shiftRequestPeriodColumns+` FROM shift_request_periods WHERE id = $1`, id))
+	return p, notFound(err)
+}
+
+func (r *postgresShiftRequestPeriodRepository) FindByDate(date string) (models.ShiftRequestPeriod, error) {
+	p, err := scanShiftRequestPeriod(r.db.QueryRow(`
+		SELECT `+shiftRequestPeriodColumns+` FROM shift_request_periods
+		WHERE start_date <= $1 AND end_date >= $1
+		ORDER BY start_date
+		LIMIT 1
+	`, date))
+	return p, notFound(err)
+}
+
+func (r *postgresShiftRequestPeriodRepository) Create(period models.ShiftRequestPeriod) (models.ShiftRequestPeriod, error) {
+	return scanShiftRequestPeriod(r.db.QueryRow(`
+		INSERT INTO shift_request_periods (start_date, end_date, opens_at, closes_at, late_until, reminder_hours)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+shiftRequestPeriodColumns,
+		period.StartDate, period.EndDate, period.OpensAt, period.ClosesAt, period.LateUntil, pq.Array(period.ReminderHours)))
+}
+
+func (r *postgresShiftRequestPeriodRepository) Update(period models.ShiftRequestPeriod) error {
+	return execAffected(r.db, `
+		UPDATE shift_request_periods
+		SET start_date = $1,
+		    end_date = $2,
+		    opens_at = $3,
+		    closes_at = $4,
+		    late_until = $5,
+		    reminder_hours = $6,
+		    reminded_at = $7,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $8
+	`, period.StartDate, period.EndDate, period.OpensAt, period.ClosesAt, period.LateUntil,
+		pq.Array(period.ReminderHours), period.RemindedAt, period.ID)
+}
+
+func (r *postgresShiftRequestPeriodRepository) Delete(id int) error {
+	return execAffected(r.db, "DELETE FROM shift_request_periods WHERE id = $1", id)
+}
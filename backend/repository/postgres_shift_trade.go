@@ -0,0 +1,92 @@
+package repository
+
+import "shift-management-backend/models"
+
+type postgresShiftTradeRepository struct {
+	db dbtx
+}
+
+const shiftTradeSelect = `
+	SELECT t.id, t.shift_id, t.type, t.offered_by, t.accepted_by, t.swap_shift_id, t.status, t.note,
+	       t.decision_reason, t.decided_at, t.created_at, t.updated_at,
+	       o.name as offered_by_name, COALESCE(a.name, '') as accepted_by_name
+	FROM shift_trades t
+	JOIN employees o ON t.offered_by = o.id
+	LEFT JOIN employees a ON t.accepted_by = a.id
+`
+
+func scanShiftTrade(row scanner) (models.ShiftTrade, error) {
+	var t models.ShiftTrade
+	err := row.Scan(&t.ID, &t.ShiftID, &t.Type, &t.OfferedBy, &t.AcceptedBy, &t.SwapShiftID, &t.Status, &t.Note,
+		&t.DecisionReason, &t.DecidedAt, &t.CreatedAt, &t.UpdatedAt, &t.OfferedByName, &t.AcceptedByName)
+	return t, err
+}
+
+func (r *postgresShiftTradeRepository) List(filter ShiftTradeFilter) ([]models.ShiftTrade, error) {
+	var where whereBuilder
+	if filter.Status != "" {
+		where.add("t.status =", filter.Status)
+	}
+	if filter.EmployeeID != nil {
+		where.addEither([]string{"t.offered_by", "t.accepted_by"}, *filter.EmployeeID)
+	}
+
+	rows, err := r.db.Query(shiftTradeSelect+" WHERE 1=1"+where.String()+" ORDER BY t.created_at DESC, t.id DESC", where.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var trades []models.ShiftTrade
+	for rows.Next() {
+		t, err := scanShiftTrade(rows)
+		if err != nil {
+			return nil, err
+		}
+		trades = append(trades, t)
+	}
+	return trades, rows.Err()
+}
+
+func (r *postgresShiftTradeRepository) Get(id int) (models.ShiftTrade, error) {
+	t, err := scanShiftTrade(r.db.QueryRow(shiftTradeSelect+" WHERE t.id = $1", id))
+	return t, notFound(err)
+}
+
+func (r *postgresShiftTradeRepository) Lock(id int) error {
+	var locked int
+	err := r.db.QueryRow("SELECT id FROM shift_trades WHERE id = $1 FOR UPDATE", id).Scan(&locked)
+	return notFound(err)
+}
+
+func (r *postgresShiftTradeRepository) Active(shiftID int) (models.ShiftTrade, error) {
+	t, err := scanShiftTrade(r.db.QueryRow(shiftTradeSelect+" WHERE t.shift_id = $1 AND t.status IN ('open', 'accepted')", shiftID))
+	return t, notFound(err)
+}
+
+func (r *postgresShiftTradeRepository) Create(trade models.ShiftTrade) (models.ShiftTrade, error) {
+	var id int
+	err := r.db.QueryRow(`
+		INSERT INTO shift_trades (shift_id, type, offered_by, status, note)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, trade.ShiftID, trade.Type, trade.OfferedBy, trade.Status, trade.Note).Scan(&id)
+	if err != nil {
+		return models.ShiftTrade{}, err
+	}
+	return r.Get(id)
+}
+
+func (r *postgresShiftTradeRepository) Update(trade models.ShiftTrade) error {
+	return execAffected(r.db, `
+		UPDATE shift_trades
+		SET accepted_by = $1,
+		    swap_shift_id = $2,
+		    status = $3,
+		    note = $4,
+		    decision_reason = $5,
+		    decided_at = $6,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $7
+	`, trade.AcceptedBy, trade.SwapShiftID, trade.Status, trade.Note, trade.DecisionReason, trade.DecidedAt, trade.ID)
+}
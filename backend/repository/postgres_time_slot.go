@@ -0,0 +1,71 @@
+package repository
+
+import "shift-management-backend/models"
+
+type postgresTimeSlotRepository struct {
+	db dbtx
+}
+
+const timeSlotColumns = `id, day_of_week, start_time, end_time, ends_next_day, position, required_count, created_at, updated_at`
+
+func scanTimeSlot(row scanner) (models.TimeSlot, error) {
+	var ts models.TimeSlot
+	err := row.Scan(&ts.ID, &ts.DayOfWeek, &ts.StartTime, &ts.EndTime, &ts.EndsNextDay,
+		&ts.Position, &ts.RequiredCount, &ts.CreatedAt, &ts.UpdatedAt)
+	return ts, err
+}
+
+func (r *postgresTimeSlotRepository) List(dayOfWeek *int) ([]models.TimeSlot, error) {
+	var where whereBuilder
+	if dayOfWeek != nil {
+		where.add("day_of_week =", *dayOfWeek)
+	}
+
+	rows, err := r.db.Query(`SELECT `+timeSlotColumns+` FROM time_slots WHERE 1=1`+where.String()+
+		` ORDER BY day_of_week, start_time, position`, where.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var timeSlots []models.TimeSlot
+	for rows.Next() {
+		ts, err := scanTimeSlot(rows)
+		if err != nil {
+			return nil, err
+		}
+		timeSlots = append(timeSlots, ts)
+	}
+	return timeSlots, rows.Err()
+}
+
+func (r *postgresTimeSlotRepository) Get(id int) (models.TimeSlot, error) {
+	ts, err := scanTimeSlot(r.db.QueryRow(`SELECT `+timeSlotColumns+` FROM time_slots WHERE id = $1`, id))
+	return ts, notFound(err)
+}
+
+func (r *postgresTimeSlotRepository) Create(slot models.TimeSlot) (models.TimeSlot, error) {
+	return scanTimeSlot(r.db.QueryRow(`
+		INSERT INTO time_slots (day_of_week, start_time, end_time, ends_next_day, position, required_count)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+timeSlotColumns,
+		slot.DayOfWeek, slot.StartTime, slot.EndTime, slot.EndsNextDay, slot.Position, slot.RequiredCount))
+}
+
+func (r *postgresTimeSlotRepository) Update(slot models.TimeSlot) error {
+	return execAffected(r.db, `
+		UPDATE time_slots
+		SET day_of_week = $1,
+		    start_time = $2,
+		    end_time = $3,
+		    ends_next_day = $4,
+		    position = $5,
+		    required_count = $6,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $7
+	`, slot.DayOfWeek, slot.StartTime, slot.EndTime, slot.EndsNextDay, slot.Position, slot.RequiredCount, slot.ID)
+}
+
+func (r *postgresTimeSlotRepository) Delete(id int) error {
+	return execAffected(r.db, "DELETE FROM time_slots WHERE id = $1", id)
+}
@@ -0,0 +1,42 @@
+package repository
+
+import "shift-management-backend/models"
+
+type postgresUserRepository struct {
+	db dbtx
+}
+
+func (r *postgresUserRepository) FindByEmail(email string) (models.User, error) {
+	var user models.User
+	err := r.db.QueryRow(`
+		SELECT id, email, password_hash, role, name, employee_id, created_at, updated_at
+		FROM users
+		WHERE email = $1
+	`, email).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Role, &user.Name, &user.EmployeeID, &user.CreatedAt, &user.UpdatedAt)
+	return user, notFound(err)
+}
+
+func (r *postgresUserRepository) ExistsByEmail(email string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)", email).Scan(&exists)
+	return exists, err
+}
+
+func (r *postgresUserRepository) Create(user models.User) (models.User, error) {
+	var created models.User
+	err := r.db.QueryRow(`
+		INSERT INTO users (email, password_hash, role, name, employee_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, email, role, name, employee_id, created_at, updated_at
+	`, user.Email, user.PasswordHash, user.Role, user.Name, user.EmployeeID).Scan(
+		&created.ID, &created.Email, &created.Role, &created.Name, &created.EmployeeID, &created.CreatedAt, &created.UpdatedAt)
+	return created, err
+}
+
+func (r *postgresUserRepository) DeleteByEmail(email string) (int64, error) {
+	result, err := r.db.Exec("DELETE FROM users WHERE email = $1", email)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
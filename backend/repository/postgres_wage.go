@@ -0,0 +1,92 @@
+package repository
+
+import "shift-management-backend/models"
+
+type postgresWageRepository struct {
+	db dbtx
+}
+
+const wageSelect = `
+	SELECT hw.id, hw.employee_id, hw.hourly_wage, hw.effective_date,
+	       hw.created_at, hw.updated_at, e.name as employee_name
+	FROM hourly_wages hw
+	JOIN employees e ON hw.employee_id = e.id
+`
+
+func scanWage(row scanner) (models.HourlyWage, error) {
+	var hw models.HourlyWage
+	err := row.Scan(&hw.ID, &hw.EmployeeID, &hw.HourlyWage, &hw.EffectiveDate,
+		&hw.CreatedAt, &hw.UpdatedAt, &hw.EmployeeName)
+	return hw, err
+}
+
+func (r *postgresWageRepository) List(employeeID *int) ([]models.HourlyWage, error) {
+	var where whereBuilder
+	if employeeID != nil {
+		where.add("hw.employee_id =", *employeeID)
+	}
+
+	rows, err := r.db.Query(wageSelect+" WHERE 1=1"+where.String()+" ORDER BY hw.effective_date DESC, hw.created_at DESC", where.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var wages []models.HourlyWage
+	for rows.Next() {
+		hw, err := scanWage(rows)
+		if err != nil {
+			return nil, err
+		}
+		wages = append(wages, hw)
+	}
+	return wages, rows.Err()
+}
+
+func (r *postgresWageRepository) Get(id int) (models.HourlyWage, error) {
+	hw, err := scanWage(r.db.QueryRow(wageSelect+" WHERE hw.id = $1", id))
+	return hw, notFound(err)
+}
+
+func (r *postgresWageRepository) ExistsOnDate(employeeID int, effectiveDate string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS(
+			SELECT 1 FROM hourly_wages
+			WHERE employee_id = $1 AND effective_date = $2
+		)
+	`, employeeID, effectiveDate).Scan(&exists)
+	return exists, err
+}
+
+func (r *postgresWageRepository) Current(employeeID int) (models.HourlyWage, error) {
+	hw, err := scanWage(r.db.QueryRow(wageSelect+`
+		WHERE hw.employee_id = $1
+		ORDER BY hw.effective_date DESC, hw.created_at DESC
+		LIMIT 1
+	`, employeeID))
+	return hw, notFound(err)
+}
+
+func (r *postgresWageRepository) Create(wage models.HourlyWage) (models.HourlyWage, error) {
+	var created models.HourlyWage
+	err := r.db.QueryRow(`
+		INSERT INTO hourly_wages (employee_id, hourly_wage, effective_date)
+		VALUES ($1, $2, $3)
+		RETURNING id, employee_id, hourly_wage, effective_date, created_at, updated_at
+	`, wage.EmployeeID, wage.HourlyWage, wage.EffectiveDate).Scan(
+		&created.ID, &created.EmployeeID, &created.HourlyWage, &created.EffectiveDate, &created.CreatedAt, &created.UpdatedAt)
+	return created, err
+}
+
+func (r *postgresWageRepository) Update(id int, req models.UpdateHourlyWageRequest) error {
+	return execAffected(r.db, `
+		UPDATE hourly_wages
+		SET hourly_wage = $1, effective_date = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+	`, req.HourlyWage, req.EffectiveDate, id)
+}
+
+func (r *postgresWageRepository) Delete(id int) error {
+	return execAffected(r.db, "DELETE FROM hourly_wages WHERE id = $1", id)
+}
@@ -0,0 +1,288 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"shift-management-backend/models"
+)
+
+// ErrNotFound 対象のレコードが存在しない
+var ErrNotFound = errors.New("record not found")
+
+// EmployeeRepository 従業員の永続化
+type EmployeeRepository interface {
+	List() ([]models.Employee, error)
+	Get(id int) (models.Employee, error)
+	Exists(id int) (bool, error)
+	// Lock トランザクション内で従業員の行をロックし、同じ従業員への並行した割り当てを直列化する
+	Lock(id int) error
+	Create(req models.CreateEmployeeRequest) (models.Employee, error)
+	Update(id int, req models.UpdateEmployeeRequest) error
+	Delete(id int) error
+}
+
+// ShiftFilter シフト一覧の絞り込み条件（ゼロ値の項目は条件に含めない）
+type ShiftFilter struct {
+	EmployeeID  *int
+	StartDate   string
+	EndDate     string
+	NewestFirst bool // 日付の降順で並べる
+	OpenOnly    bool // 担当者が決まっていないシフトのみ
+	PatternID   *int // 繰り返しシフトから作成したシフトのみ
+}
+
+// ShiftRepository シフトの永続化
+type ShiftRepository interface {
+	List(filter ShiftFilter) ([]models.Shift, error)
+	Get(id int) (models.Shift, error)
+	Create(shift models.Shift) (models.Shift, error)
+	Update(shift models.Shift) error
+	// Lock トランザクション内でシフトの行をロックし、同じシフトへの並行した応募を直列化する
+	Lock(id int) error
+	Delete(id int) error
+	// AddHolder シフトの担当者の履歴を追加
+	AddHolder(holder models.ShiftHolder) error
+	// Holders シフトの担当者の履歴（古い順）
+	Holders(shiftID int) ([]models.ShiftHolder, error)
+}
+
+// AttendanceFilter 出退勤記録一覧の絞り込み条件（ゼロ値の項目は条件に含めない）
+type AttendanceFilter struct {
+	EmployeeID *int
+	StartDate  string
+	EndDate    string
+}
+
+// AttendanceRepository 出退勤記録の永続化
+type AttendanceRepository interface {
+	List(filter AttendanceFilter) ([]models.Attendance, error)
+	Get(id int) (models.Attendance, error)
+	Create(attendance models.Attendance) (models.Attendance, error)
+	// Update ID で指定した記録の全項目を更新
+	Update(attendance models.Attendance) error
+	Delete(id int) error
+}
+
+// ShiftRequestRepository シフト希望の永続化
+type ShiftRequestRepository interface {
+	// List employeeID が nil の場合は全従業員分を返す
+	List(employeeID *int) ([]models.ShiftRequest, error)
+	Get(id int) (models.ShiftRequest, error)
+	Create(request models.ShiftRequest) (models.ShiftRequest, error)
+	// Update ID で指定したシフト希望の全項目を更新
+	Update(request models.ShiftRequest) error
+	Delete(id int) error
+}
+
+// DesiredHoursRepository 従業員が希望する勤務時間の永続化
+type DesiredHoursRepository interface {
+	// List 従業員ID順に返す
+	List() ([]models.DesiredHours, error)
+	GetByEmployee(employeeID int) (models.DesiredHours, error)
+	// Save 従業員の希望する勤務時間を作成または更新する
+	Save(hours models.DesiredHours) (models.DesiredHours, error)
+	// Delete 従業員の希望する勤務時間を削除
+	Delete(employeeID int) error
+}
+
+// AvailabilityProfileRepository 従業員の勤務可能な時間帯の永続化
+type AvailabilityProfileRepository interface {
+	// List 従業員ID・適用開始日の順に返す（時間帯は曜日・開始時刻の順）。employeeID が nil の場合は全従業員分を返す
+	List(employeeID *int) ([]models.AvailabilityProfile, error)
+	Get(id int) (models.AvailabilityProfile, error)
+	// Create 勤務可能な時間帯を時間帯とあわせて作成
+	Create(profile models.AvailabilityProfile) (models.AvailabilityProfile, error)
+	// Update ID で指定した勤務可能な時間帯の全項目（時間帯を含む）を更新
+	Update(profile models.AvailabilityProfile) error
+	Delete(id int) error
+}
+
+// WageRepository 時給設定の永続化
+type WageRepository interface {
+	// List 適用日の新しい順に返す。employeeID が nil の場合は全従業員分を返す
+	List(employeeID *int) ([]models.HourlyWage, error)
+	Get(id int) (models.HourlyWage, error)
+	// ExistsOnDate 指定した従業員の指定適用日の設定が存在するか
+	ExistsOnDate(employeeID int, effectiveDate string) (bool, error)
+	// Current 最新の適用日の設定を取得
+	Current(employeeID int) (models.HourlyWage, error)
+	Create(wage models.HourlyWage) (models.HourlyWage, error)
+	Update(id int, req models.UpdateHourlyWageRequest) error
+	Delete(id int) error
+}
+
+// TimeSlotRepository 時間帯設定の永続化
+type TimeSlotRepository interface {
+	// List dayOfWeek が nil の場合は全曜日分を返す
+	List(dayOfWeek *int) ([]models.TimeSlot, error)
+	Get(id int) (models.TimeSlot, error)
+	Create(slot models.TimeSlot) (models.TimeSlot, error)
+	// Update ID で指定した時間帯設定の全項目を更新
+	Update(slot models.TimeSlot) error
+	Delete(id int) error
+}
+
+// PermissionRepository 権限設定の永続化
+type PermissionRepository interface {
+	List() ([]models.EmployeePermission, error)
+	GetByEmployee(employeeID int) (models.EmployeePermission, error)
+	// Save 従業員の権限設定を作成または更新する。新規作成した場合は created が true
+	Save(req models.CreatePermissionRequest) (permission models.EmployeePermission, created bool, err error)
+	Delete(id int) error
+}
+
+// UserRepository ログインユーザーの永続化
+type UserRepository interface {
+	FindByEmail(email string) (models.User, error)
+	ExistsByEmail(email string) (bool, error)
+	Create(user models.User) (models.User, error)
+	DeleteByEmail(email string) (int64, error)
+}
+
+// GanttSettingsRepository ガントチャート設定の永続化
+type GanttSettingsRepository interface {
+	Get() (models.GanttSettings, error)
+	// Replace 既存の設定を置き換える
+	Replace(startHour, endHour int) (models.GanttSettings, error)
+}
+
+// ScheduleJobRepository 自動シフト作成ジョブの永続化
+type ScheduleJobRepository interface {
+	// List 作成日時の新しい順に返す
+	List() ([]models.ScheduleJob, error)
+	Get(id int) (models.ScheduleJob, error)
+	// Lock トランザクション内でジョブの行をロックし、同じ作成案の並行した反映を直列化する
+	Lock(id int) error
+	Create(job models.ScheduleJob) (models.ScheduleJob, error)
+	// Update ステータス・結果・エラー・完了日時を更新（リースの期限は変更しない）
+	Update(job models.ScheduleJob) error
+	// RenewLease 待機中・実行中のジョブのリースの期限を延長する（完了したジョブは変更しない）
+	RenewLease(id int, expiresAt time.Time) error
+	// FailExpired リースの期限が now より前の待機中・実行中のジョブをまとめて失敗にし、件数を返す
+	FailExpired(now time.Time, message string) (int, error)
+}
+
+// SchedulePeriodRepository シフト期間と公開時のスナップショットの永続化
+type SchedulePeriodRepository interface {
+	// List 開始日の新しい順に返す
+	List() ([]models.SchedulePeriod, error)
+	// ListOverlapping startDate〜endDate と重なる期間を開始日の新しい順に返す（空の日付は制限なし）
+	ListOverlapping(startDate, endDate string) ([]models.SchedulePeriod, error)
+	Get(id int) (models.SchedulePeriod, error)
+	Create(period models.SchedulePeriod) (models.SchedulePeriod, error)
+	// Update ステータスと公開情報を更新（期間は変更しない）
+	Update(period models.SchedulePeriod) error
+	// Delete 期間とスナップショットを削除する（シフトは削除しない）
+	Delete(id int) error
+	CreateSnapshot(snapshot models.SchedulePeriodSnapshot) (models.SchedulePeriodSnapshot, error)
+	// Snapshot 指定した版のスナップショットをシフトとあわせて取得
+	Snapshot(periodID, version int) (models.SchedulePeriodSnapshot, error)
+	// ListSnapshots 版の新しい順に返す（シフトは含めない）
+	ListSnapshots(periodID int) ([]models.SchedulePeriodSnapshot, error)
+}
+
+// ShiftTradeFilter シフト交代一覧の絞り込み条件（ゼロ値の項目は条件に含めない）
+type ShiftTradeFilter struct {
+	Status string
+	// EmployeeID 募集した、または引き受けた従業員
+	EmployeeID *int
+}
+
+// ShiftTradeRepository シフト交代の永続化
+type ShiftTradeRepository interface {
+	// List 作成日時の新しい順に返す
+	List(filter ShiftTradeFilter) ([]models.ShiftTrade, error)
+	Get(id int) (models.ShiftTrade, error)
+	// Lock トランザクション内で募集の行をロックし、同じ募集への並行した引き受け・承認・取り下げを直列化する
+	Lock(id int) error
+	// Active シフトの進行中（募集中・承認待ち）の募集を取得
+	Active(shiftID int) (models.ShiftTrade, error)
+	Create(trade models.ShiftTrade) (models.ShiftTrade, error)
+	// Update ID で指定した募集の全項目を更新
+	Update(trade models.ShiftTrade) error
+}
+
+// OpenShiftClaimFilter 募集中のシフトへの応募一覧の絞り込み条件（ゼロ値の項目は条件に含めない）
+type OpenShiftClaimFilter struct {
+	ShiftID    *int
+	EmployeeID *int
+	Status     string
+}
+
+// OpenShiftClaimRepository 募集中のシフトへの応募の永続化
+type OpenShiftClaimRepository interface {
+	// List 作成日時の古い順（先着順）に返す
+	List(filter OpenShiftClaimFilter) ([]models.OpenShiftClaim, error)
+	Get(id int) (models.OpenShiftClaim, error)
+	Create(claim models.OpenShiftClaim) (models.OpenShiftClaim, error)
+	// Update ステータスと判断の内容を更新
+	Update(claim models.OpenShiftClaim) error
+}
+
+// ShiftPatternRepository 繰り返しシフトの永続化
+type ShiftPatternRepository interface {
+	// List 開始日の順に返す（employeeID を指定した場合はその従業員のみ）
+	List(employeeID *int) ([]models.ShiftPattern, error)
+	Get(id int) (models.ShiftPattern, error)
+	Create(pattern models.ShiftPattern) (models.ShiftPattern, error)
+	// Update ID で指定した定義の全項目を更新
+	Update(pattern models.ShiftPattern) error
+	// Delete 定義を削除する（作成済みのシフトは残し、繰り返しから外す）
+	Delete(id int) error
+}
+
+// ScheduleTemplateRepository シフトテンプレートの永続化
+type ScheduleTemplateRepository interface {
+	// List 名前の順に返す（シフトは含めず件数のみ）
+	List() ([]models.ScheduleTemplate, error)
+	// Get シフトとあわせて取得（シフトは曜日・開始時刻の順）
+	Get(id int) (models.ScheduleTemplate, error)
+	// Create テンプレートをシフトとあわせて作成
+	Create(template models.ScheduleTemplate) (models.ScheduleTemplate, error)
+	Delete(id int) error
+}
+
+// ShiftRequestPeriodRepository シフト希望の提出期間の永続化
+type ShiftRequestPeriodRepository interface {
+	// List 対象の日付の新しい順に返す
+	List() ([]models.ShiftRequestPeriod, error)
+	Get(id int) (models.ShiftRequestPeriod, error)
+	// FindByDate 日付を対象に含む提出期間を返す（ない場合は ErrNotFound）
+	FindByDate(date string) (models.ShiftRequestPeriod, error)
+	Create(period models.ShiftRequestPeriod) (models.ShiftRequestPeriod, error)
+	// Update ID で指定した提出期間の全項目を更新
+	Update(period models.ShiftRequestPeriod) error
+	// Delete 提出期間を削除する（提出済みのシフト希望は残し、提出期間から外す）
+	Delete(id int) error
+}
+
+// Repositories ハンドラーが利用するリポジトリ一式
+type Repositories struct {
+	Employees       EmployeeRepository
+	Shifts          ShiftRepository
+	Attendance      AttendanceRepository
+	ShiftRequests   ShiftRequestRepository
+	Wages           WageRepository
+	TimeSlots       TimeSlotRepository
+	Permissions     PermissionRepository
+	Users           UserRepository
+	GanttSettings   GanttSettingsRepository
+	ScheduleJobs    ScheduleJobRepository
+	Periods         SchedulePeriodRepository
+	ShiftTrades     ShiftTradeRepository
+	OpenShiftClaims OpenShiftClaimRepository
+	ShiftPatterns   ShiftPatternRepository
+	Templates       ScheduleTemplateRepository
+	RequestPeriods  ShiftRequestPeriodRepository
+	DesiredHours    DesiredHoursRepository
+	Availability    AvailabilityProfileRepository
+
+	inTx func(fn func(r *Repositories) error) error
+}
+
+// InTx fn 内のリポジトリ操作を1つのトランザクションで実行する
+// fn がエラーを返した場合はロールバックする
+func (r *Repositories) InTx(fn func(r *Repositories) error) error {
+	return r.inTx(fn)
+}
@@ -0,0 +1,648 @@
+// Package scheduler 時間帯設定とシフト希望からシフトの作成案を作る
+//
+// 時間帯（曜日ごとの必要人数）を開始時刻順に1つずつ埋めていく貪欲法で、
+// 同じ入力と Seed からは常に同じ作成案を返す。
+// 「勤務したい」「終日勤務できる」希望のある従業員を「必要なら勤務できる」希望の従業員より優先し、
+// 「勤務できない」希望のある日時には割り当てない。
+package scheduler
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"shift-management-backend/models"
+)
+
+// Input 作成案の入力
+type Input struct {
+	// StartDate, EndDate 対象期間（両端を含む）
+	StartDate time.Time
+	EndDate   time.Time
+
+	TimeSlots []models.TimeSlot
+	Requests  []models.ShiftRequest
+	Employees []models.Employee
+	// DesiredHours 従業員が希望する勤務時間（超える場合は候補者の順位を下げる）
+	DesiredHours []models.DesiredHours
+	// Availability 従業員の勤務可能な時間帯
+	// 勤務できる希望（勤務したい・必要なら勤務できる・終日勤務できる）がない日は、その曜日の時間帯を希望として扱う
+	Availability []models.AvailabilityProfile
+	// Existing 既存のシフト
+	// 週・月の勤務時間と休息時間を判定するため、対象期間を含む週全体・月全体と前後1日分を渡す
+	Existing []models.Shift
+	// HourlyWage 指定日の従業員の時給
+	HourlyWage func(employeeID int, date string) int
+
+	// MaxWeeklyHours 1週間（日曜始まり）の勤務時間（休憩を除く）の上限
+	MaxWeeklyHours int
+	// MinRest 勤務日が異なるシフトの間に必要な休息時間（同じ日の分割シフトには適用しない）
+	MinRest time.Duration
+	// Seed 優先度が同じ候補者の並び順を決める乱数のシード
+	Seed int64
+}
+
+// block 従業員の勤務区間（既存シフトまたは作成案）
+type block struct {
+	date      string // 勤務日（"2006-01-02"）
+	span      models.Span
+	breakTime int                   // 休憩時間（分）
+	parts     []models.PositionSpan // ポジションごとの区間
+	proposed  bool
+}
+
+// netHours 休憩を除く勤務時間
+func (b *block) netHours() float64 {
+	return b.span.Hours() - float64(b.breakTime)/60
+}
+
+// シフト希望の優先度（小さいほど優先）
+const (
+	rankPreferred = iota // 勤務したい・終日勤務できる
+	rankAvailable        // 勤務可能な時間帯（シフト希望がない日）
+	rankIfNeeded         // 必要なら勤務できる
+)
+
+// window シフト希望の区間
+type window struct {
+	date string // 希望の日付（"2006-01-02"）
+	span models.Span
+	// allDay 1日全体の希望（その日に始まる時間帯すべてを対象にする）
+	allDay bool
+	rank   int
+}
+
+// contains 時間帯全体が希望の区間に含まれるか
+func (w window) contains(inst slotInstance) bool {
+	if w.allDay {
+		return w.date == inst.date
+	}
+	return !w.span.Start.After(inst.span.Start) && !w.span.End.Before(inst.span.End)
+}
+
+// overlaps 時間帯が希望の区間と重なるか
+func (w window) overlaps(inst slotInstance) bool {
+	if w.allDay && w.date == inst.date {
+		return true
+	}
+	return w.span.Overlaps(inst.span)
+}
+
+// worker 従業員ごとの割り当て状況
+type worker struct {
+	employee models.Employee
+	// requests 勤務できる希望（シフト希望がない日の勤務可能な時間帯を含む）、unavailable 勤務できない希望
+	requests    []window
+	unavailable []window
+	desired     *models.DesiredHours
+	blocks      []*block
+	// hours 対象期間内の勤務時間（休憩を除く）
+	hours float64
+}
+
+// slotInstance 日付に適用した時間帯
+type slotInstance struct {
+	date string
+	slot models.TimeSlot
+	span models.Span
+}
+
+// Generate 入力から作成案を作る
+func Generate(in Input) models.ScheduleResult {
+	startKey := in.StartDate.Format(models.DateLayout)
+	endKey := in.EndDate.Format(models.DateLayout)
+	inPeriod := func(date string) bool {
+		return date >= startKey && date <= endKey
+	}
+
+	workers := make(map[int]*worker)
+	var order []*worker
+	employees := append([]models.Employee(nil), in.Employees...)
+	sort.Slice(employees, func(i, j int) bool {
+		return employees[i].ID < employees[j].ID
+	})
+	for _, e := range employees {
+		w := &worker{employee: e}
+		workers[e.ID] = w
+		order = append(order, w)
+	}
+	// 一覧にない（削除済みの）従業員の既存シフトも配置人数には数える
+	workerOf := func(employeeID int) *worker {
+		w, ok := workers[employeeID]
+		if !ok {
+			w = &worker{employee: models.Employee{ID: employeeID}}
+			workers[employeeID] = w
+		}
+		return w
+	}
+
+	for _, shift := range in.Existing {
+		// 担当者が決まっていないシフトは誰の勤務時間にも数えない
+		if shift.IsOpen() {
+			continue
+		}
+		span, err := shift.Span()
+		if err != nil {
+			continue
+		}
+		parts, err := shift.PositionSpans()
+		if err != nil {
+			continue
+		}
+		date := dateKey(shift.Date)
+		w := workerOf(shift.EmployeeID)
+		b := &block{date: date, span: span, breakTime: shift.BreakTime, parts: parts}
+		w.blocks = append(w.blocks, b)
+		if inPeriod(date) {
+			w.hours += b.netHours()
+		}
+	}
+	for _, req := range in.Requests {
+		w, ok := workers[req.EmployeeID]
+		// 却下したシフト希望は考慮しない
+		if !ok || req.Status == models.ShiftRequestRejected {
+			continue
+		}
+		spans, err := req.Spans()
+		if err != nil {
+			continue
+		}
+		for _, span := range spans {
+			win := window{date: dateKey(req.Date), span: span, allDay: req.AllDay(), rank: rankPreferred}
+			switch req.Availability {
+			case models.ShiftRequestUnavailable:
+				w.unavailable = append(w.unavailable, win)
+				continue
+			case models.ShiftRequestIfNeeded:
+				win.rank = rankIfNeeded
+			}
+			w.requests = append(w.requests, win)
+		}
+	}
+	// 日付をまたぐ時間帯を考慮して、対象期間の前日から勤務可能な時間帯を適用する
+	requested := make(map[int]map[string]bool)
+	for _, w := range workers {
+		for _, win := range w.requests {
+			if requested[w.employee.ID] == nil {
+				requested[w.employee.ID] = make(map[string]bool)
+			}
+			requested[w.employee.ID][win.date] = true
+		}
+	}
+	profiles := make(map[int][]models.AvailabilityProfile)
+	for _, p := range in.Availability {
+		profiles[p.EmployeeID] = append(profiles[p.EmployeeID], p)
+	}
+	for employeeID, list := range profiles {
+		w, ok := workers[employeeID]
+		if !ok {
+			continue
+		}
+		for day := in.StartDate.AddDate(0, 0, -1); !day.After(in.EndDate); day = day.AddDate(0, 0, 1) {
+			date := day.Format(models.DateLayout)
+			if requested[employeeID][date] {
+				continue
+			}
+			profile, ok := models.ActiveProfile(list, day)
+			if !ok {
+				continue
+			}
+			spans, err := profile.Spans(day)
+			if err != nil {
+				continue
+			}
+			for _, span := range spans {
+				w.requests = append(w.requests, window{date: date, span: span, rank: rankAvailable})
+			}
+		}
+	}
+	for i := range in.DesiredHours {
+		if w, ok := workers[in.DesiredHours[i].EmployeeID]; ok {
+			w.desired = &in.DesiredHours[i]
+		}
+	}
+
+	rng := rand.New(rand.NewSource(in.Seed))
+	result := models.ScheduleResult{
+		Shifts:    []models.Shift{},
+		Unfilled:  []models.UnfilledSlot{},
+		Employees: []models.EmployeeScheduleSummary{},
+	}
+
+	for _, inst := range slotInstances(in.TimeSlots, in.StartDate, in.EndDate) {
+		// 既に時間帯を担当している従業員（既存シフト・作成案）
+		covered := make(map[int]bool)
+		for id, w := range workers {
+			if w.covers(inst) {
+				covered[id] = true
+			}
+		}
+		need := inst.slot.RequiredCount - len(covered)
+		if need <= 0 {
+			continue
+		}
+
+		var candidates []*worker
+		rejected := make(map[string]int)
+		for _, w := range order {
+			if covered[w.employee.ID] {
+				continue
+			}
+			if reason := w.reject(inst, in); reason != "" {
+				rejected[reason]++
+				continue
+			}
+			candidates = append(candidates, w)
+		}
+
+		// 「勤務したい」希望の従業員、勤務可能な時間帯の従業員、「必要なら勤務できる」希望の従業員の順に優先し、
+		// 同じ場合は希望する勤務時間を超えない従業員、勤務時間の少ない従業員の順に優先する（直前・直後の作成案とつながる場合はその分を差し引く）。
+		// それも同じ場合は時給の低い従業員を優先し、時給も同じ場合は乱数で決めた順にする
+		rng.Shuffle(len(candidates), func(i, j int) {
+			candidates[i], candidates[j] = candidates[j], candidates[i]
+		})
+		sort.SliceStable(candidates, func(i, j int) bool {
+			a, b := candidates[i], candidates[j]
+			if ra, rb := a.requestRank(inst), b.requestRank(inst); ra != rb {
+				return ra < rb
+			}
+			if ea, eb := a.exceedsDesired(inst), b.exceedsDesired(inst); ea != eb {
+				return !ea
+			}
+			if pa, pb := a.priority(inst), b.priority(inst); pa != pb {
+				return pa < pb
+			}
+			return in.HourlyWage(a.employee.ID, inst.date) < in.HourlyWage(b.employee.ID, inst.date)
+		})
+
+		assigned := 0
+		for _, w := range candidates {
+			if assigned == need {
+				break
+			}
+			w.assign(inst, inPeriod(inst.date))
+			assigned++
+		}
+
+		if assigned < need {
+			result.Unfilled = append(result.Unfilled, unfilledSlot(inst, len(covered)+assigned, rejected))
+		}
+	}
+
+	for _, w := range order {
+		summary := models.EmployeeScheduleSummary{
+			EmployeeID:   w.employee.ID,
+			EmployeeName: w.employee.Name,
+		}
+		proposed := w.proposedBlocks()
+		for _, b := range proposed {
+			shift := b.shift(w.employee)
+			cost := int(b.netHours() * float64(in.HourlyWage(w.employee.ID, b.date)))
+			result.Shifts = append(result.Shifts, shift)
+			summary.ShiftCount++
+			summary.Hours += b.netHours()
+			summary.EstimatedCost += cost
+		}
+		result.TotalHours += summary.Hours
+		result.EstimatedCost += summary.EstimatedCost
+		result.Employees = append(result.Employees, summary)
+	}
+	sort.SliceStable(result.Shifts, func(i, j int) bool {
+		a, b := result.Shifts[i], result.Shifts[j]
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.StartTime != b.StartTime {
+			return a.StartTime < b.StartTime
+		}
+		return a.EmployeeID < b.EmployeeID
+	})
+
+	return result
+}
+
+// slotInstances 期間内の各日に時間帯を適用し、開始時刻順に並べる
+func slotInstances(timeSlots []models.TimeSlot, startDate, endDate time.Time) []slotInstance {
+	var instances []slotInstance
+	for day := startDate; !day.After(endDate); day = day.AddDate(0, 0, 1) {
+		date := day.Format(models.DateLayout)
+		for _, ts := range timeSlots {
+			if ts.DayOfWeek != int(day.Weekday()) || ts.RequiredCount <= 0 {
+				continue
+			}
+			span, err := ts.SpanOn(date)
+			if err != nil {
+				continue
+			}
+			instances = append(instances, slotInstance{date: date, slot: ts, span: span})
+		}
+	}
+	sort.SliceStable(instances, func(i, j int) bool {
+		a, b := instances[i], instances[j]
+		if !a.span.Start.Equal(b.span.Start) {
+			return a.span.Start.Before(b.span.Start)
+		}
+		if a.slot.Position != b.slot.Position {
+			return a.slot.Position < b.slot.Position
+		}
+		return a.slot.ID < b.slot.ID
+	})
+	return instances
+}
+
+// covers 時間帯と同じポジションを担当する区間が時間帯と重なっているか
+func (w *worker) covers(inst slotInstance) bool {
+	for _, b := range w.blocks {
+		for _, part := range b.parts {
+			if part.Position == inst.slot.Position && part.Overlaps(inst.span) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// reject 時間帯に割り当てられない理由を返す（割り当てられる場合は空文字）
+func (w *worker) reject(inst slotInstance, in Input) string {
+	if !w.employee.IsQualifiedFor(inst.slot.Position) {
+		return models.UnfilledNotQualified
+	}
+
+	for _, u := range w.unavailable {
+		if u.overlaps(inst) {
+			return models.UnfilledUnavailable
+		}
+	}
+	if w.requestRank(inst) < 0 {
+		return models.UnfilledNoRequest
+	}
+
+	for _, b := range w.blocks {
+		if b.span.Overlaps(inst.span) {
+			return models.UnfilledOverlap
+		}
+	}
+
+	if in.MaxWeeklyHours > 0 {
+		week := weekStart(inst.date)
+		hours := w.addedHours(inst)
+		for _, b := range w.blocks {
+			if weekStart(b.date) == week {
+				hours += b.netHours()
+			}
+		}
+		if hours > float64(in.MaxWeeklyHours) {
+			return models.UnfilledWeeklyLimit
+		}
+	}
+
+	if in.MinRest > 0 {
+		for _, b := range w.blocks {
+			if b.date == inst.date {
+				continue
+			}
+			if !b.span.End.After(inst.span.Start) && inst.span.Start.Sub(b.span.End) < in.MinRest {
+				return models.UnfilledInsufficientRest
+			}
+			if !inst.span.End.After(b.span.Start) && b.span.Start.Sub(inst.span.End) < in.MinRest {
+				return models.UnfilledInsufficientRest
+			}
+		}
+	}
+	return ""
+}
+
+// requestRank 時間帯全体を含むシフト希望のうち最も高い優先度（含むシフト希望がない場合は -1）
+func (w *worker) requestRank(inst slotInstance) int {
+	rank := -1
+	for _, req := range w.requests {
+		if req.contains(inst) && (rank < 0 || req.rank < rank) {
+			rank = req.rank
+		}
+	}
+	return rank
+}
+
+// exceedsDesired 時間帯を割り当てると、希望する1週間または1か月の勤務時間を超えるか
+func (w *worker) exceedsDesired(inst slotInstance) bool {
+	if w.desired == nil {
+		return false
+	}
+	key := periodKey(w.desired.Unit, inst.date)
+	hours := w.addedHours(inst)
+	for _, b := range w.blocks {
+		if periodKey(w.desired.Unit, b.date) == key {
+			hours += b.netHours()
+		}
+	}
+	return hours > w.desired.Hours
+}
+
+// adjacent 時間帯の直前・直後につながる同じ日の作成案
+func (w *worker) adjacent(inst slotInstance) *block {
+	for _, b := range w.blocks {
+		if !b.proposed || b.date != inst.date {
+			continue
+		}
+		if !b.span.End.Equal(inst.span.Start) && !b.span.Start.Equal(inst.span.End) {
+			continue
+		}
+		// シフトは24時間以内で表すため、それを超える場合はつなげない
+		if b.span.Duration()+inst.span.Duration() >= 24*time.Hour {
+			continue
+		}
+		return b
+	}
+	return nil
+}
+
+// addedHours 時間帯を割り当てた場合に増える勤務時間（休憩を除く）
+func (w *worker) addedHours(inst slotInstance) float64 {
+	if b := w.adjacent(inst); b != nil {
+		merged := b.span.Duration() + inst.span.Duration()
+		return netHours(merged) - b.netHours()
+	}
+	return netHours(inst.span.Duration())
+}
+
+// priority 候補者の優先度（小さいほど優先）
+func (w *worker) priority(inst slotInstance) float64 {
+	p := w.hours
+	if w.adjacent(inst) != nil {
+		p -= inst.span.Hours()
+	}
+	return p
+}
+
+// assign 時間帯を割り当てる。直前・直後の作成案とつながる場合は1つのシフトにまとめる
+func (w *worker) assign(inst slotInstance, countHours bool) {
+	part := models.PositionSpan{Span: inst.span, Position: inst.slot.Position}
+	before := 0.0
+
+	b := w.adjacent(inst)
+	if b == nil {
+		b = &block{date: inst.date, span: inst.span, proposed: true}
+		w.blocks = append(w.blocks, b)
+	} else {
+		before = b.netHours()
+		if inst.span.Start.Before(b.span.Start) {
+			b.span.Start = inst.span.Start
+		} else {
+			b.span.End = inst.span.End
+		}
+	}
+	b.parts = append(b.parts, part)
+	sort.Slice(b.parts, func(i, j int) bool {
+		return b.parts[i].Start.Before(b.parts[j].Start)
+	})
+	b.breakTime = breakMinutes(b.span.Duration())
+
+	if countHours {
+		w.hours += b.netHours() - before
+	}
+}
+
+// proposedBlocks 作成案の勤務区間（開始時刻順）
+func (w *worker) proposedBlocks() []*block {
+	var blocks []*block
+	for _, b := range w.blocks {
+		if b.proposed {
+			blocks = append(blocks, b)
+		}
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i].span.Start.Before(blocks[j].span.Start)
+	})
+	return blocks
+}
+
+// shift 作成案の勤務区間をシフトにする
+// 最も長く担当するポジションをシフトのポジションとし、それ以外のポジションは区間として設定する
+func (b *block) shift(employee models.Employee) models.Shift {
+	day, _ := models.ParseDate(b.date)
+	shift := models.Shift{
+		EmployeeID:   employee.ID,
+		EmployeeName: employee.Name,
+		Date:         b.date,
+		StartTime:    b.span.Start.Format("15:04"),
+		EndTime:      b.span.End.Format("15:04"),
+		EndsNextDay:  !b.span.End.Before(day.AddDate(0, 0, 1)),
+		BreakTime:    b.breakTime,
+	}
+
+	durations := make(map[string]time.Duration)
+	for _, part := range b.parts {
+		durations[part.Position] += part.Duration()
+	}
+	for _, part := range b.parts {
+		if shift.Position == "" || durations[part.Position] > durations[shift.Position] ||
+			(durations[part.Position] == durations[shift.Position] && part.Position < shift.Position) {
+			shift.Position = part.Position
+		}
+	}
+	for _, part := range b.parts {
+		if part.Position == shift.Position {
+			continue
+		}
+		shift.Segments = append(shift.Segments, models.ShiftSegment{
+			StartTime: part.Start.Format("15:04"),
+			EndTime:   part.End.Format("15:04"),
+			Position:  part.Position,
+		})
+	}
+	return shift
+}
+
+// unfilledSlot 必要人数を満たせなかった時間帯とその理由
+func unfilledSlot(inst slotInstance, assigned int, rejected map[string]int) models.UnfilledSlot {
+	slot := models.UnfilledSlot{
+		Date:          inst.date,
+		TimeSlotID:    inst.slot.ID,
+		Position:      inst.slot.Position,
+		StartTime:     inst.slot.StartTime,
+		EndTime:       inst.slot.EndTime,
+		EndsNextDay:   inst.slot.EndsNextDay,
+		RequiredCount: inst.slot.RequiredCount,
+		AssignedCount: assigned,
+		Shortage:      inst.slot.RequiredCount - assigned,
+		Reasons:       []models.UnfilledReason{},
+	}
+
+	var details []string
+	for _, reason := range reasonOrder {
+		if rejected[reason] == 0 {
+			continue
+		}
+		label := models.UnfilledReasonLabels[reason]
+		slot.Reasons = append(slot.Reasons, models.UnfilledReason{Reason: reason, Label: label, Count: rejected[reason]})
+		details = append(details, fmt.Sprintf("%s %d人", label, rejected[reason]))
+	}
+
+	slot.Explanation = fmt.Sprintf("%s の%s: 必要%d人に対して%d人（%d人不足）。",
+		inst.span.String(), inst.slot.Position, slot.RequiredCount, slot.AssignedCount, slot.Shortage)
+	if len(details) == 0 {
+		slot.Explanation += "割り当てられる従業員がいません"
+	} else {
+		slot.Explanation += "割り当てられなかった理由: " + strings.Join(details, "、")
+	}
+	return slot
+}
+
+// reasonOrder 割り当てられなかった理由を判定する順
+var reasonOrder = []string{
+	models.UnfilledNotQualified,
+	models.UnfilledUnavailable,
+	models.UnfilledNoRequest,
+	models.UnfilledOverlap,
+	models.UnfilledWeeklyLimit,
+	models.UnfilledInsufficientRest,
+}
+
+// breakMinutes 勤務時間に応じた休憩時間（6時間超は45分、8時間超は60分）
+func breakMinutes(d time.Duration) int {
+	switch {
+	case d > 8*time.Hour:
+		return 60
+	case d > 6*time.Hour:
+		return 45
+	default:
+		return 0
+	}
+}
+
+// netHours 休憩を除く勤務時間
+func netHours(d time.Duration) float64 {
+	return d.Hours() - float64(breakMinutes(d))/60
+}
+
+// weekStart 日付を含む週（日曜始まり）の初日
+func weekStart(date string) string {
+	day, err := models.ParseDate(date)
+	if err != nil {
+		return date
+	}
+	return day.AddDate(0, 0, -int(day.Weekday())).Format(models.DateLayout)
+}
+
+// periodKey 希望する勤務時間の単位（週または月）で日付が属する期間（週の初日または "2006-01"）
+func periodKey(unit, date string) string {
+	if unit != models.DesiredHoursMonth {
+		return weekStart(date)
+	}
+	day, err := models.ParseDate(date)
+	if err != nil {
+		return date
+	}
+	return day.Format("2006-01")
+}
+
+// dateKey 日付を "2006-01-02" 形式にする
+func dateKey(date string) string {
+	day, err := models.ParseDate(date)
+	if err != nil {
+		return date
+	}
+	return day.Format(models.DateLayout)
+}
@@ -0,0 +1,232 @@
+package scheduler
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"shift-management-backend/models"
+)
+
+// date テスト用に日付を作る
+func date(t *testing.T, s string) time.Time {
+	t.Helper()
+	d, err := models.ParseDate(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+// allDay 期間内の毎日について終日勤務できる希望を作る
+func allDay(employeeID int, start, end time.Time) []models.ShiftRequest {
+	var requests []models.ShiftRequest
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		requests = append(requests, models.ShiftRequest{
+			EmployeeID:   employeeID,
+			Date:         day.Format(models.DateLayout),
+			Availability: models.ShiftRequestAllDay,
+			Status:       models.ShiftRequestSubmitted,
+		})
+	}
+	return requests
+}
+
+// everyDay すべての曜日の時間帯を作る
+func everyDay(id int, start, end string, endsNextDay bool, position string, required int) []models.TimeSlot {
+	slots := make([]models.TimeSlot, 7)
+	for d := range slots {
+		slots[d] = models.TimeSlot{
+			ID:            id*10 + d,
+			DayOfWeek:     d,
+			StartTime:     start,
+			EndTime:       end,
+			EndsNextDay:   endsNextDay,
+			Position:      position,
+			RequiredCount: required,
+		}
+	}
+	return slots
+}
+
+// flatWage すべての従業員・日付で同じ時給
+func flatWage(int, string) int {
+	return 1000
+}
+
+// hasReason 割り当てられなかった理由に reason が含まれるか
+func hasReason(slot models.UnfilledSlot, reason string) bool {
+	for _, r := range slot.Reasons {
+		if r.Reason == reason && r.Count > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func TestGenerateIsDeterministic(t *testing.T) {
+	start, end := date(t, "2030-10-06"), date(t, "2030-10-19")
+	in := Input{
+		StartDate:      start,
+		EndDate:        end,
+		TimeSlots:      append(everyDay(1, "09:00", "13:00", false, "ホール", 2), everyDay(2, "13:00", "17:00", false, "キッチン", 1)...),
+		HourlyWage:     flatWage,
+		MaxWeeklyHours: 40,
+		MinRest:        11 * time.Hour,
+		Seed:           42,
+	}
+	// 優先度がすべて同じ従業員を並べ、乱数で決める順序に依存させる
+	for id := 1; id <= 6; id++ {
+		in.Employees = append(in.Employees, models.Employee{ID: id, Name: "従業員", Positions: []string{"ホール", "キッチン"}})
+		in.Requests = append(in.Requests, allDay(id, start, end)...)
+	}
+
+	first := Generate(in)
+	if len(first.Shifts) == 0 {
+		t.Fatal("作成案にシフトがありません")
+	}
+	for i := 0; i < 5; i++ {
+		if got := Generate(in); !reflect.DeepEqual(got, first) {
+			t.Fatalf("同じ入力と Seed から異なる作成案が作られました（%d回目）", i+2)
+		}
+	}
+}
+
+func TestGenerateQualifications(t *testing.T) {
+	day := date(t, "2030-10-07")
+	in := Input{
+		StartDate: day,
+		EndDate:   day,
+		TimeSlots: everyDay(1, "10:00", "14:00", false, "キッチン", 2),
+		Employees: []models.Employee{
+			{ID: 1, Name: "ホール担当", Positions: []string{"ホール"}},
+			{ID: 2, Name: "キッチン担当", Positions: []string{"キッチン"}},
+		},
+		Requests:   append(allDay(1, day, day), allDay(2, day, day)...),
+		HourlyWage: flatWage,
+		Seed:       1,
+	}
+
+	res := Generate(in)
+	if len(res.Shifts) != 1 || res.Shifts[0].EmployeeID != 2 || res.Shifts[0].Position != "キッチン" {
+		t.Fatalf("キッチンを担当できる従業員だけが割り当てられるはずです: %+v", res.Shifts)
+	}
+	if len(res.Unfilled) != 1 {
+		t.Fatalf("不足する時間帯が1件あるはずです: %+v", res.Unfilled)
+	}
+	if slot := res.Unfilled[0]; slot.Shortage != 1 || !hasReason(slot, models.UnfilledNotQualified) {
+		t.Errorf("ポジションを担当できない従業員が理由に含まれるはずです: %+v", slot)
+	}
+}
+
+func TestGenerateMaxWeeklyHours(t *testing.T) {
+	// 2030-10-06 は日曜日
+	start, end := date(t, "2030-10-06"), date(t, "2030-10-12")
+	in := Input{
+		StartDate:      start,
+		EndDate:        end,
+		TimeSlots:      everyDay(1, "09:00", "15:00", false, "ホール", 1),
+		Employees:      []models.Employee{{ID: 1, Name: "A", Positions: []string{"ホール"}}},
+		Requests:       allDay(1, start, end),
+		HourlyWage:     flatWage,
+		MaxWeeklyHours: 20,
+		Seed:           1,
+	}
+
+	res := Generate(in)
+	if res.TotalHours > 20 {
+		t.Errorf("週の勤務時間が上限を超えています: %v時間", res.TotalHours)
+	}
+	if len(res.Shifts) != 3 {
+		t.Errorf("6時間のシフトは週3回まで割り当てられるはずです: %d件", len(res.Shifts))
+	}
+	if len(res.Unfilled) != 4 {
+		t.Fatalf("不足する時間帯が4件あるはずです: %d件", len(res.Unfilled))
+	}
+	for _, slot := range res.Unfilled {
+		if !hasReason(slot, models.UnfilledWeeklyLimit) {
+			t.Errorf("%s の時間帯の理由に週の勤務時間の上限が含まれていません: %+v", slot.Date, slot.Reasons)
+		}
+	}
+}
+
+func TestGenerateMinRest(t *testing.T) {
+	// 月曜の夜と火曜の朝の時間帯の間は7時間しか空かない
+	start, end := date(t, "2030-10-07"), date(t, "2030-10-08")
+	in := Input{
+		StartDate: start,
+		EndDate:   end,
+		TimeSlots: []models.TimeSlot{
+			{ID: 1, DayOfWeek: 1, StartTime: "17:00", EndTime: "23:00", Position: "ホール", RequiredCount: 1},
+			{ID: 2, DayOfWeek: 2, StartTime: "06:00", EndTime: "10:00", Position: "ホール", RequiredCount: 1},
+		},
+		Employees:  []models.Employee{{ID: 1, Name: "A", Positions: []string{"ホール"}}},
+		Requests:   allDay(1, start, end),
+		HourlyWage: flatWage,
+		MinRest:    11 * time.Hour,
+		Seed:       1,
+	}
+
+	res := Generate(in)
+	if len(res.Shifts) != 1 || res.Shifts[0].Date != "2030-10-07" {
+		t.Fatalf("月曜の夜の時間帯だけが割り当てられるはずです: %+v", res.Shifts)
+	}
+	if len(res.Unfilled) != 1 || res.Unfilled[0].Date != "2030-10-08" {
+		t.Fatalf("火曜の朝の時間帯が不足するはずです: %+v", res.Unfilled)
+	}
+	if !hasReason(res.Unfilled[0], models.UnfilledInsufficientRest) {
+		t.Errorf("理由に休息時間が含まれていません: %+v", res.Unfilled[0].Reasons)
+	}
+
+	// 休息時間を確認しない場合は両方とも割り当てる
+	in.MinRest = 0
+	if res := Generate(in); len(res.Shifts) != 2 || len(res.Unfilled) != 0 {
+		t.Errorf("休息時間を確認しない場合は両方の時間帯に割り当てるはずです: %+v", res.Shifts)
+	}
+}
+
+func TestGenerateUnfilledExplanations(t *testing.T) {
+	day := date(t, "2030-10-07")
+	in := Input{
+		StartDate: day,
+		EndDate:   day,
+		TimeSlots: everyDay(1, "10:00", "14:00", false, "ホール", 3),
+		Employees: []models.Employee{
+			{ID: 1, Name: "A", Positions: []string{"ホール"}},
+			{ID: 2, Name: "B", Positions: []string{"ホール"}},
+			{ID: 3, Name: "C", Positions: []string{"キッチン"}},
+		},
+		Requests: append(allDay(1, day, day), models.ShiftRequest{
+			EmployeeID:   2,
+			Date:         "2030-10-07",
+			Availability: models.ShiftRequestUnavailable,
+			Status:       models.ShiftRequestSubmitted,
+		}),
+		HourlyWage: flatWage,
+		Seed:       1,
+	}
+
+	res := Generate(in)
+	if len(res.Unfilled) != 1 {
+		t.Fatalf("不足する時間帯が1件あるはずです: %+v", res.Unfilled)
+	}
+	slot := res.Unfilled[0]
+	if slot.RequiredCount != 3 || slot.AssignedCount != 1 || slot.Shortage != 2 {
+		t.Errorf("必要人数・配置人数・不足人数が正しくありません: %+v", slot)
+	}
+	for _, reason := range []string{models.UnfilledUnavailable, models.UnfilledNotQualified} {
+		if !hasReason(slot, reason) {
+			t.Errorf("理由に %s が含まれていません: %+v", reason, slot.Reasons)
+		}
+	}
+	if slot.Explanation == "" {
+		t.Error("不足する時間帯に説明がありません")
+	}
+
+	// 割り当てられる従業員がいない場合もその旨を説明する
+	in.Employees, in.Requests = nil, nil
+	res = Generate(in)
+	if len(res.Unfilled) != 1 || len(res.Unfilled[0].Reasons) != 0 || res.Unfilled[0].Explanation == "" {
+		t.Errorf("従業員がいない場合も説明が必要です: %+v", res.Unfilled)
+	}
+}
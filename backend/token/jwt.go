@@ -0,0 +1,116 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"shift-management-backend/models"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidToken アクセストークンが不正または期限切れ
+var ErrInvalidToken = errors.New("invalid access token")
+
+// DefaultAccessTokenTTL アクセストークンのデフォルト有効期間
+const DefaultAccessTokenTTL = 15 * time.Minute
+
+// Claims アクセストークンに含めるクレーム
+type Claims struct {
+	UserID     int    `json:"uid"`
+	Role       string `json:"role"`
+	EmployeeID *int   `json:"employee_id,omitempty"`
+	SessionID  int    `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+// Signer アクセストークンの署名・検証を行う
+// 複数の鍵を kid で管理し、署名は ActiveKeyID の鍵で行う
+// 検証は kid ヘッダーに対応する鍵で行うため、鍵のローテーション中も古いトークンを受け付けられる
+type Signer struct {
+	keys        map[string][]byte
+	activeKeyID string
+	ttl         time.Duration
+	issuer      string
+}
+
+// NewSigner 署名鍵のセットから Signer を作成
+func NewSigner(keys map[string][]byte, activeKeyID string, ttl time.Duration) (*Signer, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("署名鍵が設定されていません")
+	}
+	if _, ok := keys[activeKeyID]; !ok {
+		return nil, fmt.Errorf("有効な署名鍵 %q が見つかりません", activeKeyID)
+	}
+	for kid, key := range keys {
+		if len(key) < 32 {
+			return nil, fmt.Errorf("署名鍵 %q は32バイト以上必要です", kid)
+		}
+	}
+	if ttl <= 0 {
+		ttl = DefaultAccessTokenTTL
+	}
+
+	return &Signer{
+		keys:        keys,
+		activeKeyID: activeKeyID,
+		ttl:         ttl,
+		issuer:      "shift-management",
+	}, nil
+}
+
+// TTL アクセストークンの有効期間
+func (s *Signer) TTL() time.Duration {
+	return s.ttl
+}
+
+// Issue ユーザーとセッションに対するアクセストークンを発行
+func (s *Signer) Issue(user models.User, sessionID int) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.ttl)
+	claims := Claims{
+		UserID:     user.ID,
+		Role:       user.Role,
+		EmployeeID: user.EmployeeID,
+		SessionID:  sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.issuer,
+			Subject:   strconv.Itoa(user.ID),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	t.Header["kid"] = s.activeKeyID
+
+	signed, err := t.SignedString(s.keys[s.activeKeyID])
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("アクセストークン署名エラー: %v", err)
+	}
+	return signed, expiresAt, nil
+}
+
+// Parse アクセストークンを検証してクレームを取り出す
+func (s *Signer) Parse(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, ok := s.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown kid %q", kid)
+		}
+		return key, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(s.issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}